@@ -63,6 +63,10 @@ type Staker struct {
 	RewardAddress ids.ShortID               `json:"rewardAddress"`
 	DelegationFee uint32                    `json:"delegationFee"`
 	Signer        *signer.ProofOfPossession `json:"signer,omitempty"`
+	// Weight is the amount of the initially staked funds staked by this
+	// staker. If no staker specifies a weight, the initially staked funds are
+	// split evenly.
+	Weight uint64 `json:"weight,omitempty"`
 }
 
 func (s Staker) Unparse(networkID uint32) (UnparsedStaker, error) {
@@ -76,6 +80,7 @@ func (s Staker) Unparse(networkID uint32) (UnparsedStaker, error) {
 		RewardAddress: avaxAddr,
 		DelegationFee: s.DelegationFee,
 		Signer:        s.Signer,
+		Weight:        s.Weight,
 	}, err
 }
 
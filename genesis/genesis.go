@@ -14,6 +14,7 @@ import (
 	"github.com/ava-labs/avalanchego/utils/formatting"
 	"github.com/ava-labs/avalanchego/utils/formatting/address"
 	"github.com/ava-labs/avalanchego/utils/json"
+	"github.com/ava-labs/avalanchego/utils/math"
 	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/vms/avm"
 	"github.com/ava-labs/avalanchego/vms/avm/fxs"
@@ -46,6 +47,8 @@ var (
 	errFutureStartTime                 = errors.New("startTime cannot be in the future")
 	errInitialStakeDurationTooLow      = errors.New("initial stake duration is too low")
 	errOverridesStandardNetworkConfig  = errors.New("overrides standard network genesis config")
+	errMissingStakerWeight             = errors.New("initial staker is missing a weight")
+	errStakerWeightsMismatch           = errors.New("initial staker weights don't sum to the initially staked funds")
 )
 
 // validateInitialStakedFunds ensures all staked
@@ -113,6 +116,56 @@ func validateInitialStakedFunds(config *Config) error {
 	return nil
 }
 
+// validateInitialStakerWeights ensures that either none of the initial stakers
+// specify a weight, or that all of them do and that the weights account for
+// exactly the initially staked funds.
+func validateInitialStakerWeights(config *Config) error {
+	if config.InitialStakers[0].Weight == 0 {
+		for _, staker := range config.InitialStakers {
+			if staker.Weight != 0 {
+				return fmt.Errorf("%w: %s", errMissingStakerWeight, config.InitialStakers[0].NodeID)
+			}
+		}
+		return nil
+	}
+
+	totalWeight := uint64(0)
+	for _, staker := range config.InitialStakers {
+		if staker.Weight == 0 {
+			return fmt.Errorf("%w: %s", errMissingStakerWeight, staker.NodeID)
+		}
+		newTotalWeight, err := math.Add64(totalWeight, staker.Weight)
+		if err != nil {
+			return err
+		}
+		totalWeight = newTotalWeight
+	}
+
+	initiallyStaked := set.Of(config.InitialStakedFunds...)
+	stakedAmount := uint64(0)
+	for _, allocation := range config.Allocations {
+		if !initiallyStaked.Contains(allocation.AVAXAddr) {
+			continue
+		}
+		for _, unlock := range allocation.UnlockSchedule {
+			newStakedAmount, err := math.Add64(stakedAmount, unlock.Amount)
+			if err != nil {
+				return err
+			}
+			stakedAmount = newStakedAmount
+		}
+	}
+	if totalWeight != stakedAmount {
+		return fmt.Errorf(
+			"%w: weights sum to %d but %d is staked",
+			errStakerWeightsMismatch,
+			totalWeight,
+			stakedAmount,
+		)
+	}
+	return nil
+}
+
 // validateConfig returns an error if the provided
 // *Config is not considered valid.
 func validateConfig(networkID uint32, config *Config, stakingCfg *StakingConfig) error {
@@ -173,6 +226,10 @@ func validateConfig(networkID uint32, config *Config, stakingCfg *StakingConfig)
 		return fmt.Errorf("initial staked funds validation failed: %w", err)
 	}
 
+	if err := validateInitialStakerWeights(config); err != nil {
+		return fmt.Errorf("initial staker weights validation failed: %w", err)
+	}
+
 	if len(config.CChainGenesis) == 0 {
 		return errNoCChainGenesis
 	}
@@ -381,7 +438,7 @@ func FromConfig(config *Config) ([]byte, ids.ID, error) {
 		}
 	}
 
-	allNodeAllocations := splitAllocations(skippedAllocations, len(config.InitialStakers))
+	allNodeAllocations := splitAllocations(skippedAllocations, stakerWeights(config.InitialStakers, skippedAllocations))
 	endStakingTime := genesisTime.Add(time.Duration(config.InitialStakeDuration) * time.Second)
 	stakingOffset := time.Duration(0)
 	for i, staker := range config.InitialStakers {
@@ -474,7 +531,18 @@ func FromConfig(config *Config) ([]byte, ids.ID, error) {
 	return genesisBytes, avaxAssetID, nil
 }
 
-func splitAllocations(allocations []Allocation, numSplits int) [][]Allocation {
+// stakerWeights returns the amount of [allocations] to be staked by each of
+// the [stakers]. If the stakers don't specify weights, the allocations are
+// split evenly.
+func stakerWeights(stakers []Staker, allocations []Allocation) []uint64 {
+	weights := make([]uint64, len(stakers))
+	if stakers[0].Weight != 0 {
+		for i, staker := range stakers {
+			weights[i] = staker.Weight
+		}
+		return weights
+	}
+
 	totalAmount := uint64(0)
 	for _, allocation := range allocations {
 		for _, unlock := range allocation.UnlockSchedule {
@@ -482,7 +550,18 @@ func splitAllocations(allocations []Allocation, numSplits int) [][]Allocation {
 		}
 	}
 
-	nodeWeight := totalAmount / uint64(numSplits)
+	nodeWeight := totalAmount / uint64(len(stakers))
+	for i := range weights {
+		weights[i] = nodeWeight
+	}
+	return weights
+}
+
+// splitAllocations splits [allocations] into len([weights]) groups, where the
+// i-th group contains [weights][i] of the allocated amount. The last group
+// contains the remainder.
+func splitAllocations(allocations []Allocation, weights []uint64) [][]Allocation {
+	numSplits := len(weights)
 	allNodeAllocations := make([][]Allocation, 0, numSplits)
 
 	currentNodeAllocation := []Allocation(nil)
@@ -496,8 +575,8 @@ func splitAllocations(allocations []Allocation, numSplits int) [][]Allocation {
 
 		for _, unlock := range allocation.UnlockSchedule {
 			unlock := unlock
-			for currentNodeAmount+unlock.Amount > nodeWeight && len(allNodeAllocations) < numSplits-1 {
-				amountToAdd := nodeWeight - currentNodeAmount
+			for len(allNodeAllocations) < numSplits-1 && currentNodeAmount+unlock.Amount > weights[len(allNodeAllocations)] {
+				amountToAdd := weights[len(allNodeAllocations)] - currentNodeAmount
 				currentAllocation.UnlockSchedule = append(currentAllocation.UnlockSchedule, LockedAmount{
 					Amount:   amountToAdd,
 					Locktime: unlock.Locktime,
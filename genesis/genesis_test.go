@@ -17,10 +17,14 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"golang.org/x/exp/slices"
+
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/hashing"
 	"github.com/ava-labs/avalanchego/utils/perms"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/utils/units"
 	"github.com/ava-labs/avalanchego/vms/platformvm/genesis"
 )
 
@@ -143,6 +147,46 @@ func TestValidateConfig(t *testing.T) {
 			}(),
 			expectedErr: errNoAllocationToStake,
 		},
+		"missing initial staker weight": {
+			networkID: 12345,
+			config: func() *Config {
+				thisConfig := LocalConfig
+				thisConfig.InitialStakers = slices.Clone(thisConfig.InitialStakers)
+				thisConfig.InitialStakers[1].Weight = units.Avax
+				return &thisConfig
+			}(),
+			expectedErr: errMissingStakerWeight,
+		},
+		"initial staker weights don't match staked funds": {
+			networkID: 12345,
+			config: func() *Config {
+				thisConfig := LocalConfig
+				thisConfig.InitialStakers = slices.Clone(thisConfig.InitialStakers)
+				for i := range thisConfig.InitialStakers {
+					thisConfig.InitialStakers[i].Weight = units.Avax
+				}
+				return &thisConfig
+			}(),
+			expectedErr: errStakerWeightsMismatch,
+		},
+		"initial staker weights": {
+			networkID: 12345,
+			config: func() *Config {
+				thisConfig := LocalConfig
+				thisConfig.InitialStakers = slices.Clone(thisConfig.InitialStakers)
+				numStakers := uint64(len(thisConfig.InitialStakers))
+				stakedAmount := localStakedAmount()
+				for i := range thisConfig.InitialStakers {
+					thisConfig.InitialStakers[i].Weight = stakedAmount / numStakers
+				}
+				// Move the remainder and an AVAX of the second staker's weight
+				// to the first staker.
+				thisConfig.InitialStakers[0].Weight += stakedAmount%numStakers + units.Avax
+				thisConfig.InitialStakers[1].Weight -= units.Avax
+				return &thisConfig
+			}(),
+			expectedErr: nil,
+		},
 		"empty C-Chain genesis": {
 			networkID: 12345,
 			config: func() *Config {
@@ -171,6 +215,64 @@ func TestValidateConfig(t *testing.T) {
 	}
 }
 
+func TestSplitAllocations(t *testing.T) {
+	require := require.New(t)
+
+	allocations := []Allocation{
+		{
+			AVAXAddr: ids.ShortID{1},
+			UnlockSchedule: []LockedAmount{
+				{Amount: 3, Locktime: 1},
+				{Amount: 5, Locktime: 2},
+			},
+		},
+		{
+			AVAXAddr: ids.ShortID{2},
+			UnlockSchedule: []LockedAmount{
+				{Amount: 4, Locktime: 3},
+			},
+		},
+	}
+
+	nodeAllocations := splitAllocations(allocations, []uint64{2, 7, 3})
+	require.Equal(
+		[][]Allocation{
+			{
+				{
+					AVAXAddr: ids.ShortID{1},
+					UnlockSchedule: []LockedAmount{
+						{Amount: 2, Locktime: 1},
+					},
+				},
+			},
+			{
+				{
+					AVAXAddr: ids.ShortID{1},
+					UnlockSchedule: []LockedAmount{
+						{Amount: 1, Locktime: 1},
+						{Amount: 5, Locktime: 2},
+					},
+				},
+				{
+					AVAXAddr: ids.ShortID{2},
+					UnlockSchedule: []LockedAmount{
+						{Amount: 1, Locktime: 3},
+					},
+				},
+			},
+			{
+				{
+					AVAXAddr: ids.ShortID{2},
+					UnlockSchedule: []LockedAmount{
+						{Amount: 3, Locktime: 3},
+					},
+				},
+			},
+		},
+		nodeAllocations,
+	)
+}
+
 func TestGenesisFromFile(t *testing.T) {
 	tests := map[string]struct {
 		networkID       uint32
@@ -487,3 +589,19 @@ func TestAVAXAssetID(t *testing.T) {
 		})
 	}
 }
+
+// localStakedAmount returns the amount of funds initially staked in
+// [LocalConfig].
+func localStakedAmount() uint64 {
+	initiallyStaked := set.Of(LocalConfig.InitialStakedFunds...)
+	amount := uint64(0)
+	for _, allocation := range LocalConfig.Allocations {
+		if !initiallyStaked.Contains(allocation.AVAXAddr) {
+			continue
+		}
+		for _, unlock := range allocation.UnlockSchedule {
+			amount += unlock.Amount
+		}
+	}
+	return amount
+}
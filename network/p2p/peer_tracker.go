@@ -13,6 +13,8 @@ import (
 
 	"go.uber.org/zap"
 
+	"golang.org/x/exp/slices"
+
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils"
 	"github.com/ava-labs/avalanchego/utils/heap"
@@ -34,12 +36,20 @@ const (
 	// The probability that, when we select a peer, we select randomly rather
 	// than based on their performance.
 	randomPeerProbability = 0.2
+
+	// The number of most recent request latencies of a peer that are used to
+	// calculate its latency percentiles.
+	latencySampleSize = 100
 )
 
 // information we track on a given peer
 type peerInfo struct {
 	version   *version.Application
 	bandwidth safemath.Averager
+	// The most recent request latencies of the peer. Once full, the oldest
+	// latency is overwritten at [nextLatencyIndex].
+	latencies        []time.Duration
+	nextLatencyIndex int
 }
 
 // Tracks the bandwidth of responses coming from peers,
@@ -62,6 +72,7 @@ type PeerTracker struct {
 	numTrackedPeers        prometheus.Gauge
 	numResponsivePeers     prometheus.Gauge
 	averageBandwidthMetric prometheus.Gauge
+	requestLatency         prometheus.Summary
 }
 
 func NewPeerTracker(
@@ -99,12 +110,24 @@ func NewPeerTracker(
 				Help:      "average sync bandwidth used by peers",
 			},
 		),
+		requestLatency: prometheus.NewSummary(
+			prometheus.SummaryOpts{
+				Namespace: metricsNamespace,
+				Name:      "request_latency",
+				Help:      "time (in ns) for peers to respond to requests",
+				Objectives: map[float64]float64{
+					0.5:  0.05,
+					0.95: 0.005,
+				},
+			},
+		),
 	}
 
 	err := utils.Err(
 		registerer.Register(t.numTrackedPeers),
 		registerer.Register(t.numResponsivePeers),
 		registerer.Register(t.averageBandwidthMetric),
+		registerer.Register(t.requestLatency),
 	)
 	return t, err
 }
@@ -137,19 +160,49 @@ func (p *PeerTracker) shouldTrackNewPeer() bool {
 	return rand.Float64() < newPeerProbability // #nosec G404
 }
 
-// TODO get rid of minVersion
-// Returns a peer that we're connected to.
-// If we should track more peers, returns a random peer with version >= [minVersion], if any exist.
+// TODO get rid of minVersion and maxVersion
+// Returns a peer that we're connected to with a version in the range
+// [minVersion, maxVersion]. A nil bound is not enforced.
+// If we should track more peers, returns a random peer in the version range, if any exist.
 // Otherwise, with probability [randomPeerProbability] returns a random peer from [p.responsivePeers].
 // With probability [1-randomPeerProbability] returns the peer in [p.bandwidthHeap] with the highest bandwidth.
-func (p *PeerTracker) GetAnyPeer(minVersion *version.Application) (ids.NodeID, bool) {
+// If no such peer is in the version range, returns a tracked peer in the version range or, failing that,
+// any connected peer in the version range, even if it isn't tracked.
+// Returns false if no connected peer is in the version range.
+func (p *PeerTracker) GetAnyPeer(minVersion, maxVersion *version.Application) (ids.NodeID, bool) {
+	return p.GetAnyPeerWithBandwidth(minVersion, maxVersion, 0)
+}
+
+// GetAnyPeerWithBandwidth is like GetAnyPeer, but peers whose measured
+// bandwidth is less than [minBandwidth] bytes per second are only returned if
+// no other peer in the version range is available. Peers whose bandwidth
+// hasn't been measured yet are not skipped.
+func (p *PeerTracker) GetAnyPeerWithBandwidth(
+	minVersion *version.Application,
+	maxVersion *version.Application,
+	minBandwidth float64,
+) (ids.NodeID, bool) {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
+	nodeID, ok := p.getAnyPeer(minVersion, maxVersion, minBandwidth)
+	if ok || minBandwidth <= 0 {
+		return nodeID, ok
+	}
+	// Prefer a slow peer over failing the request.
+	return p.getAnyPeer(minVersion, maxVersion, 0)
+}
+
+// Assumes p.lock is held.
+func (p *PeerTracker) getAnyPeer(
+	minVersion *version.Application,
+	maxVersion *version.Application,
+	minBandwidth float64,
+) (ids.NodeID, bool) {
 	if p.shouldTrackNewPeer() {
 		for nodeID := range p.peers {
-			// if minVersion is specified and peer's version is less, skip
-			if minVersion != nil && p.peers[nodeID].version.Compare(minVersion) < 0 {
+			// skip peers that can't serve the request
+			if !p.canServe(nodeID, minVersion, maxVersion, minBandwidth) {
 				continue
 			}
 			// skip peers already tracked
@@ -171,13 +224,29 @@ func (p *PeerTracker) GetAnyPeer(minVersion *version.Application) (ids.NodeID, b
 	)
 	useRand := rand.Float64() < randomPeerProbability // #nosec G404
 	if useRand {
-		nodeID, ok = p.responsivePeers.Peek()
+		nodeID, ok = p.getPeerThatCanServe(p.responsivePeers, minVersion, maxVersion, minBandwidth)
 	} else {
-		nodeID, _, ok = p.bandwidthHeap.Pop()
+		var bandwidth safemath.Averager
+		nodeID, bandwidth, ok = p.bandwidthHeap.Pop()
+		if ok && !p.canServe(nodeID, minVersion, maxVersion, minBandwidth) {
+			// Keep the peer available for requests that it can serve.
+			p.bandwidthHeap.Push(nodeID, bandwidth)
+			ok = false
+		}
 	}
 	if !ok {
 		// if no nodes found in the bandwidth heap, return a tracked node at random
-		return p.trackedPeers.Peek()
+		nodeID, ok = p.getPeerThatCanServe(p.trackedPeers, minVersion, maxVersion, minBandwidth)
+		if ok {
+			return nodeID, true
+		}
+		// if no tracked nodes can serve the request, return any node that can
+		for nodeID := range p.peers {
+			if p.canServe(nodeID, minVersion, maxVersion, minBandwidth) {
+				return nodeID, true
+			}
+		}
+		return ids.EmptyNodeID, false
 	}
 	p.log.Debug(
 		"peer tracking: popping peer",
@@ -187,6 +256,73 @@ func (p *PeerTracker) GetAnyPeer(minVersion *version.Application) (ids.NodeID, b
 	return nodeID, true
 }
 
+// Returns an arbitrary peer in [nodeIDs] that can serve a request as defined
+// by canServe.
+// Assumes p.lock is held.
+func (p *PeerTracker) getPeerThatCanServe(
+	nodeIDs set.Set[ids.NodeID],
+	minVersion *version.Application,
+	maxVersion *version.Application,
+	minBandwidth float64,
+) (ids.NodeID, bool) {
+	for nodeID := range nodeIDs {
+		if p.canServe(nodeID, minVersion, maxVersion, minBandwidth) {
+			return nodeID, true
+		}
+	}
+	return ids.EmptyNodeID, false
+}
+
+// Returns true if [nodeID] is connected with a version in the range
+// [minVersion, maxVersion] and its measured bandwidth, if any, is at least
+// [minBandwidth].
+// Assumes p.lock is held.
+func (p *PeerTracker) canServe(
+	nodeID ids.NodeID,
+	minVersion *version.Application,
+	maxVersion *version.Application,
+	minBandwidth float64,
+) bool {
+	if !p.inVersionRange(nodeID, minVersion, maxVersion) {
+		return false
+	}
+	bandwidth := p.peers[nodeID].bandwidth
+	return bandwidth == nil || bandwidth.Read() >= minBandwidth
+}
+
+// Returns true if [nodeID] is connected with a version in the range
+// [minVersion, maxVersion] and its measured bandwidth, if any, is at least
+// [minBandwidth] bytes per second. A nil version bound is not enforced.
+func (p *PeerTracker) CanServe(
+	nodeID ids.NodeID,
+	minVersion *version.Application,
+	maxVersion *version.Application,
+	minBandwidth float64,
+) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.canServe(nodeID, minVersion, maxVersion, minBandwidth)
+}
+
+// Returns true if [nodeID] is connected with a version in the range
+// [minVersion, maxVersion]. A nil bound is not enforced.
+// Assumes p.lock is held.
+func (p *PeerTracker) inVersionRange(
+	nodeID ids.NodeID,
+	minVersion *version.Application,
+	maxVersion *version.Application,
+) bool {
+	peer, ok := p.peers[nodeID]
+	if !ok {
+		return false
+	}
+	if minVersion != nil && peer.version.Compare(minVersion) < 0 {
+		return false
+	}
+	return maxVersion == nil || peer.version.Compare(maxVersion) <= 0
+}
+
 // Record that we sent a request to [nodeID].
 func (p *PeerTracker) TrackPeer(nodeID ids.NodeID) {
 	p.lock.Lock()
@@ -229,6 +365,55 @@ func (p *PeerTracker) TrackBandwidth(nodeID ids.NodeID, bandwidth float64) {
 	p.numResponsivePeers.Set(float64(p.responsivePeers.Len()))
 }
 
+// Record that [nodeID] responded to a request after [latency].
+func (p *PeerTracker) TrackLatency(nodeID ids.NodeID, latency time.Duration) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	peer := p.peers[nodeID]
+	if peer == nil {
+		// we're not connected to this peer, nothing to do here
+		p.log.Debug("tracking latency for untracked peer", zap.Stringer("nodeID", nodeID))
+		return
+	}
+
+	if len(peer.latencies) < latencySampleSize {
+		peer.latencies = append(peer.latencies, latency)
+	} else {
+		peer.latencies[peer.nextLatencyIndex] = latency
+		peer.nextLatencyIndex = (peer.nextLatencyIndex + 1) % latencySampleSize
+	}
+	p.requestLatency.Observe(float64(latency))
+}
+
+// Returns the 50th and 95th percentiles of the latencies of the most recent
+// requests [nodeID] responded to.
+// Returns false if [nodeID] isn't connected or hasn't responded to any
+// requests.
+func (p *PeerTracker) GetLatencyPercentiles(nodeID ids.NodeID) (time.Duration, time.Duration, bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	peer := p.peers[nodeID]
+	if peer == nil || len(peer.latencies) == 0 {
+		return 0, 0, false
+	}
+
+	latencies := slices.Clone(peer.latencies)
+	slices.Sort(latencies)
+	return percentile(latencies, 0.5), percentile(latencies, 0.95), true
+}
+
+// Returns the [p]th percentile of [sorted] using the nearest-rank method.
+// Assumes [sorted] is sorted and isn't empty.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
 // Connected should be called when [nodeID] connects to this node
 func (p *PeerTracker) Connected(nodeID ids.NodeID, nodeVersion *version.Application) {
 	p.lock.Lock()
@@ -242,18 +427,18 @@ func (p *PeerTracker) Connected(nodeID ids.NodeID, nodeVersion *version.Applicat
 		return
 	}
 
-	// Peer is already connected, update the version if it has changed.
+	// Peer is already connected, update the version if it has changed so that
+	// peer selection reflects the new version. The rest of the peer's stats are
+	// kept.
 	// Log a warning message since the consensus engine should never call Connected on a peer
 	// that we have already marked as Connected.
 	if nodeVersion.Compare(peer.version) != 0 {
-		p.peers[nodeID] = &peerInfo{
-			version:   nodeVersion,
-			bandwidth: peer.bandwidth,
-		}
+		storedVersion := peer.version
+		peer.version = nodeVersion
 		p.log.Warn(
 			"updating node version of already connected peer",
 			zap.Stringer("nodeID", nodeID),
-			zap.Stringer("storedVersion", peer.version),
+			zap.Stringer("storedVersion", storedVersion),
 			zap.Stringer("nodeVersion", nodeVersion),
 		)
 	} else {
@@ -277,6 +462,15 @@ func (p *PeerTracker) Disconnected(nodeID ids.NodeID) {
 	delete(p.peers, nodeID)
 }
 
+// Returns true if [nodeID] is currently connected to this node.
+func (p *PeerTracker) IsConnected(nodeID ids.NodeID) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	_, ok := p.peers[nodeID]
+	return ok
+}
+
 // Returns the number of peers the node is connected to.
 func (p *PeerTracker) Size() int {
 	p.lock.Lock()
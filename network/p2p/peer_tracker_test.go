@@ -5,6 +5,7 @@ package p2p
 
 import (
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 
@@ -39,7 +40,7 @@ func TestPeerTracker(t *testing.T) {
 
 	// Expect requests to go to new peers until we have desiredMinResponsivePeers responsive peers.
 	for i := 0; i < desiredMinResponsivePeers+numExtraPeers/2; i++ {
-		peer, ok := p.GetAnyPeer(nil)
+		peer, ok := p.GetAnyPeer(nil, nil)
 		require.True(ok)
 		require.NotNil(peer)
 
@@ -65,7 +66,7 @@ func TestPeerTracker(t *testing.T) {
 	// Expect requests to go to responsive or new peers, so long as they are available
 	numRequests := 50
 	for i := 0; i < numRequests; i++ {
-		peer, ok := p.GetAnyPeer(nil)
+		peer, ok := p.GetAnyPeer(nil, nil)
 		require.True(ok)
 		require.NotNil(peer)
 
@@ -89,7 +90,7 @@ func TestPeerTracker(t *testing.T) {
 	}
 
 	// Requests should fall back on non-responsive peers when no other choice is left
-	peer, ok := p.GetAnyPeer(nil)
+	peer, ok := p.GetAnyPeer(nil, nil)
 	require.True(ok)
 	require.NotNil(peer)
 
@@ -97,3 +98,211 @@ func TestPeerTracker(t *testing.T) {
 	require.True(ok)
 	require.Falsef(responsive, "expected connecting to a non-responsive peer, but got a peer that was responsive: peer %s", peer)
 }
+
+func TestPeerTrackerVersionRange(t *testing.T) {
+	require := require.New(t)
+	p, err := NewPeerTracker(logging.NoLog{}, "", prometheus.NewRegistry())
+	require.NoError(err)
+
+	var (
+		v1 = &version.Application{Major: 1}
+		v2 = &version.Application{Major: 2}
+		v3 = &version.Application{Major: 3}
+
+		nodeID1 = ids.GenerateTestNodeID()
+		nodeID2 = ids.GenerateTestNodeID()
+		nodeID3 = ids.GenerateTestNodeID()
+	)
+	p.Connected(nodeID1, v1)
+	p.Connected(nodeID2, v2)
+	p.Connected(nodeID3, v3)
+
+	// Make all peers responsive so that they can be selected by every
+	// selection strategy.
+	for _, nodeID := range []ids.NodeID{nodeID1, nodeID2, nodeID3} {
+		p.TrackPeer(nodeID)
+		p.TrackBandwidth(nodeID, 10)
+	}
+
+	tests := []struct {
+		minVersion     *version.Application
+		maxVersion     *version.Application
+		expectedNodeID ids.NodeID
+	}{
+		{
+			minVersion:     v2,
+			maxVersion:     v2,
+			expectedNodeID: nodeID2,
+		},
+		{
+			maxVersion:     v1,
+			expectedNodeID: nodeID1,
+		},
+		{
+			minVersion:     v3,
+			expectedNodeID: nodeID3,
+		},
+	}
+	for i := 0; i < 100; i++ {
+		for _, test := range tests {
+			peer, ok := p.GetAnyPeer(test.minVersion, test.maxVersion)
+			require.True(ok)
+			require.Equal(test.expectedNodeID, peer)
+
+			// Record the response to re-add the peer to the bandwidth heap
+			p.TrackBandwidth(peer, 10)
+		}
+	}
+
+	// No peers are in the range
+	_, ok := p.GetAnyPeer(v3, v2)
+	require.False(ok)
+
+	// Peers that are filtered out must not be removed from the bandwidth heap
+	require.Equal(3, p.bandwidthHeap.Len())
+}
+
+func TestPeerTrackerUntrackedPeerInVersionRange(t *testing.T) {
+	require := require.New(t)
+	p, err := NewPeerTracker(logging.NoLog{}, "", prometheus.NewRegistry())
+	require.NoError(err)
+
+	var (
+		v1 = &version.Application{Major: 1}
+		v2 = &version.Application{Major: 2}
+
+		untrackedNodeID = ids.GenerateTestNodeID()
+	)
+
+	// Enough responsive peers that new peers are rarely tracked, none of
+	// which are in the requested version range.
+	for i := 0; i < desiredMinResponsivePeers; i++ {
+		nodeID := ids.GenerateTestNodeID()
+		p.Connected(nodeID, v1)
+		p.TrackPeer(nodeID)
+		p.TrackBandwidth(nodeID, 10)
+	}
+	p.Connected(untrackedNodeID, v2)
+
+	// If no tracked peer is in the version range, a peer that isn't tracked
+	// is returned.
+	for i := 0; i < 100; i++ {
+		peer, ok := p.GetAnyPeer(v2, nil)
+		require.True(ok)
+		require.Equal(untrackedNodeID, peer)
+	}
+}
+
+func TestPeerTrackerMinBandwidth(t *testing.T) {
+	require := require.New(t)
+	p, err := NewPeerTracker(logging.NoLog{}, "", prometheus.NewRegistry())
+	require.NoError(err)
+
+	var (
+		slowNodeID = ids.GenerateTestNodeID()
+		fastNodeID = ids.GenerateTestNodeID()
+	)
+	p.Connected(slowNodeID, version.CurrentApp)
+	p.Connected(fastNodeID, version.CurrentApp)
+	p.TrackPeer(slowNodeID)
+	p.TrackBandwidth(slowNodeID, 10)
+	p.TrackPeer(fastNodeID)
+	p.TrackBandwidth(fastNodeID, 1000)
+
+	for i := 0; i < 100; i++ {
+		peer, ok := p.GetAnyPeerWithBandwidth(nil, nil, 100)
+		require.True(ok)
+		require.Equal(fastNodeID, peer)
+
+		// Record the response to re-add the peer to the bandwidth heap
+		p.TrackBandwidth(peer, 1000)
+	}
+
+	// If no peer is fast enough, a slow peer is returned rather than none.
+	_, ok := p.GetAnyPeerWithBandwidth(nil, nil, 10000)
+	require.True(ok)
+
+	// The bandwidth doesn't override the version range.
+	_, ok = p.GetAnyPeerWithBandwidth(&version.Application{Major: version.CurrentApp.Major + 1}, nil, 100)
+	require.False(ok)
+}
+
+func TestPeerTrackerConnectedUpdatesVersion(t *testing.T) {
+	require := require.New(t)
+	p, err := NewPeerTracker(logging.NoLog{}, "", prometheus.NewRegistry())
+	require.NoError(err)
+
+	var (
+		v1 = &version.Application{Major: 1}
+		v2 = &version.Application{Major: 2}
+		v3 = &version.Application{Major: 3}
+
+		nodeID = ids.GenerateTestNodeID()
+	)
+	p.Connected(nodeID, v1)
+	p.TrackPeer(nodeID)
+	p.TrackBandwidth(nodeID, 10)
+
+	_, ok := p.GetAnyPeer(v2, nil)
+	require.False(ok)
+
+	// The peer upgrades without being reported as disconnected
+	p.Connected(nodeID, v2)
+
+	peer, ok := p.GetAnyPeer(v2, nil)
+	require.True(ok)
+	require.Equal(nodeID, peer)
+
+	// The bandwidth of the peer must be kept
+	require.Equal(float64(10), p.peers[nodeID].bandwidth.Read())
+
+	_, ok = p.GetAnyPeer(v3, nil)
+	require.False(ok)
+
+	// The peer upgrades and reconnects
+	p.Disconnected(nodeID)
+	p.Connected(nodeID, v3)
+
+	peer, ok = p.GetAnyPeer(v3, nil)
+	require.True(ok)
+	require.Equal(nodeID, peer)
+}
+
+func TestPeerTrackerLatencyPercentiles(t *testing.T) {
+	require := require.New(t)
+
+	p, err := NewPeerTracker(logging.NoLog{}, "", prometheus.NewRegistry())
+	require.NoError(err)
+
+	nodeID := ids.GenerateTestNodeID()
+
+	// Latencies of peers that aren't connected are ignored
+	p.TrackLatency(nodeID, time.Second)
+	_, _, ok := p.GetLatencyPercentiles(nodeID)
+	require.False(ok)
+
+	p.Connected(nodeID, version.CurrentApp)
+	_, _, ok = p.GetLatencyPercentiles(nodeID)
+	require.False(ok)
+
+	for i := 1; i <= latencySampleSize; i++ {
+		p.TrackLatency(nodeID, time.Duration(i)*time.Millisecond)
+	}
+	p50, p95, ok := p.GetLatencyPercentiles(nodeID)
+	require.True(ok)
+	require.Equal(50*time.Millisecond, p50)
+	require.Equal(95*time.Millisecond, p95)
+
+	// Only the most recent latencies are considered
+	for i := 0; i < latencySampleSize/2; i++ {
+		p.TrackLatency(nodeID, time.Second)
+	}
+	p50, p95, ok = p.GetLatencyPercentiles(nodeID)
+	require.True(ok)
+	require.Equal(100*time.Millisecond, p50)
+	require.Equal(time.Second, p95)
+
+	p.Disconnected(nodeID)
+	_, _, ok = p.GetLatencyPercentiles(nodeID)
+	require.False(ok)
+}
@@ -13,6 +13,8 @@ import (
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/tests/fixture/e2e"
+	"github.com/ava-labs/avalanchego/tests/fixture/tmpnet"
+	"github.com/ava-labs/avalanchego/tests/fixture/tmpnet/local"
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/units"
 	"github.com/ava-labs/avalanchego/vms/components/avax"
@@ -114,21 +116,27 @@ var _ = e2e.DescribePChain("[Permissionless Subnets]", func() {
 			})
 
 			ginkgo.By("make subnet permissionless", func() {
-				_, err := pWallet.IssueTransformSubnetTx(
-					subnetID,
+				subnet := &local.LocalSubnet{
+					Name:     "permissionless",
+					SubnetID: subnetID,
+					StakingConfig: &tmpnet.SubnetStakingConfig{
+						MinValidatorStake:        1,
+						MaxValidatorStake:        100 * units.MegaAvax,
+						MinDelegatorStake:        1,
+						MinDelegationFee:         0,
+						UptimeRequirement:        .80 * reward.PercentDenominator,
+						MaxValidatorWeightFactor: 5,
+						MinStakeDuration:         time.Second,
+						MaxStakeDuration:         365 * 24 * time.Hour,
+					},
+				}
+				_, err := subnet.Transform(
+					pWallet,
 					subnetAssetID,
 					50*units.MegaAvax,
 					100*units.MegaAvax,
 					reward.PercentDenominator,
 					reward.PercentDenominator,
-					1,
-					100*units.MegaAvax,
-					time.Second,
-					365*24*time.Hour,
-					0,
-					1,
-					5,
-					.80*reward.PercentDenominator,
 					e2e.WithDefaultContext(),
 				)
 				require.NoError(err)
@@ -144,6 +144,27 @@ func AddEphemeralNode(network tmpnet.Network, flags tmpnet.FlagsMap) tmpnet.Node
 	return node
 }
 
+// Add ephemeral nodes that are only intended to be used by a single test.
+// The nodes are started concurrently, which is faster than adding them one
+// at a time with AddEphemeralNode.
+func AddEphemeralNodes(network tmpnet.Network, count int, flags tmpnet.FlagsMap) []tmpnet.Node {
+	require := require.New(ginkgo.GinkgoT())
+
+	nodes, err := network.AddEphemeralNodes(DefaultContext(), ginkgo.GinkgoWriter, count, flags)
+	require.NoError(err)
+
+	// Ensure nodes are stopped on teardown. Their configuration is not removed to enable
+	// collection in CI to aid in troubleshooting failures.
+	ginkgo.DeferCleanup(func() {
+		for _, node := range nodes {
+			tests.Outf("Shutting down ephemeral node %s\n", node.GetID())
+			require.NoError(node.Stop())
+		}
+	})
+
+	return nodes
+}
+
 // Wait for the given node to report healthy.
 func WaitForHealthy(node tmpnet.Node) {
 	// Need to use explicit context (vs DefaultContext()) to support use with DeferCleanup
@@ -28,6 +28,7 @@ import (
 	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
 	"github.com/ava-labs/avalanchego/utils/formatting/address"
 	"github.com/ava-labs/avalanchego/utils/perms"
+	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/utils/units"
 	"github.com/ava-labs/avalanchego/vms/platformvm/reward"
 )
@@ -43,6 +44,18 @@ const (
 
 	// A short min stake duration enables testing of staking logic.
 	DefaultMinStakeDuration = time.Second
+
+	// Stake and delegation fee of genesis validators that aren't explicitly
+	// configured.
+	DefaultGenesisValidatorWeight        = units.MegaAvax
+	DefaultGenesisDelegationFee   uint32 = .01 * reward.PercentDenominator
+)
+
+var (
+	ErrUnknownGenesisValidator     = errors.New("genesis validator config provided for a node that isn't a genesis validator")
+	ErrInvalidGenesisWeight        = errors.New("genesis validator weight is outside of the staking bounds")
+	ErrInvalidGenesisDelegationFee = errors.New("genesis validator delegation fee exceeds 100%")
+	ErrInvalidSubnetStakingConfig  = errors.New("invalid subnet staking config")
 )
 
 var (
@@ -126,6 +139,62 @@ type NetworkConfig struct {
 	CChainConfig FlagsMap
 	DefaultFlags FlagsMap
 	FundedKeys   []*secp256k1.PrivateKey
+
+	// Optional configuration of the genesis validators, only used if genesis
+	// is generated. Validators without an entry use the default weight and
+	// delegation fee.
+	GenesisValidators map[ids.NodeID]GenesisValidatorConfig
+}
+
+// GenesisValidatorConfig configures the stake of a genesis validator. Zero
+// values are replaced with the defaults.
+type GenesisValidatorConfig struct {
+	Weight        uint64
+	DelegationFee uint32
+}
+
+// SubnetStakingConfig defines the staking rules of a subnet that differ from
+// those of the primary network. The genesis can only define the primary
+// network, so the rules are applied by transforming the subnet into an
+// elastic subnet once it has been created.
+type SubnetStakingConfig struct {
+	MinValidatorStake uint64
+	MaxValidatorStake uint64
+	MinDelegatorStake uint64
+	// Delegation fee and uptime requirement are denominated in
+	// reward.PercentDenominator.
+	MinDelegationFee         uint32
+	UptimeRequirement        uint32
+	MaxValidatorWeightFactor byte
+	MinStakeDuration         time.Duration
+	MaxStakeDuration         time.Duration
+}
+
+// Verify returns an error if the staking rules can't be applied to a subnet.
+func (c *SubnetStakingConfig) Verify() error {
+	switch {
+	case c.MinValidatorStake == 0:
+		return fmt.Errorf("%w: min validator stake is zero", ErrInvalidSubnetStakingConfig)
+	case c.MinValidatorStake > c.MaxValidatorStake:
+		return fmt.Errorf("%w: min validator stake %d exceeds max validator stake %d",
+			ErrInvalidSubnetStakingConfig, c.MinValidatorStake, c.MaxValidatorStake)
+	case c.MinDelegatorStake == 0:
+		return fmt.Errorf("%w: min delegator stake is zero", ErrInvalidSubnetStakingConfig)
+	case c.MinDelegationFee > reward.PercentDenominator:
+		return fmt.Errorf("%w: min delegation fee exceeds 100%%", ErrInvalidSubnetStakingConfig)
+	case c.UptimeRequirement > reward.PercentDenominator:
+		return fmt.Errorf("%w: uptime requirement exceeds 100%%", ErrInvalidSubnetStakingConfig)
+	case c.MaxValidatorWeightFactor == 0:
+		return fmt.Errorf("%w: max validator weight factor is zero", ErrInvalidSubnetStakingConfig)
+	case c.MinStakeDuration < time.Second:
+		return fmt.Errorf("%w: min stake duration %s is less than a second",
+			ErrInvalidSubnetStakingConfig, c.MinStakeDuration)
+	case c.MinStakeDuration > c.MaxStakeDuration:
+		return fmt.Errorf("%w: min stake duration %s exceeds max stake duration %s",
+			ErrInvalidSubnetStakingConfig, c.MinStakeDuration, c.MaxStakeDuration)
+	default:
+		return nil
+	}
 }
 
 // Ensure genesis is generated if not already present.
@@ -151,7 +220,7 @@ func (c *NetworkConfig) EnsureGenesis(networkID uint32, validatorIDs []ids.NodeI
 		}
 	}
 
-	genesis, err := NewTestGenesis(networkID, xChainBalances, cChainBalances, validatorIDs)
+	genesis, err := NewTestGenesis(networkID, xChainBalances, cChainBalances, validatorIDs, c.GenesisValidators)
 	if err != nil {
 		return err
 	}
@@ -170,6 +239,9 @@ type NodeURI struct {
 type NodeConfig struct {
 	NodeID ids.NodeID
 	Flags  FlagsMap
+	// Environment variables to set for the node process in addition to
+	// the environment of the process starting the node.
+	Env map[string]string
 }
 
 func NewNodeConfig() *NodeConfig {
@@ -307,12 +379,15 @@ type XChainBalanceMap map[ids.ShortID]uint64
 
 // Create a genesis struct valid for bootstrapping a test
 // network. Note that many of the genesis fields (e.g. reward
-// addresses) are randomly generated or hard-coded.
+// addresses) are randomly generated or hard-coded. The stake of
+// the validators can optionally be configured with
+// [validatorConfigs].
 func NewTestGenesis(
 	networkID uint32,
 	xChainBalances XChainBalanceMap,
 	cChainBalances core.GenesisAlloc,
 	validatorIDs []ids.NodeID,
+	validatorConfigs map[ids.NodeID]GenesisValidatorConfig,
 ) (*genesis.UnparsedConfig, error) {
 	// Validate inputs
 	switch networkID {
@@ -325,6 +400,10 @@ func NewTestGenesis(
 	if len(xChainBalances) == 0 || len(cChainBalances) == 0 {
 		return nil, errMissingBalancesForGenesis
 	}
+	stakers, err := newGenesisStakers(validatorIDs, validatorConfigs)
+	if err != nil {
+		return nil, err
+	}
 
 	// Address that controls stake doesn't matter -- generate it randomly
 	stakeAddress, err := address.Format(
@@ -336,8 +415,11 @@ func NewTestGenesis(
 		return nil, fmt.Errorf("failed to format stake address: %w", err)
 	}
 
-	// Ensure the total stake allows a MegaAvax per validator
-	totalStake := uint64(len(validatorIDs)) * units.MegaAvax
+	// Ensure the total stake covers the weight of every validator
+	totalStake := uint64(0)
+	for _, staker := range stakers {
+		totalStake += staker.Weight
+	}
 
 	// The eth address is only needed to link pre-mainnet assets. Until that capability
 	// becomes necessary for testing, use a bogus address.
@@ -418,16 +500,64 @@ func NewTestGenesis(
 	}
 
 	// Configure provided validator node IDs as initial stakers
-	for _, validatorID := range validatorIDs {
-		config.InitialStakers = append(
-			config.InitialStakers,
-			genesis.UnparsedStaker{
-				NodeID:        validatorID,
-				RewardAddress: rewardAddr,
-				DelegationFee: .01 * reward.PercentDenominator,
-			},
-		)
+	for _, staker := range stakers {
+		staker.RewardAddress = rewardAddr
+		config.InitialStakers = append(config.InitialStakers, staker)
 	}
 
 	return config, nil
 }
+
+// newGenesisStakers returns the initial stakers for [validatorIDs], applying
+// the defaults to any value not provided by [validatorConfigs]. The reward
+// address of the returned stakers is left unset.
+func newGenesisStakers(
+	validatorIDs []ids.NodeID,
+	validatorConfigs map[ids.NodeID]GenesisValidatorConfig,
+) ([]genesis.UnparsedStaker, error) {
+	isValidator := set.Of(validatorIDs...)
+	for nodeID := range validatorConfigs {
+		if !isValidator.Contains(nodeID) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownGenesisValidator, nodeID)
+		}
+	}
+
+	// Test networks use the staking config of the local network
+	stakingConfig := genesis.LocalParams.StakingConfig
+	stakers := make([]genesis.UnparsedStaker, len(validatorIDs))
+	for i, validatorID := range validatorIDs {
+		validatorConfig := validatorConfigs[validatorID]
+		if validatorConfig.Weight == 0 {
+			validatorConfig.Weight = DefaultGenesisValidatorWeight
+		}
+		if validatorConfig.DelegationFee == 0 {
+			validatorConfig.DelegationFee = DefaultGenesisDelegationFee
+		}
+
+		if validatorConfig.Weight < stakingConfig.MinValidatorStake || validatorConfig.Weight > stakingConfig.MaxValidatorStake {
+			return nil, fmt.Errorf(
+				"%w: weight %d of %s must be in [%d, %d]",
+				ErrInvalidGenesisWeight,
+				validatorConfig.Weight,
+				validatorID,
+				stakingConfig.MinValidatorStake,
+				stakingConfig.MaxValidatorStake,
+			)
+		}
+		if validatorConfig.DelegationFee > reward.PercentDenominator {
+			return nil, fmt.Errorf(
+				"%w: %d of %s",
+				ErrInvalidGenesisDelegationFee,
+				validatorConfig.DelegationFee,
+				validatorID,
+			)
+		}
+
+		stakers[i] = genesis.UnparsedStaker{
+			NodeID:        validatorID,
+			DelegationFee: validatorConfig.DelegationFee,
+			Weight:        validatorConfig.Weight,
+		}
+	}
+	return stakers, nil
+}
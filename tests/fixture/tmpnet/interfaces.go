@@ -16,6 +16,7 @@ type Network interface {
 	GetConfig() NetworkConfig
 	GetNodes() []Node
 	AddEphemeralNode(w io.Writer, flags FlagsMap) (Node, error)
+	AddEphemeralNodes(ctx context.Context, w io.Writer, count int, flags FlagsMap) ([]Node, error)
 }
 
 // Defines node capabilities supportable regardless of how a network is orchestrated.
@@ -25,4 +26,9 @@ type Node interface {
 	GetProcessContext() node.NodeProcessContext
 	IsHealthy(ctx context.Context) (bool, error)
 	Stop() error
+	// Suspends the node without stopping it. Not all platforms support
+	// pausing a node.
+	Pause() error
+	// Resumes a node suspended by Pause.
+	Resume() error
 }
@@ -20,6 +20,18 @@ const (
 	DefaultNetworkStartTimeout = 2 * time.Minute
 	DefaultNodeInitTimeout     = 10 * time.Second
 	DefaultNodeStopTimeout     = 5 * time.Second
+
+	// The duration a previously-healthy node is allowed to report
+	// unhealthy before it is considered to have failed.
+	DefaultUnhealthyNodeGracePeriod = 30 * time.Second
+
+	// The maximum duration to wait for a single node to respond to a
+	// health query when taking a snapshot of network health.
+	DefaultNodeHealthCheckTimeout = 5 * time.Second
+
+	// The maximum number of node configurations to write to disk
+	// concurrently.
+	DefaultWriteNodesParallelism = 8
 )
 
 // A set of flags appropriate for local testing.
@@ -0,0 +1,87 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package local
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/set"
+)
+
+var errNodeUnhealthy = errors.New("node unhealthy beyond grace period")
+
+// The health of a node at the time it was queried.
+type NodeHealthStatus struct {
+	// The API URI of the node. Empty if the node is not running.
+	URI string
+	// Whether the node process was found to be running
+	Running bool
+	// Whether the node reported healthy
+	Healthy bool
+	// The error encountered while querying the node, if any. A node
+	// that is not running reports tmpnet.ErrNotRunning.
+	Err error
+}
+
+type healthTransition int
+
+const (
+	noTransition healthTransition = iota
+	becameHealthy
+	becameUnhealthy
+)
+
+// Tracks the health of a set of nodes over time to distinguish a node
+// that is not yet healthy from one that was healthy and has since
+// become unhealthy.
+type nodeHealthTracker struct {
+	gracePeriod time.Duration
+
+	// Nodes that have reported healthy at least once
+	everHealthy set.Set[ids.NodeID]
+	// Nodes that reported healthy and are currently reporting unhealthy,
+	// mapped to the time they were first seen unhealthy.
+	unhealthySince map[ids.NodeID]time.Time
+}
+
+func newNodeHealthTracker(gracePeriod time.Duration) *nodeHealthTracker {
+	return &nodeHealthTracker{
+		gracePeriod:    gracePeriod,
+		everHealthy:    set.Set[ids.NodeID]{},
+		unhealthySince: map[ids.NodeID]time.Time{},
+	}
+}
+
+// Records the result of a health check performed at [now]. Returns the
+// resulting health transition, or an error if a previously-healthy node
+// has been unhealthy for longer than the grace period.
+func (t *nodeHealthTracker) Observe(nodeID ids.NodeID, healthy bool, now time.Time) (healthTransition, error) {
+	if healthy {
+		_, wasUnhealthy := t.unhealthySince[nodeID]
+		delete(t.unhealthySince, nodeID)
+		if wasUnhealthy || !t.everHealthy.Contains(nodeID) {
+			t.everHealthy.Add(nodeID)
+			return becameHealthy, nil
+		}
+		return noTransition, nil
+	}
+
+	if !t.everHealthy.Contains(nodeID) {
+		// Not yet healthy
+		return noTransition, nil
+	}
+
+	since, ok := t.unhealthySince[nodeID]
+	if !ok {
+		t.unhealthySince[nodeID] = now
+		return becameUnhealthy, nil
+	}
+	if unhealthyDuration := now.Sub(since); unhealthyDuration > t.gracePeriod {
+		return noTransition, fmt.Errorf("%w: %s has been unhealthy for %s", errNodeUnhealthy, nodeID, unhealthyDuration)
+	}
+	return noTransition, nil
+}
@@ -0,0 +1,64 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package local
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+func TestNodeHealthTracker(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		gracePeriod = 10 * time.Second
+		tracker     = newNodeHealthTracker(gracePeriod)
+		nodeID      = ids.GenerateTestNodeID()
+		now         = time.Now()
+	)
+
+	// A node that has never been healthy is not subject to the grace period
+	transition, err := tracker.Observe(nodeID, false, now)
+	require.NoError(err)
+	require.Equal(noTransition, transition)
+
+	transition, err = tracker.Observe(nodeID, false, now.Add(2*gracePeriod))
+	require.NoError(err)
+	require.Equal(noTransition, transition)
+
+	now = now.Add(2 * gracePeriod)
+	transition, err = tracker.Observe(nodeID, true, now)
+	require.NoError(err)
+	require.Equal(becameHealthy, transition)
+
+	transition, err = tracker.Observe(nodeID, true, now)
+	require.NoError(err)
+	require.Equal(noTransition, transition)
+
+	// A brief blip within the grace period is tolerated
+	transition, err = tracker.Observe(nodeID, false, now)
+	require.NoError(err)
+	require.Equal(becameUnhealthy, transition)
+
+	transition, err = tracker.Observe(nodeID, false, now.Add(gracePeriod))
+	require.NoError(err)
+	require.Equal(noTransition, transition)
+
+	now = now.Add(gracePeriod)
+	transition, err = tracker.Observe(nodeID, true, now)
+	require.NoError(err)
+	require.Equal(becameHealthy, transition)
+
+	// Remaining unhealthy beyond the grace period is a failure
+	transition, err = tracker.Observe(nodeID, false, now)
+	require.NoError(err)
+	require.Equal(becameUnhealthy, transition)
+
+	_, err = tracker.Observe(nodeID, false, now.Add(gracePeriod+time.Second))
+	require.ErrorIs(err, errNodeUnhealthy)
+}
@@ -10,9 +10,11 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"net"
 	"os"
 	"path/filepath"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/ava-labs/avalanchego/config"
@@ -23,6 +25,9 @@ import (
 	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
 	"github.com/ava-labs/avalanchego/utils/perms"
 	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/ava-labs/avalanchego/vms/platformvm/status"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
 )
 
 const (
@@ -40,6 +45,11 @@ var (
 	errLocalNetworkDirNotSet = errors.New("local network directory not set - has Create() been called?")
 	errInvalidNetworkDir     = errors.New("failed to write local network: invalid network directory")
 	errMissingBootstrapNodes = errors.New("failed to add node due to missing bootstrap nodes")
+	errNoHealthyNodes        = errors.New("failed to find a healthy node")
+	errMissingGenesis        = errors.New("failed to set genesis: genesis not provided")
+	errGenesisNetworkID      = errors.New("genesis network ID does not match the network ID")
+	errNegativeCount         = errors.New("failed to validate local network: node and key counts must not be negative")
+	errNoInitialStakers      = errors.New("failed to validate local network: at least one initial staker is required")
 )
 
 // Default root dir for storing networks and their configuration.
@@ -55,10 +65,40 @@ func GetDefaultRootDir() (string, error) {
 // directory numbered from 1000 until creation succeeds. Returns the
 // network id and the full path of the created directory.
 func FindNextNetworkID(rootDir string) (uint32, string, error) {
-	var (
-		networkID uint32 = 1000
-		dirPath   string
-	)
+	return findNetworkID(rootDir, func(dirPath string) (bool, error) {
+		err := os.Mkdir(dirPath, perms.ReadWriteExecute)
+		if errors.Is(err, fs.ErrExist) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to create network directory: %w", err)
+		}
+		return true, nil
+	})
+}
+
+// Find the next available network ID without creating a directory for
+// it. Since the returned ID is not reserved, a concurrent call to
+// FindNextNetworkID may claim it first.
+func PeekNextNetworkID(rootDir string) (uint32, error) {
+	networkID, _, err := findNetworkID(rootDir, func(dirPath string) (bool, error) {
+		_, err := os.Stat(dirPath)
+		if errors.Is(err, fs.ErrNotExist) {
+			return true, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to check network directory: %w", err)
+		}
+		return false, nil
+	})
+	return networkID, err
+}
+
+// Iterate over the unreserved network IDs, starting from 1000, and return
+// the first one whose directory under [rootDir] is reported available by
+// [isAvailable].
+func findNetworkID(rootDir string, isAvailable func(dirPath string) (bool, error)) (uint32, string, error) {
+	var networkID uint32 = 1000
 	for {
 		_, reserved := constants.NetworkIDToNetworkName[networkID]
 		if reserved {
@@ -66,14 +106,13 @@ func FindNextNetworkID(rootDir string) (uint32, string, error) {
 			continue
 		}
 
-		dirPath = filepath.Join(rootDir, strconv.FormatUint(uint64(networkID), 10))
-		err := os.Mkdir(dirPath, perms.ReadWriteExecute)
-		if err == nil {
-			return networkID, dirPath, nil
+		dirPath := filepath.Join(rootDir, strconv.FormatUint(uint64(networkID), 10))
+		available, err := isAvailable(dirPath)
+		if err != nil {
+			return 0, "", err
 		}
-
-		if !errors.Is(err, fs.ErrExist) {
-			return 0, "", fmt.Errorf("failed to create network directory: %w", err)
+		if available {
+			return networkID, dirPath, nil
 		}
 
 		// Directory already exists, keep iterating
@@ -91,6 +130,30 @@ type LocalNetwork struct {
 
 	// Path where network configuration will be stored
 	Dir string
+
+	// Duration a node that has reported healthy may subsequently
+	// report unhealthy before it is considered to have failed. If
+	// zero, DefaultUnhealthyNodeGracePeriod is used.
+	UnhealthyNodeGracePeriod time.Duration
+
+	// Duration to wait for a single node to respond to a health query
+	// when taking a health snapshot. If zero,
+	// DefaultNodeHealthCheckTimeout is used.
+	NodeHealthCheckTimeout time.Duration
+
+	// Maximum number of node configurations to write concurrently. If
+	// zero, DefaultWriteNodesParallelism is used.
+	WriteNodesParallelism int
+
+	// Whether to reserve the HTTP and staking ports of each node before
+	// it is started. If false, nodes use dynamically allocated ports.
+	//
+	// Reserving ports avoids the possibility of nodes started at nearly
+	// the same time being allocated the same port. The ports are reserved
+	// again each time the network is started. Once a node has started, its
+	// stored configuration reverts to dynamically allocated ports so that
+	// restarting the node doesn't reuse ports that are no longer reserved.
+	ReservePorts bool
 }
 
 // Returns the configuration of the network in backend-agnostic form.
@@ -119,6 +182,70 @@ func (ln *LocalNetwork) AddEphemeralNode(w io.Writer, flags tmpnet.FlagsMap) (tm
 	}, true /* isEphemeral */)
 }
 
+// Adds the requested number of backend-agnostic ephemeral nodes to the
+// network. The bootstrap IPs and IDs are read once for all of the nodes,
+// and the nodes are started concurrently. If any node fails to start,
+// the nodes that were started are stopped.
+func (ln *LocalNetwork) AddEphemeralNodes(
+	ctx context.Context,
+	w io.Writer,
+	count int,
+	flags tmpnet.FlagsMap,
+) ([]tmpnet.Node, error) {
+	bootstrapIPs, bootstrapIDs, err := ln.GetBootstrapIPsAndIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		localNodes = make([]*LocalNode, count)
+		// Errors are indexed by node to keep their order deterministic
+		errs = make([]error, count)
+		wg   sync.WaitGroup
+	)
+	for i := range localNodes {
+		// Each node needs its own flags since they are modified when the
+		// node is configured.
+		nodeFlags := tmpnet.FlagsMap{}
+		nodeFlags.SetDefaults(flags)
+		localNodes[i] = &LocalNode{
+			NodeConfig: tmpnet.NodeConfig{
+				Flags: nodeFlags,
+			},
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			if err := ctx.Err(); err != nil {
+				errs[i] = err
+				return
+			}
+			_, errs[i] = ln.addLocalNode(w, localNodes[i], true /* isEphemeral */, bootstrapIPs, bootstrapIDs)
+		}(i)
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		// Attempt to stop the nodes that were started to provide some
+		// assurance to the caller that an error condition will not result
+		// in lingering processes.
+		for i, node := range localNodes {
+			if errs[i] == nil {
+				err = errors.Join(err, node.Stop())
+			}
+		}
+		return nil, fmt.Errorf("failed to add ephemeral nodes: %w", err)
+	}
+
+	nodes := make([]tmpnet.Node, count)
+	for i, node := range localNodes {
+		nodes[i] = node
+	}
+	return nodes, nil
+}
+
 // Starts a new network stored under the provided root dir. Required
 // configuration will be defaulted if not provided.
 func StartNetwork(
@@ -129,6 +256,10 @@ func StartNetwork(
 	nodeCount int,
 	keyCount int,
 ) (*LocalNetwork, error) {
+	if err := network.Validate(nodeCount, keyCount); err != nil {
+		return nil, err
+	}
+
 	if _, err := fmt.Fprintf(w, "Preparing configuration for new local network with %s\n", network.ExecPath); err != nil {
 		return nil, err
 	}
@@ -219,7 +350,64 @@ func StopNetwork(dir string) error {
 	return network.Stop()
 }
 
-// Ensure the network has the configuration it needs to start.
+// Installs the provided genesis for use by the network in place of a
+// generated genesis. The genesis is used verbatim, so it must define
+// any validators and funded keys required by the network.
+func (ln *LocalNetwork) SetGenesis(unparsed *genesis.UnparsedConfig) error {
+	if unparsed == nil {
+		return errMissingGenesis
+	}
+	if ln.Genesis != nil && ln.Genesis.NetworkID != unparsed.NetworkID {
+		return fmt.Errorf("%w: %d != %d", errGenesisNetworkID, unparsed.NetworkID, ln.Genesis.NetworkID)
+	}
+	if _, err := unparsed.Parse(); err != nil {
+		return fmt.Errorf("failed to parse genesis: %w", err)
+	}
+	ln.Genesis = unparsed
+	return nil
+}
+
+// Validate checks that the network can be populated with [nodeCount] nodes
+// and [keyCount] keys as by PopulateLocalNetworkConfig. This allows errors in
+// the network definition to be reported before anything is written to disk or
+// any node is started.
+func (ln *LocalNetwork) Validate(nodeCount int, keyCount int) error {
+	if nodeCount < 0 || keyCount < 0 {
+		return fmt.Errorf("%w: node count %d, key count %d", errNegativeCount, nodeCount, keyCount)
+	}
+	if len(ln.Nodes) > 0 && nodeCount > 0 {
+		return errInvalidNodeCount
+	}
+	if len(ln.FundedKeys) > 0 && keyCount > 0 {
+		return errInvalidKeyCount
+	}
+
+	if ln.Genesis != nil {
+		// The genesis provides the initial stakers
+		if len(ln.Genesis.InitialStakers) == 0 {
+			return errNoInitialStakers
+		}
+		return nil
+	}
+
+	// All initial nodes are genesis validators
+	if len(ln.Nodes) == 0 && nodeCount == 0 {
+		return errNoInitialStakers
+	}
+	nodeIDs := set.NewSet[ids.NodeID](len(ln.Nodes))
+	for _, node := range ln.Nodes {
+		nodeIDs.Add(node.NodeID)
+	}
+	for nodeID := range ln.GenesisValidators {
+		if !nodeIDs.Contains(nodeID) {
+			return fmt.Errorf("%w: %s", tmpnet.ErrUnknownGenesisValidator, nodeID)
+		}
+	}
+	return nil
+}
+
+// Ensure the network has the configuration it needs to start. Genesis
+// will only be generated if one was not provided (e.g. by SetGenesis).
 func (ln *LocalNetwork) PopulateLocalNetworkConfig(networkID uint32, nodeCount int, keyCount int) error {
 	if len(ln.Nodes) > 0 && nodeCount > 0 {
 		return errInvalidNodeCount
@@ -227,6 +415,9 @@ func (ln *LocalNetwork) PopulateLocalNetworkConfig(networkID uint32, nodeCount i
 	if len(ln.FundedKeys) > 0 && keyCount > 0 {
 		return errInvalidKeyCount
 	}
+	if ln.Genesis != nil && ln.Genesis.NetworkID != networkID {
+		return fmt.Errorf("%w: %d != %d", errGenesisNetworkID, ln.Genesis.NetworkID, networkID)
+	}
 
 	if nodeCount > 0 {
 		// Add the specified number of nodes
@@ -328,6 +519,11 @@ func (ln *LocalNetwork) Start(w io.Writer) error {
 		return errLocalNetworkDirNotSet
 	}
 
+	// Ensure nodes track the subnets they validate
+	if err := ln.TrackSubnets(); err != nil {
+		return err
+	}
+
 	// Ensure configuration on disk is current
 	if err := ln.WriteAll(); err != nil {
 		return err
@@ -345,9 +541,23 @@ func (ln *LocalNetwork) Start(w io.Writer) error {
 	bootstrapIDs := make([]string, 0, len(ln.Nodes))
 	bootstrapIPs := make([]string, 0, len(ln.Nodes))
 
+	var portReservations [][]net.Listener
+	if ln.ReservePorts {
+		var err error
+		portReservations, err = reserveNodePorts(ln.Nodes)
+		if err != nil {
+			return err
+		}
+		// Release the reservations of any nodes that were not started
+		defer func() {
+			_ = releaseNodePorts(portReservations)
+		}()
+	}
+
 	// Configure networking and start each node
-	for _, node := range ln.Nodes {
-		// Update network configuration
+	for i, node := range ln.Nodes {
+		// Update network configuration. Reserved ports will have
+		// already been set and take precedence over dynamic ports.
 		node.SetNetworkingConfigDefaults(0, 0, bootstrapIDs, bootstrapIPs)
 
 		// Write configuration to disk in preparation for node start
@@ -355,6 +565,14 @@ func (ln *LocalNetwork) Start(w io.Writer) error {
 			return err
 		}
 
+		if portReservations != nil {
+			err := releasePorts(portReservations[i])
+			portReservations[i] = nil
+			if err != nil {
+				return err
+			}
+		}
+
 		// Start waits for the process context to be written which
 		// indicates that the node will be accepting connections on
 		// its staking port. The network will start faster with this
@@ -363,6 +581,11 @@ func (ln *LocalNetwork) Start(w io.Writer) error {
 		if err := node.Start(w, ln.ExecPath); err != nil {
 			return err
 		}
+		if portReservations != nil {
+			if err := unsetReservedPorts(node); err != nil {
+				return err
+			}
+		}
 
 		// Collect bootstrap nodes for subsequently started nodes to use
 		bootstrapIDs = append(bootstrapIDs, node.NodeID.String())
@@ -372,30 +595,42 @@ func (ln *LocalNetwork) Start(w io.Writer) error {
 	return nil
 }
 
-// Wait until all nodes in the network are healthy.
+// Wait until all nodes in the network are healthy. A node that reports
+// unhealthy after having reported healthy is given the network's grace
+// period to recover before it is considered to have failed.
 func (ln *LocalNetwork) WaitForHealthy(ctx context.Context, w io.Writer) error {
 	ticker := time.NewTicker(networkHealthCheckInterval)
 	defer ticker.Stop()
 
-	healthyNodes := set.NewSet[ids.NodeID](len(ln.Nodes))
-	for healthyNodes.Len() < len(ln.Nodes) {
+	tracker := newNodeHealthTracker(ln.GetUnhealthyNodeGracePeriod())
+	for {
+		healthyNodes := 0
 		for _, node := range ln.Nodes {
-			if healthyNodes.Contains(node.NodeID) {
-				continue
-			}
-
 			healthy, err := node.IsHealthy(ctx)
 			if err != nil && !errors.Is(err, tmpnet.ErrNotRunning) {
 				return err
 			}
-			if !healthy {
-				continue
-			}
 
-			healthyNodes.Add(node.NodeID)
-			if _, err := fmt.Fprintf(w, "%s is healthy @ %s\n", node.NodeID, node.URI); err != nil {
+			transition, err := tracker.Observe(node.NodeID, healthy, time.Now())
+			if err != nil {
 				return err
 			}
+			switch transition {
+			case becameHealthy:
+				if _, err := fmt.Fprintf(w, "%s is healthy @ %s\n", node.NodeID, node.URI); err != nil {
+					return err
+				}
+			case becameUnhealthy:
+				if _, err := fmt.Fprintf(w, "%s reported unhealthy after being healthy\n", node.NodeID); err != nil {
+					return err
+				}
+			}
+			if healthy {
+				healthyNodes++
+			}
+		}
+		if healthyNodes == len(ln.Nodes) {
+			return nil
 		}
 
 		select {
@@ -404,7 +639,131 @@ func (ln *LocalNetwork) WaitForHealthy(ctx context.Context, w io.Writer) error {
 		case <-ticker.C:
 		}
 	}
-	return nil
+}
+
+// Returns the grace period to allow a previously-healthy node to recover
+// from reporting unhealthy.
+func (ln *LocalNetwork) GetUnhealthyNodeGracePeriod() time.Duration {
+	if ln.UnhealthyNodeGracePeriod > 0 {
+		return ln.UnhealthyNodeGracePeriod
+	}
+	return DefaultUnhealthyNodeGracePeriod
+}
+
+// Returns the duration to wait for a single node to respond to a
+// health query.
+func (ln *LocalNetwork) GetNodeHealthCheckTimeout() time.Duration {
+	if ln.NodeHealthCheckTimeout > 0 {
+		return ln.NodeHealthCheckTimeout
+	}
+	return DefaultNodeHealthCheckTimeout
+}
+
+// Returns the maximum number of node configurations to write
+// concurrently.
+func (ln *LocalNetwork) GetWriteNodesParallelism() int {
+	if ln.WriteNodesParallelism > 0 {
+		return ln.WriteNodesParallelism
+	}
+	return DefaultWriteNodesParallelism
+}
+
+// Returns the current health of every node in the network. Nodes are
+// queried concurrently and each query is bounded by the network's node
+// health check timeout, so a slow or unresponsive node is reported as
+// unhealthy rather than delaying the snapshot. An error is only
+// returned if the context is done before the snapshot is complete.
+func (ln *LocalNetwork) HealthSnapshot(ctx context.Context) (map[ids.NodeID]NodeHealthStatus, error) {
+	var (
+		timeout  = ln.GetNodeHealthCheckTimeout()
+		lock     sync.Mutex
+		wg       sync.WaitGroup
+		statuses = make(map[ids.NodeID]NodeHealthStatus, len(ln.Nodes))
+	)
+	for _, node := range ln.Nodes {
+		wg.Add(1)
+		go func(node *LocalNode) {
+			defer wg.Done()
+
+			nodeCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			healthy, err := node.IsHealthy(nodeCtx)
+			status := NodeHealthStatus{
+				URI:     node.URI,
+				Running: !errors.Is(err, tmpnet.ErrNotRunning),
+				Healthy: healthy,
+				Err:     err,
+			}
+
+			lock.Lock()
+			defer lock.Unlock()
+			statuses[node.NodeID] = status
+		}(node)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to complete health snapshot: %w", err)
+	}
+	return statuses, nil
+}
+
+// Returns the first node in the network that reports healthy.
+func (ln *LocalNetwork) GetHealthyNode(ctx context.Context) (*LocalNode, error) {
+	for _, node := range ln.Nodes {
+		healthy, err := node.IsHealthy(ctx)
+		if err != nil && !errors.Is(err, tmpnet.ErrNotRunning) {
+			return nil, err
+		}
+		if healthy {
+			return node, nil
+		}
+	}
+	return nil, errNoHealthyNodes
+}
+
+// Issues the provided tx to the P-Chain API of a healthy node and
+// waits until the tx is committed or the context is done. An error
+// is returned if the tx is aborted or dropped.
+func (ln *LocalNetwork) IssueAndConfirmTx(ctx context.Context, w io.Writer, tx *txs.Tx) error {
+	node, err := ln.GetHealthyNode(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to issue tx %s: %w", tx.ID(), err)
+	}
+
+	client := platformvm.NewClient(node.URI)
+	txID, err := client.IssueTx(ctx, tx.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to issue tx %s to node %s: %w", tx.ID(), node.NodeID, err)
+	}
+	if _, err := fmt.Fprintf(w, "Issued tx %s to %s\n", txID, node.NodeID); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(tmpnet.DefaultNodeTickerInterval)
+	defer ticker.Stop()
+
+	for {
+		res, err := client.GetTxStatus(ctx, txID)
+		if err == nil {
+			switch res.Status {
+			case status.Committed:
+				_, err := fmt.Fprintf(w, "Confirmed tx %s\n", txID)
+				return err
+			case status.Aborted:
+				return fmt.Errorf("tx %s was aborted", txID)
+			case status.Dropped:
+				return fmt.Errorf("tx %s was dropped: %s", txID, res.Reason)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("failed to see tx %s committed before timeout: %w", txID, ctx.Err())
+		case <-ticker.C:
+		}
+	}
 }
 
 // Retrieve API URIs for all running primary validator nodes. URIs for
@@ -496,9 +855,10 @@ func (ln *LocalNetwork) WriteCChainConfig() error {
 
 // Used to marshal/unmarshal persistent local network defaults.
 type localDefaults struct {
-	Flags      tmpnet.FlagsMap
-	ExecPath   string
-	FundedKeys []*secp256k1.PrivateKey
+	Flags        tmpnet.FlagsMap
+	ExecPath     string
+	FundedKeys   []*secp256k1.PrivateKey
+	ReservePorts bool
 }
 
 func (ln *LocalNetwork) GetDefaultsPath() string {
@@ -517,14 +877,16 @@ func (ln *LocalNetwork) ReadDefaults() error {
 	ln.DefaultFlags = defaults.Flags
 	ln.ExecPath = defaults.ExecPath
 	ln.FundedKeys = defaults.FundedKeys
+	ln.ReservePorts = defaults.ReservePorts
 	return nil
 }
 
 func (ln *LocalNetwork) WriteDefaults() error {
 	defaults := localDefaults{
-		Flags:      ln.DefaultFlags,
-		ExecPath:   ln.ExecPath,
-		FundedKeys: ln.FundedKeys,
+		Flags:        ln.DefaultFlags,
+		ExecPath:     ln.ExecPath,
+		FundedKeys:   ln.FundedKeys,
+		ReservePorts: ln.ReservePorts,
 	}
 	bytes, err := tmpnet.DefaultJSONMarshal(defaults)
 	if err != nil {
@@ -552,13 +914,33 @@ func (ln *LocalNetwork) WriteEnvFile() error {
 	return nil
 }
 
+// Write the configuration of every node in the network. Each node
+// writes to its own directory, so configurations are written
+// concurrently, bounded by the network's write parallelism.
 func (ln *LocalNetwork) WriteNodes() error {
-	for _, node := range ln.Nodes {
-		if err := node.WriteConfig(); err != nil {
-			return err
-		}
+	var (
+		// Errors are indexed by node to keep their order deterministic
+		errs = make([]error, len(ln.Nodes))
+		sem  = make(chan struct{}, ln.GetWriteNodesParallelism())
+		wg   sync.WaitGroup
+	)
+	for i, node := range ln.Nodes {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, node *LocalNode) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := node.WriteConfig(); err != nil {
+				errs[i] = fmt.Errorf("failed to write node %s: %w", node.NodeID, err)
+			}
+		}(i, node)
 	}
-	return nil
+	wg.Wait()
+
+	return errors.Join(errs...)
 }
 
 // Write network configuration to disk.
@@ -623,6 +1005,35 @@ func (ln *LocalNetwork) ReadNodes() error {
 	return nil
 }
 
+// Retrieve the IDs of the network's ephemeral nodes from the names of the
+// directories in the ephemeral dir. Node configuration is not read, so
+// this is cheaper than reading the ephemeral nodes when only their IDs
+// are required.
+func (ln *LocalNetwork) GetEphemeralNodeIDs() ([]ids.NodeID, error) {
+	entries, err := os.ReadDir(filepath.Join(ln.Dir, defaultEphemeralDirName))
+	if errors.Is(err, os.ErrNotExist) {
+		// No ephemeral nodes have been added to the network
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read ephemeral path: %w", err)
+	}
+
+	nodeIDs := make([]ids.NodeID, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		nodeID, err := ids.NodeIDFromString(entry.Name())
+		if err != nil {
+			// If the name is not a node ID, assume this is not the path of
+			// an ephemeral node
+			continue
+		}
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	return nodeIDs, nil
+}
+
 // Read network and node configuration from disk.
 func (ln *LocalNetwork) ReadAll() error {
 	if err := ln.ReadConfig(); err != nil {
@@ -632,6 +1043,23 @@ func (ln *LocalNetwork) ReadAll() error {
 }
 
 func (ln *LocalNetwork) AddLocalNode(w io.Writer, node *LocalNode, isEphemeral bool) (*LocalNode, error) {
+	bootstrapIPs, bootstrapIDs, err := ln.GetBootstrapIPsAndIDs()
+	if err != nil {
+		return nil, err
+	}
+	return ln.addLocalNode(w, node, isEphemeral, bootstrapIPs, bootstrapIDs)
+}
+
+// Adds a node that bootstraps from the provided nodes. Reading the
+// bootstrap IPs and IDs is left to the caller so that they can be
+// reused when adding multiple nodes.
+func (ln *LocalNetwork) addLocalNode(
+	w io.Writer,
+	node *LocalNode,
+	isEphemeral bool,
+	bootstrapIPs []string,
+	bootstrapIDs []string,
+) (*LocalNode, error) {
 	// Assume network configuration has been written to disk and is current in memory
 
 	if node == nil {
@@ -658,19 +1086,31 @@ func (ln *LocalNetwork) AddLocalNode(w io.Writer, node *LocalNode, isEphemeral b
 		return nil, err
 	}
 
-	bootstrapIPs, bootstrapIDs, err := ln.GetBootstrapIPsAndIDs()
-	if err != nil {
-		return nil, err
+	var (
+		portReservations [][]net.Listener
+		err              error
+	)
+	if ln.ReservePorts {
+		portReservations, err = reserveNodePorts([]*LocalNode{node})
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	var (
-		// Use dynamic port allocation.
+		// Use dynamic port allocation unless ports were reserved.
 		httpPort    uint16 = 0
 		stakingPort uint16 = 0
 	)
 	node.SetNetworkingConfigDefaults(httpPort, stakingPort, bootstrapIDs, bootstrapIPs)
 
 	if err := node.WriteConfig(); err != nil {
+		return nil, errors.Join(err, releaseNodePorts(portReservations))
+	}
+
+	// Release the reserved ports immediately before start to minimize
+	// the window in which they could be allocated to another process.
+	if err := releaseNodePorts(portReservations); err != nil {
 		return nil, err
 	}
 
@@ -684,6 +1124,11 @@ func (ln *LocalNetwork) AddLocalNode(w io.Writer, node *LocalNode, isEphemeral b
 		}
 		return nil, err
 	}
+	if ln.ReservePorts {
+		if err := unsetReservedPorts(node); err != nil {
+			return nil, err
+		}
+	}
 
 	return node, nil
 }
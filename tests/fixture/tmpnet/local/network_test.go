@@ -4,9 +4,36 @@
 package local
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/coreth/core"
+	"github.com/ava-labs/coreth/plugin/evm"
+
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/genesis"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/node"
+	"github.com/ava-labs/avalanchego/tests/fixture/tmpnet"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/perms"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/vms/platformvm/reward"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+
+	platformgenesis "github.com/ava-labs/avalanchego/vms/platformvm/genesis"
 )
 
 func TestNetworkSerialization(t *testing.T) {
@@ -14,7 +41,10 @@ func TestNetworkSerialization(t *testing.T) {
 
 	tmpDir := t.TempDir()
 
-	network := &LocalNetwork{Dir: tmpDir}
+	network := &LocalNetwork{
+		Dir:          tmpDir,
+		ReservePorts: true,
+	}
 	require.NoError(network.PopulateLocalNetworkConfig(1337, 1, 1))
 	require.NoError(network.WriteAll())
 
@@ -27,3 +57,398 @@ func TestNetworkSerialization(t *testing.T) {
 	}
 	require.Equal(network, loadedNetwork)
 }
+
+func TestNetworkSetGenesis(t *testing.T) {
+	require := require.New(t)
+
+	const networkID = 1338
+
+	node := NewLocalNode("")
+	require.NoError(node.EnsureKeys())
+	key, err := secp256k1.NewPrivateKey()
+	require.NoError(err)
+
+	customGenesis, err := tmpnet.NewTestGenesis(
+		networkID,
+		tmpnet.XChainBalanceMap{
+			key.Address(): tmpnet.DefaultFundedKeyXChainAmount,
+		},
+		core.GenesisAlloc{
+			evm.GetEthAddress(key): core.GenesisAccount{
+				Balance: tmpnet.DefaultFundedKeyCChainAmount,
+			},
+		},
+		[]ids.NodeID{node.NodeID},
+		nil,
+	)
+	require.NoError(err)
+	customGenesis.Message = "custom"
+
+	tmpDir := t.TempDir()
+	network := &LocalNetwork{
+		Dir:   tmpDir,
+		Nodes: []*LocalNode{node},
+	}
+	require.NoError(network.SetGenesis(customGenesis))
+
+	// The network ID of the provided genesis must be used
+	err = network.PopulateLocalNetworkConfig(networkID+1, 0, 0)
+	require.ErrorIs(err, errGenesisNetworkID)
+
+	require.NoError(network.PopulateLocalNetworkConfig(networkID, 0, 0))
+	require.Equal(customGenesis, network.Genesis)
+	require.NoError(network.WriteAll())
+
+	loadedNetwork, err := ReadNetwork(tmpDir)
+	require.NoError(err)
+	require.Equal(customGenesis, loadedNetwork.Genesis)
+	require.Len(loadedNetwork.Nodes, 1)
+	require.Equal(
+		strconv.FormatUint(networkID, 10),
+		loadedNetwork.Nodes[0].Flags[config.NetworkNameKey],
+	)
+
+	// A genesis for a different network can't replace the existing genesis
+	otherGenesis := *customGenesis
+	otherGenesis.NetworkID = networkID + 1
+	err = network.SetGenesis(&otherGenesis)
+	require.ErrorIs(err, errGenesisNetworkID)
+}
+
+// Creates a node whose process context reports it as running with an
+// API served by [handler]. If [handler] is nil, the node is not running.
+func newFakeHealthNode(t *testing.T, handler http.HandlerFunc) *LocalNode {
+	require := require.New(t)
+
+	n := NewLocalNode(t.TempDir())
+	n.NodeID = ids.GenerateTestNodeID()
+	if handler == nil {
+		return n
+	}
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	processContext, err := json.Marshal(node.NodeProcessContext{
+		PID: os.Getpid(),
+		URI: server.URL,
+	})
+	require.NoError(err)
+	require.NoError(os.WriteFile(n.GetProcessContextPath(), processContext, 0o600))
+	return n
+}
+
+func fakeHealthHandler(healthy bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","result":{"checks":{},"healthy":%t},"id":1}`, healthy)
+	}
+}
+
+func TestNetworkGenesisValidators(t *testing.T) {
+	require := require.New(t)
+
+	nodes := []*LocalNode{NewLocalNode(""), NewLocalNode(""), NewLocalNode("")}
+	for _, node := range nodes {
+		require.NoError(node.EnsureKeys())
+	}
+	network := &LocalNetwork{
+		Dir:   t.TempDir(),
+		Nodes: nodes,
+	}
+	// A single validator holds most of the stake
+	network.GenesisValidators = map[ids.NodeID]tmpnet.GenesisValidatorConfig{
+		nodes[0].NodeID: {
+			Weight:        3 * units.MegaAvax,
+			DelegationFee: reward.PercentDenominator / 10,
+		},
+		nodes[1].NodeID: {
+			Weight: 2 * units.KiloAvax,
+		},
+	}
+	require.NoError(network.PopulateLocalNetworkConfig(1339, 0, 1))
+
+	stakers := network.Genesis.InitialStakers
+	require.Len(stakers, 3)
+	require.Equal(nodes[0].NodeID, stakers[0].NodeID)
+	require.Equal(uint64(3*units.MegaAvax), stakers[0].Weight)
+	require.Equal(uint32(reward.PercentDenominator/10), stakers[0].DelegationFee)
+	require.Equal(nodes[1].NodeID, stakers[1].NodeID)
+	require.Equal(uint64(2*units.KiloAvax), stakers[1].Weight)
+	require.Equal(tmpnet.DefaultGenesisDelegationFee, stakers[1].DelegationFee)
+	require.Equal(nodes[2].NodeID, stakers[2].NodeID)
+	require.Equal(tmpnet.DefaultGenesisValidatorWeight, stakers[2].Weight)
+	require.Equal(tmpnet.DefaultGenesisDelegationFee, stakers[2].DelegationFee)
+
+	// Each genesis validator stakes its configured weight
+	genesisConfig, err := network.Genesis.Parse()
+	require.NoError(err)
+	genesisBytes, _, err := genesis.FromConfig(&genesisConfig)
+	require.NoError(err)
+	platformGenesis, err := platformgenesis.Parse(genesisBytes)
+	require.NoError(err)
+	weights := make(map[ids.NodeID]uint64)
+	for _, tx := range platformGenesis.Validators {
+		require.IsType(&txs.AddValidatorTx{}, tx.Unsigned)
+		validatorTx := tx.Unsigned.(*txs.AddValidatorTx)
+		weights[validatorTx.NodeID()] = validatorTx.Weight()
+	}
+	require.Equal(
+		map[ids.NodeID]uint64{
+			nodes[0].NodeID: 3 * units.MegaAvax,
+			nodes[1].NodeID: 2 * units.KiloAvax,
+			nodes[2].NodeID: tmpnet.DefaultGenesisValidatorWeight,
+		},
+		weights,
+	)
+
+	// Weights must be within the staking bounds
+	network.Genesis = nil
+	network.GenesisValidators[nodes[0].NodeID] = tmpnet.GenesisValidatorConfig{
+		Weight: units.Avax,
+	}
+	err = network.PopulateLocalNetworkConfig(1339, 0, 0)
+	require.ErrorIs(err, tmpnet.ErrInvalidGenesisWeight)
+}
+
+func TestNetworkWriteNodes(t *testing.T) {
+	require := require.New(t)
+
+	network := &LocalNetwork{
+		Dir:                   t.TempDir(),
+		WriteNodesParallelism: 3,
+	}
+	require.NoError(network.PopulateLocalNetworkConfig(1340, 10, 1))
+	require.NoError(network.WriteNodes())
+
+	for _, node := range network.Nodes {
+		loadedNode := NewLocalNode(node.GetDataDir())
+		require.NoError(loadedNode.ReadConfig())
+		require.Equal(node.NodeID, loadedNode.NodeID)
+	}
+
+	// A node that fails to write its config must not prevent the
+	// other nodes from being written.
+	blockingFile := filepath.Join(network.Dir, "file")
+	require.NoError(os.WriteFile(blockingFile, nil, perms.ReadWrite))
+	failingNode := network.Nodes[0]
+	failingNode.Flags[config.DataDirKey] = filepath.Join(blockingFile, "node")
+
+	otherNode := network.Nodes[1]
+	require.NoError(os.RemoveAll(otherNode.GetDataDir()))
+
+	// The error is OS-specific, but is always reported for the path.
+	err := network.WriteNodes()
+	var pathErr *fs.PathError
+	require.ErrorAs(err, &pathErr)
+	_, err = os.Stat(otherNode.GetConfigPath())
+	require.NoError(err)
+}
+
+func TestNetworkGetEphemeralNodeIDs(t *testing.T) {
+	require := require.New(t)
+
+	network := &LocalNetwork{
+		Dir: t.TempDir(),
+	}
+
+	// No ephemeral dir exists before an ephemeral node is added
+	nodeIDs, err := network.GetEphemeralNodeIDs()
+	require.NoError(err)
+	require.Empty(nodeIDs)
+
+	ephemeralDir := filepath.Join(network.Dir, defaultEphemeralDirName)
+	expectedNodeIDs := []ids.NodeID{
+		ids.GenerateTestNodeID(),
+		ids.GenerateTestNodeID(),
+	}
+	for _, nodeID := range expectedNodeIDs {
+		require.NoError(os.MkdirAll(filepath.Join(ephemeralDir, nodeID.String()), perms.ReadWriteExecute))
+	}
+
+	// Entries that are not node directories must be ignored
+	require.NoError(os.MkdirAll(filepath.Join(ephemeralDir, "not-a-node"), perms.ReadWriteExecute))
+	require.NoError(os.WriteFile(filepath.Join(ephemeralDir, ids.GenerateTestNodeID().String()), nil, perms.ReadWrite))
+
+	nodeIDs, err = network.GetEphemeralNodeIDs()
+	require.NoError(err)
+	require.ElementsMatch(expectedNodeIDs, nodeIDs)
+}
+
+func TestNetworkAddEphemeralNodesWithoutBootstrapNodes(t *testing.T) {
+	require := require.New(t)
+
+	network := &LocalNetwork{
+		Dir: t.TempDir(),
+	}
+	require.NoError(network.PopulateLocalNetworkConfig(1341, 2, 1))
+	require.NoError(network.WriteNodes())
+
+	// None of the nodes are running, so the ephemeral nodes can't be
+	// bootstrapped and none of them should be configured.
+	_, err := network.AddEphemeralNodes(context.Background(), io.Discard, 3, nil)
+	require.ErrorIs(err, errMissingBootstrapNodes)
+
+	nodeIDs, err := network.GetEphemeralNodeIDs()
+	require.NoError(err)
+	require.Empty(nodeIDs)
+}
+
+func TestNetworkHealthSnapshot(t *testing.T) {
+	require := require.New(t)
+
+	// Closed at the end of the test to release the hung request
+	hang := make(chan struct{})
+	defer close(hang)
+
+	var (
+		healthyNode      = newFakeHealthNode(t, fakeHealthHandler(true))
+		unhealthyNode    = newFakeHealthNode(t, fakeHealthHandler(false))
+		stoppedNode      = newFakeHealthNode(t, nil)
+		unresponsiveNode = newFakeHealthNode(t, func(http.ResponseWriter, *http.Request) {
+			<-hang
+		})
+		network = &LocalNetwork{
+			Nodes: []*LocalNode{
+				healthyNode,
+				unhealthyNode,
+				stoppedNode,
+				unresponsiveNode,
+			},
+			NodeHealthCheckTimeout: 100 * time.Millisecond,
+		}
+	)
+
+	statuses, err := network.HealthSnapshot(context.Background())
+	require.NoError(err)
+	require.Len(statuses, len(network.Nodes))
+
+	status := statuses[healthyNode.NodeID]
+	require.NoError(status.Err)
+	require.True(status.Running)
+	require.True(status.Healthy)
+	require.Equal(healthyNode.URI, status.URI)
+
+	status = statuses[unhealthyNode.NodeID]
+	require.NoError(status.Err)
+	require.True(status.Running)
+	require.False(status.Healthy)
+
+	status = statuses[stoppedNode.NodeID]
+	require.ErrorIs(status.Err, tmpnet.ErrNotRunning)
+	require.False(status.Running)
+	require.False(status.Healthy)
+	require.Empty(status.URI)
+
+	status = statuses[unresponsiveNode.NodeID]
+	require.ErrorIs(status.Err, context.DeadlineExceeded)
+	require.True(status.Running)
+	require.False(status.Healthy)
+}
+
+func TestPeekNextNetworkID(t *testing.T) {
+	require := require.New(t)
+
+	rootDir := t.TempDir()
+
+	networkID, err := PeekNextNetworkID(rootDir)
+	require.NoError(err)
+	require.Equal(uint32(1000), networkID)
+
+	// Peeking must not create the network directory
+	entries, err := os.ReadDir(rootDir)
+	require.NoError(err)
+	require.Empty(entries)
+
+	createdID, _, err := FindNextNetworkID(rootDir)
+	require.NoError(err)
+	require.Equal(networkID, createdID)
+
+	networkID, err = PeekNextNetworkID(rootDir)
+	require.NoError(err)
+	require.Equal(uint32(1001), networkID)
+
+	createdID, _, err = FindNextNetworkID(rootDir)
+	require.NoError(err)
+	require.Equal(networkID, createdID)
+}
+
+func TestNetworkValidate(t *testing.T) {
+	nodeWithKeys := NewLocalNode("")
+	require.NoError(t, nodeWithKeys.EnsureKeys())
+
+	tests := []struct {
+		name        string
+		network     *LocalNetwork
+		nodeCount   int
+		keyCount    int
+		expectedErr error
+	}{
+		{
+			name:      "generated nodes",
+			network:   &LocalNetwork{},
+			nodeCount: 1,
+			keyCount:  1,
+		},
+		{
+			name: "provided nodes with genesis validator config",
+			network: &LocalNetwork{
+				NetworkConfig: tmpnet.NetworkConfig{
+					GenesisValidators: map[ids.NodeID]tmpnet.GenesisValidatorConfig{
+						nodeWithKeys.NodeID: {},
+					},
+				},
+				Nodes: []*LocalNode{nodeWithKeys},
+			},
+			keyCount: 1,
+		},
+		{
+			name:        "negative node count",
+			network:     &LocalNetwork{},
+			nodeCount:   -1,
+			expectedErr: errNegativeCount,
+		},
+		{
+			name: "node count with provided nodes",
+			network: &LocalNetwork{
+				Nodes: []*LocalNode{nodeWithKeys},
+			},
+			nodeCount:   1,
+			expectedErr: errInvalidNodeCount,
+		},
+		{
+			name:        "no initial stakers",
+			network:     &LocalNetwork{},
+			keyCount:    1,
+			expectedErr: errNoInitialStakers,
+		},
+		{
+			name: "genesis without initial stakers",
+			network: &LocalNetwork{
+				NetworkConfig: tmpnet.NetworkConfig{
+					Genesis: &genesis.UnparsedConfig{},
+				},
+			},
+			nodeCount:   1,
+			expectedErr: errNoInitialStakers,
+		},
+		{
+			name: "unknown genesis validator",
+			network: &LocalNetwork{
+				NetworkConfig: tmpnet.NetworkConfig{
+					GenesisValidators: map[ids.NodeID]tmpnet.GenesisValidatorConfig{
+						ids.GenerateTestNodeID(): {},
+					},
+				},
+			},
+			nodeCount:   1,
+			expectedErr: tmpnet.ErrUnknownGenesisValidator,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.network.Validate(test.nodeCount, test.keyCount)
+			require.ErrorIs(t, err, test.expectedErr)
+		})
+	}
+}
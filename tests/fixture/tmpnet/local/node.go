@@ -19,6 +19,9 @@ import (
 
 	"github.com/spf13/cast"
 
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
 	"github.com/ava-labs/avalanchego/api/health"
 	"github.com/ava-labs/avalanchego/config"
 	"github.com/ava-labs/avalanchego/ids"
@@ -106,10 +109,55 @@ func (n *LocalNode) ReadConfig() error {
 	if err := config.EnsureNodeID(); err != nil {
 		return err
 	}
+	env, err := n.readEnv()
+	if err != nil {
+		return err
+	}
+	config.Env = env
 	n.NodeConfig = config
 	return nil
 }
 
+func (n *LocalNode) GetEnvPath() string {
+	return filepath.Join(n.GetDataDir(), "env.json")
+}
+
+// The env is stored separately from the flags since the config file
+// is supplied to the node.
+func (n *LocalNode) readEnv() (map[string]string, error) {
+	bytes, err := os.ReadFile(n.GetEnvPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local node env: %w", err)
+	}
+	env := map[string]string{}
+	if err := json.Unmarshal(bytes, &env); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal local node env: %w", err)
+	}
+	return env, nil
+}
+
+func (n *LocalNode) writeEnv() error {
+	if len(n.Env) == 0 {
+		// Ensure a previously written env is not read back
+		if err := os.Remove(n.GetEnvPath()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to remove local node env: %w", err)
+		}
+		return nil
+	}
+
+	bytes, err := tmpnet.DefaultJSONMarshal(n.Env)
+	if err != nil {
+		return fmt.Errorf("failed to marshal local node env: %w", err)
+	}
+	if err := os.WriteFile(n.GetEnvPath(), bytes, perms.ReadWrite); err != nil {
+		return fmt.Errorf("failed to write local node env: %w", err)
+	}
+	return nil
+}
+
 func (n *LocalNode) WriteConfig() error {
 	if err := os.MkdirAll(n.GetDataDir(), perms.ReadWriteExecute); err != nil {
 		return fmt.Errorf("failed to create node dir: %w", err)
@@ -123,7 +171,7 @@ func (n *LocalNode) WriteConfig() error {
 	if err := os.WriteFile(n.GetConfigPath(), bytes, perms.ReadWrite); err != nil {
 		return fmt.Errorf("failed to write local node config: %w", err)
 	}
-	return nil
+	return n.writeEnv()
 }
 
 func (n *LocalNode) GetProcessContextPath() string {
@@ -179,6 +227,9 @@ func (n *LocalNode) Start(w io.Writer, defaultExecPath string) error {
 	}
 
 	cmd := exec.Command(execPath, "--config-file", n.GetConfigPath())
+	if len(n.Env) > 0 {
+		cmd.Env = mergeEnv(os.Environ(), n.Env)
+	}
 	if err := cmd.Start(); err != nil {
 		return err
 	}
@@ -215,6 +266,21 @@ func (n *LocalNode) Start(w io.Writer, defaultExecPath string) error {
 	return err
 }
 
+// Returns [base] with the variables in [env] appended. Since the last
+// value of a duplicated key takes precedence, [env] overrides [base].
+func mergeEnv(base []string, env map[string]string) []string {
+	// Ensure a deterministic order
+	keys := maps.Keys(env)
+	slices.Sort(keys)
+
+	merged := make([]string, 0, len(base)+len(env))
+	merged = append(merged, base...)
+	for _, key := range keys {
+		merged = append(merged, key+"="+env[key])
+	}
+	return merged
+}
+
 // Retrieve the node process if it is running. As part of determining
 // process liveness, the node's process context will be refreshed if
 // live or cleared if not running.
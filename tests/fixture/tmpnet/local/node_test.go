@@ -22,3 +22,41 @@ func TestNodeSerialization(t *testing.T) {
 	require.NoError(err)
 	require.Equal(node, loadedNode)
 }
+
+func TestNodeEnvSerialization(t *testing.T) {
+	require := require.New(t)
+
+	tmpDir := t.TempDir()
+
+	node := NewLocalNode(tmpDir)
+	require.NoError(node.EnsureKeys())
+	node.Env = map[string]string{
+		"GOMAXPROCS": "2",
+	}
+	require.NoError(node.WriteConfig())
+
+	loadedNode, err := ReadNode(tmpDir)
+	require.NoError(err)
+	require.Equal(node, loadedNode)
+
+	// Clearing the env should also clear the persisted env
+	node.Env = nil
+	require.NoError(node.WriteConfig())
+
+	loadedNode, err = ReadNode(tmpDir)
+	require.NoError(err)
+	require.Equal(node, loadedNode)
+}
+
+func TestMergeEnv(t *testing.T) {
+	require := require.New(t)
+
+	merged := mergeEnv(
+		[]string{"A=1", "B=2"},
+		map[string]string{
+			"C": "3",
+			"B": "4",
+		},
+	)
+	require.Equal([]string{"A=1", "B=2", "B=4", "C=3"}, merged)
+}
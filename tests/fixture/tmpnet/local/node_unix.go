@@ -0,0 +1,43 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build !windows
+// +build !windows
+
+package local
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/ava-labs/avalanchego/tests/fixture/tmpnet"
+)
+
+// Suspends the node process by sending SIGSTOP. Unlike Stop, the
+// process context of the node is retained so that the node can be
+// resumed with Resume. While paused, the node will not respond to API
+// requests or network messages.
+func (n *LocalNode) Pause() error {
+	return n.signal(syscall.SIGSTOP)
+}
+
+// Resumes a node process suspended by Pause by sending SIGCONT.
+func (n *LocalNode) Resume() error {
+	return n.signal(syscall.SIGCONT)
+}
+
+// Sends the provided signal to the node process.
+func (n *LocalNode) signal(sig os.Signal) error {
+	proc, err := n.GetProcess()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve process to signal: %w", err)
+	}
+	if proc == nil {
+		return tmpnet.ErrNotRunning
+	}
+	if err := proc.Signal(sig); err != nil {
+		return fmt.Errorf("failed to send %s to pid %d: %w", sig, n.PID, err)
+	}
+	return nil
+}
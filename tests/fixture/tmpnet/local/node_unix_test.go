@@ -0,0 +1,96 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build !windows
+// +build !windows
+
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/node"
+)
+
+// Set to the data dir of the node that TestFakeNodeProcess should
+// serve health requests for.
+const fakeNodeDataDirEnvName = "TMPNET_FAKE_NODE_DATA_DIR"
+
+// Not a real test. Serves a healthy health API on behalf of the node
+// when run as a subprocess of TestNodePauseResume.
+func TestFakeNodeProcess(t *testing.T) {
+	dataDir := os.Getenv(fakeNodeDataDirEnvName)
+	if len(dataDir) == 0 {
+		t.Skip("only run as a subprocess of TestNodePauseResume")
+	}
+	require := require.New(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(err)
+
+	processContext, err := json.Marshal(node.NodeProcessContext{
+		PID: os.Getpid(),
+		URI: "http://" + listener.Addr().String(),
+	})
+	require.NoError(err)
+	require.NoError(os.WriteFile(NewLocalNode(dataDir).GetProcessContextPath(), processContext, 0o600))
+
+	server := &http.Server{
+		Handler:           fakeHealthHandler(true),
+		ReadHeaderTimeout: time.Second,
+	}
+	require.NoError(server.Serve(listener))
+}
+
+func TestNodePauseResume(t *testing.T) {
+	require := require.New(t)
+
+	dataDir := t.TempDir()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFakeNodeProcess$") // #nosec G204
+	cmd.Env = append(os.Environ(), fakeNodeDataDirEnvName+"="+dataDir)
+	require.NoError(cmd.Start())
+	t.Cleanup(func() {
+		// SIGKILL terminates the process even if it is paused
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	n := NewLocalNode(dataDir)
+	require.NoError(n.WaitForProcessContext(context.Background()))
+
+	isHealthy := func() (bool, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+		return n.IsHealthy(ctx)
+	}
+
+	healthy, err := isHealthy()
+	require.NoError(err)
+	require.True(healthy)
+
+	require.NoError(n.Pause())
+
+	// The paused process is still running but can't respond
+	proc, err := n.GetProcess()
+	require.NoError(err)
+	require.NotNil(proc)
+
+	healthy, err = isHealthy()
+	require.ErrorIs(err, context.DeadlineExceeded)
+	require.False(healthy)
+
+	require.NoError(n.Resume())
+
+	healthy, err = isHealthy()
+	require.NoError(err)
+	require.True(healthy)
+}
@@ -0,0 +1,21 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build windows
+// +build windows
+
+package local
+
+import "errors"
+
+var errPauseNotSupported = errors.New("pausing a node process is only supported on POSIX platforms")
+
+// Pausing a node process relies on SIGSTOP, which is not available on
+// windows.
+func (*LocalNode) Pause() error {
+	return errPauseNotSupported
+}
+
+func (*LocalNode) Resume() error {
+	return errPauseNotSupported
+}
@@ -0,0 +1,87 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package local
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/ava-labs/avalanchego/config"
+)
+
+// Reserves an HTTP and a staking port for each of the provided nodes
+// and configures the nodes to use them. A port is reserved by binding
+// a listener to it so that the OS can't assign the port to another
+// process before the node is started. The listeners for a node, at
+// the same index as the node, must be released immediately before
+// the node is started.
+func reserveNodePorts(nodes []*LocalNode) ([][]net.Listener, error) {
+	reservations := make([][]net.Listener, 0, len(nodes))
+	for _, node := range nodes {
+		listeners, ports, err := reservePorts(2)
+		if err != nil {
+			return nil, errors.Join(err, releaseNodePorts(reservations))
+		}
+		reservations = append(reservations, listeners)
+
+		// Explicitly set rather than defaulted to ensure that the
+		// ports previously assigned to the node are not reused.
+		node.Flags[config.HTTPPortKey] = ports[0]
+		node.Flags[config.StakingPortKey] = ports[1]
+	}
+	return reservations, nil
+}
+
+// Configures [node] to use dynamically allocated ports once it has
+// started with reserved ports. The reservations are released when the
+// node starts, so restarting the node with the same ports could collide
+// with another process.
+func unsetReservedPorts(node *LocalNode) error {
+	node.Flags[config.HTTPPortKey] = uint16(0)
+	node.Flags[config.StakingPortKey] = uint16(0)
+	return node.WriteConfig()
+}
+
+// Releases the ports reserved by reserveNodePorts. Reservations that
+// have already been released must be set to nil.
+func releaseNodePorts(reservations [][]net.Listener) error {
+	var errs []error
+	for _, listeners := range reservations {
+		if err := releasePorts(listeners); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Reserves the requested number of distinct localhost ports.
+func reservePorts(count int) ([]net.Listener, []uint16, error) {
+	var (
+		listeners = make([]net.Listener, 0, count)
+		ports     = make([]uint16, 0, count)
+	)
+	for i := 0; i < count; i++ {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return nil, nil, errors.Join(
+				fmt.Errorf("failed to reserve port: %w", err),
+				releasePorts(listeners),
+			)
+		}
+		listeners = append(listeners, listener)
+		ports = append(ports, uint16(listener.Addr().(*net.TCPAddr).Port))
+	}
+	return listeners, ports, nil
+}
+
+func releasePorts(listeners []net.Listener) error {
+	var errs []error
+	for _, listener := range listeners {
+		if err := listener.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to release port: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package local
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/utils/set"
+)
+
+func TestReserveNodePorts(t *testing.T) {
+	require := require.New(t)
+
+	const nodeCount = 50
+	nodes := make([]*LocalNode, nodeCount)
+	for i := range nodes {
+		nodes[i] = NewLocalNode("")
+		// Reserved ports must replace any previously assigned ports
+		nodes[i].Flags[config.HTTPPortKey] = uint16(0)
+	}
+
+	reservations, err := reserveNodePorts(nodes)
+	require.NoError(err)
+	require.Len(reservations, nodeCount)
+
+	ports := set.Set[uint16]{}
+	for i, node := range nodes {
+		httpPort, ok := node.Flags[config.HTTPPortKey].(uint16)
+		require.True(ok)
+		stakingPort, ok := node.Flags[config.StakingPortKey].(uint16)
+		require.True(ok)
+		require.NotZero(httpPort)
+		require.NotZero(stakingPort)
+
+		// Ports must be distinct across all nodes
+		require.False(ports.Contains(httpPort))
+		ports.Add(httpPort)
+		require.False(ports.Contains(stakingPort))
+		ports.Add(stakingPort)
+
+		// The ports configured for the node must be the reserved ports
+		require.Len(reservations[i], 2)
+		require.Equal(int(httpPort), reservations[i][0].Addr().(*net.TCPAddr).Port)
+		require.Equal(int(stakingPort), reservations[i][1].Addr().(*net.TCPAddr).Port)
+
+		// Reserved ports can't be bound by another listener
+		_, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", httpPort))
+		require.Error(err) //nolint:forbidigo // the error is OS-specific
+	}
+
+	require.NoError(releaseNodePorts(reservations))
+}
+
+func TestUnsetReservedPorts(t *testing.T) {
+	require := require.New(t)
+
+	node := NewLocalNode(t.TempDir())
+	require.NoError(node.EnsureKeys())
+	reservations, err := reserveNodePorts([]*LocalNode{node})
+	require.NoError(err)
+	require.NoError(releaseNodePorts(reservations))
+
+	// The stored config of the node must use dynamically allocated ports
+	require.NoError(unsetReservedPorts(node))
+	loadedNode := NewLocalNode(node.GetDataDir())
+	require.NoError(loadedNode.ReadConfig())
+	require.Equal(float64(0), loadedNode.Flags[config.HTTPPortKey])
+	require.Equal(float64(0), loadedNode.Flags[config.StakingPortKey])
+}
@@ -0,0 +1,236 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package local
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/perms"
+)
+
+var (
+	errNodeRunning          = errors.New("node is running")
+	errSnapshotDirNotEmpty  = errors.New("snapshot dir is not empty")
+	errUnsupportedFileType  = errors.New("unsupported file type")
+	errDataDirHashMismatch  = errors.New("copied data dir does not match the original")
+	errSnapshotNotDirectory = errors.New("snapshot path is not a directory")
+)
+
+// Copies the node's data dir to [destDir], which must not exist or be
+// empty. The node must be stopped so that its databases aren't modified
+// while they are being copied.
+func (n *LocalNode) SnapshotData(destDir string) error {
+	if err := n.ensureStopped(); err != nil {
+		return fmt.Errorf("failed to snapshot data of node %q: %w", n.NodeID, err)
+	}
+
+	entries, err := os.ReadDir(destDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read snapshot dir: %w", err)
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("%w: %s", errSnapshotDirNotEmpty, destDir)
+	}
+
+	return copyDataDir(n.GetDataDir(), destDir)
+}
+
+// Replaces the node's data dir with the snapshot at [srcDir] and reloads the
+// node's configuration from the restored data dir. The node must be stopped.
+func (n *LocalNode) RestoreData(srcDir string) error {
+	if err := n.ensureStopped(); err != nil {
+		return fmt.Errorf("failed to restore data of node %q: %w", n.NodeID, err)
+	}
+
+	info, err := os.Stat(srcDir)
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot dir: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%w: %s", errSnapshotNotDirectory, srcDir)
+	}
+
+	if err := replaceDataDir(srcDir, n.GetDataDir()); err != nil {
+		return fmt.Errorf("failed to restore data of node %q: %w", n.NodeID, err)
+	}
+	return n.ReadAll()
+}
+
+// Replaces [dataDir] with a copy of [srcDir]. The copy is made and verified
+// next to [dataDir] before replacing it, so that [dataDir] is left unmodified
+// if the copy fails.
+func replaceDataDir(srcDir string, dataDir string) error {
+	parentDir := filepath.Dir(dataDir)
+	if err := os.MkdirAll(parentDir, perms.ReadWriteExecute); err != nil {
+		return fmt.Errorf("failed to create parent of data dir: %w", err)
+	}
+	tmpDir, err := os.MkdirTemp(parentDir, filepath.Base(dataDir)+".restore-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary data dir: %w", err)
+	}
+	// Removes the copy if it wasn't moved into place
+	defer os.RemoveAll(tmpDir)
+
+	if err := copyDataDir(srcDir, tmpDir); err != nil {
+		return err
+	}
+
+	// A directory can't be renamed over a non-empty directory, so the
+	// existing data dir is moved aside until the copy is in place.
+	oldDir := tmpDir + ".old"
+	if err := os.Rename(dataDir, oldDir); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to move data dir: %w", err)
+		}
+		oldDir = ""
+	}
+	if err := os.Rename(tmpDir, dataDir); err != nil {
+		if len(oldDir) > 0 {
+			_ = os.Rename(oldDir, dataDir)
+		}
+		return fmt.Errorf("failed to move restored data dir: %w", err)
+	}
+	if len(oldDir) > 0 {
+		if err := os.RemoveAll(oldDir); err != nil {
+			return fmt.Errorf("failed to remove previous data dir: %w", err)
+		}
+	}
+	return nil
+}
+
+// Returns an error if the node's process is running.
+func (n *LocalNode) ensureStopped() error {
+	proc, err := n.GetProcess()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve process: %w", err)
+	}
+	if proc != nil {
+		return errNodeRunning
+	}
+	return nil
+}
+
+// Snapshots the data dir of every node to a subdirectory of [destDir] named
+// for the node's ID. All nodes must be stopped.
+func (ln *LocalNetwork) SnapshotData(destDir string) error {
+	for _, node := range ln.Nodes {
+		if err := node.SnapshotData(filepath.Join(destDir, node.NodeID.String())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Restores the data dir of every node from a snapshot taken by
+// SnapshotData. All nodes must be stopped.
+func (ln *LocalNetwork) RestoreData(srcDir string) error {
+	for _, node := range ln.Nodes {
+		if err := node.RestoreData(filepath.Join(srcDir, node.NodeID.String())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Copies the contents of [srcDir] to [destDir] and verifies that the copy has
+// the same contents as the original.
+func copyDataDir(srcDir string, destDir string) error {
+	err := filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		destPath := filepath.Join(destDir, relPath)
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(destPath, perms.ReadWriteExecute)
+		case d.Type().IsRegular():
+			return copyFile(path, destPath)
+		default:
+			return fmt.Errorf("%w: %s", errUnsupportedFileType, path)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to copy %s to %s: %w", srcDir, destDir, err)
+	}
+
+	srcHash, err := dataDirHash(srcDir)
+	if err != nil {
+		return err
+	}
+	destHash, err := dataDirHash(destDir)
+	if err != nil {
+		return err
+	}
+	if srcHash != destHash {
+		return fmt.Errorf("%w: %s", errDataDirHashMismatch, destDir)
+	}
+	return nil
+}
+
+func copyFile(srcPath string, destPath string) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	dest, err := os.OpenFile(destPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dest, src); err != nil {
+		_ = dest.Close()
+		return err
+	}
+	return dest.Close()
+}
+
+// Returns a hash of the relative paths and contents of the files in [dir].
+func dataDirHash(dir string) (ids.ID, error) {
+	hasher := sha256.New()
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		// Each file is hashed separately so that the contents of one file
+		// can't be confused with the path of the next.
+		fileHasher := sha256.New()
+		if _, err := io.Copy(fileHasher, f); err != nil {
+			return err
+		}
+		_, _ = hasher.Write([]byte(filepath.ToSlash(relPath) + "\x00"))
+		_, _ = hasher.Write(fileHasher.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return ids.Empty, fmt.Errorf("failed to hash %s: %w", dir, err)
+	}
+	return ids.ID(hasher.Sum(nil)), nil
+}
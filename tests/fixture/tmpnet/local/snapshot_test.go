@@ -0,0 +1,92 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package local
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/node"
+	"github.com/ava-labs/avalanchego/utils/perms"
+)
+
+func TestNodeSnapshotAndRestoreData(t *testing.T) {
+	require := require.New(t)
+
+	dataDir := filepath.Join(t.TempDir(), "node")
+	n := NewLocalNode(dataDir)
+	require.NoError(n.EnsureKeys())
+	require.NoError(n.WriteConfig())
+
+	// Simulate the databases written by a node
+	dbDir := filepath.Join(dataDir, "db", "network")
+	require.NoError(os.MkdirAll(dbDir, perms.ReadWriteExecute))
+	require.NoError(os.WriteFile(filepath.Join(dbDir, "000001.log"), []byte("data"), perms.ReadWrite))
+
+	originalHash, err := dataDirHash(dataDir)
+	require.NoError(err)
+
+	snapshotDir := filepath.Join(t.TempDir(), "snapshot")
+	require.NoError(n.SnapshotData(snapshotDir))
+
+	// A snapshot can't overwrite another snapshot
+	err = n.SnapshotData(snapshotDir)
+	require.ErrorIs(err, errSnapshotDirNotEmpty)
+
+	// Modify the data dir and then wipe it
+	require.NoError(os.WriteFile(filepath.Join(dbDir, "000002.log"), []byte("more data"), perms.ReadWrite))
+	require.NoError(os.RemoveAll(dataDir))
+
+	require.NoError(n.RestoreData(snapshotDir))
+	restoredHash, err := dataDirHash(dataDir)
+	require.NoError(err)
+	require.Equal(originalHash, restoredHash)
+
+	// Restoring over an existing data dir replaces its contents
+	require.NoError(os.WriteFile(filepath.Join(dbDir, "000002.log"), []byte("more data"), perms.ReadWrite))
+	require.NoError(n.RestoreData(snapshotDir))
+	restoredHash, err = dataDirHash(dataDir)
+	require.NoError(err)
+	require.Equal(originalHash, restoredHash)
+
+	// A snapshot that can't be copied leaves the data dir unmodified
+	require.NoError(os.Symlink(dbDir, filepath.Join(snapshotDir, "link")))
+	err = n.RestoreData(snapshotDir)
+	require.ErrorIs(err, errUnsupportedFileType)
+	restoredHash, err = dataDirHash(dataDir)
+	require.NoError(err)
+	require.Equal(originalHash, restoredHash)
+
+	// No temporary dirs are left next to the data dir
+	entries, err := os.ReadDir(filepath.Dir(dataDir))
+	require.NoError(err)
+	require.Len(entries, 1)
+}
+
+func TestNodeSnapshotDataRefusesRunningNode(t *testing.T) {
+	require := require.New(t)
+
+	dataDir := t.TempDir()
+	n := NewLocalNode(dataDir)
+	require.NoError(n.EnsureKeys())
+	require.NoError(n.WriteConfig())
+
+	// Report the test process as the node's process so that the node
+	// appears to be running.
+	processContextBytes, err := json.Marshal(node.NodeProcessContext{
+		PID: os.Getpid(),
+	})
+	require.NoError(err)
+	require.NoError(os.WriteFile(n.GetProcessContextPath(), processContextBytes, perms.ReadWrite))
+
+	err = n.SnapshotData(t.TempDir())
+	require.ErrorIs(err, errNodeRunning)
+
+	err = n.RestoreData(t.TempDir())
+	require.ErrorIs(err, errNodeRunning)
+}
@@ -0,0 +1,211 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package local
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/tests/fixture/tmpnet"
+	"github.com/ava-labs/avalanchego/utils/perms"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary/common"
+)
+
+const defaultSubnetDirName = "subnets"
+
+var (
+	errMissingSubnetName    = errors.New("failed to write subnet: name not set")
+	errMissingStakingConfig = errors.New("subnet has no staking config")
+)
+
+// SubnetTransformer issues the transaction that transforms a permissioned
+// subnet into an elastic subnet. It is implemented by the P-Chain wallet.
+type SubnetTransformer interface {
+	IssueTransformSubnetTx(
+		subnetID ids.ID,
+		assetID ids.ID,
+		initialSupply uint64,
+		maxSupply uint64,
+		minConsumptionRate uint64,
+		maxConsumptionRate uint64,
+		minValidatorStake uint64,
+		maxValidatorStake uint64,
+		minStakeDuration time.Duration,
+		maxStakeDuration time.Duration,
+		minDelegationFee uint32,
+		minDelegatorStake uint64,
+		maxValidatorWeightFactor byte,
+		uptimeRequirement uint32,
+		options ...common.Option,
+	) (*txs.Tx, error)
+}
+
+// Defines a subnet of a local network. Subnet definitions are stored in
+// the network's subnet dir so that nodes can be configured to track
+// the subnets they validate whenever the network is started.
+type LocalSubnet struct {
+	// Name of the subnet, used as the name of its definition file
+	Name string
+
+	// ID of the subnet on the P-Chain
+	SubnetID ids.ID
+
+	// IDs of the nodes validating the subnet
+	ValidatorIDs []ids.NodeID
+
+	// Optional staking rules of the subnet, applied by Transform. If nil,
+	// the subnet is a permissioned subnet.
+	StakingConfig *tmpnet.SubnetStakingConfig `json:",omitempty"`
+}
+
+// Transform the created subnet into an elastic subnet with the staking rules
+// of its StakingConfig. Stakers are rewarded with [assetID], of which
+// [maxSupply] - [initialSupply] is converted to staking rewards.
+func (s *LocalSubnet) Transform(
+	transformer SubnetTransformer,
+	assetID ids.ID,
+	initialSupply uint64,
+	maxSupply uint64,
+	minConsumptionRate uint64,
+	maxConsumptionRate uint64,
+	options ...common.Option,
+) (*txs.Tx, error) {
+	if s.StakingConfig == nil {
+		return nil, fmt.Errorf("failed to transform subnet %s: %w", s.Name, errMissingStakingConfig)
+	}
+	if err := s.StakingConfig.Verify(); err != nil {
+		return nil, fmt.Errorf("failed to transform subnet %s: %w", s.Name, err)
+	}
+	c := s.StakingConfig
+	return transformer.IssueTransformSubnetTx(
+		s.SubnetID,
+		assetID,
+		initialSupply,
+		maxSupply,
+		minConsumptionRate,
+		maxConsumptionRate,
+		c.MinValidatorStake,
+		c.MaxValidatorStake,
+		c.MinStakeDuration,
+		c.MaxStakeDuration,
+		c.MinDelegationFee,
+		c.MinDelegatorStake,
+		c.MaxValidatorWeightFactor,
+		c.UptimeRequirement,
+		options...,
+	)
+}
+
+func (ln *LocalNetwork) GetSubnetDir() string {
+	return filepath.Join(ln.Dir, defaultSubnetDirName)
+}
+
+// Write the subnet definition to the network's subnet dir.
+func (ln *LocalNetwork) WriteSubnet(subnet *LocalSubnet) error {
+	if len(subnet.Name) == 0 {
+		return errMissingSubnetName
+	}
+	if subnet.StakingConfig != nil {
+		if err := subnet.StakingConfig.Verify(); err != nil {
+			return fmt.Errorf("failed to write subnet %s: %w", subnet.Name, err)
+		}
+	}
+	if err := os.MkdirAll(ln.GetSubnetDir(), perms.ReadWriteExecute); err != nil {
+		return fmt.Errorf("failed to create subnet dir: %w", err)
+	}
+	bytes, err := tmpnet.DefaultJSONMarshal(subnet)
+	if err != nil {
+		return fmt.Errorf("failed to marshal subnet %s: %w", subnet.Name, err)
+	}
+	path := filepath.Join(ln.GetSubnetDir(), subnet.Name+".json")
+	if err := os.WriteFile(path, bytes, perms.ReadWrite); err != nil {
+		return fmt.Errorf("failed to write subnet %s: %w", subnet.Name, err)
+	}
+	return nil
+}
+
+// Read the subnet definitions stored in the network's subnet dir.
+func (ln *LocalNetwork) GetSubnets() ([]*LocalSubnet, error) {
+	entries, err := os.ReadDir(ln.GetSubnetDir())
+	if errors.Is(err, os.ErrNotExist) {
+		// No subnets have been defined
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read subnet dir: %w", err)
+	}
+
+	subnets := make([]*LocalSubnet, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		path := filepath.Join(ln.GetSubnetDir(), entry.Name())
+		bytes, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read subnet: %w", err)
+		}
+		subnet := &LocalSubnet{}
+		if err := json.Unmarshal(bytes, subnet); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal subnet %s: %w", path, err)
+		}
+		subnets = append(subnets, subnet)
+	}
+	return subnets, nil
+}
+
+// Ensure that each node validating one of the network's subnets is
+// configured to track the subnet. Subnets already tracked by a node
+// are preserved.
+func (ln *LocalNetwork) TrackSubnets() error {
+	subnets, err := ln.GetSubnets()
+	if err != nil {
+		return err
+	}
+
+	subnetIDsByNodeID := map[ids.NodeID][]ids.ID{}
+	for _, subnet := range subnets {
+		for _, nodeID := range subnet.ValidatorIDs {
+			subnetIDsByNodeID[nodeID] = append(subnetIDsByNodeID[nodeID], subnet.SubnetID)
+		}
+	}
+
+	for _, node := range ln.Nodes {
+		subnetIDs, ok := subnetIDsByNodeID[node.NodeID]
+		if !ok {
+			continue
+		}
+
+		trackedSubnets, err := node.Flags.GetStringVal(config.TrackSubnetsKey)
+		if err != nil {
+			return err
+		}
+		subnetIDStrs := set.Set[string]{}
+		for _, subnetIDStr := range strings.Split(trackedSubnets, ",") {
+			if len(subnetIDStr) > 0 {
+				subnetIDStrs.Add(subnetIDStr)
+			}
+		}
+		for _, subnetID := range subnetIDs {
+			subnetIDStrs.Add(subnetID.String())
+		}
+
+		sortedSubnetIDStrs := subnetIDStrs.List()
+		slices.Sort(sortedSubnetIDStrs)
+		node.Flags[config.TrackSubnetsKey] = strings.Join(sortedSubnetIDStrs, ",")
+	}
+	return nil
+}
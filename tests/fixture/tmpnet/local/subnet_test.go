@@ -0,0 +1,201 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package local
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/tests/fixture/tmpnet"
+	"github.com/ava-labs/avalanchego/vms/platformvm/reward"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary/common"
+)
+
+func TestNetworkTrackSubnets(t *testing.T) {
+	require := require.New(t)
+
+	network := &LocalNetwork{Dir: t.TempDir()}
+	require.NoError(network.PopulateLocalNetworkConfig(1341, 3, 1))
+
+	// Nodes without subnet definitions don't track any subnets
+	require.NoError(network.TrackSubnets())
+	for _, node := range network.Nodes {
+		require.NotContains(node.Flags, config.TrackSubnetsKey)
+	}
+
+	var (
+		subnetA = &LocalSubnet{
+			Name:         "a",
+			SubnetID:     ids.GenerateTestID(),
+			ValidatorIDs: []ids.NodeID{network.Nodes[0].NodeID, network.Nodes[1].NodeID},
+		}
+		subnetB = &LocalSubnet{
+			Name:         "b",
+			SubnetID:     ids.GenerateTestID(),
+			ValidatorIDs: []ids.NodeID{network.Nodes[1].NodeID},
+		}
+	)
+	require.NoError(network.WriteSubnet(subnetA))
+	require.NoError(network.WriteSubnet(subnetB))
+
+	subnets, err := network.GetSubnets()
+	require.NoError(err)
+	require.Equal([]*LocalSubnet{subnetA, subnetB}, subnets)
+
+	// Previously tracked subnets must be preserved
+	existingSubnetID := ids.GenerateTestID()
+	network.Nodes[0].Flags[config.TrackSubnetsKey] = existingSubnetID.String()
+
+	require.NoError(network.TrackSubnets())
+	// Tracking must be idempotent
+	require.NoError(network.TrackSubnets())
+
+	trackedSubnets := func(node *LocalNode) []string {
+		value, err := node.Flags.GetStringVal(config.TrackSubnetsKey)
+		require.NoError(err)
+		return strings.Split(value, ",")
+	}
+	require.ElementsMatch(
+		[]string{existingSubnetID.String(), subnetA.SubnetID.String()},
+		trackedSubnets(network.Nodes[0]),
+	)
+	require.ElementsMatch(
+		[]string{subnetA.SubnetID.String(), subnetB.SubnetID.String()},
+		trackedSubnets(network.Nodes[1]),
+	)
+	require.NotContains(network.Nodes[2].Flags, config.TrackSubnetsKey)
+
+	// The tracked subnets must be included in the written node config
+	require.NoError(network.WriteNodes())
+	loadedNode, err := ReadNode(network.Nodes[1].GetDataDir())
+	require.NoError(err)
+	require.ElementsMatch(
+		[]string{subnetA.SubnetID.String(), subnetB.SubnetID.String()},
+		trackedSubnets(loadedNode),
+	)
+}
+
+func TestWriteSubnetStakingConfig(t *testing.T) {
+	require := require.New(t)
+
+	network := &LocalNetwork{Dir: t.TempDir()}
+
+	subnet := &LocalSubnet{
+		Name:     "elastic",
+		SubnetID: ids.GenerateTestID(),
+		StakingConfig: &tmpnet.SubnetStakingConfig{
+			MinValidatorStake:        1,
+			MaxValidatorStake:        100,
+			MinDelegatorStake:        1,
+			MinDelegationFee:         .02 * reward.PercentDenominator,
+			UptimeRequirement:        .9 * reward.PercentDenominator,
+			MaxValidatorWeightFactor: 5,
+			MinStakeDuration:         time.Minute,
+			MaxStakeDuration:         time.Hour,
+		},
+	}
+	require.NoError(network.WriteSubnet(subnet))
+
+	subnets, err := network.GetSubnets()
+	require.NoError(err)
+	require.Equal([]*LocalSubnet{subnet}, subnets)
+
+	// Staking rules that couldn't be applied to the subnet are rejected
+	subnet.StakingConfig.MinStakeDuration = 2 * time.Hour
+	err = network.WriteSubnet(subnet)
+	require.ErrorIs(err, tmpnet.ErrInvalidSubnetStakingConfig)
+}
+
+// testSubnetTransformer builds the unsigned transaction that would be issued
+// to transform a subnet.
+type testSubnetTransformer struct{}
+
+func (testSubnetTransformer) IssueTransformSubnetTx(
+	subnetID ids.ID,
+	assetID ids.ID,
+	initialSupply uint64,
+	maxSupply uint64,
+	minConsumptionRate uint64,
+	maxConsumptionRate uint64,
+	minValidatorStake uint64,
+	maxValidatorStake uint64,
+	minStakeDuration time.Duration,
+	maxStakeDuration time.Duration,
+	minDelegationFee uint32,
+	minDelegatorStake uint64,
+	maxValidatorWeightFactor byte,
+	uptimeRequirement uint32,
+	_ ...common.Option,
+) (*txs.Tx, error) {
+	return &txs.Tx{Unsigned: &txs.TransformSubnetTx{
+		Subnet:                   subnetID,
+		AssetID:                  assetID,
+		InitialSupply:            initialSupply,
+		MaximumSupply:            maxSupply,
+		MinConsumptionRate:       minConsumptionRate,
+		MaxConsumptionRate:       maxConsumptionRate,
+		MinValidatorStake:        minValidatorStake,
+		MaxValidatorStake:        maxValidatorStake,
+		MinStakeDuration:         uint32(minStakeDuration / time.Second),
+		MaxStakeDuration:         uint32(maxStakeDuration / time.Second),
+		MinDelegationFee:         minDelegationFee,
+		MinDelegatorStake:        minDelegatorStake,
+		MaxValidatorWeightFactor: maxValidatorWeightFactor,
+		UptimeRequirement:        uptimeRequirement,
+	}}, nil
+}
+
+func TestSubnetTransform(t *testing.T) {
+	require := require.New(t)
+
+	subnet := &LocalSubnet{
+		Name:     "elastic",
+		SubnetID: ids.GenerateTestID(),
+	}
+	assetID := ids.GenerateTestID()
+
+	// A permissioned subnet can't be transformed
+	_, err := subnet.Transform(testSubnetTransformer{}, assetID, 1, 2, 0, 0)
+	require.ErrorIs(err, errMissingStakingConfig)
+
+	subnet.StakingConfig = &tmpnet.SubnetStakingConfig{
+		MinValidatorStake:        1,
+		MaxValidatorStake:        100,
+		MinDelegatorStake:        2,
+		MinDelegationFee:         .02 * reward.PercentDenominator,
+		UptimeRequirement:        .9 * reward.PercentDenominator,
+		MaxValidatorWeightFactor: 5,
+		MinStakeDuration:         time.Minute,
+		MaxStakeDuration:         time.Hour,
+	}
+	tx, err := subnet.Transform(testSubnetTransformer{}, assetID, 1, 2, 0, 0)
+	require.NoError(err)
+
+	// The subnet is given the staking rules of its config
+	require.Equal(&txs.TransformSubnetTx{
+		Subnet:                   subnet.SubnetID,
+		AssetID:                  assetID,
+		InitialSupply:            1,
+		MaximumSupply:            2,
+		MinValidatorStake:        1,
+		MaxValidatorStake:        100,
+		MinStakeDuration:         60,
+		MaxStakeDuration:         3600,
+		MinDelegationFee:         .02 * reward.PercentDenominator,
+		MinDelegatorStake:        2,
+		MaxValidatorWeightFactor: 5,
+		UptimeRequirement:        .9 * reward.PercentDenominator,
+	}, tx.Unsigned)
+
+	// Invalid staking rules aren't issued
+	subnet.StakingConfig.MinStakeDuration = 2 * time.Hour
+	_, err = subnet.Transform(testSubnetTransformer{}, assetID, 1, 2, 0, 0)
+	require.ErrorIs(err, tmpnet.ErrInvalidSubnetStakingConfig)
+}
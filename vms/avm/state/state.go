@@ -38,6 +38,11 @@ const (
 	blockIDCacheSize = 8192
 	blockCacheSize   = 2048
 
+	// Maximum number of bytes of UTXOs to cache. This allows 8192 UTXOs whose
+	// cache entries, including the UTXO ID and pointer overhead, average 256
+	// bytes.
+	utxoCacheSize = 8192 * 256
+
 	pruneCommitLimit           = 1024
 	pruneCommitSleepMultiplier = 5
 	pruneCommitSleepCap        = 10 * time.Second
@@ -227,7 +232,7 @@ func New(
 		return nil, err
 	}
 
-	utxoState, err := avax.NewMeteredUTXOState(utxoDB, parser.Codec(), metrics, trackChecksums)
+	utxoState, err := avax.NewMeteredUTXOState(utxoDB, parser.Codec(), metrics, utxoCacheSize, trackChecksums)
 	if err != nil {
 		return nil, err
 	}
@@ -19,14 +19,24 @@ func Produce(
 	txID ids.ID,
 	outs []*TransferableOutput,
 ) {
+	for _, utxo := range ProducedUTXOs(txID, outs) {
+		utxoDB.AddUTXO(utxo)
+	}
+}
+
+// Returns the UTXOs created by [outs].
+// [txID] is the ID of the tx that created [outs].
+func ProducedUTXOs(txID ids.ID, outs []*TransferableOutput) []*UTXO {
+	utxos := make([]*UTXO, len(outs))
 	for index, out := range outs {
-		utxoDB.AddUTXO(&UTXO{
+		utxos[index] = &UTXO{
 			UTXOID: UTXOID{
 				TxID:        txID,
 				OutputIndex: uint32(index),
 			},
 			Asset: out.Asset,
 			Out:   out.Output(),
-		})
+		}
 	}
+	return utxos
 }
@@ -13,10 +13,15 @@ import (
 	"github.com/ava-labs/avalanchego/database/linkeddb"
 	"github.com/ava-labs/avalanchego/database/prefixdb"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/units"
 )
 
 const (
-	utxoCacheSize  = 8192
+	// DefaultUTXOCacheSize is the default maximum number of bytes of UTXOs to
+	// cache.
+	DefaultUTXOCacheSize = 2 * units.MiB
+
 	indexCacheSize = 64
 )
 
@@ -33,6 +38,10 @@ type UTXOState interface {
 
 	// Checksum returns the current UTXOChecksum.
 	Checksum() ids.ID
+
+	// GetUTXOWithCacheHit attempts to load a utxo. The returned bool is true
+	// if the result, including a missing utxo, was served from the cache.
+	GetUTXOWithCacheHit(utxoID ids.ID) (*UTXO, bool, error)
 }
 
 // UTXOReader is a thin wrapper around a database to provide fetching of UTXOs.
@@ -43,6 +52,9 @@ type UTXOReader interface {
 	// [previous].
 	// If [previous] is not in the list, starts at beginning.
 	// Returns at most [limit] IDs.
+	// If there are no such IDs, an empty, non-nil slice is returned. Addresses
+	// aren't tracked, so an address that never had any UTXOs can't be
+	// distinguished from an address whose UTXOs have all been consumed.
 	UTXOIDs(addr []byte, previous ids.ID, limit int) ([]ids.ID, error)
 }
 
@@ -74,7 +86,7 @@ type utxoState struct {
 	codec codec.Manager
 
 	// UTXO ID -> *UTXO. If the *UTXO is nil the UTXO doesn't exist
-	utxoCache cache.Cacher[ids.ID, *UTXO]
+	utxoCache cache.Cacher[ids.ID, utxoAndSize]
 	utxoDB    database.Database
 
 	indexDB    database.Database
@@ -84,6 +96,19 @@ type utxoState struct {
 	checksum      ids.ID
 }
 
+type utxoAndSize struct {
+	utxo *UTXO
+	// size is the length of the serialized utxo.
+	size int
+}
+
+func utxoSize(_ ids.ID, u utxoAndSize) int {
+	if u.utxo == nil {
+		return ids.IDLen + constants.PointerOverhead
+	}
+	return ids.IDLen + u.size + constants.PointerOverhead
+}
+
 func NewUTXOState(
 	db database.Database,
 	codec codec.Manager,
@@ -92,7 +117,7 @@ func NewUTXOState(
 	s := &utxoState{
 		codec: codec,
 
-		utxoCache: &cache.LRU[ids.ID, *UTXO]{Size: utxoCacheSize},
+		utxoCache: cache.NewSizedLRU[ids.ID, utxoAndSize](DefaultUTXOCacheSize, utxoSize),
 		utxoDB:    prefixdb.New(utxoPrefix, db),
 
 		indexDB:    prefixdb.New(indexPrefix, db),
@@ -103,16 +128,19 @@ func NewUTXOState(
 	return s, s.initChecksum()
 }
 
+// NewMeteredUTXOState returns a UTXOState that reports cache metrics to
+// [metrics] and caches at most [utxoCacheSize] bytes of UTXOs.
 func NewMeteredUTXOState(
 	db database.Database,
 	codec codec.Manager,
 	metrics prometheus.Registerer,
+	utxoCacheSize int,
 	trackChecksum bool,
 ) (UTXOState, error) {
-	utxoCache, err := metercacher.New[ids.ID, *UTXO](
+	utxoCache, err := metercacher.New[ids.ID, utxoAndSize](
 		"utxo_cache",
 		metrics,
-		&cache.LRU[ids.ID, *UTXO]{Size: utxoCacheSize},
+		cache.NewSizedLRU[ids.ID, utxoAndSize](utxoCacheSize, utxoSize),
 	)
 	if err != nil {
 		return nil, err
@@ -144,30 +172,38 @@ func NewMeteredUTXOState(
 }
 
 func (s *utxoState) GetUTXO(utxoID ids.ID) (*UTXO, error) {
-	if utxo, found := s.utxoCache.Get(utxoID); found {
-		if utxo == nil {
-			return nil, database.ErrNotFound
+	utxo, _, err := s.GetUTXOWithCacheHit(utxoID)
+	return utxo, err
+}
+
+func (s *utxoState) GetUTXOWithCacheHit(utxoID ids.ID) (*UTXO, bool, error) {
+	if cached, found := s.utxoCache.Get(utxoID); found {
+		if cached.utxo == nil {
+			return nil, true, database.ErrNotFound
 		}
-		return utxo, nil
+		return cached.utxo, true, nil
 	}
 
 	bytes, err := s.utxoDB.Get(utxoID[:])
 	if err == database.ErrNotFound {
-		s.utxoCache.Put(utxoID, nil)
-		return nil, database.ErrNotFound
+		s.utxoCache.Put(utxoID, utxoAndSize{})
+		return nil, false, database.ErrNotFound
 	}
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	// The key was in the database
 	utxo := &UTXO{}
 	if _, err := s.codec.Unmarshal(bytes, utxo); err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
-	s.utxoCache.Put(utxoID, utxo)
-	return utxo, nil
+	s.utxoCache.Put(utxoID, utxoAndSize{
+		utxo: utxo,
+		size: len(bytes),
+	})
+	return utxo, false, nil
 }
 
 func (s *utxoState) PutUTXO(utxo *UTXO) error {
@@ -179,7 +215,10 @@ func (s *utxoState) PutUTXO(utxo *UTXO) error {
 	utxoID := utxo.InputID()
 	s.updateChecksum(utxoID)
 
-	s.utxoCache.Put(utxoID, utxo)
+	s.utxoCache.Put(utxoID, utxoAndSize{
+		utxo: utxo,
+		size: len(utxoBytes),
+	})
 	if err := s.utxoDB.Put(utxoID[:], utxoBytes); err != nil {
 		return err
 	}
@@ -210,7 +249,7 @@ func (s *utxoState) DeleteUTXO(utxoID ids.ID) error {
 
 	s.updateChecksum(utxoID)
 
-	s.utxoCache.Put(utxoID, nil)
+	s.utxoCache.Put(utxoID, utxoAndSize{})
 	if err := s.utxoDB.Delete(utxoID[:]); err != nil {
 		return err
 	}
@@ -235,7 +274,7 @@ func (s *utxoState) UTXOIDs(addr []byte, start ids.ID, limit int) ([]ids.ID, err
 	iter := indexList.NewIteratorWithStart(start[:])
 	defer iter.Release()
 
-	utxoIDs := []ids.ID(nil)
+	utxoIDs := []ids.ID{}
 	for len(utxoIDs) < limit && iter.Next() {
 		utxoID, err := ids.ToID(iter.Key())
 		if err != nil {
@@ -6,6 +6,8 @@ package avax
 import (
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus"
+
 	"github.com/stretchr/testify/require"
 
 	"github.com/ava-labs/avalanchego/codec"
@@ -13,6 +15,7 @@ import (
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/database/memdb"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
 )
 
@@ -92,3 +95,173 @@ func TestUTXOState(t *testing.T) {
 	require.NoError(err)
 	require.Equal([]ids.ID{utxoID}, utxoIDs)
 }
+
+func TestUTXOStateUTXOIDs(t *testing.T) {
+	c := linearcodec.NewDefault()
+	manager := codec.NewDefaultManager()
+	require.NoError(t, c.RegisterType(&secp256k1fx.TransferOutput{}))
+	require.NoError(t, manager.RegisterCodec(codecVersion, c))
+
+	newUTXOs := func(addr ids.ShortID, numUTXOs int) []*UTXO {
+		utxos := make([]*UTXO, numUTXOs)
+		for i := range utxos {
+			utxos[i] = &UTXO{
+				UTXOID: UTXOID{TxID: ids.GenerateTestID()},
+				Asset:  Asset{ID: ids.GenerateTestID()},
+				Out: &secp256k1fx.TransferOutput{
+					Amt: 12345,
+					OutputOwners: secp256k1fx.OutputOwners{
+						Threshold: 1,
+						Addrs:     []ids.ShortID{addr},
+					},
+				},
+			}
+		}
+		return utxos
+	}
+
+	var (
+		addr         = ids.GenerateTestShortID()
+		oneUTXO      = newUTXOs(addr, 1)
+		severalUTXOs = newUTXOs(addr, 3)
+	)
+	tests := []struct {
+		name     string
+		utxos    []*UTXO
+		start    func(utxoIDs []ids.ID) ids.ID
+		limit    int
+		expected func(utxoIDs []ids.ID) []ids.ID
+	}{
+		{
+			name:  "no utxos",
+			utxos: nil,
+			start: func([]ids.ID) ids.ID {
+				return ids.Empty
+			},
+			limit: 5,
+			expected: func([]ids.ID) []ids.ID {
+				return []ids.ID{}
+			},
+		},
+		{
+			name:  "one utxo equal to start",
+			utxos: oneUTXO,
+			start: func(utxoIDs []ids.ID) ids.ID {
+				return utxoIDs[0]
+			},
+			limit: 5,
+			expected: func([]ids.ID) []ids.ID {
+				return []ids.ID{}
+			},
+		},
+		{
+			name:  "several utxos from the first page",
+			utxos: severalUTXOs,
+			start: func([]ids.ID) ids.ID {
+				return ids.Empty
+			},
+			limit: 5,
+			expected: func(utxoIDs []ids.ID) []ids.ID {
+				return utxoIDs
+			},
+		},
+		{
+			name:  "several utxos limited",
+			utxos: severalUTXOs,
+			start: func([]ids.ID) ids.ID {
+				return ids.Empty
+			},
+			limit: 2,
+			expected: func(utxoIDs []ids.ID) []ids.ID {
+				return utxoIDs[:2]
+			},
+		},
+		{
+			name:  "several utxos after start",
+			utxos: severalUTXOs,
+			start: func(utxoIDs []ids.ID) ids.ID {
+				return utxoIDs[0]
+			},
+			limit: 5,
+			expected: func(utxoIDs []ids.ID) []ids.ID {
+				return utxoIDs[1:]
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			s, err := NewUTXOState(memdb.New(), manager, trackChecksum)
+			require.NoError(err)
+			for _, utxo := range test.utxos {
+				require.NoError(s.PutUTXO(utxo))
+			}
+
+			// Fetch every UTXO ID to learn the iteration order.
+			allUTXOIDs, err := s.UTXOIDs(addr[:], ids.Empty, len(test.utxos)+1)
+			require.NoError(err)
+			require.Len(allUTXOIDs, len(test.utxos))
+
+			utxoIDs, err := s.UTXOIDs(addr[:], test.start(allUTXOIDs), test.limit)
+			require.NoError(err)
+			require.NotNil(utxoIDs)
+			require.Equal(test.expected(allUTXOIDs), utxoIDs)
+		})
+	}
+}
+
+func TestUTXOStateCacheSize(t *testing.T) {
+	require := require.New(t)
+
+	c := linearcodec.NewDefault()
+	manager := codec.NewDefaultManager()
+	require.NoError(c.RegisterType(&secp256k1fx.TransferOutput{}))
+	require.NoError(manager.RegisterCodec(codecVersion, c))
+
+	newUTXO := func() *UTXO {
+		return &UTXO{
+			UTXOID: UTXOID{TxID: ids.GenerateTestID()},
+			Asset:  Asset{ID: ids.GenerateTestID()},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: 12345,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{ids.GenerateTestShortID()},
+				},
+			},
+		}
+	}
+	utxo0 := newUTXO()
+	utxo1 := newUTXO()
+
+	utxoBytes, err := manager.Marshal(codecVersion, utxo0)
+	require.NoError(err)
+	utxoSize := ids.IDLen + len(utxoBytes) + constants.PointerOverhead
+
+	// Only a single UTXO fits in the cache
+	s, err := NewMeteredUTXOState(memdb.New(), manager, prometheus.NewRegistry(), utxoSize, trackChecksum)
+	require.NoError(err)
+	utxoCache := s.(*utxoState).utxoCache
+
+	require.NoError(s.PutUTXO(utxo0))
+	require.Equal(1, utxoCache.Len())
+	require.Equal(1.0, utxoCache.PortionFilled())
+
+	require.NoError(s.PutUTXO(utxo1))
+	require.Equal(1, utxoCache.Len())
+	_, ok := utxoCache.Get(utxo0.InputID())
+	require.False(ok)
+
+	// Evicted UTXOs are read from the database
+	readUTXO, cacheHit, err := s.GetUTXOWithCacheHit(utxo0.InputID())
+	require.NoError(err)
+	require.False(cacheHit)
+	require.Equal(utxo0.InputID(), readUTXO.InputID())
+	_, ok = utxoCache.Get(utxo1.InputID())
+	require.False(ok)
+
+	_, cacheHit, err = s.GetUTXOWithCacheHit(utxo0.InputID())
+	require.NoError(err)
+	require.True(cacheHit)
+}
@@ -211,15 +211,31 @@ func bech32ToID(addrStr string) (ids.ShortID, error) {
 
 // BuildGenesis build the genesis state of the Platform Chain (and thereby the Avalanche network.)
 func (*StaticService) BuildGenesis(_ *http.Request, args *BuildGenesisArgs, reply *BuildGenesisReply) error {
+	_, bytes, err := BuildGenesis(args)
+	if err != nil {
+		return err
+	}
+	reply.Bytes, err = formatting.Encode(args.Encoding, bytes)
+	if err != nil {
+		return fmt.Errorf("couldn't encode genesis as string: %w", err)
+	}
+	reply.Encoding = args.Encoding
+	return nil
+}
+
+// BuildGenesis builds the genesis state of the Platform Chain. It returns both
+// the genesis state and its serialized bytes, so callers that need to inspect
+// the genesis state don't need to parse the bytes.
+func BuildGenesis(args *BuildGenesisArgs) (*genesis.Genesis, []byte, error) {
 	// Specify the UTXOs on the Platform chain that exist at genesis.
 	utxos := make([]*genesis.UTXO, 0, len(args.UTXOs))
 	for i, apiUTXO := range args.UTXOs {
 		if apiUTXO.Amount == 0 {
-			return errUTXOHasNoValue
+			return nil, nil, errUTXOHasNoValue
 		}
 		addrID, err := bech32ToID(apiUTXO.Address)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 
 		utxo := avax.UTXO{
@@ -245,7 +261,7 @@ func (*StaticService) BuildGenesis(_ *http.Request, args *BuildGenesisArgs, repl
 		}
 		messageBytes, err := formatting.Decode(args.Encoding, apiUTXO.Message)
 		if err != nil {
-			return fmt.Errorf("problem decoding UTXO message bytes: %w", err)
+			return nil, nil, fmt.Errorf("problem decoding UTXO message bytes: %w", err)
 		}
 		utxos = append(utxos, &genesis.UTXO{
 			UTXO:    utxo,
@@ -262,7 +278,7 @@ func (*StaticService) BuildGenesis(_ *http.Request, args *BuildGenesisArgs, repl
 		for i, apiUTXO := range vdr.Staked {
 			addrID, err := bech32ToID(apiUTXO.Address)
 			if err != nil {
-				return err
+				return nil, nil, err
 			}
 
 			utxo := &avax.TransferableOutput{
@@ -286,16 +302,16 @@ func (*StaticService) BuildGenesis(_ *http.Request, args *BuildGenesisArgs, repl
 
 			newWeight, err := math.Add64(weight, uint64(apiUTXO.Amount))
 			if err != nil {
-				return errStakeOverflow
+				return nil, nil, errStakeOverflow
 			}
 			weight = newWeight
 		}
 
 		if weight == 0 {
-			return errValidatorHasNoWeight
+			return nil, nil, errValidatorHasNoWeight
 		}
 		if uint64(vdr.EndTime) <= uint64(args.Time) {
-			return errValidatorAlreadyExited
+			return nil, nil, errValidatorAlreadyExited
 		}
 
 		owner := &secp256k1fx.OutputOwners{
@@ -305,7 +321,7 @@ func (*StaticService) BuildGenesis(_ *http.Request, args *BuildGenesisArgs, repl
 		for _, addrStr := range vdr.RewardOwner.Addresses {
 			addrID, err := bech32ToID(addrStr)
 			if err != nil {
-				return err
+				return nil, nil, err
 			}
 			owner.Addrs = append(owner.Addrs, addrID)
 		}
@@ -350,7 +366,7 @@ func (*StaticService) BuildGenesis(_ *http.Request, args *BuildGenesisArgs, repl
 		}
 
 		if err := tx.Initialize(txs.GenesisCodec); err != nil {
-			return err
+			return nil, nil, err
 		}
 
 		vdrs.Add(tx)
@@ -361,7 +377,7 @@ func (*StaticService) BuildGenesis(_ *http.Request, args *BuildGenesisArgs, repl
 	for _, chain := range args.Chains {
 		genesisBytes, err := formatting.Decode(args.Encoding, chain.GenesisData)
 		if err != nil {
-			return fmt.Errorf("problem decoding chain genesis data: %w", err)
+			return nil, nil, fmt.Errorf("problem decoding chain genesis data: %w", err)
 		}
 		tx := &txs.Tx{Unsigned: &txs.CreateChainTx{
 			BaseTx: txs.BaseTx{BaseTx: avax.BaseTx{
@@ -376,7 +392,7 @@ func (*StaticService) BuildGenesis(_ *http.Request, args *BuildGenesisArgs, repl
 			SubnetAuth:  &secp256k1fx.Input{},
 		}}
 		if err := tx.Initialize(txs.GenesisCodec); err != nil {
-			return err
+			return nil, nil, err
 		}
 
 		chains = append(chains, tx)
@@ -385,7 +401,7 @@ func (*StaticService) BuildGenesis(_ *http.Request, args *BuildGenesisArgs, repl
 	validatorTxs := vdrs.List()
 
 	// genesis holds the genesis state
-	g := genesis.Genesis{
+	g := &genesis.Genesis{
 		UTXOs:         utxos,
 		Validators:    validatorTxs,
 		Chains:        chains,
@@ -397,12 +413,7 @@ func (*StaticService) BuildGenesis(_ *http.Request, args *BuildGenesisArgs, repl
 	// Marshal genesis to bytes
 	bytes, err := genesis.Codec.Marshal(genesis.Version, g)
 	if err != nil {
-		return fmt.Errorf("couldn't marshal genesis: %w", err)
-	}
-	reply.Bytes, err = formatting.Encode(args.Encoding, bytes)
-	if err != nil {
-		return fmt.Errorf("couldn't encode genesis as string: %w", err)
+		return nil, nil, fmt.Errorf("couldn't marshal genesis: %w", err)
 	}
-	reply.Encoding = args.Encoding
-	return nil
+	return g, bytes, nil
 }
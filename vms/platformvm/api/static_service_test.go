@@ -237,6 +237,63 @@ func TestBuildGenesisReturnsSortedValidators(t *testing.T) {
 	require.Len(validators, 3)
 }
 
+func TestBuildGenesisReturnsParsedGenesis(t *testing.T) {
+	require := require.New(t)
+	nodeID := ids.BuildTestNodeID([]byte{1})
+	addr, err := address.FormatBech32(constants.UnitTestHRP, nodeID.Bytes())
+	require.NoError(err)
+
+	weight := json.Uint64(987654321)
+	args := BuildGenesisArgs{
+		AvaxAssetID: ids.ID{'d', 'u', 'm', 'm', 'y', ' ', 'I', 'D'},
+		UTXOs: []UTXO{{
+			Address: addr,
+			Amount:  123456789,
+		}},
+		Validators: []GenesisPermissionlessValidator{{
+			GenesisValidator: GenesisValidator{
+				EndTime: 20,
+				NodeID:  nodeID,
+			},
+			RewardOwner: &Owner{
+				Threshold: 1,
+				Addresses: []string{addr},
+			},
+			Staked: []UTXO{{
+				Amount:  weight,
+				Address: addr,
+			}},
+		}},
+		Time:          5,
+		InitialSupply: 360,
+		Message:       "hello",
+		Encoding:      formatting.Hex,
+	}
+
+	builtGenesis, genesisBytes, err := BuildGenesis(&args)
+	require.NoError(err)
+
+	// The bytes must match the ones returned by the static service.
+	reply := BuildGenesisReply{}
+	ss := StaticService{}
+	require.NoError(ss.BuildGenesis(nil, &args, &reply))
+	replyBytes, err := formatting.Decode(reply.Encoding, reply.Bytes)
+	require.NoError(err)
+	require.Equal(replyBytes, genesisBytes)
+
+	// The returned genesis must match the parsed bytes.
+	parsedGenesis, err := genesis.Parse(genesisBytes)
+	require.NoError(err)
+	require.Equal(parsedGenesis.Timestamp, builtGenesis.Timestamp)
+	require.Equal(parsedGenesis.InitialSupply, builtGenesis.InitialSupply)
+	require.Equal(parsedGenesis.Message, builtGenesis.Message)
+	require.Len(builtGenesis.UTXOs, 1)
+	require.Equal(parsedGenesis.UTXOs[0].InputID(), builtGenesis.UTXOs[0].InputID())
+	require.Len(builtGenesis.Validators, 1)
+	require.Equal(parsedGenesis.Validators[0].ID(), builtGenesis.Validators[0].ID())
+	require.Empty(builtGenesis.Chains)
+}
+
 func TestUTXOLess(t *testing.T) {
 	var (
 		smallerAddr = ids.ShortID{}
@@ -282,10 +282,14 @@ func (a *acceptor) commonAccept(b block.Block) error {
 		return fmt.Errorf("failed to accept block %s: %w", blkID, err)
 	}
 
+	// The block must be added before it is marked as last accepted, so that a
+	// block that fails its linkage check is never reported as accepted.
+	if err := a.state.AddStatelessBlock(b); err != nil {
+		return fmt.Errorf("failed to accept block %s: %w", blkID, err)
+	}
 	a.backend.lastAccepted = blkID
 	a.state.SetLastAccepted(blkID)
 	a.state.SetHeight(b.Height())
-	a.state.AddStatelessBlock(b)
 	a.validators.OnAcceptedBlockID(blkID)
 	return nil
 }
@@ -4,7 +4,9 @@
 package executor
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -156,6 +158,44 @@ func TestAcceptorVisitAtomicBlock(t *testing.T) {
 	require.NoError(acceptor.ApricotAtomicBlock(blk))
 }
 
+func TestAcceptorAddStatelessBlockFails(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	s := state.NewMockState(ctrl)
+
+	parentID := ids.GenerateTestID()
+	acceptor := &acceptor{
+		backend: &backend{
+			lastAccepted: parentID,
+			blkIDToState: make(map[ids.ID]*blockState),
+			state:        s,
+			ctx: &snow.Context{
+				Log: logging.NoLog{},
+			},
+		},
+		metrics:    metrics.Noop,
+		validators: validators.TestManager,
+	}
+
+	blk, err := block.NewBanffStandardBlock(
+		time.Time{},
+		parentID,
+		1,
+		nil,
+	)
+	require.NoError(err)
+
+	// The last accepted block and height must not be updated if the block
+	// can't be added.
+	errAddBlock := errors.New("failed to add block")
+	s.EXPECT().AddStatelessBlock(blk).Return(errAddBlock).Times(1)
+
+	err = acceptor.BanffStandardBlock(blk)
+	require.ErrorIs(err, errAddBlock)
+	require.Equal(parentID, acceptor.backend.lastAccepted)
+}
+
 func TestAcceptorVisitStandardBlock(t *testing.T) {
 	require := require.New(t)
 	ctrl := gomock.NewController(t)
@@ -296,14 +336,14 @@ func TestAcceptorVisitCommitBlock(t *testing.T) {
 	// Make sure the parent is accepted first.
 	gomock.InOrder(
 		parentStatelessBlk.EXPECT().ID().Return(parentID).Times(2),
+		s.EXPECT().AddStatelessBlock(parentState.statelessBlock).Times(1),
 		s.EXPECT().SetLastAccepted(parentID).Times(1),
 		parentStatelessBlk.EXPECT().Height().Return(blk.Height()-1).Times(1),
 		s.EXPECT().SetHeight(blk.Height()-1).Times(1),
-		s.EXPECT().AddStatelessBlock(parentState.statelessBlock).Times(1),
 
+		s.EXPECT().AddStatelessBlock(blk).Times(1),
 		s.EXPECT().SetLastAccepted(blkID).Times(1),
 		s.EXPECT().SetHeight(blk.Height()).Times(1),
-		s.EXPECT().AddStatelessBlock(blk).Times(1),
 	)
 
 	err = acceptor.ApricotCommitBlock(blk)
@@ -320,14 +360,14 @@ func TestAcceptorVisitCommitBlock(t *testing.T) {
 	// Make sure the parent is accepted first.
 	gomock.InOrder(
 		parentStatelessBlk.EXPECT().ID().Return(parentID).Times(2),
+		s.EXPECT().AddStatelessBlock(parentState.statelessBlock).Times(1),
 		s.EXPECT().SetLastAccepted(parentID).Times(1),
 		parentStatelessBlk.EXPECT().Height().Return(blk.Height()-1).Times(1),
 		s.EXPECT().SetHeight(blk.Height()-1).Times(1),
-		s.EXPECT().AddStatelessBlock(parentState.statelessBlock).Times(1),
 
+		s.EXPECT().AddStatelessBlock(blk).Times(1),
 		s.EXPECT().SetLastAccepted(blkID).Times(1),
 		s.EXPECT().SetHeight(blk.Height()).Times(1),
-		s.EXPECT().AddStatelessBlock(blk).Times(1),
 
 		onAcceptState.EXPECT().Apply(s).Times(1),
 		s.EXPECT().Commit().Return(nil).Times(1),
@@ -387,14 +427,14 @@ func TestAcceptorVisitAbortBlock(t *testing.T) {
 	// Make sure the parent is accepted first.
 	gomock.InOrder(
 		parentStatelessBlk.EXPECT().ID().Return(parentID).Times(2),
+		s.EXPECT().AddStatelessBlock(parentState.statelessBlock).Times(1),
 		s.EXPECT().SetLastAccepted(parentID).Times(1),
 		parentStatelessBlk.EXPECT().Height().Return(blk.Height()-1).Times(1),
 		s.EXPECT().SetHeight(blk.Height()-1).Times(1),
-		s.EXPECT().AddStatelessBlock(parentState.statelessBlock).Times(1),
 
+		s.EXPECT().AddStatelessBlock(blk).Times(1),
 		s.EXPECT().SetLastAccepted(blkID).Times(1),
 		s.EXPECT().SetHeight(blk.Height()).Times(1),
-		s.EXPECT().AddStatelessBlock(blk).Times(1),
 	)
 
 	err = acceptor.ApricotAbortBlock(blk)
@@ -412,14 +452,14 @@ func TestAcceptorVisitAbortBlock(t *testing.T) {
 	// Make sure the parent is accepted first.
 	gomock.InOrder(
 		parentStatelessBlk.EXPECT().ID().Return(parentID).Times(2),
+		s.EXPECT().AddStatelessBlock(parentState.statelessBlock).Times(1),
 		s.EXPECT().SetLastAccepted(parentID).Times(1),
 		parentStatelessBlk.EXPECT().Height().Return(blk.Height()-1).Times(1),
 		s.EXPECT().SetHeight(blk.Height()-1).Times(1),
-		s.EXPECT().AddStatelessBlock(parentState.statelessBlock).Times(1),
 
+		s.EXPECT().AddStatelessBlock(blk).Times(1),
 		s.EXPECT().SetLastAccepted(blkID).Times(1),
 		s.EXPECT().SetHeight(blk.Height()).Times(1),
-		s.EXPECT().AddStatelessBlock(blk).Times(1),
 
 		onAcceptState.EXPECT().Apply(s).Times(1),
 		s.EXPECT().Commit().Return(nil).Times(1),
@@ -5,7 +5,11 @@ package executor
 
 import (
 	"errors"
+	"fmt"
 
+	"golang.org/x/exp/maps"
+
+	"github.com/ava-labs/avalanchego/chains/atomic"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
 	"github.com/ava-labs/avalanchego/vms/platformvm/block"
@@ -21,6 +25,9 @@ var (
 	_ Manager = (*manager)(nil)
 
 	ErrChainNotSynced = errors.New("chain not synced")
+
+	errBlockAlreadyAccepted      = errors.New("block already accepted")
+	errBlockNotAboveLastAccepted = errors.New("block height is not above the last accepted height")
 )
 
 type Manager interface {
@@ -39,6 +46,28 @@ type Manager interface {
 	// VerifyTx verifies that the transaction can be issued based on the currently
 	// preferred state. This should *not* be used to verify transactions in a block.
 	VerifyTx(tx *txs.Tx) error
+
+	// PreviewBlockEffects executes [blk] on top of the state of its parent and
+	// returns the resulting changes. The block is not registered as
+	// processing, its transactions are not removed from the mempool, and
+	// nothing is committed. This is intended for debugging state divergence.
+	//
+	// Blocks that were already accepted, or that are not above the last
+	// accepted block, can't be executed on top of their parent's state, so an
+	// error is returned for them.
+	PreviewBlockEffects(blk block.Block) (*BlockEffects, error)
+}
+
+// BlockEffects are the changes that would result from accepting a block.
+type BlockEffects struct {
+	// State after accepting the block. Nil for proposal blocks.
+	OnAcceptState state.Diff
+	// States after committing or aborting the block. Only set for proposal
+	// blocks.
+	OnCommitState state.Diff
+	OnAbortState  state.Diff
+	// Shared memory operations to apply to other chains.
+	AtomicRequests map[ids.ID]*atomic.Requests
 }
 
 func NewManager(
@@ -129,3 +158,59 @@ func (m *manager) VerifyTx(tx *txs.Tx) error {
 		Tx:            tx,
 	})
 }
+
+func (m *manager) PreviewBlockEffects(blk block.Block) (*BlockEffects, error) {
+	blkID := blk.ID()
+	accepted, err := m.state.HasBlock(blkID)
+	if err != nil {
+		return nil, err
+	}
+	if accepted {
+		return nil, fmt.Errorf("%w: %s", errBlockAlreadyAccepted, blkID)
+	}
+
+	lastAccepted, err := m.state.GetStatelessBlock(m.lastAccepted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last accepted block: %w", err)
+	}
+	if height, lastAcceptedHeight := blk.Height(), lastAccepted.Height(); height <= lastAcceptedHeight {
+		return nil, fmt.Errorf("%w: block %s has height %d, last accepted height is %d",
+			errBlockNotAboveLastAccepted, blkID, height, lastAcceptedHeight,
+		)
+	}
+
+	// Verify the block with a copy of the backend so that the processing
+	// blocks and the mempool are left untouched.
+	previewBackend := &backend{
+		Mempool:      &previewMempool{Mempool: m.Mempool},
+		lastAccepted: m.lastAccepted,
+		blkIDToState: maps.Clone(m.blkIDToState),
+		state:        m.state,
+		ctx:          m.ctx,
+	}
+	err = blk.Visit(&verifier{
+		backend:           previewBackend,
+		txExecutorBackend: m.txExecutorBackend,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	blkState := previewBackend.blkIDToState[blkID]
+	return &BlockEffects{
+		OnAcceptState:  blkState.onAcceptState,
+		OnCommitState:  blkState.onCommitState,
+		OnAbortState:   blkState.onAbortState,
+		AtomicRequests: blkState.atomicRequests,
+	}, nil
+}
+
+// previewMempool ignores the removal of txs included in a previewed block and
+// the dropping of txs that failed verification.
+type previewMempool struct {
+	mempool.Mempool
+}
+
+func (*previewMempool) Remove([]*txs.Tx) {}
+
+func (*previewMempool) MarkDropped(ids.ID, error) {}
@@ -12,8 +12,12 @@ import (
 
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
 	"github.com/ava-labs/avalanchego/vms/platformvm/block"
 	"github.com/ava-labs/avalanchego/vms/platformvm/state"
+	"github.com/ava-labs/avalanchego/vms/platformvm/status"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs/executor"
 )
 
 func TestGetBlock(t *testing.T) {
@@ -72,3 +76,114 @@ func TestManagerLastAccepted(t *testing.T) {
 
 	require.Equal(t, lastAcceptedID, manager.LastAccepted())
 }
+
+func TestManagerPreviewBlockEffects(t *testing.T) {
+	require := require.New(t)
+	env := newEnvironment(t, nil)
+	defer func() {
+		require.NoError(shutdownEnvironment(env))
+	}()
+
+	tx, err := env.txBuilder.NewCreateSubnetTx(
+		1, // threshold
+		[]ids.ShortID{preFundedKeys[0].PublicKey().Address()},
+		[]*secp256k1.PrivateKey{preFundedKeys[0]},
+		preFundedKeys[0].PublicKey().Address(),
+	)
+	require.NoError(err)
+	require.NoError(env.mempool.Add(tx))
+
+	lastAcceptedID := env.state.GetLastAccepted()
+	lastAccepted, err := env.state.GetStatelessBlock(lastAcceptedID)
+	require.NoError(err)
+	blk, err := block.NewApricotStandardBlock(
+		lastAcceptedID,
+		lastAccepted.Height()+1,
+		[]*txs.Tx{tx},
+	)
+	require.NoError(err)
+
+	effects, err := env.blkManager.PreviewBlockEffects(blk)
+	require.NoError(err)
+	require.NotNil(effects.OnAcceptState)
+	require.Nil(effects.OnCommitState)
+	require.Nil(effects.OnAbortState)
+
+	// The previewed state includes the block's tx and its spent inputs.
+	_, txStatus, err := effects.OnAcceptState.GetTx(tx.ID())
+	require.NoError(err)
+	require.Equal(status.Committed, txStatus)
+	for _, in := range tx.Unsigned.InputIDs().List() {
+		_, err := effects.OnAcceptState.GetUTXO(in)
+		require.ErrorIs(err, database.ErrNotFound)
+	}
+
+	// Previewing the block must not modify the manager, the mempool, or the
+	// committed state.
+	require.NotContains(env.blkManager.(*manager).blkIDToState, blk.ID())
+	require.True(env.mempool.Has(tx.ID()))
+	_, _, err = env.state.GetTx(tx.ID())
+	require.ErrorIs(err, database.ErrNotFound)
+}
+
+func TestManagerPreviewBlockEffectsFailure(t *testing.T) {
+	require := require.New(t)
+	env := newEnvironment(t, nil)
+	defer func() {
+		require.NoError(shutdownEnvironment(env))
+	}()
+
+	// Reward txs can't be included in standard blocks, so the block is
+	// invalid.
+	tx := &txs.Tx{
+		Unsigned: &txs.RewardValidatorTx{
+			TxID: ids.GenerateTestID(),
+		},
+	}
+	require.NoError(tx.Initialize(txs.Codec))
+
+	lastAcceptedID := env.state.GetLastAccepted()
+	lastAccepted, err := env.state.GetStatelessBlock(lastAcceptedID)
+	require.NoError(err)
+	blk, err := block.NewApricotStandardBlock(
+		lastAcceptedID,
+		lastAccepted.Height()+1,
+		[]*txs.Tx{tx},
+	)
+	require.NoError(err)
+
+	_, err = env.blkManager.PreviewBlockEffects(blk)
+	require.ErrorIs(err, executor.ErrWrongTxType)
+
+	// A failed preview must not mark the tx as dropped.
+	require.NoError(env.mempool.GetDropReason(tx.ID()))
+}
+
+func TestManagerPreviewBlockEffectsAccepted(t *testing.T) {
+	require := require.New(t)
+	env := newEnvironment(t, nil)
+	defer func() {
+		require.NoError(shutdownEnvironment(env))
+	}()
+
+	lastAcceptedID := env.state.GetLastAccepted()
+	lastAccepted, err := env.state.GetStatelessBlock(lastAcceptedID)
+	require.NoError(err)
+
+	// Re-executing an accepted block would apply it to the current state a
+	// second time.
+	_, err = env.blkManager.PreviewBlockEffects(lastAccepted)
+	require.ErrorIs(err, errBlockAlreadyAccepted)
+
+	// A block that conflicts with the last accepted block can no longer be
+	// accepted.
+	blk, err := block.NewApricotStandardBlock(
+		ids.GenerateTestID(),
+		lastAccepted.Height(),
+		nil,
+	)
+	require.NoError(err)
+
+	_, err = env.blkManager.PreviewBlockEffects(blk)
+	require.ErrorIs(err, errBlockNotAboveLastAccepted)
+}
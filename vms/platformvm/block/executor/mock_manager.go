@@ -128,6 +128,21 @@ func (mr *MockManagerMockRecorder) Preferred() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preferred", reflect.TypeOf((*MockManager)(nil).Preferred))
 }
 
+// PreviewBlockEffects mocks base method.
+func (m *MockManager) PreviewBlockEffects(arg0 block.Block) (*BlockEffects, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreviewBlockEffects", arg0)
+	ret0, _ := ret[0].(*BlockEffects)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreviewBlockEffects indicates an expected call of PreviewBlockEffects.
+func (mr *MockManagerMockRecorder) PreviewBlockEffects(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewBlockEffects", reflect.TypeOf((*MockManager)(nil).PreviewBlockEffects), arg0)
+}
+
 // SetPreference mocks base method.
 func (m *MockManager) SetPreference(arg0 ids.ID) bool {
 	m.ctrl.T.Helper()
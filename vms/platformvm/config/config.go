@@ -115,6 +115,27 @@ type Config struct {
 	// on recently created subnets (without this, users need to wait for
 	// [recentlyAcceptedWindowTTL] to pass for activation to occur).
 	UseCurrentHeight bool
+
+	// SubnetIDCacheSize is the number of chainID -> subnetID mappings that are
+	// cached by [GetSubnetID]. If 0, a default size is used.
+	SubnetIDCacheSize int
+
+	// RecentlyAcceptedWindowSize is the maximum number of recently accepted
+	// blocks tracked to determine the minimum height that can be used for
+	// validator lookups. If 0, a default size is used.
+	RecentlyAcceptedWindowSize int
+
+	// RecentlyAcceptedWindowTTL is how long a recently accepted block is
+	// tracked to determine the minimum height that can be used for validator
+	// lookups. If nil, a default duration is used. A TTL of 0 only tracks the
+	// minimum number of recently accepted blocks.
+	RecentlyAcceptedWindowTTL *time.Duration
+
+	// ValidatorSetsCacheBytes is the maximum estimated number of bytes of the
+	// validator sets cached for each tracked subnet. Because validator sets
+	// vary greatly in size, this bounds memory usage better than a count. If
+	// 0, a fixed number of validator sets is cached for each subnet.
+	ValidatorSetsCacheBytes int
 }
 
 func (c *Config) IsApricotPhase3Activated(timestamp time.Time) bool {
@@ -5,6 +5,7 @@ package config
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/ava-labs/avalanchego/utils/units"
 )
@@ -18,7 +19,17 @@ var DefaultExecutionConfig = ExecutionConfig{
 	ChainDBCacheSize:             2048,
 	BlockIDCacheSize:             8192,
 	FxOwnerCacheSize:             4 * units.MiB,
+	UTXOCacheSize:                2 * units.MiB,
 	ChecksumsEnabled:             false,
+	VerifyStakersOnLoad:          false,
+	MaxDeferredCommits:           0,
+	MaxDeferredCommitDuration:    0,
+	UptimeFlushFrequency:         0,
+	MaxPendingUptimeUpdates:      0,
+	DelegatorTreeDegree:          2,
+	VerifyBlockLinkage:           false,
+	ConcurrentReadsEnabled:       false,
+	BlockCompressionEnabled:      false,
 }
 
 // ExecutionConfig provides execution parameters of PlatformVM
@@ -31,7 +42,55 @@ type ExecutionConfig struct {
 	ChainDBCacheSize             int  `json:"chain-db-cache-size"`
 	BlockIDCacheSize             int  `json:"block-id-cache-size"`
 	FxOwnerCacheSize             int  `json:"fx-owner-cache-size"`
+	UTXOCacheSize                int  `json:"utxo-cache-size"`
 	ChecksumsEnabled             bool `json:"checksums-enabled"`
+	// VerifyStakersOnLoad enables checking the consistency of the in-memory
+	// staker sets after they are loaded from disk. This is intended for
+	// debugging.
+	VerifyStakersOnLoad bool `json:"verify-stakers-on-load"`
+	// MaxDeferredCommits is the maximum number of state commits whose writes
+	// may be buffered in memory before they are flushed to disk together.
+	// Deferring commits reduces write amplification, at the cost of
+	// reverting to the last flushed commit if the node crashes. If 0, every
+	// commit is flushed.
+	MaxDeferredCommits int `json:"max-deferred-commits"`
+	// MaxDeferredCommitDuration is the maximum amount of time a deferred
+	// commit may remain buffered in memory. If 0, deferred commits are only
+	// flushed once MaxDeferredCommits is reached.
+	MaxDeferredCommitDuration time.Duration `json:"max-deferred-commit-duration"`
+	// UptimeFlushFrequency is the maximum amount of time an uptime update may
+	// remain unwritten. Uptime updates are local to this node, so unlike the
+	// other state changes, they don't need to be written on every commit. The
+	// uptime of a validator that is due to be rewarded is always written on
+	// the next commit. If 0, uptime updates are written on every commit.
+	UptimeFlushFrequency time.Duration `json:"uptime-flush-frequency"`
+	// MaxPendingUptimeUpdates is the number of unwritten uptime updates that
+	// causes all uptime updates to be written on the next commit, regardless
+	// of UptimeFlushFrequency. If 0, the number of unwritten uptime updates
+	// isn't limited.
+	MaxPendingUptimeUpdates int `json:"max-pending-uptime-updates"`
+	// DelegatorTreeDegree is the degree of the btrees holding the delegators
+	// of each validator. A larger degree results in shallower trees, which
+	// reduces the number of nodes visited when adding, removing, or looking up
+	// a delegator of a validator with many delegators, at the cost of copying
+	// more items on each insertion and removal. A degree larger than the number
+	// of delegators of most validators wastes memory, as every tree allocates
+	// nodes sized for the degree. Values less than 2 are replaced by the
+	// default.
+	DelegatorTreeDegree int `json:"delegator-tree-degree"`
+	// VerifyBlockLinkage enables checking that every block added to the state
+	// after genesis has a known parent and a height one greater than its
+	// parent. This is intended for debugging.
+	VerifyBlockLinkage bool `json:"verify-block-linkage"`
+	// ConcurrentReadsEnabled enables an internal lock in the state that
+	// allows a subset of its read methods to be called concurrently with
+	// block execution. When disabled, all access to the state must be
+	// synchronized by the caller.
+	ConcurrentReadsEnabled bool `json:"concurrent-reads-enabled"`
+	// BlockCompressionEnabled enables compressing blocks with zstd before
+	// they are written to disk. Blocks that were written uncompressed, or
+	// compressed, remain readable regardless of this option.
+	BlockCompressionEnabled bool `json:"block-compression-enabled"`
 }
 
 // GetExecutionConfig returns an ExecutionConfig
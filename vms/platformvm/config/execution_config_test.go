@@ -47,7 +47,17 @@ func TestExecutionConfigUnmarshal(t *testing.T) {
 			"chain-db-cache-size": 7,
 			"block-id-cache-size": 8,
 			"fx-owner-cache-size": 9,
-			"checksums-enabled": true
+			"utxo-cache-size": 12,
+			"checksums-enabled": true,
+			"verify-stakers-on-load": true,
+			"max-deferred-commits": 10,
+			"max-deferred-commit-duration": 11,
+			"uptime-flush-frequency": 13,
+			"max-pending-uptime-updates": 14,
+			"delegator-tree-degree": 15,
+			"verify-block-linkage": true,
+			"concurrent-reads-enabled": true,
+			"block-compression-enabled": true
 		}`)
 		ec, err := GetExecutionConfig(b)
 		require.NoError(err)
@@ -60,7 +70,17 @@ func TestExecutionConfigUnmarshal(t *testing.T) {
 			ChainDBCacheSize:             7,
 			BlockIDCacheSize:             8,
 			FxOwnerCacheSize:             9,
+			UTXOCacheSize:                12,
 			ChecksumsEnabled:             true,
+			VerifyStakersOnLoad:          true,
+			MaxDeferredCommits:           10,
+			MaxDeferredCommitDuration:    11,
+			UptimeFlushFrequency:         13,
+			MaxPendingUptimeUpdates:      14,
+			DelegatorTreeDegree:          15,
+			VerifyBlockLinkage:           true,
+			ConcurrentReadsEnabled:       true,
+			BlockCompressionEnabled:      true,
 		}
 		require.Equal(expected, ec)
 	})
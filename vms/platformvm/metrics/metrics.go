@@ -42,6 +42,23 @@ type Metrics interface {
 	SetTimeUntilUnstake(time.Duration)
 	// Mark when this node will unstake from a subnet.
 	SetTimeUntilSubnetUnstake(subnetID ids.ID, timeUntilUnstake time.Duration)
+	// Mark that a validator weight diff was written for the subnet.
+	IncValidatorWeightDiffs(subnetID ids.ID)
+	// Mark that a validator public key diff was written for the subnet.
+	IncValidatorPublicKeyDiffs(subnetID ids.ID)
+	// Mark the estimated number of bytes stored in the state section.
+	SetSectionSize(section string, size int64)
+	// Mark that an accepted block event was dropped for a subscriber.
+	IncAcceptedEventsDropped()
+	// Mark that the state's changes were committed.
+	IncStateCommits()
+	// Mark that the state's uncommitted changes were aborted. [afterCommit]
+	// is true if the abort only released a committed batch, rather than
+	// rolling back changes.
+	IncStateAborts(afterCommit bool)
+	// Mark the time spent initializing the state from genesis, along with the
+	// number of genesis UTXOs, validators, and chains that were processed.
+	SetGenesisInitialization(syncDuration, commitDuration time.Duration, numUTXOs, numValidators, numChains int)
 }
 
 func New(
@@ -106,6 +123,64 @@ func New(
 			Name:      "validator_sets_duration_sum",
 			Help:      "Total amount of time generating validator sets in nanoseconds",
 		}),
+		validatorWeightDiffs: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "validator_weight_diffs",
+				Help:      "Total number of validator weight diffs written",
+			},
+			[]string{"subnetID"},
+		),
+		validatorPublicKeyDiffs: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "validator_public_key_diffs",
+				Help:      "Total number of validator public key diffs written",
+			},
+			[]string{"subnetID"},
+		),
+		sectionSizes: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "state_section_size",
+				Help:      "Estimated number of bytes stored in the state section",
+			},
+			[]string{"section"},
+		),
+		acceptedEventsDropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "accepted_events_dropped",
+			Help:      "Total number of accepted block events dropped because a subscriber was full",
+		}),
+		stateCommits: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "state_commits",
+			Help:      "Total number of times the state's changes were committed",
+		}),
+		stateAborts: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "state_aborts",
+				Help:      "Total number of times the state's changes were aborted, either after a commit or as a rollback",
+			},
+			[]string{"type"},
+		),
+		genesisDurations: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "genesis_duration",
+				Help:      "Time spent in each phase of initializing the state from genesis in nanoseconds",
+			},
+			[]string{"phase"},
+		),
+		genesisItems: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "genesis_items",
+				Help:      "Number of items of each type processed while initializing the state from genesis",
+			},
+			[]string{"type"},
+		),
 	}
 
 	errs := wrappers.Errs{Err: err}
@@ -125,6 +200,18 @@ func New(
 		registerer.Register(m.validatorSetsCached),
 		registerer.Register(m.validatorSetsHeightDiff),
 		registerer.Register(m.validatorSetsDuration),
+
+		registerer.Register(m.validatorWeightDiffs),
+		registerer.Register(m.validatorPublicKeyDiffs),
+
+		registerer.Register(m.sectionSizes),
+		registerer.Register(m.acceptedEventsDropped),
+
+		registerer.Register(m.stateCommits),
+		registerer.Register(m.stateAborts),
+
+		registerer.Register(m.genesisDurations),
+		registerer.Register(m.genesisItems),
 	)
 
 	return m, errs.Err
@@ -146,6 +233,18 @@ type metrics struct {
 	validatorSetsCreated    prometheus.Counter
 	validatorSetsHeightDiff prometheus.Gauge
 	validatorSetsDuration   prometheus.Gauge
+
+	validatorWeightDiffs    *prometheus.CounterVec
+	validatorPublicKeyDiffs *prometheus.CounterVec
+
+	sectionSizes          *prometheus.GaugeVec
+	acceptedEventsDropped prometheus.Counter
+
+	stateCommits prometheus.Counter
+	stateAborts  *prometheus.CounterVec
+
+	genesisDurations *prometheus.GaugeVec
+	genesisItems     *prometheus.GaugeVec
 }
 
 func (m *metrics) MarkOptionVoteWon() {
@@ -191,3 +290,39 @@ func (m *metrics) SetTimeUntilUnstake(timeUntilUnstake time.Duration) {
 func (m *metrics) SetTimeUntilSubnetUnstake(subnetID ids.ID, timeUntilUnstake time.Duration) {
 	m.timeUntilSubnetUnstake.WithLabelValues(subnetID.String()).Set(float64(timeUntilUnstake))
 }
+
+func (m *metrics) IncValidatorWeightDiffs(subnetID ids.ID) {
+	m.validatorWeightDiffs.WithLabelValues(subnetID.String()).Inc()
+}
+
+func (m *metrics) IncValidatorPublicKeyDiffs(subnetID ids.ID) {
+	m.validatorPublicKeyDiffs.WithLabelValues(subnetID.String()).Inc()
+}
+
+func (m *metrics) SetSectionSize(section string, size int64) {
+	m.sectionSizes.WithLabelValues(section).Set(float64(size))
+}
+
+func (m *metrics) IncAcceptedEventsDropped() {
+	m.acceptedEventsDropped.Inc()
+}
+
+func (m *metrics) IncStateCommits() {
+	m.stateCommits.Inc()
+}
+
+func (m *metrics) IncStateAborts(afterCommit bool) {
+	abortType := "rollback"
+	if afterCommit {
+		abortType = "after_commit"
+	}
+	m.stateAborts.WithLabelValues(abortType).Inc()
+}
+
+func (m *metrics) SetGenesisInitialization(syncDuration, commitDuration time.Duration, numUTXOs, numValidators, numChains int) {
+	m.genesisDurations.WithLabelValues("sync").Set(float64(syncDuration))
+	m.genesisDurations.WithLabelValues("commit").Set(float64(commitDuration))
+	m.genesisItems.WithLabelValues("utxos").Set(float64(numUTXOs))
+	m.genesisItems.WithLabelValues("validators").Set(float64(numValidators))
+	m.genesisItems.WithLabelValues("chains").Set(float64(numChains))
+}
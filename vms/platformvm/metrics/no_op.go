@@ -47,6 +47,20 @@ func (noopMetrics) SetTimeUntilUnstake(time.Duration) {}
 
 func (noopMetrics) SetTimeUntilSubnetUnstake(ids.ID, time.Duration) {}
 
+func (noopMetrics) IncValidatorWeightDiffs(ids.ID) {}
+
+func (noopMetrics) IncValidatorPublicKeyDiffs(ids.ID) {}
+
+func (noopMetrics) SetSectionSize(string, int64) {}
+
+func (noopMetrics) IncAcceptedEventsDropped() {}
+
+func (noopMetrics) IncStateCommits() {}
+
+func (noopMetrics) IncStateAborts(bool) {}
+
+func (noopMetrics) SetGenesisInitialization(time.Duration, time.Duration, int, int, int) {}
+
 func (noopMetrics) SetSubnetPercentConnected(ids.ID, float64) {}
 
 func (noopMetrics) SetPercentConnected(float64) {}
@@ -0,0 +1,126 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/block"
+)
+
+// acceptedEventsBufferSize is the number of accepted block events that are
+// buffered for each subscriber before events are dropped.
+const acceptedEventsBufferSize = 256
+
+func (s *state) SubscribeAccepted() (<-chan block.Block, func()) {
+	s.acceptedSubscribersLock.Lock()
+	defer s.acceptedSubscribersLock.Unlock()
+
+	subscriber := make(chan block.Block, acceptedEventsBufferSize)
+	if s.acceptedSubscribers == nil {
+		s.acceptedSubscribers = make(map[chan block.Block]struct{})
+	}
+	s.acceptedSubscribers[subscriber] = struct{}{}
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			s.acceptedSubscribersLock.Lock()
+			defer s.acceptedSubscribersLock.Unlock()
+
+			// The subscriber may have already been closed by Close.
+			if _, ok := s.acceptedSubscribers[subscriber]; ok {
+				delete(s.acceptedSubscribers, subscriber)
+				close(subscriber)
+			}
+		})
+	}
+	return subscriber, unsubscribe
+}
+
+// notifyAccepted sends the blocks accepted on disk since the subscribers were
+// last notified to the subscribers, in order.
+//
+// Invariant: This must only be called once the writes to [s.baseDB] and
+// [s.deferredDB] were flushed or discarded, so that only the last accepted
+// block on disk is read.
+func (s *state) notifyAccepted() {
+	blkID, err := database.GetID(s.singletonDB, lastAcceptedKey)
+	if err != nil || blkID == s.flushedLastAccepted {
+		return
+	}
+
+	// The blocks are looked up before acquiring the subscribers lock so that
+	// subscribing isn't blocked by reading from disk.
+	blks, err := s.getAcceptedBlocks(s.flushedLastAccepted, blkID)
+	s.flushedLastAccepted = blkID
+
+	s.acceptedSubscribersLock.Lock()
+	defer s.acceptedSubscribersLock.Unlock()
+
+	for subscriber := range s.acceptedSubscribers {
+		if err != nil {
+			// The accepted blocks should always be available. If they aren't,
+			// the events can't be delivered.
+			s.metrics.IncAcceptedEventsDropped()
+			continue
+		}
+
+		for _, blk := range blks {
+			select {
+			case subscriber <- blk:
+			default:
+				s.metrics.IncAcceptedEventsDropped()
+			}
+		}
+	}
+}
+
+// getAcceptedBlocks returns the accepted blocks after [fromID] up to and
+// including [toID], ordered by height. Assumes [toID] is a descendant of
+// [fromID].
+func (s *state) getAcceptedBlocks(fromID, toID ids.ID) ([]block.Block, error) {
+	from, err := s.getStatelessBlock(fromID)
+	if err != nil {
+		return nil, err
+	}
+	blk, err := s.getStatelessBlock(toID)
+	if err != nil {
+		return nil, err
+	}
+	if blk.Height() <= from.Height() {
+		return nil, fmt.Errorf(
+			"%w: block %s at height %d doesn't follow block %s at height %d",
+			errUnexpectedBlockHeight,
+			toID,
+			blk.Height(),
+			fromID,
+			from.Height(),
+		)
+	}
+
+	blks := make([]block.Block, blk.Height()-from.Height())
+	for i := len(blks) - 1; i > 0; i-- {
+		blks[i] = blk
+		blk, err = s.getStatelessBlock(blk.Parent())
+		if err != nil {
+			return nil, err
+		}
+	}
+	blks[0] = blk
+	return blks, nil
+}
+
+func (s *state) closeAcceptedSubscribers() {
+	s.acceptedSubscribersLock.Lock()
+	defer s.acceptedSubscribersLock.Unlock()
+
+	for subscriber := range s.acceptedSubscribers {
+		close(subscriber)
+	}
+	s.acceptedSubscribers = nil
+}
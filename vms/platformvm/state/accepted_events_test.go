@@ -0,0 +1,145 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/vms/platformvm/block"
+	"github.com/ava-labs/avalanchego/vms/platformvm/metrics"
+)
+
+// droppedEventsMetrics counts the accepted block events that were dropped.
+type droppedEventsMetrics struct {
+	metrics.Metrics
+
+	numDropped int
+}
+
+func (m *droppedEventsMetrics) IncAcceptedEventsDropped() {
+	m.numDropped++
+}
+
+// acceptBlock adds a child of the last accepted block to [s] and sets it as
+// the last accepted block without committing it.
+func acceptBlock(require *require.Assertions, s State) block.Block {
+	lastAccepted, err := s.GetStatelessBlock(s.GetLastAccepted())
+	require.NoError(err)
+
+	blk, err := block.NewApricotCommitBlock(lastAccepted.ID(), lastAccepted.Height()+1)
+	require.NoError(err)
+	require.NoError(s.AddStatelessBlock(blk))
+	s.SetLastAccepted(blk.ID())
+	s.SetHeight(blk.Height())
+	return blk
+}
+
+func TestStateSubscribeAccepted(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+	require.NoError(s.Commit())
+	m := &droppedEventsMetrics{
+		Metrics: metrics.Noop,
+	}
+	s.(*state).metrics = m
+
+	var (
+		subscriber0, _           = s.SubscribeAccepted()
+		subscriber1, unsubscribe = s.SubscribeAccepted()
+	)
+
+	// Every subscriber should be notified of each commit, in order.
+	blk1 := acceptBlock(require, s)
+	require.NoError(s.Commit())
+	blk2 := acceptBlock(require, s)
+	require.NoError(s.Commit())
+	for _, subscriber := range []<-chan block.Block{subscriber0, subscriber1} {
+		require.Equal(blk1.ID(), (<-subscriber).ID())
+		require.Equal(blk2.ID(), (<-subscriber).ID())
+	}
+
+	// Unsubscribing should close the channel and stop deliveries.
+	unsubscribe()
+	acceptBlock(require, s)
+	require.NoError(s.Commit())
+	<-subscriber0
+	_, ok := <-subscriber1
+	require.False(ok)
+
+	// A subscriber that isn't reading shouldn't block the commit.
+	for i := 0; i < acceptedEventsBufferSize; i++ {
+		acceptBlock(require, s)
+		require.NoError(s.Commit())
+	}
+	require.Zero(m.numDropped)
+
+	acceptBlock(require, s)
+	require.NoError(s.Commit())
+	require.Equal(1, m.numDropped)
+	require.Len(subscriber0, acceptedEventsBufferSize)
+
+	// Closing the state should close the subscriptions. Unsubscribing is
+	// idempotent, including after the state is closed.
+	unsubscribe()
+	require.NoError(s.Close())
+	unsubscribe()
+	for len(subscriber0) > 0 {
+		<-subscriber0
+	}
+	_, ok = <-subscriber0
+	require.False(ok)
+}
+
+func TestStateSubscribeAcceptedDeferredCommits(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+	require.NoError(s.Commit())
+	s.(*state).execCfg.MaxDeferredCommits = 1
+
+	subscriber, _ := s.SubscribeAccepted()
+
+	// The deferred commit isn't delivered until it is flushed.
+	blk1 := acceptBlock(require, s)
+	require.NoError(s.Commit())
+	require.Empty(subscriber)
+
+	// Every block accepted since the last flush is delivered, in order.
+	blk2 := acceptBlock(require, s)
+	require.NoError(s.Commit())
+	require.Equal(blk1.ID(), (<-subscriber).ID())
+	require.Equal(blk2.ID(), (<-subscriber).ID())
+	require.Empty(subscriber)
+}
+
+func TestStateSubscribeAcceptedCommitBatch(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+	require.NoError(s.Commit())
+
+	subscriber, _ := s.SubscribeAccepted()
+
+	// A batch that isn't written isn't delivered.
+	acceptBlock(require, s)
+	_, err := s.CommitBatch()
+	require.NoError(err)
+	s.Abort()
+	require.Empty(subscriber)
+
+	// A batch that is written is delivered once the state is aborted.
+	require.NoError(s.AbortAndReload())
+	require.Empty(subscriber)
+	blk := acceptBlock(require, s)
+	batch, err := s.CommitBatch()
+	require.NoError(err)
+	require.NoError(batch.Write())
+	require.Empty(subscriber)
+
+	s.Abort()
+	require.Equal(blk.ID(), (<-subscriber).ID())
+}
@@ -0,0 +1,57 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"errors"
+
+	"github.com/ava-labs/avalanchego/utils/units"
+)
+
+const (
+	// compressedBlockHeader prefixes the stored bytes of a compressed block.
+	// Uncompressed blocks are stored without a header. They start with the
+	// codec version, whose first byte is always 0, so they can't be confused
+	// with compressed blocks.
+	compressedBlockHeader byte = 1
+
+	// maxCompressedBlockSize is the size of the largest block that is
+	// compressed. Larger blocks are stored uncompressed.
+	maxCompressedBlockSize = 64 * units.MiB
+)
+
+var errEmptyStoredBlock = errors.New("stored block is empty")
+
+// encodeBlockBytes returns the bytes to store for a block with [blkBytes].
+// The block is compressed if block compression is enabled and compressing it
+// reduces its size.
+func (s *state) encodeBlockBytes(blkBytes []byte) ([]byte, error) {
+	if !s.execCfg.BlockCompressionEnabled || len(blkBytes) > maxCompressedBlockSize {
+		return blkBytes, nil
+	}
+
+	compressedBytes, err := s.blockCompressor.Compress(blkBytes)
+	if err != nil {
+		return nil, err
+	}
+	if len(compressedBytes)+1 >= len(blkBytes) {
+		return blkBytes, nil
+	}
+
+	storedBytes := make([]byte, len(compressedBytes)+1)
+	storedBytes[0] = compressedBlockHeader
+	copy(storedBytes[1:], compressedBytes)
+	return storedBytes, nil
+}
+
+// decodeBlockBytes returns the block bytes stored as [storedBytes].
+func (s *state) decodeBlockBytes(storedBytes []byte) ([]byte, error) {
+	if len(storedBytes) == 0 {
+		return nil, errEmptyStoredBlock
+	}
+	if storedBytes[0] != compressedBlockHeader {
+		return storedBytes, nil
+	}
+	return s.blockCompressor.Decompress(storedBytes[1:])
+}
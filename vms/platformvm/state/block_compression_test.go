@@ -0,0 +1,133 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/block"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+// newBlockWithTxs returns a standard block with [numTxs] txs that spend from
+// and pay to the same address, as a wallet issuing many txs would.
+func newBlockWithTxs(require *require.Assertions, numTxs int) block.Block {
+	var (
+		addr    = ids.GenerateTestShortID()
+		assetID = ids.GenerateTestID()
+		owner   = secp256k1fx.OutputOwners{
+			Threshold: 1,
+			Addrs:     []ids.ShortID{addr},
+		}
+		blkTxs = make([]*txs.Tx, numTxs)
+	)
+	for i := range blkTxs {
+		blkTxs[i] = &txs.Tx{
+			Unsigned: &txs.CreateSubnetTx{
+				BaseTx: txs.BaseTx{BaseTx: avax.BaseTx{
+					NetworkID:    constants.UnitTestID,
+					BlockchainID: constants.PlatformChainID,
+					Ins: []*avax.TransferableInput{{
+						UTXOID: avax.UTXOID{TxID: ids.GenerateTestID()},
+						Asset:  avax.Asset{ID: assetID},
+						In: &secp256k1fx.TransferInput{
+							Amt:   units.Avax,
+							Input: secp256k1fx.Input{SigIndices: []uint32{0}},
+						},
+					}},
+					Outs: []*avax.TransferableOutput{{
+						Asset: avax.Asset{ID: assetID},
+						Out: &secp256k1fx.TransferOutput{
+							Amt:          units.Avax - units.MilliAvax,
+							OutputOwners: owner,
+						},
+					}},
+				}},
+				Owner: &owner,
+			},
+		}
+	}
+
+	blk, err := block.NewBanffStandardBlock(time.Now(), ids.GenerateTestID(), 1, blkTxs)
+	require.NoError(err)
+	return blk
+}
+
+func TestBlockCompression(t *testing.T) {
+	require := require.New(t)
+
+	s, db := newInitializedState(require)
+	s.(*state).execCfg.BlockCompressionEnabled = true
+
+	blk := newBlockWithTxs(require, 100)
+	blkID := blk.ID()
+	require.NoError(s.AddStatelessBlock(blk))
+	require.NoError(s.Commit())
+
+	storedBytes, err := s.(*state).blockDB.Get(blkID[:])
+	require.NoError(err)
+	require.Equal(compressedBlockHeader, storedBytes[0])
+	require.Less(len(storedBytes), len(blk.Bytes()))
+
+	// Compressed blocks are readable regardless of whether compression is
+	// enabled.
+	s = newStateFromDB(require, db)
+	require.False(s.(*state).execCfg.BlockCompressionEnabled)
+
+	gotBlk, err := s.GetStatelessBlock(blkID)
+	require.NoError(err)
+	require.Equal(blk.Bytes(), gotBlk.Bytes())
+}
+
+func TestBlockCompressionReadsUncompressedBlocks(t *testing.T) {
+	require := require.New(t)
+
+	s, db := newInitializedState(require)
+
+	blk := newBlockWithTxs(require, 100)
+	blkID := blk.ID()
+	require.NoError(s.AddStatelessBlock(blk))
+	require.NoError(s.Commit())
+
+	storedBytes, err := s.(*state).blockDB.Get(blkID[:])
+	require.NoError(err)
+	require.Equal(blk.Bytes(), storedBytes)
+
+	s = newStateFromDB(require, db)
+	s.(*state).execCfg.BlockCompressionEnabled = true
+
+	gotBlk, err := s.GetStatelessBlock(blkID)
+	require.NoError(err)
+	require.Equal(blk.Bytes(), gotBlk.Bytes())
+}
+
+func TestEncodeBlockBytes(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+	internalState := s.(*state)
+	internalState.execCfg.BlockCompressionEnabled = true
+
+	// Blocks that don't shrink when compressed are stored uncompressed.
+	blkBytes := ids.GenerateTestID()
+	blkBytes[0] = 0
+	storedBytes, err := internalState.encodeBlockBytes(blkBytes[:])
+	require.NoError(err)
+	require.Equal(blkBytes[:], storedBytes)
+
+	gotBytes, err := internalState.decodeBlockBytes(storedBytes)
+	require.NoError(err)
+	require.Equal(blkBytes[:], gotBytes)
+
+	_, err = internalState.decodeBlockBytes(nil)
+	require.ErrorIs(err, errEmptyStoredBlock)
+}
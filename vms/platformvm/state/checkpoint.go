@@ -0,0 +1,264 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/perms"
+	"github.com/ava-labs/avalanchego/utils/units"
+)
+
+const (
+	checkpointEntriesFileName  = "state"
+	checkpointChecksumFileName = "checksum"
+
+	// checkpointBatchSize is the size at which the batches written while
+	// restoring a checkpoint are flushed.
+	checkpointBatchSize = units.MiB
+)
+
+var (
+	errCorruptCheckpoint  = errors.New("corrupt checkpoint")
+	errCheckpointMismatch = errors.New("restored state doesn't match checkpoint")
+)
+
+// Checkpoint doesn't include the uncommitted changes, but does include the
+// commits that haven't been flushed to disk yet.
+func (s *state) Checkpoint(dir string) error {
+	return checkpoint(s.deferredDB, dir)
+}
+
+// checkpoint writes every key-value pair in [db], along with their checksum,
+// to [dir].
+func checkpoint(db database.Iteratee, dir string) error {
+	if err := os.MkdirAll(dir, perms.ReadWriteExecute); err != nil {
+		return fmt.Errorf("failed to create checkpoint dir: %w", err)
+	}
+
+	file, err := os.OpenFile(
+		filepath.Join(dir, checkpointEntriesFileName),
+		os.O_CREATE|os.O_TRUNC|os.O_WRONLY,
+		perms.ReadWrite,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint: %w", err)
+	}
+
+	checksum, err := writeCheckpoint(db, file)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close checkpoint: %w", closeErr)
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(
+		filepath.Join(dir, checkpointChecksumFileName),
+		[]byte(checksum.String()),
+		perms.ReadWrite,
+	)
+}
+
+// writeCheckpoint writes every key-value pair in [db] to [file] and returns
+// their checksum.
+func writeCheckpoint(db database.Iteratee, file *os.File) (ids.ID, error) {
+	var (
+		writer   = bufio.NewWriter(file)
+		checksum = sha256.New()
+		out      = io.MultiWriter(writer, checksum)
+	)
+	err := withIterator(db.NewIterator(), func(key, value []byte) error {
+		if err := writeCheckpointBytes(out, key); err != nil {
+			return err
+		}
+		return writeCheckpointBytes(out, value)
+	})
+	if err != nil {
+		return ids.Empty, fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := writer.Flush(); err != nil {
+		return ids.Empty, fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return ids.Empty, fmt.Errorf("failed to sync checkpoint: %w", err)
+	}
+	return checksumToID(checksum), nil
+}
+
+// RestoreCheckpoint replaces the contents of [db] with the checkpoint written
+// to [dir] by State.Checkpoint. The checkpoint is verified against its
+// checksum before [db] is modified, and the restored contents are verified
+// against it afterwards.
+//
+// The checkpoint is restored in multiple batches, so [db] may be left partially
+// restored if an error is returned after it was modified. Restoring the
+// checkpoint again overwrites the partial contents.
+//
+// Invariant: [db] must not be in use by a State. The restored state can be
+// loaded by passing [db] to New.
+func RestoreCheckpoint(db database.Database, dir string) error {
+	checksumBytes, err := os.ReadFile(filepath.Join(dir, checkpointChecksumFileName))
+	if err != nil {
+		return fmt.Errorf("failed to read checkpoint checksum: %w", err)
+	}
+	expectedChecksum, err := ids.FromString(string(checksumBytes))
+	if err != nil {
+		return fmt.Errorf("%w: invalid checksum: %w", errCorruptCheckpoint, err)
+	}
+
+	entriesPath := filepath.Join(dir, checkpointEntriesFileName)
+	checksum, err := fileChecksum(entriesPath)
+	if err != nil {
+		return err
+	}
+	if checksum != expectedChecksum {
+		return fmt.Errorf("%w: expected checksum %s but got %s",
+			errCorruptCheckpoint,
+			expectedChecksum,
+			checksum,
+		)
+	}
+
+	// Remove the existing contents of [db] so that only the checkpoint
+	// remains.
+	if err := database.Clear(db, checkpointBatchSize); err != nil {
+		return fmt.Errorf("failed to clear database: %w", err)
+	}
+
+	file, err := os.Open(entriesPath)
+	if err != nil {
+		return fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+	err = restoreCheckpoint(db, bufio.NewReader(file))
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close checkpoint: %w", closeErr)
+	}
+	if err != nil {
+		return err
+	}
+
+	restoredChecksum, err := databaseChecksum(db)
+	if err != nil {
+		return err
+	}
+	if restoredChecksum != expectedChecksum {
+		return fmt.Errorf("%w: expected checksum %s but got %s",
+			errCheckpointMismatch,
+			expectedChecksum,
+			restoredChecksum,
+		)
+	}
+	return nil
+}
+
+// restoreCheckpoint writes the key-value pairs read from [r] to [db], writing
+// each batch when it reaches [checkpointBatchSize].
+func restoreCheckpoint(db database.Database, r *bufio.Reader) error {
+	batch := db.NewBatch()
+	for {
+		key, err := readCheckpointBytes(r)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		value, err := readCheckpointBytes(r)
+		if errors.Is(err, io.EOF) {
+			return errCorruptCheckpoint
+		}
+		if err != nil {
+			return err
+		}
+		if err := batch.Put(key, value); err != nil {
+			return err
+		}
+
+		if batch.Size() < checkpointBatchSize {
+			continue
+		}
+		if err := batch.Write(); err != nil {
+			return fmt.Errorf("failed to write checkpoint: %w", err)
+		}
+		batch.Reset()
+	}
+	if err := batch.Write(); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// fileChecksum returns the checksum of the contents of the file at [path].
+func fileChecksum(path string) (ids.ID, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return ids.Empty, fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+
+	checksum := sha256.New()
+	_, err = io.Copy(checksum, file)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = closeErr
+	}
+	if err != nil {
+		return ids.Empty, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	return checksumToID(checksum), nil
+}
+
+// databaseChecksum returns the checksum of a checkpoint of [db].
+func databaseChecksum(db database.Iteratee) (ids.ID, error) {
+	checksum := sha256.New()
+	err := withIterator(db.NewIterator(), func(key, value []byte) error {
+		if err := writeCheckpointBytes(checksum, key); err != nil {
+			return err
+		}
+		return writeCheckpointBytes(checksum, value)
+	})
+	return checksumToID(checksum), err
+}
+
+func checksumToID(checksum hash.Hash) ids.ID {
+	var id ids.ID
+	copy(id[:], checksum.Sum(nil))
+	return id
+}
+
+func writeCheckpointBytes(w io.Writer, b []byte) error {
+	var size [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(size[:], uint64(len(b)))
+	if _, err := w.Write(size[:n]); err != nil {
+		return err
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+// readCheckpointBytes reads the next length-prefixed bytes from [r]. io.EOF is
+// only returned if [r] was empty.
+func readCheckpointBytes(r *bufio.Reader) ([]byte, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		return nil, errCorruptCheckpoint
+	}
+	b := make([]byte, size)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, errCorruptCheckpoint
+	}
+	return b, nil
+}
@@ -0,0 +1,147 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/perms"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/block"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+func TestStateCheckpoint(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+	genesisUTXOID := (&avax.UTXOID{TxID: initialTxID}).InputID()
+
+	blk, err := block.NewApricotCommitBlock(s.GetLastAccepted(), 1)
+	require.NoError(err)
+	require.NoError(s.AddStatelessBlock(blk))
+	s.SetLastAccepted(blk.ID())
+	s.SetHeight(1)
+	require.NoError(s.Commit())
+
+	dir := t.TempDir()
+	require.NoError(s.Checkpoint(dir))
+
+	// Changes after the checkpoint aren't restored.
+	s.DeleteUTXO(genesisUTXOID)
+	require.NoError(s.Commit())
+
+	db := memdb.New()
+	require.NoError(db.Put([]byte("stale"), []byte("value")))
+	require.NoError(RestoreCheckpoint(db, dir))
+
+	has, err := db.Has([]byte("stale"))
+	require.NoError(err)
+	require.False(has)
+
+	restored := newStateFromDB(require, db)
+	require.NoError(restored.(*state).load())
+	require.Equal(blk.ID(), restored.GetLastAccepted())
+	require.Equal(s.GetTimestamp(), restored.GetTimestamp())
+
+	_, err = restored.GetUTXO(genesisUTXOID)
+	require.NoError(err)
+
+	restoredBlk, err := restored.GetStatelessBlock(blk.ID())
+	require.NoError(err)
+	require.Equal(blk.ID(), restoredBlk.ID())
+}
+
+func TestRestoreCorruptCheckpoint(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+	require.NoError(s.Commit())
+
+	dir := t.TempDir()
+	require.NoError(s.Checkpoint(dir))
+
+	entriesPath := filepath.Join(dir, checkpointEntriesFileName)
+	entries, err := os.ReadFile(entriesPath)
+	require.NoError(err)
+	entries[len(entries)-1]++
+	require.NoError(os.WriteFile(entriesPath, entries, perms.ReadWrite))
+
+	db := memdb.New()
+	err = RestoreCheckpoint(db, dir)
+	require.ErrorIs(err, errCorruptCheckpoint)
+
+	// The database isn't modified if the checkpoint is corrupt.
+	it := db.NewIterator()
+	defer it.Release()
+	require.False(it.Next())
+}
+
+func TestStateCheckpointDeferredCommits(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+	require.NoError(s.Commit())
+	s.(*state).execCfg.MaxDeferredCommits = 1
+	genesisUTXOID := (&avax.UTXOID{TxID: initialTxID}).InputID()
+
+	// The deferred commit is included in the checkpoint.
+	s.DeleteUTXO(genesisUTXOID)
+	require.NoError(s.Commit())
+	require.Equal(1, s.(*state).numDeferredCommits)
+
+	// The uncommitted changes aren't included in the checkpoint.
+	utxo := &avax.UTXO{
+		UTXOID: avax.UTXOID{TxID: ids.GenerateTestID()},
+		Asset:  avax.Asset{ID: ids.GenerateTestID()},
+		Out:    &secp256k1fx.TransferOutput{Amt: units.Avax},
+	}
+	s.AddUTXO(utxo)
+	require.NoError(s.(*state).write(false /*=updateValidators*/, 0))
+
+	dir := t.TempDir()
+	require.NoError(s.Checkpoint(dir))
+
+	db := memdb.New()
+	require.NoError(RestoreCheckpoint(db, dir))
+
+	restored := newStateFromDB(require, db)
+	_, err := restored.GetUTXO(genesisUTXOID)
+	require.ErrorIs(err, database.ErrNotFound)
+	_, err = restored.GetUTXO(utxo.InputID())
+	require.ErrorIs(err, database.ErrNotFound)
+}
+
+func TestRestoreCheckpointBatches(t *testing.T) {
+	require := require.New(t)
+
+	// The values are large enough that multiple batches are written.
+	db := memdb.New()
+	for i := 0; i < 4; i++ {
+		value := make([]byte, checkpointBatchSize/2)
+		value[0] = byte(i)
+		require.NoError(db.Put([]byte{byte(i)}, value))
+	}
+
+	dir := t.TempDir()
+	require.NoError(checkpoint(db, dir))
+
+	restoredDB := memdb.New()
+	require.NoError(restoredDB.Put([]byte("stale"), []byte("value")))
+	require.NoError(RestoreCheckpoint(restoredDB, dir))
+
+	expectedChecksum, err := databaseChecksum(db)
+	require.NoError(err)
+	restoredChecksum, err := databaseChecksum(restoredDB)
+	require.NoError(err)
+	require.Equal(expectedChecksum, restoredChecksum)
+}
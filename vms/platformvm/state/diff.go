@@ -293,8 +293,11 @@ func (d *diff) GetSubnetTransformation(subnetID ids.ID) (*txs.Tx, error) {
 	return parentState.GetSubnetTransformation(subnetID)
 }
 
-func (d *diff) AddSubnetTransformation(transformSubnetTxIntf *txs.Tx) {
+func (d *diff) AddSubnetTransformation(transformSubnetTxIntf *txs.Tx) error {
 	transformSubnetTx := transformSubnetTxIntf.Unsigned.(*txs.TransformSubnetTx)
+	if err := verifyNotTransformed(d, transformSubnetTx.Subnet); err != nil {
+		return err
+	}
 	if d.transformedSubnets == nil {
 		d.transformedSubnets = map[ids.ID]*txs.Tx{
 			transformSubnetTx.Subnet: transformSubnetTxIntf,
@@ -302,6 +305,7 @@ func (d *diff) AddSubnetTransformation(transformSubnetTxIntf *txs.Tx) {
 	} else {
 		d.transformedSubnets[transformSubnetTx.Subnet] = transformSubnetTxIntf
 	}
+	return nil
 }
 
 func (d *diff) AddChain(createChainTx *txs.Tx) {
@@ -374,6 +378,15 @@ func (d *diff) AddUTXO(utxo *avax.UTXO) {
 	}
 }
 
+func (d *diff) AddUTXOs(utxos []*avax.UTXO) {
+	if d.modifiedUTXOs == nil {
+		d.modifiedUTXOs = make(map[ids.ID]*avax.UTXO, len(utxos))
+	}
+	for _, utxo := range utxos {
+		d.modifiedUTXOs[utxo.InputID()] = utxo
+	}
+}
+
 func (d *diff) DeleteUTXO(utxoID ids.ID) {
 	if d.modifiedUTXOs == nil {
 		d.modifiedUTXOs = map[ids.ID]*avax.UTXO{
@@ -440,7 +453,9 @@ func (d *diff) Apply(baseState Chain) error {
 		baseState.AddSubnet(subnet)
 	}
 	for _, tx := range d.transformedSubnets {
-		baseState.AddSubnetTransformation(tx)
+		if err := baseState.AddSubnetTransformation(tx); err != nil {
+			return err
+		}
 	}
 	for _, chains := range d.addedChains {
 		for _, chain := range chains {
@@ -294,6 +294,54 @@ func TestDiffSubnet(t *testing.T) {
 	}, subnets)
 }
 
+func TestDiffSubnetTransformation(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	state, _ := newInitializedState(require)
+
+	states := NewMockVersions(ctrl)
+	lastAcceptedID := ids.GenerateTestID()
+	states.EXPECT().GetState(lastAcceptedID).Return(state, true).AnyTimes()
+
+	subnetID := ids.GenerateTestID()
+	newTransformSubnetTx := func() *txs.Tx {
+		return &txs.Tx{
+			Unsigned: &txs.TransformSubnetTx{
+				Subnet:  subnetID,
+				AssetID: ids.GenerateTestID(),
+			},
+			TxID: ids.GenerateTestID(),
+		}
+	}
+
+	d, err := NewDiff(lastAcceptedID, states)
+	require.NoError(err)
+
+	transformSubnetTx := newTransformSubnetTx()
+	require.NoError(d.AddSubnetTransformation(transformSubnetTx))
+
+	// A subnet can only be transformed once
+	err = d.AddSubnetTransformation(newTransformSubnetTx())
+	require.ErrorIs(err, ErrSubnetAlreadyTransformed)
+
+	require.NoError(d.Apply(state))
+
+	gotTransformSubnetTx, err := state.GetSubnetTransformation(subnetID)
+	require.NoError(err)
+	require.Equal(transformSubnetTx, gotTransformSubnetTx)
+
+	// The transformation is also rejected once it is in the parent state
+	err = state.AddSubnetTransformation(newTransformSubnetTx())
+	require.ErrorIs(err, ErrSubnetAlreadyTransformed)
+
+	d, err = NewDiff(lastAcceptedID, states)
+	require.NoError(err)
+
+	err = d.AddSubnetTransformation(newTransformSubnetTx())
+	require.ErrorIs(err, ErrSubnetAlreadyTransformed)
+}
+
 func TestDiffChain(t *testing.T) {
 	require := require.New(t)
 	ctrl := gomock.NewController(t)
@@ -489,6 +537,40 @@ func TestDiffUTXO(t *testing.T) {
 	}
 }
 
+func TestDiffAddUTXOs(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	state := NewMockState(ctrl)
+	// Called in NewDiff
+	state.EXPECT().GetTimestamp().Return(time.Now()).Times(1)
+
+	states := NewMockVersions(ctrl)
+	lastAcceptedID := ids.GenerateTestID()
+	states.EXPECT().GetState(lastAcceptedID).Return(state, true).AnyTimes()
+
+	d, err := NewDiff(lastAcceptedID, states)
+	require.NoError(err)
+
+	utxos := []*avax.UTXO{
+		{UTXOID: avax.UTXOID{TxID: ids.GenerateTestID()}},
+		{UTXOID: avax.UTXOID{TxID: ids.GenerateTestID()}},
+	}
+	d.AddUTXOs(utxos)
+	for _, utxo := range utxos {
+		gotUTXO, err := d.GetUTXO(utxo.InputID())
+		require.NoError(err)
+		require.Equal(utxo, gotUTXO)
+	}
+
+	// The UTXOs are written to the parent state when applied
+	state.EXPECT().SetTimestamp(gomock.Any()).Times(1)
+	for _, utxo := range utxos {
+		state.EXPECT().AddUTXO(utxo).Times(1)
+	}
+	require.NoError(d.Apply(state))
+}
+
 func assertChainsEqual(t *testing.T, expected, actual Chain) {
 	require := require.New(t)
 
@@ -0,0 +1,61 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+// By default, the state performs no locking and every call must be
+// synchronized by the caller, typically with the P-chain's context lock.
+//
+// If [config.ExecutionConfig.ConcurrentReadsEnabled] is set, the state guards
+// its uncommitted changes with an internal RWMutex, allowing the following
+// read methods to be called from other goroutines concurrently with block
+// execution:
+//
+//   - GetBlockIDAtHeight
+//   - GetCurrentSupply
+//   - GetCurrentValidator
+//   - GetLastAccepted
+//   - GetStatelessBlock
+//   - GetTimestamp
+//   - GetTx
+//   - GetUTXO
+//   - UTXOIDs
+//
+// These methods hold the read lock, while the methods that modify the data
+// they read hold the write lock:
+//
+//   - Abort, Commit, and CommitBatch
+//   - AddStatelessBlock and SetHeight
+//   - AddTx
+//   - AddUTXO, AddUTXOs, DeleteUTXO, and DeleteUTXOsForAddress
+//   - PutCurrentValidator, DeleteCurrentValidator, PutCurrentDelegator, and
+//     DeleteCurrentDelegator
+//   - SetCurrentSupply
+//   - SetLastAccepted
+//   - SetTimestamp
+//
+// All other methods remain unsynchronized and must not be called concurrently
+// with any method that modifies the state. Methods holding the lock must not
+// call any of the methods above, as the lock isn't reentrant.
+
+func noop() {}
+
+// readLock acquires the read lock if concurrent reads are enabled and returns
+// the function that releases it.
+func (s *state) readLock() func() {
+	if !s.concurrentReads {
+		return noop
+	}
+	s.lock.RLock()
+	return s.lock.RUnlock
+}
+
+// writeLock acquires the write lock if concurrent reads are enabled and returns
+// the function that releases it.
+func (s *state) writeLock() func() {
+	if !s.concurrentReads {
+		return noop
+	}
+	s.lock.Lock()
+	return s.lock.Unlock
+}
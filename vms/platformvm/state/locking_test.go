@@ -0,0 +1,169 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/block"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+// This test is intended to be run with the race detector enabled.
+func TestStateConcurrentReads(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+	s.(*state).concurrentReads = true
+
+	const (
+		numReaders = 4
+		numBlocks  = 16
+	)
+
+	var (
+		done     = make(chan struct{})
+		started  sync.WaitGroup
+		readers  sync.WaitGroup
+		utxoID   = ids.GenerateTestID()
+		readErrs = make(chan error, numReaders)
+	)
+	for i := 0; i < numReaders; i++ {
+		started.Add(1)
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+
+			started.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				_ = s.GetTimestamp()
+				_, _ = s.GetUTXO(utxoID)
+				_, _, _ = s.GetTx(initialTxID)
+				_, _ = s.GetCurrentValidator(constants.PrimaryNetworkID, initialNodeID)
+				_, _ = s.GetBlockIDAtHeight(0)
+				if _, err := s.GetStatelessBlock(s.GetLastAccepted()); err != nil {
+					readErrs <- err
+					return
+				}
+				if _, err := s.GetCurrentSupply(constants.PrimaryNetworkID); err != nil {
+					readErrs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	// Ensure the reads overlap with the writes below.
+	started.Wait()
+	for height := uint64(1); height <= numBlocks; height++ {
+		blk, err := block.NewApricotCommitBlock(s.GetLastAccepted(), height)
+		require.NoError(err)
+
+		s.AddUTXO(&avax.UTXO{
+			UTXOID: avax.UTXOID{
+				TxID:        utxoID,
+				OutputIndex: uint32(height),
+			},
+			Asset: avax.Asset{ID: initialTxID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: units.MilliAvax,
+			},
+		})
+		s.SetTimestamp(initialTime.Add(time.Duration(height) * time.Second))
+		require.NoError(s.AddStatelessBlock(blk))
+		s.SetLastAccepted(blk.ID())
+		s.SetHeight(height)
+		require.NoError(s.Commit())
+	}
+
+	close(done)
+	readers.Wait()
+	close(readErrs)
+	for err := range readErrs {
+		require.NoError(err)
+	}
+}
+
+// This test is intended to be run with the race detector enabled.
+func TestStateConcurrentReadsAbortAndReload(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+	require.NoError(s.Commit())
+	s.(*state).concurrentReads = true
+
+	validator, err := s.GetCurrentValidator(constants.PrimaryNetworkID, initialNodeID)
+	require.NoError(err)
+
+	const (
+		numReaders = 4
+		numReloads = 16
+	)
+
+	var (
+		done     = make(chan struct{})
+		started  sync.WaitGroup
+		readers  sync.WaitGroup
+		readErrs = make(chan error, numReaders)
+	)
+	for i := 0; i < numReaders; i++ {
+		started.Add(1)
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+
+			started.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				_, _ = s.GetUTXO(ids.GenerateTestID())
+				if _, _, err := s.GetTx(validator.TxID); err != nil {
+					readErrs <- err
+					return
+				}
+				if _, err := s.GetCurrentValidator(constants.PrimaryNetworkID, initialNodeID); err != nil {
+					readErrs <- err
+					return
+				}
+				if _, err := s.GetStatelessBlock(s.GetLastAccepted()); err != nil {
+					readErrs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	// Ensure the reads overlap with the reloads below.
+	started.Wait()
+	for i := 0; i < numReloads; i++ {
+		s.SetTimestamp(initialTime.Add(time.Duration(i+1) * time.Second))
+		require.NoError(s.AbortAndReload())
+		require.Equal(initialTime, s.GetTimestamp())
+	}
+
+	close(done)
+	readers.Wait()
+	close(readErrs)
+	for err := range readErrs {
+		require.NoError(err)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+
+	safemath "github.com/ava-labs/avalanchego/utils/math"
+)
+
+var errInvalidWeightInterval = errors.New("invalid weight interval")
+
+func (s *state) GetMaxValidatorWeight(
+	subnetID ids.ID,
+	nodeID ids.NodeID,
+	startTime time.Time,
+	endTime time.Time,
+) (uint64, error) {
+	validator, err := s.GetCurrentValidator(subnetID, nodeID)
+	if err == database.ErrNotFound {
+		validator, err = s.GetPendingValidator(subnetID, nodeID)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if startTime.Before(validator.StartTime) ||
+		!startTime.Before(endTime) ||
+		endTime.After(validator.EndTime) {
+		return 0, fmt.Errorf(
+			"%w: [%s, %s] isn't within the staking period [%s, %s] of %s",
+			errInvalidWeightInterval,
+			startTime,
+			endTime,
+			validator.StartTime,
+			validator.EndTime,
+			nodeID,
+		)
+	}
+	return GetMaxWeight(s, validator, startTime, endTime)
+}
+
+// GetMaxWeight returns the maximum total weight of the [validator], including
+// its own weight, between [startTime] and [endTime].
+// The weight changes are applied in the order they will be applied as chain
+// time advances.
+// Invariant:
+// - [validator.StartTime] <= [startTime] < [endTime] <= [validator.EndTime]
+func GetMaxWeight(
+	chainState Chain,
+	validator *Staker,
+	startTime time.Time,
+	endTime time.Time,
+) (uint64, error) {
+	currentDelegatorIterator, err := chainState.GetCurrentDelegatorIterator(validator.SubnetID, validator.NodeID)
+	if err != nil {
+		return 0, err
+	}
+
+	// TODO: We can optimize this by moving the current total weight to be
+	//       stored in the validator state.
+	//
+	// Calculate the current total weight on this validator, including the
+	// weight of the actual validator and the sum of the weights of all of the
+	// currently active delegators.
+	currentWeight := validator.Weight
+	for currentDelegatorIterator.Next() {
+		currentDelegator := currentDelegatorIterator.Value()
+
+		currentWeight, err = safemath.Add64(currentWeight, currentDelegator.Weight)
+		if err != nil {
+			currentDelegatorIterator.Release()
+			return 0, err
+		}
+	}
+	currentDelegatorIterator.Release()
+
+	currentDelegatorIterator, err = chainState.GetCurrentDelegatorIterator(validator.SubnetID, validator.NodeID)
+	if err != nil {
+		return 0, err
+	}
+	pendingDelegatorIterator, err := chainState.GetPendingDelegatorIterator(validator.SubnetID, validator.NodeID)
+	if err != nil {
+		currentDelegatorIterator.Release()
+		return 0, err
+	}
+	delegatorChangesIterator := NewStakerDiffIterator(currentDelegatorIterator, pendingDelegatorIterator)
+	defer delegatorChangesIterator.Release()
+
+	// Iterate over the future stake weight changes and calculate the maximum
+	// total weight on the validator, only including the points in the time
+	// range [startTime, endTime].
+	var currentMax uint64
+	for delegatorChangesIterator.Next() {
+		delegator, isAdded := delegatorChangesIterator.Value()
+		// [delegator.NextTime] > [endTime]
+		if delegator.NextTime.After(endTime) {
+			// This delegation change (and all following changes) occurs after
+			// [endTime]. Since we're calculating the max amount staked in
+			// [startTime, endTime], we can stop.
+			break
+		}
+
+		// [delegator.NextTime] >= [startTime]
+		if !delegator.NextTime.Before(startTime) {
+			// We have advanced time to be at the inside of the delegation
+			// window. Make sure that the max weight is updated accordingly.
+			currentMax = safemath.Max(currentMax, currentWeight)
+		}
+
+		var op func(uint64, uint64) (uint64, error)
+		if isAdded {
+			op = safemath.Add64
+		} else {
+			op = safemath.Sub[uint64]
+		}
+		currentWeight, err = op(currentWeight, delegator.Weight)
+		if err != nil {
+			return 0, err
+		}
+	}
+	// Because we assume [startTime] < [endTime], we have advanced time to
+	// be at the end of the delegation window. Make sure that the max weight is
+	// updated accordingly.
+	return safemath.Max(currentMax, currentWeight), nil
+}
@@ -0,0 +1,222 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+)
+
+func TestStateGetMaxValidatorWeight(t *testing.T) {
+	const (
+		validatorWeight = 1000
+		delegatorWeight = 100
+	)
+	var (
+		nodeID             = ids.GenerateTestNodeID()
+		validatorStartTime = initialTime
+		validatorEndTime   = validatorStartTime.Add(10 * time.Hour)
+		midTime            = validatorStartTime.Add(5 * time.Hour)
+	)
+
+	type delegator struct {
+		startTime time.Time
+		endTime   time.Time
+		pending   bool
+	}
+	tests := []struct {
+		name           string
+		delegators     []delegator
+		startTime      time.Time
+		endTime        time.Time
+		expectedWeight uint64
+	}{
+		{
+			name:           "[validator.StartTime] == [startTime] < [endTime] == [validator.EndTime]",
+			startTime:      validatorStartTime,
+			endTime:        validatorEndTime,
+			expectedWeight: validatorWeight,
+		},
+		{
+			name:           "[validator.StartTime] < [startTime] < [endTime] == [validator.EndTime]",
+			startTime:      validatorStartTime.Add(time.Minute),
+			endTime:        validatorEndTime,
+			expectedWeight: validatorWeight,
+		},
+		{
+			name:           "[validator.StartTime] == [startTime] < [endTime] < [validator.EndTime]",
+			startTime:      validatorStartTime,
+			endTime:        validatorEndTime.Add(-time.Minute),
+			expectedWeight: validatorWeight,
+		},
+		{
+			name:           "[validator.StartTime] < [startTime] < [endTime] < [validator.EndTime]",
+			startTime:      validatorStartTime.Add(time.Minute),
+			endTime:        validatorEndTime.Add(-time.Minute),
+			expectedWeight: validatorWeight,
+		},
+		{
+			name: "delegator leaves mid-window",
+			delegators: []delegator{
+				{
+					startTime: validatorStartTime,
+					endTime:   midTime,
+				},
+			},
+			startTime:      validatorStartTime.Add(time.Minute),
+			endTime:        validatorEndTime,
+			expectedWeight: validatorWeight + delegatorWeight,
+		},
+		{
+			name: "delegator leaves before the window",
+			delegators: []delegator{
+				{
+					startTime: validatorStartTime,
+					endTime:   midTime,
+				},
+			},
+			startTime:      midTime.Add(time.Minute),
+			endTime:        validatorEndTime,
+			expectedWeight: validatorWeight,
+		},
+		{
+			name: "delegator enters mid-window",
+			delegators: []delegator{
+				{
+					startTime: midTime,
+					endTime:   validatorEndTime,
+					pending:   true,
+				},
+			},
+			startTime:      validatorStartTime,
+			endTime:        validatorEndTime,
+			expectedWeight: validatorWeight + delegatorWeight,
+		},
+		{
+			name: "delegator enters after the window",
+			delegators: []delegator{
+				{
+					startTime: midTime,
+					endTime:   validatorEndTime,
+					pending:   true,
+				},
+			},
+			startTime:      validatorStartTime,
+			endTime:        midTime.Add(-time.Minute),
+			expectedWeight: validatorWeight,
+		},
+		{
+			name: "delegators don't overlap",
+			delegators: []delegator{
+				{
+					startTime: validatorStartTime,
+					endTime:   midTime,
+				},
+				{
+					startTime: midTime.Add(time.Minute),
+					endTime:   validatorEndTime,
+					pending:   true,
+				},
+			},
+			startTime:      validatorStartTime,
+			endTime:        validatorEndTime,
+			expectedWeight: validatorWeight + delegatorWeight,
+		},
+		{
+			name: "delegators overlap",
+			delegators: []delegator{
+				{
+					startTime: validatorStartTime,
+					endTime:   midTime.Add(time.Minute),
+				},
+				{
+					startTime: midTime,
+					endTime:   validatorEndTime,
+					pending:   true,
+				},
+			},
+			startTime:      validatorStartTime,
+			endTime:        validatorEndTime,
+			expectedWeight: validatorWeight + 2*delegatorWeight,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			s, _ := newInitializedState(require)
+			s.PutCurrentValidator(&Staker{
+				TxID:      ids.GenerateTestID(),
+				NodeID:    nodeID,
+				SubnetID:  constants.PrimaryNetworkID,
+				Weight:    validatorWeight,
+				StartTime: validatorStartTime,
+				EndTime:   validatorEndTime,
+				NextTime:  validatorEndTime,
+				Priority:  txs.PrimaryNetworkValidatorCurrentPriority,
+			})
+			for _, d := range test.delegators {
+				staker := &Staker{
+					TxID:      ids.GenerateTestID(),
+					NodeID:    nodeID,
+					SubnetID:  constants.PrimaryNetworkID,
+					Weight:    delegatorWeight,
+					StartTime: d.startTime,
+					EndTime:   d.endTime,
+				}
+				if d.pending {
+					staker.NextTime = d.startTime
+					staker.Priority = txs.PrimaryNetworkDelegatorBanffPendingPriority
+					s.PutPendingDelegator(staker)
+				} else {
+					staker.NextTime = d.endTime
+					staker.Priority = txs.PrimaryNetworkDelegatorCurrentPriority
+					s.PutCurrentDelegator(staker)
+				}
+			}
+
+			weight, err := s.GetMaxValidatorWeight(constants.PrimaryNetworkID, nodeID, test.startTime, test.endTime)
+			require.NoError(err)
+			require.Equal(test.expectedWeight, weight)
+		})
+	}
+}
+
+func TestStateGetMaxValidatorWeightErrors(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+
+	_, err := s.GetMaxValidatorWeight(
+		constants.PrimaryNetworkID,
+		ids.GenerateTestNodeID(),
+		initialTime,
+		initialValidatorEndTime,
+	)
+	require.ErrorIs(err, database.ErrNotFound)
+
+	// The interval must be within the staking period of the validator.
+	_, err = s.GetMaxValidatorWeight(
+		constants.PrimaryNetworkID,
+		initialNodeID,
+		initialTime,
+		initialValidatorEndTime.Add(time.Second),
+	)
+	require.ErrorIs(err, errInvalidWeightInterval)
+
+	_, err = s.GetMaxValidatorWeight(
+		constants.PrimaryNetworkID,
+		initialNodeID,
+		initialValidatorEndTime,
+		initialTime,
+	)
+	require.ErrorIs(err, errInvalidWeightInterval)
+}
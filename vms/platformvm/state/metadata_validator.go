@@ -95,7 +95,8 @@ type validatorState interface {
 
 	// SetUptime updates the uptime measurements of [vdrID] on [subnetID].
 	// Unless these measurements are deleted first, the next call to
-	// WriteUptimes will write this update to disk.
+	// WriteValidatorMetadata, or to WriteUptimes that includes [vdrID] on
+	// [subnetID], will write this update to disk.
 	SetUptime(
 		vdrID ids.NodeID,
 		subnetID ids.ID,
@@ -112,7 +113,8 @@ type validatorState interface {
 
 	// SetDelegateeReward updates the rewards accrued to [vdrID] on [subnetID].
 	// Unless these measurements are deleted first, the next call to
-	// WriteUptimes will write this update to disk.
+	// WriteValidatorMetadata or WriteDelegateeRewards will write this update
+	// to disk.
 	SetDelegateeReward(
 		subnetID ids.ID,
 		vdrID ids.NodeID,
@@ -131,18 +133,44 @@ type validatorState interface {
 		dbPrimary database.KeyValueWriter,
 		dbSubnet database.KeyValueWriter,
 	) error
+
+	// WriteDelegateeRewards writes all staged updates from prior calls to
+	// SetDelegateeReward. The staged uptime updates of the same validators are
+	// written as well.
+	WriteDelegateeRewards(
+		dbPrimary database.KeyValueWriter,
+		dbSubnet database.KeyValueWriter,
+	) error
+
+	// WriteUptimes writes the staged updates from prior calls to SetUptime
+	// for which [shouldWrite] returns true. If [shouldWrite] is nil, all
+	// staged uptime updates are written.
+	WriteUptimes(
+		dbPrimary database.KeyValueWriter,
+		dbSubnet database.KeyValueWriter,
+		shouldWrite func(vdrID ids.NodeID, subnetID ids.ID) bool,
+	) error
+
+	// NumPendingUptimes returns the number of staged uptime updates that
+	// haven't been written.
+	NumPendingUptimes() int
 }
 
 type metadata struct {
 	metadata map[ids.NodeID]map[ids.ID]*validatorMetadata // vdrID -> subnetID -> metadata
-	// updatedMetadata tracks the updates since WriteValidatorMetadata was last called
+	// updatedMetadata tracks the delegatee reward updates that haven't been
+	// written
 	updatedMetadata map[ids.NodeID]set.Set[ids.ID] // vdrID -> subnetIDs
+	// updatedUptimes tracks the uptime updates that haven't been written
+	updatedUptimes    map[ids.NodeID]set.Set[ids.ID] // vdrID -> subnetIDs
+	numUpdatedUptimes int
 }
 
 func newValidatorState() validatorState {
 	return &metadata{
 		metadata:        make(map[ids.NodeID]map[ids.ID]*validatorMetadata),
 		updatedMetadata: make(map[ids.NodeID]set.Set[ids.ID]),
+		updatedUptimes:  make(map[ids.NodeID]set.Set[ids.ID]),
 	}
 }
 
@@ -183,7 +211,7 @@ func (m *metadata) SetUptime(
 	metadata.UpDuration = upDuration
 	metadata.lastUpdated = lastUpdated
 
-	m.addUpdatedMetadata(vdrID, subnetID)
+	m.addUpdatedUptime(vdrID, subnetID)
 	return nil
 }
 
@@ -225,34 +253,104 @@ func (m *metadata) DeleteValidatorMetadata(vdrID ids.NodeID, subnetID ids.ID) {
 	if subnetUpdatedMetadata.Len() == 0 {
 		delete(m.updatedMetadata, vdrID)
 	}
+
+	m.removeUpdatedUptime(vdrID, subnetID)
 }
 
 func (m *metadata) WriteValidatorMetadata(
 	dbPrimary database.KeyValueWriter,
 	dbSubnet database.KeyValueWriter,
+) error {
+	if err := m.WriteDelegateeRewards(dbPrimary, dbSubnet); err != nil {
+		return err
+	}
+	return m.WriteUptimes(dbPrimary, dbSubnet, nil)
+}
+
+func (m *metadata) WriteDelegateeRewards(
+	dbPrimary database.KeyValueWriter,
+	dbSubnet database.KeyValueWriter,
 ) error {
 	for vdrID, updatedSubnets := range m.updatedMetadata {
 		for subnetID := range updatedSubnets {
-			metadata := m.metadata[vdrID][subnetID]
-			metadata.LastUpdated = uint64(metadata.lastUpdated.Unix())
-
-			metadataBytes, err := metadataCodec.Marshal(v0, metadata)
-			if err != nil {
+			if err := m.writeMetadata(dbPrimary, dbSubnet, vdrID, subnetID); err != nil {
 				return err
 			}
-			db := dbSubnet
-			if subnetID == constants.PrimaryNetworkID {
-				db = dbPrimary
+			// The uptime was written along with the rest of the metadata.
+			m.removeUpdatedUptime(vdrID, subnetID)
+		}
+		delete(m.updatedMetadata, vdrID)
+	}
+	return nil
+}
+
+func (m *metadata) WriteUptimes(
+	dbPrimary database.KeyValueWriter,
+	dbSubnet database.KeyValueWriter,
+	shouldWrite func(vdrID ids.NodeID, subnetID ids.ID) bool,
+) error {
+	for vdrID, updatedSubnets := range m.updatedUptimes {
+		for subnetID := range updatedSubnets {
+			if shouldWrite != nil && !shouldWrite(vdrID, subnetID) {
+				continue
 			}
-			if err := db.Put(metadata.txID[:], metadataBytes); err != nil {
+			if err := m.writeMetadata(dbPrimary, dbSubnet, vdrID, subnetID); err != nil {
 				return err
 			}
+			m.removeUpdatedUptime(vdrID, subnetID)
 		}
-		delete(m.updatedMetadata, vdrID)
 	}
 	return nil
 }
 
+func (m *metadata) NumPendingUptimes() int {
+	return m.numUpdatedUptimes
+}
+
+func (m *metadata) writeMetadata(
+	dbPrimary database.KeyValueWriter,
+	dbSubnet database.KeyValueWriter,
+	vdrID ids.NodeID,
+	subnetID ids.ID,
+) error {
+	metadata := m.metadata[vdrID][subnetID]
+	metadata.LastUpdated = uint64(metadata.lastUpdated.Unix())
+
+	metadataBytes, err := metadataCodec.Marshal(v0, metadata)
+	if err != nil {
+		return err
+	}
+	db := dbSubnet
+	if subnetID == constants.PrimaryNetworkID {
+		db = dbPrimary
+	}
+	return db.Put(metadata.txID[:], metadataBytes)
+}
+
+func (m *metadata) addUpdatedUptime(vdrID ids.NodeID, subnetID ids.ID) {
+	updatedSubnetUptimes, ok := m.updatedUptimes[vdrID]
+	if !ok {
+		updatedSubnetUptimes = set.Set[ids.ID]{}
+		m.updatedUptimes[vdrID] = updatedSubnetUptimes
+	}
+	if !updatedSubnetUptimes.Contains(subnetID) {
+		updatedSubnetUptimes.Add(subnetID)
+		m.numUpdatedUptimes++
+	}
+}
+
+func (m *metadata) removeUpdatedUptime(vdrID ids.NodeID, subnetID ids.ID) {
+	updatedSubnetUptimes, ok := m.updatedUptimes[vdrID]
+	if !ok || !updatedSubnetUptimes.Contains(subnetID) {
+		return
+	}
+	updatedSubnetUptimes.Remove(subnetID)
+	m.numUpdatedUptimes--
+	if updatedSubnetUptimes.Len() == 0 {
+		delete(m.updatedUptimes, vdrID)
+	}
+}
+
 func (m *metadata) addUpdatedMetadata(vdrID ids.NodeID, subnetID ids.ID) {
 	updatedSubnetMetadata, ok := m.updatedMetadata[vdrID]
 	if !ok {
@@ -117,6 +117,64 @@ func TestWriteValidatorMetadata(t *testing.T) {
 	require.True(subnetDB.Has(testUptimeReward.txID[:]))
 }
 
+func TestWriteUptimes(t *testing.T) {
+	require := require.New(t)
+	state := newValidatorState()
+
+	primaryDB := memdb.New()
+	subnetDB := memdb.New()
+
+	var (
+		nodeID0  = ids.GenerateTestNodeID()
+		nodeID1  = ids.GenerateTestNodeID()
+		subnetID = ids.GenerateTestID()
+
+		metadata0 = &validatorMetadata{
+			lastUpdated: time.Now(),
+			txID:        ids.GenerateTestID(),
+		}
+		metadata1 = &validatorMetadata{
+			lastUpdated: time.Now(),
+			txID:        ids.GenerateTestID(),
+		}
+	)
+	state.LoadValidatorMetadata(nodeID0, subnetID, metadata0)
+	state.LoadValidatorMetadata(nodeID1, subnetID, metadata1)
+
+	require.NoError(state.SetUptime(nodeID0, subnetID, time.Hour, time.Now()))
+	require.NoError(state.SetUptime(nodeID1, subnetID, time.Hour, time.Now()))
+	require.Equal(2, state.NumPendingUptimes())
+
+	// Uptime updates aren't written with the delegatee rewards
+	require.NoError(state.WriteDelegateeRewards(primaryDB, subnetDB))
+	require.False(subnetDB.Has(metadata0.txID[:]))
+	require.Equal(2, state.NumPendingUptimes())
+
+	// Only the selected uptime updates are written
+	require.NoError(state.WriteUptimes(
+		primaryDB,
+		subnetDB,
+		func(vdrID ids.NodeID, _ ids.ID) bool {
+			return vdrID == nodeID0
+		},
+	))
+	require.True(subnetDB.Has(metadata0.txID[:]))
+	require.False(subnetDB.Has(metadata1.txID[:]))
+	require.Equal(1, state.NumPendingUptimes())
+
+	// Writing a delegatee reward writes the staged uptime of the validator
+	require.NoError(state.SetDelegateeReward(subnetID, nodeID1, 1))
+	require.NoError(state.WriteDelegateeRewards(primaryDB, subnetDB))
+	require.True(subnetDB.Has(metadata1.txID[:]))
+	require.Zero(state.NumPendingUptimes())
+
+	// Deleting a validator drops its staged uptime update
+	require.NoError(state.SetUptime(nodeID0, subnetID, 2*time.Hour, time.Now()))
+	require.Equal(1, state.NumPendingUptimes())
+	state.DeleteValidatorMetadata(nodeID0, subnetID)
+	require.Zero(state.NumPendingUptimes())
+}
+
 func TestValidatorDelegateeRewards(t *testing.T) {
 	require := require.New(t)
 	state := newValidatorState()
@@ -0,0 +1,72 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/database"
+)
+
+// migrations upgrade the schema of the database. The migration at index i
+// upgrades the database from schema version i to schema version i+1, so the
+// current schema version is len(migrations).
+//
+// Databases written before the schema version was tracked are at schema
+// version 0.
+//
+// Migrations must only be appended to this list.
+var migrations []migration
+
+type migration struct {
+	name string
+	// migrate is run before the state is loaded. The changes are committed
+	// along with the new schema version once migrate returns.
+	migrate func(s *state) error
+}
+
+// getSchemaVersion returns the schema version of the database.
+func (s *state) getSchemaVersion() (uint64, error) {
+	version, err := database.GetUInt64(s.singletonDB, schemaVersionKey)
+	if err == database.ErrNotFound {
+		return 0, nil
+	}
+	return version, err
+}
+
+func (s *state) putSchemaVersion(version uint64) error {
+	return database.PutUInt64(s.singletonDB, schemaVersionKey, version)
+}
+
+// migrate runs the [migrations] that haven't been run on the database yet, in
+// order. Each migration is committed along with the resulting schema version,
+// so an interrupted migration is resumed from the last committed one.
+func (s *state) migrate(migrations []migration) error {
+	version, err := s.getSchemaVersion()
+	if err != nil {
+		return fmt.Errorf("failed to get schema version: %w", err)
+	}
+
+	currentVersion := uint64(len(migrations))
+	if version > currentVersion {
+		return fmt.Errorf(
+			"%w: %d > %d",
+			errUnknownSchemaVersion, version, currentVersion,
+		)
+	}
+
+	for ; version < currentVersion; version++ {
+		m := migrations[version]
+		if err := m.migrate(s); err != nil {
+			return fmt.Errorf("failed to run migration %q: %w", m.name, err)
+		}
+		if err := s.putSchemaVersion(version + 1); err != nil {
+			return fmt.Errorf("failed to put schema version: %w", err)
+		}
+		if err := s.flushDeferredCommits(); err != nil {
+			return fmt.Errorf("failed to commit migration %q: %w", m.name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateMigrate(t *testing.T) {
+	require := require.New(t)
+
+	s, db := newInitializedState(require)
+	require.NoError(s.Commit())
+
+	var ran []string
+	newMigration := func(name string) migration {
+		return migration{
+			name: name,
+			migrate: func(s *state) error {
+				ran = append(ran, name)
+				return s.singletonDB.Put([]byte(name), nil)
+			},
+		}
+	}
+	testMigrations := []migration{
+		newMigration("first"),
+		newMigration("second"),
+	}
+
+	// The database doesn't have a schema version, so it was written before
+	// any of the migrations.
+	internalState := s.(*state)
+	version, err := internalState.getSchemaVersion()
+	require.NoError(err)
+	require.Zero(version)
+
+	require.NoError(internalState.migrate(testMigrations))
+	require.Equal([]string{"first", "second"}, ran)
+
+	// The migrations and the new schema version should have been committed.
+	reloaded := newStateFromDB(require, db).(*state)
+	version, err = reloaded.getSchemaVersion()
+	require.NoError(err)
+	require.Equal(uint64(len(testMigrations)), version)
+	for _, m := range testMigrations {
+		has, err := reloaded.singletonDB.Has([]byte(m.name))
+		require.NoError(err)
+		require.True(has)
+	}
+
+	// Migrating a database with the current schema shouldn't run any
+	// migrations.
+	ran = nil
+	require.NoError(reloaded.migrate(testMigrations))
+	require.Empty(ran)
+
+	// Adding a migration should only run the new migration.
+	testMigrations = append(testMigrations, newMigration("third"))
+	require.NoError(reloaded.migrate(testMigrations))
+	require.Equal([]string{"third"}, ran)
+}
+
+func TestStateMigrateFailure(t *testing.T) {
+	require := require.New(t)
+
+	s, db := newInitializedState(require)
+	require.NoError(s.Commit())
+
+	errTest := errors.New("non-nil error")
+	testMigrations := []migration{
+		{
+			name: "succeeds",
+			migrate: func(*state) error {
+				return nil
+			},
+		},
+		{
+			name: "fails",
+			migrate: func(*state) error {
+				return errTest
+			},
+		},
+	}
+	err := s.(*state).migrate(testMigrations)
+	require.ErrorIs(err, errTest)
+
+	// The successful migration should still be committed.
+	version, err := newStateFromDB(require, db).(*state).getSchemaVersion()
+	require.NoError(err)
+	require.Equal(uint64(1), version)
+}
+
+func TestStateMigrateUnknownSchemaVersion(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+	internalState := s.(*state)
+	require.NoError(internalState.putSchemaVersion(uint64(len(migrations) + 1)))
+
+	err := internalState.migrate(migrations)
+	require.ErrorIs(err, errUnknownSchemaVersion)
+}
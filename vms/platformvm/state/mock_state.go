@@ -16,7 +16,9 @@ import (
 	database "github.com/ava-labs/avalanchego/database"
 	ids "github.com/ava-labs/avalanchego/ids"
 	validators "github.com/ava-labs/avalanchego/snow/validators"
+	bls "github.com/ava-labs/avalanchego/utils/crypto/bls"
 	logging "github.com/ava-labs/avalanchego/utils/logging"
+	set "github.com/ava-labs/avalanchego/utils/set"
 	avax "github.com/ava-labs/avalanchego/vms/components/avax"
 	block "github.com/ava-labs/avalanchego/vms/platformvm/block"
 	fx "github.com/ava-labs/avalanchego/vms/platformvm/fx"
@@ -85,9 +87,11 @@ func (mr *MockChainMockRecorder) AddSubnet(arg0 interface{}) *gomock.Call {
 }
 
 // AddSubnetTransformation mocks base method.
-func (m *MockChain) AddSubnetTransformation(arg0 *txs.Tx) {
+func (m *MockChain) AddSubnetTransformation(arg0 *txs.Tx) error {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "AddSubnetTransformation", arg0)
+	ret := m.ctrl.Call(m, "AddSubnetTransformation", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // AddSubnetTransformation indicates an expected call of AddSubnetTransformation.
@@ -120,6 +124,18 @@ func (mr *MockChainMockRecorder) AddUTXO(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUTXO", reflect.TypeOf((*MockChain)(nil).AddUTXO), arg0)
 }
 
+// AddUTXOs mocks base method.
+func (m *MockChain) AddUTXOs(arg0 []*avax.UTXO) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddUTXOs", arg0)
+}
+
+// AddUTXOs indicates an expected call of AddUTXOs.
+func (mr *MockChainMockRecorder) AddUTXOs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUTXOs", reflect.TypeOf((*MockChain)(nil).AddUTXOs), arg0)
+}
+
 // DeleteCurrentDelegator mocks base method.
 func (m *MockChain) DeleteCurrentDelegator(arg0 *Staker) {
 	m.ctrl.T.Helper()
@@ -533,9 +549,11 @@ func (mr *MockDiffMockRecorder) AddSubnet(arg0 interface{}) *gomock.Call {
 }
 
 // AddSubnetTransformation mocks base method.
-func (m *MockDiff) AddSubnetTransformation(arg0 *txs.Tx) {
+func (m *MockDiff) AddSubnetTransformation(arg0 *txs.Tx) error {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "AddSubnetTransformation", arg0)
+	ret := m.ctrl.Call(m, "AddSubnetTransformation", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // AddSubnetTransformation indicates an expected call of AddSubnetTransformation.
@@ -568,6 +586,18 @@ func (mr *MockDiffMockRecorder) AddUTXO(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUTXO", reflect.TypeOf((*MockDiff)(nil).AddUTXO), arg0)
 }
 
+// AddUTXOs mocks base method.
+func (m *MockDiff) AddUTXOs(arg0 []*avax.UTXO) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddUTXOs", arg0)
+}
+
+// AddUTXOs indicates an expected call of AddUTXOs.
+func (mr *MockDiffMockRecorder) AddUTXOs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUTXOs", reflect.TypeOf((*MockDiff)(nil).AddUTXOs), arg0)
+}
+
 // Apply mocks base method.
 func (m *MockDiff) Apply(arg0 Chain) error {
 	m.ctrl.T.Helper()
@@ -970,6 +1000,20 @@ func (mr *MockStateMockRecorder) Abort() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Abort", reflect.TypeOf((*MockState)(nil).Abort))
 }
 
+// AbortAndReload mocks base method.
+func (m *MockState) AbortAndReload() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AbortAndReload")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AbortAndReload indicates an expected call of AbortAndReload.
+func (mr *MockStateMockRecorder) AbortAndReload() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AbortAndReload", reflect.TypeOf((*MockState)(nil).AbortAndReload))
+}
+
 // AddChain mocks base method.
 func (m *MockState) AddChain(arg0 *txs.Tx) {
 	m.ctrl.T.Helper()
@@ -995,9 +1039,11 @@ func (mr *MockStateMockRecorder) AddRewardUTXO(arg0, arg1 interface{}) *gomock.C
 }
 
 // AddStatelessBlock mocks base method.
-func (m *MockState) AddStatelessBlock(arg0 block.Block) {
+func (m *MockState) AddStatelessBlock(arg0 block.Block) error {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "AddStatelessBlock", arg0)
+	ret := m.ctrl.Call(m, "AddStatelessBlock", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // AddStatelessBlock indicates an expected call of AddStatelessBlock.
@@ -1019,9 +1065,11 @@ func (mr *MockStateMockRecorder) AddSubnet(arg0 interface{}) *gomock.Call {
 }
 
 // AddSubnetTransformation mocks base method.
-func (m *MockState) AddSubnetTransformation(arg0 *txs.Tx) {
+func (m *MockState) AddSubnetTransformation(arg0 *txs.Tx) error {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "AddSubnetTransformation", arg0)
+	ret := m.ctrl.Call(m, "AddSubnetTransformation", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // AddSubnetTransformation indicates an expected call of AddSubnetTransformation.
@@ -1054,6 +1102,18 @@ func (mr *MockStateMockRecorder) AddUTXO(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUTXO", reflect.TypeOf((*MockState)(nil).AddUTXO), arg0)
 }
 
+// AddUTXOs mocks base method.
+func (m *MockState) AddUTXOs(arg0 []*avax.UTXO) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddUTXOs", arg0)
+}
+
+// AddUTXOs indicates an expected call of AddUTXOs.
+func (mr *MockStateMockRecorder) AddUTXOs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUTXOs", reflect.TypeOf((*MockState)(nil).AddUTXOs), arg0)
+}
+
 // ApplyValidatorPublicKeyDiffs mocks base method.
 func (m *MockState) ApplyValidatorPublicKeyDiffs(arg0 context.Context, arg1 map[ids.NodeID]*validators.GetValidatorOutput, arg2, arg3 uint64) error {
 	m.ctrl.T.Helper()
@@ -1082,6 +1142,34 @@ func (mr *MockStateMockRecorder) ApplyValidatorWeightDiffs(arg0, arg1, arg2, arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyValidatorWeightDiffs", reflect.TypeOf((*MockState)(nil).ApplyValidatorWeightDiffs), arg0, arg1, arg2, arg3, arg4)
 }
 
+// ApplyValidatorWeightDiffsForward mocks base method.
+func (m *MockState) ApplyValidatorWeightDiffsForward(arg0 context.Context, arg1 map[ids.NodeID]*validators.GetValidatorOutput, arg2, arg3 uint64, arg4 ids.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyValidatorWeightDiffsForward", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApplyValidatorWeightDiffsForward indicates an expected call of ApplyValidatorWeightDiffsForward.
+func (mr *MockStateMockRecorder) ApplyValidatorWeightDiffsForward(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyValidatorWeightDiffsForward", reflect.TypeOf((*MockState)(nil).ApplyValidatorWeightDiffsForward), arg0, arg1, arg2, arg3, arg4)
+}
+
+// Checkpoint mocks base method.
+func (m *MockState) Checkpoint(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Checkpoint", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Checkpoint indicates an expected call of Checkpoint.
+func (mr *MockStateMockRecorder) Checkpoint(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Checkpoint", reflect.TypeOf((*MockState)(nil).Checkpoint), arg0)
+}
+
 // Checksum mocks base method.
 func (m *MockState) Checksum() ids.ID {
 	m.ctrl.T.Helper()
@@ -1199,6 +1287,83 @@ func (mr *MockStateMockRecorder) DeleteUTXO(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUTXO", reflect.TypeOf((*MockState)(nil).DeleteUTXO), arg0)
 }
 
+// DeleteUTXOsForAddress mocks base method.
+func (m *MockState) DeleteUTXOsForAddress(arg0 []byte) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUTXOsForAddress", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteUTXOsForAddress indicates an expected call of DeleteUTXOsForAddress.
+func (mr *MockStateMockRecorder) DeleteUTXOsForAddress(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUTXOsForAddress", reflect.TypeOf((*MockState)(nil).DeleteUTXOsForAddress), arg0)
+}
+
+// ExportValidatorDiffs mocks base method.
+func (m *MockState) ExportValidatorDiffs(arg0 ids.ID, arg1, arg2 uint64) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportValidatorDiffs", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportValidatorDiffs indicates an expected call of ExportValidatorDiffs.
+func (mr *MockStateMockRecorder) ExportValidatorDiffs(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportValidatorDiffs", reflect.TypeOf((*MockState)(nil).ExportValidatorDiffs), arg0, arg1, arg2)
+}
+
+// FindBlockIndexGaps mocks base method.
+func (m *MockState) FindBlockIndexGaps(arg0, arg1 uint64) ([]uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBlockIndexGaps", arg0, arg1)
+	ret0, _ := ret[0].([]uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBlockIndexGaps indicates an expected call of FindBlockIndexGaps.
+func (mr *MockStateMockRecorder) FindBlockIndexGaps(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBlockIndexGaps", reflect.TypeOf((*MockState)(nil).FindBlockIndexGaps), arg0, arg1)
+}
+
+// GetAggregatePublicKey mocks base method.
+func (m *MockState) GetAggregatePublicKey(arg0 ids.ID) (*bls.PublicKey, uint64, uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAggregatePublicKey", arg0)
+	ret0, _ := ret[0].(*bls.PublicKey)
+	ret1, _ := ret[1].(uint64)
+	ret2, _ := ret[2].(uint64)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// GetAggregatePublicKey indicates an expected call of GetAggregatePublicKey.
+func (mr *MockStateMockRecorder) GetAggregatePublicKey(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAggregatePublicKey", reflect.TypeOf((*MockState)(nil).GetAggregatePublicKey), arg0)
+}
+
+// GetAllChains mocks base method.
+func (m *MockState) GetAllChains() (map[ids.ID][]*txs.Tx, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllChains")
+	ret0, _ := ret[0].(map[ids.ID][]*txs.Tx)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllChains indicates an expected call of GetAllChains.
+func (mr *MockStateMockRecorder) GetAllChains() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllChains", reflect.TypeOf((*MockState)(nil).GetAllChains))
+}
+
 // GetBlockIDAtHeight mocks base method.
 func (m *MockState) GetBlockIDAtHeight(arg0 uint64) (ids.ID, error) {
 	m.ctrl.T.Helper()
@@ -1304,6 +1469,21 @@ func (mr *MockStateMockRecorder) GetDelegateeReward(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDelegateeReward", reflect.TypeOf((*MockState)(nil).GetDelegateeReward), arg0, arg1)
 }
 
+// GetGenesisBlockID mocks base method.
+func (m *MockState) GetGenesisBlockID() (ids.ID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGenesisBlockID")
+	ret0, _ := ret[0].(ids.ID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGenesisBlockID indicates an expected call of GetGenesisBlockID.
+func (mr *MockStateMockRecorder) GetGenesisBlockID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGenesisBlockID", reflect.TypeOf((*MockState)(nil).GetGenesisBlockID))
+}
+
 // GetLastAccepted mocks base method.
 func (m *MockState) GetLastAccepted() ids.ID {
 	m.ctrl.T.Helper()
@@ -1318,6 +1498,51 @@ func (mr *MockStateMockRecorder) GetLastAccepted() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastAccepted", reflect.TypeOf((*MockState)(nil).GetLastAccepted))
 }
 
+// GetMaxValidatorWeight mocks base method.
+func (m *MockState) GetMaxValidatorWeight(arg0 ids.ID, arg1 ids.NodeID, arg2, arg3 time.Time) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMaxValidatorWeight", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMaxValidatorWeight indicates an expected call of GetMaxValidatorWeight.
+func (mr *MockStateMockRecorder) GetMaxValidatorWeight(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMaxValidatorWeight", reflect.TypeOf((*MockState)(nil).GetMaxValidatorWeight), arg0, arg1, arg2, arg3)
+}
+
+// GetNextPendingStaker mocks base method.
+func (m *MockState) GetNextPendingStaker() (*Staker, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNextPendingStaker")
+	ret0, _ := ret[0].(*Staker)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNextPendingStaker indicates an expected call of GetNextPendingStaker.
+func (mr *MockStateMockRecorder) GetNextPendingStaker() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNextPendingStaker", reflect.TypeOf((*MockState)(nil).GetNextPendingStaker))
+}
+
+// GetNodeWeightHistory mocks base method.
+func (m *MockState) GetNodeWeightHistory(arg0 ids.ID, arg1 ids.NodeID, arg2, arg3 uint64) ([]HeightWeightDiff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNodeWeightHistory", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]HeightWeightDiff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNodeWeightHistory indicates an expected call of GetNodeWeightHistory.
+func (mr *MockStateMockRecorder) GetNodeWeightHistory(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNodeWeightHistory", reflect.TypeOf((*MockState)(nil).GetNodeWeightHistory), arg0, arg1, arg2, arg3)
+}
+
 // GetPendingDelegatorIterator mocks base method.
 func (m *MockState) GetPendingDelegatorIterator(arg0 ids.ID, arg1 ids.NodeID) (StakerIterator, error) {
 	m.ctrl.T.Helper()
@@ -1348,6 +1573,22 @@ func (mr *MockStateMockRecorder) GetPendingStakerIterator() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingStakerIterator", reflect.TypeOf((*MockState)(nil).GetPendingStakerIterator))
 }
 
+// GetPendingSupplyDelta mocks base method.
+func (m *MockState) GetPendingSupplyDelta(arg0 ids.ID) (int64, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingSupplyDelta", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetPendingSupplyDelta indicates an expected call of GetPendingSupplyDelta.
+func (mr *MockStateMockRecorder) GetPendingSupplyDelta(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingSupplyDelta", reflect.TypeOf((*MockState)(nil).GetPendingSupplyDelta), arg0)
+}
+
 // GetPendingValidator mocks base method.
 func (m *MockState) GetPendingValidator(arg0 ids.ID, arg1 ids.NodeID) (*Staker, error) {
 	m.ctrl.T.Helper()
@@ -1378,6 +1619,21 @@ func (mr *MockStateMockRecorder) GetRewardUTXOs(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRewardUTXOs", reflect.TypeOf((*MockState)(nil).GetRewardUTXOs), arg0)
 }
 
+// GetStakerTxBytes mocks base method.
+func (m *MockState) GetStakerTxBytes(arg0 ids.ID) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStakerTxBytes", arg0)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStakerTxBytes indicates an expected call of GetStakerTxBytes.
+func (mr *MockStateMockRecorder) GetStakerTxBytes(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStakerTxBytes", reflect.TypeOf((*MockState)(nil).GetStakerTxBytes), arg0)
+}
+
 // GetStartTime mocks base method.
 func (m *MockState) GetStartTime(arg0 ids.NodeID, arg1 ids.ID) (time.Time, error) {
 	m.ctrl.T.Helper()
@@ -1408,6 +1664,21 @@ func (mr *MockStateMockRecorder) GetStatelessBlock(arg0 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatelessBlock", reflect.TypeOf((*MockState)(nil).GetStatelessBlock), arg0)
 }
 
+// GetSubnet mocks base method.
+func (m *MockState) GetSubnet(arg0 ids.ID) (*txs.Tx, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubnet", arg0)
+	ret0, _ := ret[0].(*txs.Tx)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubnet indicates an expected call of GetSubnet.
+func (mr *MockStateMockRecorder) GetSubnet(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubnet", reflect.TypeOf((*MockState)(nil).GetSubnet), arg0)
+}
+
 // GetSubnetOwner mocks base method.
 func (m *MockState) GetSubnetOwner(arg0 ids.ID) (fx.Owner, error) {
 	m.ctrl.T.Helper()
@@ -1453,6 +1724,36 @@ func (mr *MockStateMockRecorder) GetSubnets() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubnets", reflect.TypeOf((*MockState)(nil).GetSubnets))
 }
 
+// GetSubnetsToTrack mocks base method.
+func (m *MockState) GetSubnetsToTrack(arg0 ids.NodeID) (set.Set[ids.ID], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubnetsToTrack", arg0)
+	ret0, _ := ret[0].(set.Set[ids.ID])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubnetsToTrack indicates an expected call of GetSubnetsToTrack.
+func (mr *MockStateMockRecorder) GetSubnetsToTrack(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubnetsToTrack", reflect.TypeOf((*MockState)(nil).GetSubnetsToTrack), arg0)
+}
+
+// GetSubnetsWithPendingStakers mocks base method.
+func (m *MockState) GetSubnetsWithPendingStakers() ([]ids.ID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubnetsWithPendingStakers")
+	ret0, _ := ret[0].([]ids.ID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubnetsWithPendingStakers indicates an expected call of GetSubnetsWithPendingStakers.
+func (mr *MockStateMockRecorder) GetSubnetsWithPendingStakers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubnetsWithPendingStakers", reflect.TypeOf((*MockState)(nil).GetSubnetsWithPendingStakers))
+}
+
 // GetTimestamp mocks base method.
 func (m *MockState) GetTimestamp() time.Time {
 	m.ctrl.T.Helper()
@@ -1467,6 +1768,21 @@ func (mr *MockStateMockRecorder) GetTimestamp() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTimestamp", reflect.TypeOf((*MockState)(nil).GetTimestamp))
 }
 
+// GetTotalPotentialReward mocks base method.
+func (m *MockState) GetTotalPotentialReward(arg0 ids.ID) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTotalPotentialReward", arg0)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTotalPotentialReward indicates an expected call of GetTotalPotentialReward.
+func (mr *MockStateMockRecorder) GetTotalPotentialReward(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTotalPotentialReward", reflect.TypeOf((*MockState)(nil).GetTotalPotentialReward), arg0)
+}
+
 // GetTx mocks base method.
 func (m *MockState) GetTx(arg0 ids.ID) (*txs.Tx, status.Status, error) {
 	m.ctrl.T.Helper()
@@ -1498,6 +1814,22 @@ func (mr *MockStateMockRecorder) GetUTXO(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUTXO", reflect.TypeOf((*MockState)(nil).GetUTXO), arg0)
 }
 
+// GetUTXOWithSource mocks base method.
+func (m *MockState) GetUTXOWithSource(arg0 ids.ID) (*avax.UTXO, UTXOSource, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUTXOWithSource", arg0)
+	ret0, _ := ret[0].(*avax.UTXO)
+	ret1, _ := ret[1].(UTXOSource)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetUTXOWithSource indicates an expected call of GetUTXOWithSource.
+func (mr *MockStateMockRecorder) GetUTXOWithSource(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUTXOWithSource", reflect.TypeOf((*MockState)(nil).GetUTXOWithSource), arg0)
+}
+
 // GetUptime mocks base method.
 func (m *MockState) GetUptime(arg0 ids.NodeID, arg1 ids.ID) (time.Duration, time.Time, error) {
 	m.ctrl.T.Helper()
@@ -1514,6 +1846,96 @@ func (mr *MockStateMockRecorder) GetUptime(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUptime", reflect.TypeOf((*MockState)(nil).GetUptime), arg0, arg1)
 }
 
+// GetValidatorRewardInfo mocks base method.
+func (m *MockState) GetValidatorRewardInfo(arg0 ids.ID, arg1 ids.NodeID) (uint64, uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetValidatorRewardInfo", arg0, arg1)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(uint64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetValidatorRewardInfo indicates an expected call of GetValidatorRewardInfo.
+func (mr *MockStateMockRecorder) GetValidatorRewardInfo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetValidatorRewardInfo", reflect.TypeOf((*MockState)(nil).GetValidatorRewardInfo), arg0, arg1)
+}
+
+// HasBlock mocks base method.
+func (m *MockState) HasBlock(arg0 ids.ID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasBlock", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasBlock indicates an expected call of HasBlock.
+func (mr *MockStateMockRecorder) HasBlock(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasBlock", reflect.TypeOf((*MockState)(nil).HasBlock), arg0)
+}
+
+// ImportValidatorDiffs mocks base method.
+func (m *MockState) ImportValidatorDiffs(arg0 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportValidatorDiffs", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ImportValidatorDiffs indicates an expected call of ImportValidatorDiffs.
+func (mr *MockStateMockRecorder) ImportValidatorDiffs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportValidatorDiffs", reflect.TypeOf((*MockState)(nil).ImportValidatorDiffs), arg0)
+}
+
+// IsCurrentValidator mocks base method.
+func (m *MockState) IsCurrentValidator(arg0 ids.ID, arg1 ids.NodeID) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsCurrentValidator", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsCurrentValidator indicates an expected call of IsCurrentValidator.
+func (mr *MockStateMockRecorder) IsCurrentValidator(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsCurrentValidator", reflect.TypeOf((*MockState)(nil).IsCurrentValidator), arg0, arg1)
+}
+
+// OldestValidatorDiffHeight mocks base method.
+func (m *MockState) OldestValidatorDiffHeight(arg0 ids.ID) (uint64, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OldestValidatorDiffHeight", arg0)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// OldestValidatorDiffHeight indicates an expected call of OldestValidatorDiffHeight.
+func (mr *MockStateMockRecorder) OldestValidatorDiffHeight(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OldestValidatorDiffHeight", reflect.TypeOf((*MockState)(nil).OldestValidatorDiffHeight), arg0)
+}
+
+// PreviewReward mocks base method.
+func (m *MockState) PreviewReward(arg0 uint64, arg1 time.Duration, arg2 ids.ID) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreviewReward", arg0, arg1, arg2)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreviewReward indicates an expected call of PreviewReward.
+func (mr *MockStateMockRecorder) PreviewReward(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewReward", reflect.TypeOf((*MockState)(nil).PreviewReward), arg0, arg1, arg2)
+}
+
 // PruneAndIndex mocks base method.
 func (m *MockState) PruneAndIndex(arg0 sync.Locker, arg1 logging.Logger) error {
 	m.ctrl.T.Helper()
@@ -1528,6 +1950,20 @@ func (mr *MockStateMockRecorder) PruneAndIndex(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneAndIndex", reflect.TypeOf((*MockState)(nil).PruneAndIndex), arg0, arg1)
 }
 
+// PruneRewardUTXOs mocks base method.
+func (m *MockState) PruneRewardUTXOs(arg0 []ids.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PruneRewardUTXOs", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PruneRewardUTXOs indicates an expected call of PruneRewardUTXOs.
+func (mr *MockStateMockRecorder) PruneRewardUTXOs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneRewardUTXOs", reflect.TypeOf((*MockState)(nil).PruneRewardUTXOs), arg0)
+}
+
 // PutCurrentDelegator mocks base method.
 func (m *MockState) PutCurrentDelegator(arg0 *Staker) {
 	m.ctrl.T.Helper()
@@ -1576,6 +2012,21 @@ func (mr *MockStateMockRecorder) PutPendingValidator(arg0 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutPendingValidator", reflect.TypeOf((*MockState)(nil).PutPendingValidator), arg0)
 }
 
+// SectionSizes mocks base method.
+func (m *MockState) SectionSizes() (map[string]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SectionSizes")
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SectionSizes indicates an expected call of SectionSizes.
+func (mr *MockStateMockRecorder) SectionSizes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SectionSizes", reflect.TypeOf((*MockState)(nil).SectionSizes))
+}
+
 // SetCurrentSupply mocks base method.
 func (m *MockState) SetCurrentSupply(arg0 ids.ID, arg1 uint64) {
 	m.ctrl.T.Helper()
@@ -1679,6 +2130,35 @@ func (mr *MockStateMockRecorder) ShouldPrune() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShouldPrune", reflect.TypeOf((*MockState)(nil).ShouldPrune))
 }
 
+// SubscribeAccepted mocks base method.
+func (m *MockState) SubscribeAccepted() (<-chan block.Block, func()) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeAccepted")
+	ret0, _ := ret[0].(<-chan block.Block)
+	ret1, _ := ret[1].(func())
+	return ret0, ret1
+}
+
+// SubscribeAccepted indicates an expected call of SubscribeAccepted.
+func (mr *MockStateMockRecorder) SubscribeAccepted() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeAccepted", reflect.TypeOf((*MockState)(nil).SubscribeAccepted))
+}
+
+// VerifyValidatorDiffChain mocks base method.
+func (m *MockState) VerifyValidatorDiffChain(arg0 context.Context, arg1 ids.ID, arg2, arg3 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyValidatorDiffChain", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyValidatorDiffChain indicates an expected call of VerifyValidatorDiffChain.
+func (mr *MockStateMockRecorder) VerifyValidatorDiffChain(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyValidatorDiffChain", reflect.TypeOf((*MockState)(nil).VerifyValidatorDiffChain), arg0, arg1, arg2, arg3)
+}
+
 // UTXOIDs mocks base method.
 func (m *MockState) UTXOIDs(arg0 []byte, arg1 ids.ID, arg2 int) ([]ids.ID, error) {
 	m.ctrl.T.Helper()
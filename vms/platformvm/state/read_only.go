@@ -0,0 +1,190 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/block"
+	"github.com/ava-labs/avalanchego/vms/platformvm/config"
+	"github.com/ava-labs/avalanchego/vms/platformvm/metrics"
+	"github.com/ava-labs/avalanchego/vms/platformvm/reward"
+	"github.com/ava-labs/avalanchego/vms/platformvm/status"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+)
+
+var (
+	_ StateReader       = (*readOnlyState)(nil)
+	_ database.Database = (*readOnlyDB)(nil)
+	_ database.Batch    = (*readOnlyBatch)(nil)
+
+	errReadOnly = errors.New("state is read-only")
+)
+
+// StateReader provides read access to the state committed by another process
+// sharing the same database.
+type StateReader interface {
+	avax.UTXOReader
+
+	GetLastAccepted() ids.ID
+	GetTimestamp() time.Time
+	GetStatelessBlock(blockID ids.ID) (block.Block, error)
+	GetBlockIDAtHeight(height uint64) (ids.ID, error)
+	GetTx(txID ids.ID) (*txs.Tx, status.Status, error)
+	GetRewardUTXOs(txID ids.ID) ([]*avax.UTXO, error)
+
+	// Refresh updates the view of the state to the latest commit. Reads are
+	// only guaranteed to reflect commits that happened before the last call
+	// to Refresh.
+	Refresh() error
+}
+
+type readOnlyState struct {
+	db      database.Database
+	execCfg *config.ExecutionConfig
+	ctx     *snow.Context
+	rewards reward.Calculator
+
+	// lock protects [state] from being replaced during a read.
+	lock  sync.RWMutex
+	state *state
+}
+
+// NewReadOnly returns a view of the state persisted in [db] by a primary
+// process. The returned state never writes to [db]. Refresh must be called,
+// either periodically or when signaled by the primary, to observe new commits.
+//
+// Invariant: [db] must have already been initialized by New.
+func NewReadOnly(
+	db database.Database,
+	execCfg *config.ExecutionConfig,
+	ctx *snow.Context,
+	rewards reward.Calculator,
+) (StateReader, error) {
+	s := &readOnlyState{
+		db:      &readOnlyDB{Database: db},
+		execCfg: execCfg,
+		ctx:     ctx,
+		rewards: rewards,
+	}
+	return s, s.Refresh()
+}
+
+func (s *readOnlyState) Refresh() error {
+	singletonDB := prefixdb.New(singletonPrefix, s.db)
+	lastAccepted, err := database.GetID(singletonDB, lastAcceptedKey)
+	if err != nil {
+		return err
+	}
+
+	s.lock.RLock()
+	upToDate := s.state != nil && s.state.GetLastAccepted() == lastAccepted
+	s.lock.RUnlock()
+	if upToDate {
+		return nil
+	}
+
+	// The caches of a state are only kept consistent with the writes made by
+	// that state, so a new state is created to avoid serving stale entries.
+	newState, err := newState(
+		s.db,
+		metrics.Noop,
+		validators.NewManager(),
+		s.execCfg,
+		s.ctx,
+		prometheus.NewRegistry(),
+		s.rewards,
+	)
+	if err != nil {
+		return err
+	}
+	if err := newState.loadMetadata(); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.state = newState
+	return nil
+}
+
+func (s *readOnlyState) GetUTXO(utxoID ids.ID) (*avax.UTXO, error) {
+	return s.getState().GetUTXO(utxoID)
+}
+
+func (s *readOnlyState) UTXOIDs(addr []byte, previous ids.ID, limit int) ([]ids.ID, error) {
+	return s.getState().UTXOIDs(addr, previous, limit)
+}
+
+func (s *readOnlyState) GetLastAccepted() ids.ID {
+	return s.getState().GetLastAccepted()
+}
+
+func (s *readOnlyState) GetTimestamp() time.Time {
+	return s.getState().GetTimestamp()
+}
+
+func (s *readOnlyState) GetStatelessBlock(blockID ids.ID) (block.Block, error) {
+	return s.getState().GetStatelessBlock(blockID)
+}
+
+func (s *readOnlyState) GetBlockIDAtHeight(height uint64) (ids.ID, error) {
+	return s.getState().GetBlockIDAtHeight(height)
+}
+
+func (s *readOnlyState) GetTx(txID ids.ID) (*txs.Tx, status.Status, error) {
+	return s.getState().GetTx(txID)
+}
+
+func (s *readOnlyState) GetRewardUTXOs(txID ids.ID) ([]*avax.UTXO, error) {
+	return s.getState().GetRewardUTXOs(txID)
+}
+
+func (s *readOnlyState) getState() *state {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.state
+}
+
+// readOnlyDB rejects all writes to the wrapped database.
+type readOnlyDB struct {
+	database.Database
+}
+
+func (*readOnlyDB) Put([]byte, []byte) error {
+	return errReadOnly
+}
+
+func (*readOnlyDB) Delete([]byte) error {
+	return errReadOnly
+}
+
+func (db *readOnlyDB) NewBatch() database.Batch {
+	return &readOnlyBatch{Batch: db.Database.NewBatch()}
+}
+
+// Close doesn't close the wrapped database, as it is owned by the primary.
+func (*readOnlyDB) Close() error {
+	return nil
+}
+
+type readOnlyBatch struct {
+	database.Batch
+}
+
+func (*readOnlyBatch) Write() error {
+	return errReadOnly
+}
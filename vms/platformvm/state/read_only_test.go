@@ -0,0 +1,115 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/config"
+	"github.com/ava-labs/avalanchego/vms/platformvm/reward"
+	"github.com/ava-labs/avalanchego/vms/platformvm/status"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+func TestReadOnlyState(t *testing.T) {
+	require := require.New(t)
+
+	primary, db := newInitializedState(require)
+	require.NoError(primary.Commit())
+
+	execCfg, err := config.GetExecutionConfig(nil)
+	require.NoError(err)
+	replica, err := NewReadOnly(
+		db,
+		execCfg,
+		&snow.Context{},
+		reward.NewCalculator(reward.Config{
+			MaxConsumptionRate: .12 * reward.PercentDenominator,
+			MinConsumptionRate: .1 * reward.PercentDenominator,
+			MintingPeriod:      365 * 24 * time.Hour,
+			SupplyCap:          720 * units.MegaAvax,
+		}),
+	)
+	require.NoError(err)
+	require.Equal(primary.GetLastAccepted(), replica.GetLastAccepted())
+	require.Equal(primary.GetTimestamp(), replica.GetTimestamp())
+
+	genesisUTXOID := avax.UTXOID{
+		TxID:        initialTxID,
+		OutputIndex: 0,
+	}
+	_, err = replica.GetUTXO(genesisUTXOID.InputID())
+	require.NoError(err)
+
+	// Commit a new tx, consume the genesis UTXO, and produce a new UTXO on the
+	// primary.
+	tx := &txs.Tx{Unsigned: &txs.BaseTx{}}
+	require.NoError(tx.Initialize(txs.Codec))
+	utxo := &avax.UTXO{
+		UTXOID: avax.UTXOID{
+			TxID:        tx.ID(),
+			OutputIndex: 0,
+		},
+		Asset: avax.Asset{ID: initialTxID},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: units.Schmeckle,
+		},
+	}
+	lastAccepted := ids.GenerateTestID()
+	primary.AddTx(tx, status.Committed)
+	primary.DeleteUTXO(genesisUTXOID.InputID())
+	primary.AddUTXO(utxo)
+	primary.SetLastAccepted(lastAccepted)
+	require.NoError(primary.Commit())
+
+	require.NoError(replica.Refresh())
+	require.Equal(lastAccepted, replica.GetLastAccepted())
+
+	_, txStatus, err := replica.GetTx(tx.ID())
+	require.NoError(err)
+	require.Equal(status.Committed, txStatus)
+
+	_, err = replica.GetUTXO(genesisUTXOID.InputID())
+	require.ErrorIs(err, database.ErrNotFound)
+
+	gotUTXO, err := replica.GetUTXO(utxo.InputID())
+	require.NoError(err)
+	require.Equal(utxo.InputID(), gotUTXO.InputID())
+}
+
+func TestReadOnlyDBRejectsWrites(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	db := &readOnlyDB{Database: baseDB}
+
+	err := db.Put([]byte{1}, []byte{2})
+	require.ErrorIs(err, errReadOnly)
+
+	err = db.Delete([]byte{1})
+	require.ErrorIs(err, errReadOnly)
+
+	batch := db.NewBatch()
+	require.NoError(batch.Put([]byte{1}, []byte{2}))
+	err = batch.Write()
+	require.ErrorIs(err, errReadOnly)
+
+	has, err := baseDB.Has([]byte{1})
+	require.NoError(err)
+	require.False(has)
+
+	// Closing the read-only view must not close the underlying database.
+	require.NoError(db.Close())
+	require.NoError(baseDB.Put([]byte{1}, []byte{2}))
+}
@@ -0,0 +1,106 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"time"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
+)
+
+// sectionSizesCacheDuration is how long the result of SectionSizes is reused
+// before the database is scanned again.
+const sectionSizesCacheDuration = 5 * time.Minute
+
+// stateSections maps the name of each section of the state to the prefix
+// chains, starting from the base database, of the data stored in the section.
+//
+// Data stored under prefixes derived from IDs or heights, such as reward UTXOs,
+// chains, and the legacy nested validator diffs, isn't included.
+var stateSections = map[string][][][]byte{
+	"blocks": {
+		{blockIDPrefix},
+		{blockPrefix},
+	},
+	"currentStakers": {
+		{validatorsPrefix, currentPrefix, validatorPrefix},
+		{validatorsPrefix, currentPrefix, delegatorPrefix},
+		{validatorsPrefix, currentPrefix, subnetValidatorPrefix},
+		{validatorsPrefix, currentPrefix, subnetDelegatorPrefix},
+	},
+	"pendingStakers": {
+		{validatorsPrefix, pendingPrefix, validatorPrefix},
+		{validatorsPrefix, pendingPrefix, delegatorPrefix},
+		{validatorsPrefix, pendingPrefix, subnetValidatorPrefix},
+		{validatorsPrefix, pendingPrefix, subnetDelegatorPrefix},
+	},
+	"validatorDiffs": {
+		{validatorsPrefix, flatValidatorWeightDiffsPrefix},
+		{validatorsPrefix, flatValidatorPublicKeyDiffsPrefix},
+	},
+	"txs": {
+		{txPrefix},
+	},
+	// The nested prefixes are the ones used by avax.UTXOState.
+	"utxos": {
+		{utxoPrefix, []byte("utxo")},
+		{utxoPrefix, []byte("index")},
+	},
+	"subnets": {
+		{subnetPrefix},
+		{subnetOwnerPrefix},
+		{transformedSubnetPrefix},
+		{supplyPrefix},
+	},
+	"singletons": {
+		{singletonPrefix},
+	},
+}
+
+func (s *state) SectionSizes() (map[string]int64, error) {
+	now := time.Now()
+	if s.sectionSizes != nil && now.Sub(s.sectionSizesTime) < sectionSizesCacheDuration {
+		return maps.Clone(s.sectionSizes), nil
+	}
+
+	sizes := make(map[string]int64, len(stateSections))
+	for section, prefixes := range stateSections {
+		size := int64(0)
+		for _, prefix := range prefixes {
+			// Only the committed state is measured.
+			db := database.Database(s.deferredDB)
+			for _, p := range prefix {
+				db = prefixdb.New(p, db)
+			}
+			prefixSize, err := databaseSize(db)
+			if err != nil {
+				return nil, err
+			}
+			size += prefixSize
+		}
+		sizes[section] = size
+		s.metrics.SetSectionSize(section, size)
+	}
+
+	s.sectionSizes = sizes
+	s.sectionSizesTime = now
+	return maps.Clone(sizes), nil
+}
+
+// databaseSize returns the number of bytes of the keys and values in [db].
+// Neither the prefixes of the keys nor the overhead of the underlying storage
+// are included.
+func databaseSize(db database.Iteratee) (int64, error) {
+	it := db.NewIterator()
+	defer it.Release()
+
+	size := int64(0)
+	for it.Next() {
+		size += int64(len(it.Key()) + len(it.Value()))
+	}
+	return size, it.Error()
+}
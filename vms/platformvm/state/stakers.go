@@ -4,12 +4,21 @@
 package state
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/google/btree"
 
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
 )
 
+var (
+	errMissingStakerEntry  = errors.New("staker is in the staker tree but not in the validators map")
+	errMissingTreeEntry    = errors.New("staker is in the validators map but not in the staker tree")
+	errStakerCountMismatch = errors.New("staker tree and validators map sizes differ")
+)
+
 type Stakers interface {
 	CurrentStakers
 	PendingStakers
@@ -59,7 +68,8 @@ type CurrentStakers interface {
 	DeleteCurrentDelegator(staker *Staker)
 
 	// GetCurrentStakerIterator returns stakers in order of their removal from
-	// the current staker set.
+	// the current staker set. Stakers with the same end time and priority are
+	// returned in order of their txIDs, as defined by [Staker.Less].
 	GetCurrentStakerIterator() (StakerIterator, error)
 }
 
@@ -99,6 +109,8 @@ type baseStakers struct {
 	// subnetID --> nodeID --> current state for the validator of the subnet
 	validators map[ids.ID]map[ids.NodeID]*baseStaker
 	stakers    *btree.BTreeG[*Staker]
+	// degree of the btree holding the delegators of each validator
+	delegatorTreeDegree int
 	// subnetID --> nodeID --> diff for that validator since the last db write
 	validatorDiffs map[ids.ID]map[ids.NodeID]*diffValidator
 }
@@ -108,11 +120,18 @@ type baseStaker struct {
 	delegators *btree.BTreeG[*Staker]
 }
 
-func newBaseStakers() *baseStakers {
+// newBaseStakers returns an empty staker set whose delegator trees have a
+// degree of [delegatorTreeDegree]. If [delegatorTreeDegree] isn't a valid btree
+// degree, [defaultTreeDegree] is used.
+func newBaseStakers(delegatorTreeDegree int) *baseStakers {
+	if delegatorTreeDegree < 2 {
+		delegatorTreeDegree = defaultTreeDegree
+	}
 	return &baseStakers{
-		validators:     make(map[ids.ID]map[ids.NodeID]*baseStaker),
-		stakers:        btree.NewG(defaultTreeDegree, (*Staker).Less),
-		validatorDiffs: make(map[ids.ID]map[ids.NodeID]*diffValidator),
+		validators:          make(map[ids.ID]map[ids.NodeID]*baseStaker),
+		stakers:             btree.NewG(defaultTreeDegree, (*Staker).Less),
+		delegatorTreeDegree: delegatorTreeDegree,
+		validatorDiffs:      make(map[ids.ID]map[ids.NodeID]*diffValidator),
 	}
 }
 
@@ -131,13 +150,31 @@ func (v *baseStakers) GetValidator(subnetID ids.ID, nodeID ids.NodeID) (*Staker,
 	return validator.validator, nil
 }
 
+// HasValidator returns true if [nodeID] is a validator of [subnetID]. Unlike
+// GetValidator, no error is constructed if the validator doesn't exist.
+func (v *baseStakers) HasValidator(subnetID ids.ID, nodeID ids.NodeID) bool {
+	validator, ok := v.validators[subnetID][nodeID]
+	return ok && validator.validator != nil
+}
+
 func (v *baseStakers) PutValidator(staker *Staker) {
-	validator := v.getOrCreateValidator(staker.SubnetID, staker.NodeID)
-	validator.validator = staker
+	v.LoadValidator(staker)
 
 	validatorDiff := v.getOrCreateValidatorDiff(staker.SubnetID, staker.NodeID)
 	validatorDiff.validatorStatus = added
 	validatorDiff.validator = staker
+}
+
+// LoadValidator adds the [staker] describing a validator to the staker set
+// without recording a diff.
+//
+// This must be used, rather than PutValidator, when populating the staker set
+// from disk. Recording a diff would cause the already persisted validator to be
+// written again on the next commit, along with a spurious weight diff at that
+// height.
+func (v *baseStakers) LoadValidator(staker *Staker) {
+	validator := v.getOrCreateValidator(staker.SubnetID, staker.NodeID)
+	validator.validator = staker
 
 	v.stakers.ReplaceOrInsert(staker)
 }
@@ -167,17 +204,26 @@ func (v *baseStakers) GetDelegatorIterator(subnetID ids.ID, nodeID ids.NodeID) S
 }
 
 func (v *baseStakers) PutDelegator(staker *Staker) {
-	validator := v.getOrCreateValidator(staker.SubnetID, staker.NodeID)
-	if validator.delegators == nil {
-		validator.delegators = btree.NewG(defaultTreeDegree, (*Staker).Less)
-	}
-	validator.delegators.ReplaceOrInsert(staker)
+	v.LoadDelegator(staker)
 
 	validatorDiff := v.getOrCreateValidatorDiff(staker.SubnetID, staker.NodeID)
 	if validatorDiff.addedDelegators == nil {
 		validatorDiff.addedDelegators = btree.NewG(defaultTreeDegree, (*Staker).Less)
 	}
 	validatorDiff.addedDelegators.ReplaceOrInsert(staker)
+}
+
+// LoadDelegator adds the [staker] describing a delegator to the staker set
+// without recording a diff.
+//
+// See LoadValidator for why this is used when populating the staker set from
+// disk.
+func (v *baseStakers) LoadDelegator(staker *Staker) {
+	validator := v.getOrCreateValidator(staker.SubnetID, staker.NodeID)
+	if validator.delegators == nil {
+		validator.delegators = btree.NewG(v.delegatorTreeDegree, (*Staker).Less)
+	}
+	validator.delegators.ReplaceOrInsert(staker)
 
 	v.stakers.ReplaceOrInsert(staker)
 }
@@ -202,6 +248,69 @@ func (v *baseStakers) GetStakerIterator() StakerIterator {
 	return NewTreeIterator(v.stakers)
 }
 
+// GetFirstStaker returns the first staker in the order of the staker
+// iterator. Returns false if there are no stakers.
+func (v *baseStakers) GetFirstStaker() (*Staker, bool) {
+	return v.stakers.Min()
+}
+
+// Verify checks that the [stakers] tree and the [validators] map contain
+// exactly the same set of stakers.
+func (v *baseStakers) Verify() error {
+	numStakers := 0
+	for subnetID, subnetValidators := range v.validators {
+		for nodeID, validator := range subnetValidators {
+			if validator.validator != nil {
+				if !v.stakers.Has(validator.validator) {
+					return fmt.Errorf("%w: validator %s of subnet %s", errMissingTreeEntry, nodeID, subnetID)
+				}
+				numStakers++
+			}
+
+			if validator.delegators == nil {
+				continue
+			}
+			var err error
+			validator.delegators.Ascend(func(delegator *Staker) bool {
+				if !v.stakers.Has(delegator) {
+					err = fmt.Errorf("%w: delegator %s of %s on subnet %s", errMissingTreeEntry, delegator.TxID, nodeID, subnetID)
+					return false
+				}
+				numStakers++
+				return true
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	var err error
+	v.stakers.Ascend(func(staker *Staker) bool {
+		validator, ok := v.validators[staker.SubnetID][staker.NodeID]
+		if !ok {
+			err = fmt.Errorf("%w: %s", errMissingStakerEntry, staker.TxID)
+			return false
+		}
+
+		isValidator := validator.validator != nil && validator.validator.TxID == staker.TxID
+		isDelegator := validator.delegators != nil && validator.delegators.Has(staker)
+		if !isValidator && !isDelegator {
+			err = fmt.Errorf("%w: %s", errMissingStakerEntry, staker.TxID)
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	if numStakers != v.stakers.Len() {
+		return fmt.Errorf("%w: %d in tree, %d in map", errStakerCountMismatch, v.stakers.Len(), numStakers)
+	}
+	return nil
+}
+
 func (v *baseStakers) getOrCreateValidator(subnetID ids.ID, nodeID ids.NodeID) *baseStaker {
 	subnetValidators, ok := v.validators[subnetID]
 	if !ok {
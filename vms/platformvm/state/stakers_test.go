@@ -21,7 +21,7 @@ func TestBaseStakersPruning(t *testing.T) {
 	delegator.SubnetID = staker.SubnetID
 	delegator.NodeID = staker.NodeID
 
-	v := newBaseStakers()
+	v := newBaseStakers(defaultTreeDegree)
 
 	v.PutValidator(staker)
 
@@ -70,7 +70,7 @@ func TestBaseStakersValidator(t *testing.T) {
 	staker := newTestStaker()
 	delegator := newTestStaker()
 
-	v := newBaseStakers()
+	v := newBaseStakers(defaultTreeDegree)
 
 	v.PutDelegator(delegator)
 
@@ -110,7 +110,7 @@ func TestBaseStakersDelegator(t *testing.T) {
 	staker := newTestStaker()
 	delegator := newTestStaker()
 
-	v := newBaseStakers()
+	v := newBaseStakers(defaultTreeDegree)
 
 	delegatorIterator := v.GetDelegatorIterator(delegator.SubnetID, delegator.NodeID)
 	assertIteratorsEqual(t, EmptyIterator, delegatorIterator)
@@ -137,6 +137,80 @@ func TestBaseStakersDelegator(t *testing.T) {
 	assertIteratorsEqual(t, EmptyIterator, delegatorIterator)
 }
 
+func TestBaseStakersDelegatorTreeDegree(t *testing.T) {
+	tests := []struct {
+		name           string
+		degree         int
+		expectedDegree int
+	}{
+		{
+			name:           "invalid degree",
+			degree:         1,
+			expectedDegree: defaultTreeDegree,
+		},
+		{
+			name:           "custom degree",
+			degree:         32,
+			expectedDegree: 32,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			v := newBaseStakers(test.degree)
+			require.Equal(test.expectedDegree, v.delegatorTreeDegree)
+
+			validator := newTestStaker()
+			v.PutValidator(validator)
+
+			delegators := make([]*Staker, 100)
+			for i := range delegators {
+				delegator := newTestStaker()
+				delegator.SubnetID = validator.SubnetID
+				delegator.NodeID = validator.NodeID
+				delegator.NextTime = delegator.NextTime.Add(time.Duration(i) * time.Second)
+				delegators[i] = delegator
+
+				v.PutDelegator(delegator)
+			}
+
+			delegatorIterator := v.GetDelegatorIterator(validator.SubnetID, validator.NodeID)
+			assertIteratorsEqual(t, NewSliceIterator(delegators...), delegatorIterator)
+		})
+	}
+}
+
+func TestBaseStakersVerify(t *testing.T) {
+	require := require.New(t)
+	staker := newTestStaker()
+	delegator := newTestStaker()
+	delegator.SubnetID = staker.SubnetID
+	delegator.NodeID = staker.NodeID
+
+	v := newBaseStakers(defaultTreeDegree)
+	require.NoError(v.Verify())
+
+	v.PutValidator(staker)
+	v.PutDelegator(delegator)
+	require.NoError(v.Verify())
+
+	// Remove the delegator from the tree only
+	v.stakers.Delete(delegator)
+	err := v.Verify()
+	require.ErrorIs(err, errMissingTreeEntry)
+
+	// Restore consistency
+	v.stakers.ReplaceOrInsert(delegator)
+	require.NoError(v.Verify())
+
+	// Add a staker to the tree only
+	orphan := newTestStaker()
+	v.stakers.ReplaceOrInsert(orphan)
+	err = v.Verify()
+	require.ErrorIs(err, errMissingStakerEntry)
+}
+
 func TestDiffStakersValidator(t *testing.T) {
 	require := require.New(t)
 	staker := newTestStaker()
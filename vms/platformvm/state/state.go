@@ -4,6 +4,7 @@
 package state
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -11,10 +12,11 @@ import (
 	"sync"
 	"time"
 
-	"github.com/google/btree"
-
 	"go.uber.org/zap"
 
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/ava-labs/avalanchego/cache"
@@ -29,10 +31,12 @@ import (
 	"github.com/ava-labs/avalanchego/snow/uptime"
 	"github.com/ava-labs/avalanchego/snow/validators"
 	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/compression"
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/crypto/bls"
 	"github.com/ava-labs/avalanchego/utils/hashing"
 	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/utils/timer"
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 	"github.com/ava-labs/avalanchego/vms/components/avax"
@@ -53,13 +57,34 @@ const (
 	pruneCommitSleepMultiplier = 5
 	pruneCommitSleepCap        = 10 * time.Second
 	pruneUpdateFrequency       = 30 * time.Second
+
+	// maxPendingRewardUTXOs is the number of reward UTXOs that are held in
+	// memory before they are flushed to the uncommitted base database.
+	maxPendingRewardUTXOs = 1024
+
+	// utxoIDsPageSize is the number of UTXO IDs that are read from the
+	// address index at a time by DeleteUTXOsForAddress.
+	utxoIDsPageSize = 1024
+
+	// missingBlockCacheSize is the number of block IDs that are remembered as
+	// not being in the database.
+	missingBlockCacheSize = 2048
 )
 
 var (
 	_ State = (*state)(nil)
 
+	ErrNoPendingStakers         = errors.New("no pending stakers")
+	ErrSubnetAlreadyTransformed = errors.New("subnet already transformed")
+
 	errValidatorSetAlreadyPopulated = errors.New("validator set already populated")
 	errIsNotSubnet                  = errors.New("is not a subnet")
+	errUnknownSchemaVersion         = errors.New("unknown schema version")
+	errGenesisMismatch              = errors.New("genesis mismatch")
+	errUnknownParentBlock           = errors.New("unknown parent block")
+	errUnexpectedBlockHeight        = errors.New("unexpected block height")
+	errInvalidHeightRange           = errors.New("from height is greater than to height")
+	errUnsupportedRewardSubnet      = errors.New("rewards can only be previewed for the primary network")
 
 	blockIDPrefix                       = []byte("blockID")
 	blockPrefix                         = []byte("block")
@@ -90,6 +115,8 @@ var (
 	heightsIndexedKey = []byte("heights indexed")
 	initializedKey    = []byte("initialized")
 	prunedKey         = []byte("pruned")
+	schemaVersionKey  = []byte("schema version")
+	genesisHashKey    = []byte("genesis hash")
 )
 
 // Chain collects all methods to manage the state of the chain for block
@@ -100,6 +127,10 @@ type Chain interface {
 	avax.UTXOGetter
 	avax.UTXODeleter
 
+	// AddUTXOs adds all of [utxos]. This is equivalent to calling AddUTXO for
+	// each UTXO, but avoids repeatedly growing the set of modified UTXOs.
+	AddUTXOs(utxos []*avax.UTXO)
+
 	GetTimestamp() time.Time
 	SetTimestamp(tm time.Time)
 
@@ -114,7 +145,10 @@ type Chain interface {
 	SetSubnetOwner(subnetID ids.ID, owner fx.Owner)
 
 	GetSubnetTransformation(subnetID ids.ID) (*txs.Tx, error)
-	AddSubnetTransformation(transformSubnetTx *txs.Tx)
+	// AddSubnetTransformation returns ErrSubnetAlreadyTransformed if the
+	// subnet has already been transformed, as a subnet can only be
+	// transformed once.
+	AddSubnetTransformation(transformSubnetTx *txs.Tx) error
 
 	AddChain(createChainTx *txs.Tx)
 
@@ -130,17 +164,138 @@ type State interface {
 	GetLastAccepted() ids.ID
 	SetLastAccepted(blkID ids.ID)
 
+	// GetNextPendingStaker returns the pending staker that will be promoted
+	// next. Pending stakers are ordered by start time, then by priority, then
+	// by tx ID. Returns ErrNoPendingStakers if there are no pending stakers.
+	GetNextPendingStaker() (*Staker, error)
+
+	// IsCurrentValidator returns true if [nodeID] is currently validating
+	// [subnetID]. Delegators of the subnet aren't considered validators.
+	IsCurrentValidator(subnetID ids.ID, nodeID ids.NodeID) bool
+
+	// GetSubnetsToTrack returns the subnets that [nodeID] should track. This
+	// is the primary network along with every subnet that [nodeID] is a
+	// current or pending validator of.
+	GetSubnetsToTrack(nodeID ids.NodeID) (set.Set[ids.ID], error)
+
+	// GetSubnetsWithPendingStakers returns the sorted IDs of the subnets that
+	// have pending stakers. The pending stakers aren't iterated.
+	GetSubnetsWithPendingStakers() ([]ids.ID, error)
+
+	// GetValidatorRewardInfo returns the potential reward of the current
+	// validator on [subnetID] with [nodeID] along with the delegation rewards
+	// accrued to it. If the validator does not exist, [database.ErrNotFound]
+	// is returned.
+	GetValidatorRewardInfo(subnetID ids.ID, nodeID ids.NodeID) (potential uint64, delegatee uint64, err error)
+
 	GetStatelessBlock(blockID ids.ID) (block.Block, error)
 
+	// HasBlock returns true if [blockID] has been accepted. The block is only
+	// parsed if the state hasn't been pruned yet, as it may then be stored in
+	// the legacy format, which records blocks that weren't accepted.
+	HasBlock(blockID ids.ID) (bool, error)
+
 	// Invariant: [block] is an accepted block.
-	AddStatelessBlock(block block.Block)
+	//
+	// If block linkage verification is enabled, an error is returned if the
+	// parent of [block] isn't known or if [block]'s height isn't one greater
+	// than its parent's height.
+	AddStatelessBlock(block block.Block) error
 
 	GetBlockIDAtHeight(height uint64) (ids.ID, error)
 
+	// FindBlockIndexGaps returns the heights in [fromHeight, toHeight], in
+	// increasing order, that don't have a block ID recorded in the height
+	// index. Heights above the current height aren't gaps, so [toHeight] is
+	// clamped to it. Only the requested range of the index is read.
+	FindBlockIndexGaps(fromHeight, toHeight uint64) ([]uint64, error)
+
+	// GetMaxValidatorWeight returns the maximum total weight, including the
+	// weight of its delegators, of the current or pending validator [nodeID]
+	// of [subnetID] between [startTime] and [endTime].
+	//
+	// [startTime, endTime] must be within the staking period of the
+	// validator and [startTime] must be before [endTime].
+	GetMaxValidatorWeight(
+		subnetID ids.ID,
+		nodeID ids.NodeID,
+		startTime time.Time,
+		endTime time.Time,
+	) (uint64, error)
+
+	// GetPendingSupplyDelta returns the signed difference between the current
+	// supply of [subnetID], including changes that haven't been committed, and
+	// its supply as of the last commit. The returned bool is false if there is
+	// no uncommitted change to the supply. An error is returned if the
+	// difference doesn't fit in an int64.
+	GetPendingSupplyDelta(subnetID ids.ID) (int64, bool, error)
+
+	// GetUTXOWithSource behaves like GetUTXO but additionally reports where
+	// the result was read from. It is intended for debugging only.
+	GetUTXOWithSource(utxoID ids.ID) (*avax.UTXO, UTXOSource, error)
+
+	// DeleteUTXOsForAddress deletes every UTXO that is owned by [addr],
+	// including UTXOs that were added but haven't been committed yet, and
+	// returns the number of UTXOs that were deleted. The deletions are
+	// persisted, and the address index updated, on the next call to Commit.
+	DeleteUTXOsForAddress(addr []byte) (int, error)
+
+	// GetTotalPotentialReward returns the sum of the potential rewards of the
+	// current validators and delegators of [subnetID].
+	GetTotalPotentialReward(subnetID ids.ID) (uint64, error)
+
+	// PreviewReward returns the potential reward that a validator of
+	// [subnetID] staking [stakeAmount] for [stakeDuration] would receive if
+	// it were added now. Only the primary network is supported, as the
+	// rewards of an elastic subnet are calculated with the parameters of its
+	// transformation.
+	PreviewReward(stakeAmount uint64, stakeDuration time.Duration, subnetID ids.ID) (uint64, error)
+
+	// GetAggregatePublicKey returns the aggregate BLS public key of the
+	// current validators of [subnetID] along with their total weight,
+	// including the weight of their delegators. The public key of a subnet
+	// validator is the key it registered on the primary network. Validators
+	// without a public key are excluded, and their total weight is returned
+	// separately. If no validator has a public key, the returned key is nil.
+	GetAggregatePublicKey(subnetID ids.ID) (
+		aggregatePublicKey *bls.PublicKey,
+		weight uint64,
+		excludedWeight uint64,
+		err error,
+	)
+
+	// GetStakerTxBytes returns the bytes of the tx [txID], such as the tx
+	// that added a staker, without parsing the tx when it is read from disk.
+	// If the tx doesn't exist, [database.ErrNotFound] is returned.
+	GetStakerTxBytes(txID ids.ID) ([]byte, error)
+
+	// GetGenesisBlockID returns the ID of the block at height 0.
+	GetGenesisBlockID() (ids.ID, error)
+
 	GetRewardUTXOs(txID ids.ID) ([]*avax.UTXO, error)
+
+	// PruneRewardUTXOs removes the reward UTXO index of each tx in [txIDs].
+	// The removal is persisted on the next call to Commit.
+	//
+	// Invariant: This must only be called for txs whose reward UTXOs will
+	// never be queried again, e.g. because they have all been consumed.
+	// After pruning, GetRewardUTXOs will report no reward UTXOs for the tx.
+	PruneRewardUTXOs(txIDs []ids.ID) error
+
 	GetSubnets() ([]*txs.Tx, error)
+
+	// GetSubnet returns the CreateSubnetTx of [subnetID] without loading the
+	// other subnets. Returns [database.ErrNotFound] if the subnet doesn't
+	// exist.
+	GetSubnet(subnetID ids.ID) (*txs.Tx, error)
+
 	GetChains(subnetID ids.ID) ([]*txs.Tx, error)
 
+	// GetAllChains returns the chains of the primary network and of every
+	// subnet, including uncommitted chains, keyed by subnet ID. Subnets without
+	// chains are omitted.
+	GetAllChains() (map[ids.ID][]*txs.Tx, error)
+
 	// ApplyValidatorWeightDiffs iterates from [startHeight] towards the genesis
 	// block until it has applied all of the diffs up to and including
 	// [endHeight]. Applying the diffs modifies [validators].
@@ -160,6 +315,24 @@ type State interface {
 		subnetID ids.ID,
 	) error
 
+	// ApplyValidatorWeightDiffsForward iterates from [startHeight] away from
+	// the genesis block until it has applied all of the diffs up to and
+	// including [endHeight]. Applying the diffs modifies [validators].
+	//
+	// Invariant: [validators] must initially contain the validator weights
+	// for [startHeight]. Afterwards, it contains the validator weights for
+	// [endHeight]. Validators added by the diffs don't have a public key.
+	//
+	// Note: If [startHeight] is greater than or equal to [endHeight], no diffs
+	// will be applied.
+	ApplyValidatorWeightDiffsForward(
+		ctx context.Context,
+		validators map[ids.NodeID]*validators.GetValidatorOutput,
+		startHeight uint64,
+		endHeight uint64,
+		subnetID ids.ID,
+	) error
+
 	// ApplyValidatorPublicKeyDiffs iterates from [startHeight] towards the
 	// genesis block until it has applied all of the diffs up to and including
 	// [endHeight]. Applying the diffs modifies [validators].
@@ -178,11 +351,74 @@ type State interface {
 		endHeight uint64,
 	) error
 
+	// ExportValidatorDiffs returns the weight and public key diffs of
+	// [subnetID] for the heights in [endHeight, startHeight], serialized into a
+	// single artifact that can be passed to ImportValidatorDiffs.
+	//
+	// Note: Following ApplyValidatorWeightDiffs, [startHeight] must be greater
+	// than or equal to [endHeight]. Only diffs recorded in the flat diff index
+	// are exported.
+	ExportValidatorDiffs(subnetID ids.ID, startHeight, endHeight uint64) ([]byte, error)
+
+	// GetNodeWeightHistory returns the weight diffs of [nodeID] on [subnetID]
+	// for the heights in [endHeight, startHeight], sorted by decreasing
+	// height.
+	//
+	// Note: Following ApplyValidatorWeightDiffs, [startHeight] must be greater
+	// than or equal to [endHeight]. Only diffs recorded in the flat diff index
+	// are returned.
+	GetNodeWeightHistory(subnetID ids.ID, nodeID ids.NodeID, startHeight, endHeight uint64) ([]HeightWeightDiff, error)
+
+	// ImportValidatorDiffs writes the diffs exported by ExportValidatorDiffs,
+	// extending the range of heights that validator sets can be generated
+	// for. The diffs are persisted, and used when generating validator sets,
+	// after the next call to Commit. Abort discards them.
+	//
+	// Invariant: The imported diffs must be contiguous with the diffs that are
+	// already indexed.
+	ImportValidatorDiffs(diffs []byte) error
+
+	// OldestValidatorDiffHeight returns the lowest height with a validator
+	// weight diff of [subnetID] in the flat diff index. Returns false if the
+	// index has no weight diffs for [subnetID].
+	OldestValidatorDiffHeight(subnetID ids.ID) (uint64, bool, error)
+
+	// VerifyValidatorDiffChain reconstructs the validator sets of [subnetID]
+	// from [startHeight] down to [endHeight] by applying the validator weight
+	// diffs, and verifies that every validator weight, and the total weight,
+	// remains valid. Returns an error reporting the first, i.e. highest,
+	// height at which the diffs are inconsistent.
+	//
+	// [startHeight] must be in [endHeight, current height]. The diffs above
+	// [startHeight] are applied in a single pass to reconstruct the validator
+	// set at [startHeight], so an inconsistency above [startHeight] is still
+	// reported, but the validator sets above [startHeight] aren't verified
+	// individually.
+	VerifyValidatorDiffChain(ctx context.Context, subnetID ids.ID, startHeight, endHeight uint64) error
+
 	SetHeight(height uint64)
 
 	// Discard uncommitted changes to the database.
 	Abort()
 
+	// AbortAndReload discards uncommitted changes like Abort and additionally
+	// discards the uncommitted changes held in memory, reloading the stakers
+	// and metadata from the database. After it returns, the state reflects
+	// the last commit. It is intended to recover from a failed execution that
+	// may have left partial changes in the state.
+	//
+	// Any deferred commits are flushed to the database before reloading.
+	AbortAndReload() error
+
+	// SubscribeAccepted returns a channel that receives each accepted block,
+	// in order, once it was written to disk, and a function that closes the
+	// channel and stops further deliveries to it. If commits are deferred,
+	// the blocks of the deferred commits are delivered when they are flushed.
+	// The channel is buffered; if it is full, the event is dropped rather
+	// than blocking the commit. If the state is closed first, the channel is
+	// closed then.
+	SubscribeAccepted() (<-chan block.Block, func())
+
 	// Returns if the state should be pruned and indexed to remove rejected
 	// blocks and generate the block height index.
 	//
@@ -195,15 +431,29 @@ type State interface {
 	// TODO: Remove after v1.11.x is activated
 	PruneAndIndex(sync.Locker, logging.Logger) error
 
-	// Commit changes to the base database.
+	// Commit changes to the base database. If commits are configured to be
+	// deferred, the changes may remain buffered in memory until a later call
+	// to Commit, CommitBatch, or Close.
 	Commit() error
 
-	// Returns a batch of unwritten changes that, when written, will commit all
-	// pending changes to the base database.
+	// Flushes any deferred commits to the base database and returns a batch
+	// of unwritten changes that, when written, will commit all pending
+	// changes to the base database. The subscribers are notified of the
+	// written batch by the following call to Abort.
 	CommitBatch() (database.Batch, error)
 
 	Checksum() ids.ID
 
+	// SectionSizes returns the estimated number of bytes stored in each
+	// section of the committed state, keyed by section name, and reports them
+	// as metrics. Computing the sizes requires iterating over the entire
+	// database, so the result is reused for a few minutes.
+	SectionSizes() (map[string]int64, error)
+
+	// Checkpoint writes a snapshot of the committed state to [dir], which can
+	// be restored into a database with RestoreCheckpoint.
+	Checkpoint(dir string) error
+
 	Close() error
 }
 
@@ -259,7 +509,7 @@ type stateBlk struct {
  * | '-- height -> blockID
  * |-. blocks
  * | '-- blockID -> block bytes
- * |-. txs
+ * |-. txs (the only location txs are stored)
  * | '-- txID -> tx bytes + tx status
  * |- rewardUTXOs
  * | '-. txID
@@ -278,6 +528,7 @@ type stateBlk struct {
  * |     '-- txID -> nil
  * '-. singletons
  *   |-- initializedKey -> nil
+ *   |-- genesisHashKey -> genesisHash
  *   |-- prunedKey -> nil
  *   |-- timestampKey -> timestamp
  *   |-- currentSupplyKey -> currentSupply
@@ -287,12 +538,48 @@ type stateBlk struct {
 type state struct {
 	validatorState
 
+	// If [concurrentReads] is true, [lock] guards the methods described in
+	// locking.go. Otherwise, [lock] is never acquired.
+	concurrentReads bool
+	lock            sync.RWMutex
+
 	validators validators.Manager
 	ctx        *snow.Context
+	execCfg    *config.ExecutionConfig
 	metrics    metrics.Metrics
 	rewards    reward.Calculator
 
-	baseDB *versiondb.Database
+	// [baseDB] buffers the writes since the last commit and is discarded by
+	// Abort. It is layered on [deferredDB], which buffers the writes of
+	// deferred commits until they are flushed to disk. Abort doesn't discard
+	// [deferredDB] unless a batch including its writes was created.
+	baseDB     *versiondb.Database
+	deferredDB *versiondb.Database
+	// Number of commits whose writes are buffered in [deferredDB] and haven't
+	// been flushed to disk.
+	numDeferredCommits int
+	// Time of the oldest commit that hasn't been flushed to disk.
+	firstDeferredCommitTime time.Time
+	// True if a batch was created by CommitBatch since the last abort. Used
+	// to distinguish releasing a committed batch from a rollback.
+	batchCommitted bool
+	// Time all staged uptime updates were last written.
+	lastUptimeFlushTime time.Time
+
+	// ID of the genesis block, or [ids.Empty] if it hasn't been looked up yet.
+	genesisBlockID ids.ID
+
+	// Result of the last call to SectionSizes and when it was computed.
+	sectionSizes     map[string]int64
+	sectionSizesTime time.Time
+
+	// Channels returned by SubscribeAccepted.
+	acceptedSubscribersLock sync.Mutex
+	acceptedSubscribers     map[chan block.Block]struct{}
+	// ID of the last accepted block on disk that the subscribers were
+	// notified of. The blocks accepted after it are delivered by the next
+	// notification.
+	flushedLastAccepted ids.ID
 
 	currentStakers *baseStakers
 	pendingStakers *baseStakers
@@ -306,6 +593,11 @@ type state struct {
 	addedBlocks map[ids.ID]block.Block            // map of blockID -> Block
 	blockCache  cache.Cacher[ids.ID, block.Block] // cache of blockID -> Block. If the entry is nil, it is not in the database
 	blockDB     database.Database
+	// Compresses blocks before they are written to [blockDB] if block
+	// compression is enabled.
+	blockCompressor compression.Compressor
+	// IDs of blocks that HasBlock found not to be in the database.
+	missingBlocks cache.Cacher[ids.ID, struct{}]
 
 	validatorsDB                 database.Database
 	currentValidatorsDB          database.Database
@@ -336,9 +628,16 @@ type state struct {
 	txCache  cache.Cacher[ids.ID, *txAndStatus] // txID -> {*txs.Tx, Status}. If the entry is nil, it isn't in the database
 	txDB     database.Database
 
-	addedRewardUTXOs map[ids.ID][]*avax.UTXO            // map of txID -> []*UTXO
-	rewardUTXOsCache cache.Cacher[ids.ID, []*avax.UTXO] // txID -> []*UTXO
-	rewardUTXODB     database.Database
+	addedRewardUTXOs    map[ids.ID][]*avax.UTXO // map of txID -> []*UTXO
+	numAddedRewardUTXOs int
+	// txIDs with reward UTXOs that were flushed to [rewardUTXODB] since the
+	// last commit.
+	flushedRewardUTXOTxIDs set.Set[ids.ID]
+	// rewardUTXOsFlushErr is the error of a failed flush of
+	// [addedRewardUTXOs]. It is reported on the next write.
+	rewardUTXOsFlushErr error
+	rewardUTXOsCache    cache.Cacher[ids.ID, []*avax.UTXO] // txID -> []*UTXO
+	rewardUTXODB        database.Database
 
 	modifiedUTXOs map[ids.ID]*avax.UTXO // map of modified UTXOID -> *UTXO if the UTXO is nil, it has been removed
 	utxoDB        database.Database
@@ -351,7 +650,7 @@ type state struct {
 
 	// Subnet ID --> Owner of the subnet
 	subnetOwners     map[ids.ID]fx.Owner
-	subnetOwnerCache cache.Cacher[ids.ID, fxOwnerAndSize] // cache of subnetID -> owner if the entry is nil, it is not in the database
+	subnetOwnerCache cache.Cacher[ids.ID, fxOwnerAndSize] // cache of subnetID -> owner if the entry is nil, it is not in the database or is not a subnet
 	subnetOwnerDB    database.Database
 
 	transformedSubnets     map[ids.ID]*txs.Tx            // map of subnetID -> transformSubnetTx
@@ -373,7 +672,11 @@ type state struct {
 	// [lastAccepted] is the most recently accepted block.
 	lastAccepted, persistedLastAccepted ids.ID
 	indexedHeights                      *heightRange
-	singletonDB                         database.Database
+	// [pendingIndexedHeights] is the indexed range including the validator
+	// diffs imported since the last commit, or nil if no diffs were imported.
+	// It is applied to [indexedHeights] on commit.
+	pendingIndexedHeights *heightRange
+	singletonDB           database.Database
 }
 
 // heightRange is used to track which heights are safe to use the native DB
@@ -425,6 +728,9 @@ type txAndStatus struct {
 type fxOwnerAndSize struct {
 	owner fx.Owner
 	size  int
+	// isNotSubnet is true if the ID was found to belong to a tx other than a
+	// CreateSubnetTx.
+	isNotSubnet bool
 }
 
 func txSize(_ ids.ID, tx *txs.Tx) int {
@@ -494,7 +800,7 @@ func New(
 			return nil, fmt.Errorf("failed to remove prunedKey from singletonDB: %w", err)
 		}
 
-		if err := s.Commit(); err != nil {
+		if err := s.flush(); err != nil {
 			return nil, fmt.Errorf("failed to commit to baseDB: %w", err)
 		}
 	}
@@ -529,7 +835,15 @@ func newState(
 		return nil, err
 	}
 
-	baseDB := versiondb.New(db)
+	// The compressor is created even if compression is disabled so that
+	// previously compressed blocks can be read.
+	blockCompressor, err := compression.NewZstdCompressor(maxCompressedBlockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	deferredDB := versiondb.New(db)
+	baseDB := versiondb.New(deferredDB)
 
 	validatorsDB := prefixdb.New(validatorsPrefix, baseDB)
 
@@ -570,7 +884,7 @@ func newState(
 	}
 
 	utxoDB := prefixdb.New(utxoPrefix, baseDB)
-	utxoState, err := avax.NewMeteredUTXOState(utxoDB, txs.GenesisCodec, metricsReg, execCfg.ChecksumsEnabled)
+	utxoState, err := avax.NewMeteredUTXOState(utxoDB, txs.GenesisCodec, metricsReg, execCfg.UTXOCacheSize, execCfg.ChecksumsEnabled)
 	if err != nil {
 		return nil, err
 	}
@@ -630,20 +944,28 @@ func newState(
 
 		validators: validators,
 		ctx:        ctx,
+		execCfg:    execCfg,
 		metrics:    metrics,
 		rewards:    rewards,
 		baseDB:     baseDB,
+		deferredDB: deferredDB,
+
+		concurrentReads: execCfg.ConcurrentReadsEnabled,
 
 		addedBlockIDs: make(map[uint64]ids.ID),
 		blockIDCache:  blockIDCache,
 		blockIDDB:     prefixdb.New(blockIDPrefix, baseDB),
 
-		addedBlocks: make(map[ids.ID]block.Block),
-		blockCache:  blockCache,
-		blockDB:     prefixdb.New(blockPrefix, baseDB),
+		addedBlocks:     make(map[ids.ID]block.Block),
+		blockCache:      blockCache,
+		blockDB:         prefixdb.New(blockPrefix, baseDB),
+		blockCompressor: blockCompressor,
+		missingBlocks: &cache.LRU[ids.ID, struct{}]{
+			Size: missingBlockCacheSize,
+		},
 
-		currentStakers: newBaseStakers(),
-		pendingStakers: newBaseStakers(),
+		currentStakers: newBaseStakers(execCfg.DelegatorTreeDegree),
+		pendingStakers: newBaseStakers(execCfg.DelegatorTreeDegree),
 
 		validatorsDB:                    validatorsDB,
 		currentValidatorsDB:             currentValidatorsDB,
@@ -706,14 +1028,55 @@ func newState(
 }
 
 func (s *state) GetCurrentValidator(subnetID ids.ID, nodeID ids.NodeID) (*Staker, error) {
+	defer s.readLock()()
+
 	return s.currentStakers.GetValidator(subnetID, nodeID)
 }
 
+func (s *state) IsCurrentValidator(subnetID ids.ID, nodeID ids.NodeID) bool {
+	return s.currentStakers.HasValidator(subnetID, nodeID)
+}
+
+func (s *state) GetSubnetsToTrack(nodeID ids.NodeID) (set.Set[ids.ID], error) {
+	subnetIDs := set.Of(constants.PrimaryNetworkID)
+	for _, stakers := range []*baseStakers{s.currentStakers, s.pendingStakers} {
+		for subnetID := range stakers.validators {
+			if stakers.HasValidator(subnetID, nodeID) {
+				subnetIDs.Add(subnetID)
+			}
+		}
+	}
+	return subnetIDs, nil
+}
+
+func (s *state) GetSubnetsWithPendingStakers() ([]ids.ID, error) {
+	// Subnets are removed from the map once they have no pending stakers.
+	subnetIDs := maps.Keys(s.pendingStakers.validators)
+	utils.Sort(subnetIDs)
+	return subnetIDs, nil
+}
+
+func (s *state) GetValidatorRewardInfo(subnetID ids.ID, nodeID ids.NodeID) (uint64, uint64, error) {
+	staker, err := s.GetCurrentValidator(subnetID, nodeID)
+	if err != nil {
+		return 0, 0, err
+	}
+	delegateeReward, err := s.GetDelegateeReward(subnetID, nodeID)
+	if err != nil {
+		return 0, 0, err
+	}
+	return staker.PotentialReward, delegateeReward, nil
+}
+
 func (s *state) PutCurrentValidator(staker *Staker) {
+	defer s.writeLock()()
+
 	s.currentStakers.PutValidator(staker)
 }
 
 func (s *state) DeleteCurrentValidator(staker *Staker) {
+	defer s.writeLock()()
+
 	s.currentStakers.DeleteValidator(staker)
 }
 
@@ -722,10 +1085,14 @@ func (s *state) GetCurrentDelegatorIterator(subnetID ids.ID, nodeID ids.NodeID)
 }
 
 func (s *state) PutCurrentDelegator(staker *Staker) {
+	defer s.writeLock()()
+
 	s.currentStakers.PutDelegator(staker)
 }
 
 func (s *state) DeleteCurrentDelegator(staker *Staker) {
+	defer s.writeLock()()
+
 	s.currentStakers.DeleteDelegator(staker)
 }
 
@@ -733,6 +1100,103 @@ func (s *state) GetCurrentStakerIterator() (StakerIterator, error) {
 	return s.currentStakers.GetStakerIterator(), nil
 }
 
+func (s *state) GetTotalPotentialReward(subnetID ids.ID) (uint64, error) {
+	it := s.currentStakers.GetStakerIterator()
+	defer it.Release()
+
+	var (
+		totalReward uint64
+		err         error
+	)
+	for it.Next() {
+		staker := it.Value()
+		if staker.SubnetID != subnetID {
+			continue
+		}
+
+		totalReward, err = safemath.Add64(totalReward, staker.PotentialReward)
+		if err != nil {
+			return 0, fmt.Errorf("failed to sum potential rewards of %s: %w", subnetID, err)
+		}
+	}
+	return totalReward, nil
+}
+
+func (s *state) PreviewReward(stakeAmount uint64, stakeDuration time.Duration, subnetID ids.ID) (uint64, error) {
+	if subnetID != constants.PrimaryNetworkID {
+		return 0, fmt.Errorf("%w: %s", errUnsupportedRewardSubnet, subnetID)
+	}
+
+	currentSupply, err := s.GetCurrentSupply(subnetID)
+	if err != nil {
+		return 0, err
+	}
+	return s.rewards.Calculate(stakeDuration, stakeAmount, currentSupply), nil
+}
+
+func (s *state) GetAggregatePublicKey(subnetID ids.ID) (*bls.PublicKey, uint64, uint64, error) {
+	var (
+		primaryValidators = s.currentStakers.validators[constants.PrimaryNetworkID]
+		subnetValidators  = s.currentStakers.validators[subnetID]
+		publicKeys        = make([]*bls.PublicKey, 0, len(subnetValidators))
+		weight            uint64
+		excludedWeight    uint64
+	)
+	for nodeID, vdr := range subnetValidators {
+		if vdr.validator == nil {
+			continue
+		}
+
+		vdrWeight, err := validatorWeight(vdr)
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("failed to calculate the weight of %s: %w", nodeID, err)
+		}
+
+		var publicKey *bls.PublicKey
+		if primaryValidator, ok := primaryValidators[nodeID]; ok && primaryValidator.validator != nil {
+			publicKey = primaryValidator.validator.PublicKey
+		}
+		if publicKey == nil {
+			excludedWeight, err = safemath.Add64(excludedWeight, vdrWeight)
+			if err != nil {
+				return nil, 0, 0, err
+			}
+			continue
+		}
+
+		publicKeys = append(publicKeys, publicKey)
+		weight, err = safemath.Add64(weight, vdrWeight)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+	}
+	if len(publicKeys) == 0 {
+		return nil, 0, excludedWeight, nil
+	}
+
+	aggregatePublicKey, err := bls.AggregatePublicKeys(publicKeys)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return aggregatePublicKey, weight, excludedWeight, nil
+}
+
+// validatorWeight returns the weight of [vdr] including the weight of its
+// delegators.
+func validatorWeight(vdr *baseStaker) (uint64, error) {
+	weight := vdr.validator.Weight
+	if vdr.delegators == nil {
+		return weight, nil
+	}
+
+	var err error
+	vdr.delegators.Ascend(func(delegator *Staker) bool {
+		weight, err = safemath.Add64(weight, delegator.Weight)
+		return err == nil
+	})
+	return weight, err
+}
+
 func (s *state) GetPendingValidator(subnetID ids.ID, nodeID ids.NodeID) (*Staker, error) {
 	return s.pendingStakers.GetValidator(subnetID, nodeID)
 }
@@ -761,6 +1225,14 @@ func (s *state) GetPendingStakerIterator() (StakerIterator, error) {
 	return s.pendingStakers.GetStakerIterator(), nil
 }
 
+func (s *state) GetNextPendingStaker() (*Staker, error) {
+	staker, ok := s.pendingStakers.GetFirstStaker()
+	if !ok {
+		return nil, ErrNoPendingStakers
+	}
+	return staker, nil
+}
+
 func (s *state) shouldInit() (bool, error) {
 	has, err := s.singletonDB.Has(initializedKey)
 	return !has, err
@@ -806,23 +1278,20 @@ func (s *state) GetSubnets() ([]*txs.Tx, error) {
 		return s.cachedSubnets, nil
 	}
 
-	subnetDBIt := s.subnetDB.NewIterator()
-	defer subnetDBIt.Release()
-
 	txs := []*txs.Tx(nil)
-	for subnetDBIt.Next() {
-		subnetIDBytes := subnetDBIt.Key()
+	err := withIterator(s.subnetDB.NewIterator(), func(subnetIDBytes, _ []byte) error {
 		subnetID, err := ids.ToID(subnetIDBytes)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		subnetTx, _, err := s.GetTx(subnetID)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		txs = append(txs, subnetTx)
-	}
-	if err := subnetDBIt.Error(); err != nil {
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 	txs = append(txs, s.addedSubnets...)
@@ -830,6 +1299,25 @@ func (s *state) GetSubnets() ([]*txs.Tx, error) {
 	return txs, nil
 }
 
+func (s *state) GetSubnet(subnetID ids.ID) (*txs.Tx, error) {
+	for _, subnetTx := range s.addedSubnets {
+		if subnetTx.ID() == subnetID {
+			return subnetTx, nil
+		}
+	}
+
+	has, err := s.subnetDB.Has(subnetID[:])
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, database.ErrNotFound
+	}
+
+	subnetTx, _, err := s.GetTx(subnetID)
+	return subnetTx, err
+}
+
 func (s *state) AddSubnet(createSubnetTx *txs.Tx) {
 	s.addedSubnets = append(s.addedSubnets, createSubnetTx)
 	if s.cachedSubnets != nil {
@@ -843,6 +1331,9 @@ func (s *state) GetSubnetOwner(subnetID ids.ID) (fx.Owner, error) {
 	}
 
 	if ownerAndSize, cached := s.subnetOwnerCache.Get(subnetID); cached {
+		if ownerAndSize.isNotSubnet {
+			return nil, fmt.Errorf("%q %w", subnetID, errIsNotSubnet)
+		}
 		if ownerAndSize.owner == nil {
 			return nil, database.ErrNotFound
 		}
@@ -875,6 +1366,9 @@ func (s *state) GetSubnetOwner(subnetID ids.ID) (fx.Owner, error) {
 
 	subnet, ok := subnetIntf.Unsigned.(*txs.CreateSubnetTx)
 	if !ok {
+		s.subnetOwnerCache.Put(subnetID, fxOwnerAndSize{
+			isNotSubnet: true,
+		})
 		return nil, fmt.Errorf("%q %w", subnetID, errIsNotSubnet)
 	}
 
@@ -915,50 +1409,111 @@ func (s *state) GetSubnetTransformation(subnetID ids.ID) (*txs.Tx, error) {
 	return transformSubnetTx, nil
 }
 
-func (s *state) AddSubnetTransformation(transformSubnetTxIntf *txs.Tx) {
+func (s *state) AddSubnetTransformation(transformSubnetTxIntf *txs.Tx) error {
 	transformSubnetTx := transformSubnetTxIntf.Unsigned.(*txs.TransformSubnetTx)
+	if err := verifyNotTransformed(s, transformSubnetTx.Subnet); err != nil {
+		return err
+	}
 	s.transformedSubnets[transformSubnetTx.Subnet] = transformSubnetTxIntf
+	return nil
+}
+
+// verifyNotTransformed returns ErrSubnetAlreadyTransformed if [subnetID] has
+// been transformed in [chain].
+func verifyNotTransformed(chain Chain, subnetID ids.ID) error {
+	_, err := chain.GetSubnetTransformation(subnetID)
+	switch err {
+	case nil:
+		return fmt.Errorf("%w: %s", ErrSubnetAlreadyTransformed, subnetID)
+	case database.ErrNotFound:
+		return nil
+	default:
+		return err
+	}
 }
 
 func (s *state) GetChains(subnetID ids.ID) ([]*txs.Tx, error) {
+	// The cached slice is copied so that callers can't observe or cause
+	// modifications of the cache.
 	if chains, cached := s.chainCache.Get(subnetID); cached {
-		return chains, nil
+		return slices.Clone(chains), nil
 	}
 	chainDB := s.getChainDB(subnetID)
-	chainDBIt := chainDB.NewIterator()
-	defer chainDBIt.Release()
 
 	txs := []*txs.Tx(nil)
-	for chainDBIt.Next() {
-		chainIDBytes := chainDBIt.Key()
+	err := withIterator(chainDB.NewIterator(), func(chainIDBytes, _ []byte) error {
 		chainID, err := ids.ToID(chainIDBytes)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		chainTx, _, err := s.GetTx(chainID)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		txs = append(txs, chainTx)
-	}
-	if err := chainDBIt.Error(); err != nil {
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 	txs = append(txs, s.addedChains[subnetID]...)
-	s.chainCache.Put(subnetID, txs)
+	// Sort the chains so that the returned order doesn't depend on whether the
+	// chains were persisted or are still pending.
+	sortTxsByID(txs)
+	s.chainCache.Put(subnetID, slices.Clone(txs))
 	return txs, nil
 }
 
+func (s *state) GetAllChains() (map[ids.ID][]*txs.Tx, error) {
+	subnets, err := s.GetSubnets()
+	if err != nil {
+		return nil, err
+	}
+
+	// The chains are stored under prefixes derived from the subnet ID, so the
+	// chains are looked up per subnet.
+	subnetIDs := set.NewSet[ids.ID](len(subnets) + 1)
+	subnetIDs.Add(constants.PrimaryNetworkID)
+	for _, subnet := range subnets {
+		subnetIDs.Add(subnet.ID())
+	}
+	for subnetID := range s.addedChains {
+		subnetIDs.Add(subnetID)
+	}
+
+	chains := make(map[ids.ID][]*txs.Tx)
+	for subnetID := range subnetIDs {
+		subnetChains, err := s.GetChains(subnetID)
+		if err != nil {
+			return nil, err
+		}
+		if len(subnetChains) > 0 {
+			chains[subnetID] = subnetChains
+		}
+	}
+	return chains, nil
+}
+
 func (s *state) AddChain(createChainTxIntf *txs.Tx) {
 	createChainTx := createChainTxIntf.Unsigned.(*txs.CreateChainTx)
 	subnetID := createChainTx.SubnetID
 	s.addedChains[subnetID] = append(s.addedChains[subnetID], createChainTxIntf)
 	if chains, cached := s.chainCache.Get(subnetID); cached {
-		chains = append(chains, createChainTxIntf)
+		// The cached slice may share its backing array with a slice that was
+		// returned by GetChains, so it must not be modified in place.
+		chains = append(slices.Clone(chains), createChainTxIntf)
+		sortTxsByID(chains)
 		s.chainCache.Put(subnetID, chains)
 	}
 }
 
+// sortTxsByID sorts [txList] in ascending order of tx ID.
+func sortTxsByID(txList []*txs.Tx) {
+	slices.SortFunc(txList, func(a, b *txs.Tx) bool {
+		return a.ID().Less(b.ID())
+	})
+}
+
 func (s *state) getChainDB(subnetID ids.ID) linkeddb.LinkedDB {
 	if chainDB, cached := s.chainDBCache.Get(subnetID); cached {
 		return chainDB
@@ -969,7 +1524,17 @@ func (s *state) getChainDB(subnetID ids.ID) linkeddb.LinkedDB {
 	return chainDB
 }
 
+// GetTx returns the tx with [txID] along with its status.
+//
+// Every tx, regardless of type, is written to [s.txDB] by AddTx, so there is no
+// other location to fall back to.
 func (s *state) GetTx(txID ids.ID) (*txs.Tx, status.Status, error) {
+	defer s.readLock()()
+
+	return s.getTx(txID)
+}
+
+func (s *state) getTx(txID ids.ID) (*txs.Tx, status.Status, error) {
 	if tx, exists := s.addedTxs[txID]; exists {
 		return tx.tx, tx.status, nil
 	}
@@ -1006,16 +1571,48 @@ func (s *state) GetTx(txID ids.ID) (*txs.Tx, status.Status, error) {
 	return ptx.tx, ptx.status, nil
 }
 
+func (s *state) GetStakerTxBytes(txID ids.ID) ([]byte, error) {
+	if tx, exists := s.addedTxs[txID]; exists {
+		return tx.tx.Bytes(), nil
+	}
+	if tx, cached := s.txCache.Get(txID); cached {
+		if tx == nil {
+			return nil, database.ErrNotFound
+		}
+		return tx.tx.Bytes(), nil
+	}
+	txBytes, err := s.txDB.Get(txID[:])
+	if err != nil {
+		return nil, err
+	}
+
+	stx := txBytesAndStatus{}
+	if _, err := txs.GenesisCodec.Unmarshal(txBytes, &stx); err != nil {
+		return nil, err
+	}
+	return stx.Tx, nil
+}
+
 func (s *state) AddTx(tx *txs.Tx, status status.Status) {
-	s.addedTxs[tx.ID()] = &txAndStatus{
+	defer s.writeLock()()
+
+	txID := tx.ID()
+	s.addedTxs[txID] = &txAndStatus{
 		tx:     tx,
 		status: status,
 	}
+
+	// GetSubnetOwner may have cached that this subnet doesn't exist.
+	if _, ok := tx.Unsigned.(*txs.CreateSubnetTx); ok {
+		s.subnetOwnerCache.Evict(txID)
+	}
 }
 
 func (s *state) GetRewardUTXOs(txID ids.ID) ([]*avax.UTXO, error) {
-	if utxos, exists := s.addedRewardUTXOs[txID]; exists {
-		return utxos, nil
+	addedUTXOs, added := s.addedRewardUTXOs[txID]
+	flushed := s.flushedRewardUTXOTxIDs.Contains(txID)
+	if added && !flushed {
+		return addedUTXOs, nil
 	}
 	if utxos, exists := s.rewardUTXOsCache.Get(txID); exists {
 		return utxos, nil
@@ -1023,30 +1620,82 @@ func (s *state) GetRewardUTXOs(txID ids.ID) ([]*avax.UTXO, error) {
 
 	rawTxDB := prefixdb.New(txID[:], s.rewardUTXODB)
 	txDB := linkeddb.NewDefault(rawTxDB)
-	it := txDB.NewIterator()
-	defer it.Release()
 
 	utxos := []*avax.UTXO(nil)
-	for it.Next() {
+	err := withIterator(txDB.NewIterator(), func(_, utxoBytes []byte) error {
 		utxo := &avax.UTXO{}
-		if _, err := txs.Codec.Unmarshal(it.Value(), utxo); err != nil {
-			return nil, err
+		if _, err := txs.Codec.Unmarshal(utxoBytes, utxo); err != nil {
+			return err
 		}
 		utxos = append(utxos, utxo)
-	}
-	if err := it.Error(); err != nil {
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	if flushed {
+		// The flushed reward UTXOs haven't been committed yet, so they aren't
+		// cached.
+		return append(utxos, addedUTXOs...), nil
+	}
 	s.rewardUTXOsCache.Put(txID, utxos)
 	return utxos, nil
 }
 
+func (s *state) PruneRewardUTXOs(txIDs []ids.ID) error {
+	for _, txID := range txIDs {
+		s.numAddedRewardUTXOs -= len(s.addedRewardUTXOs[txID])
+		delete(s.addedRewardUTXOs, txID)
+		s.rewardUTXOsCache.Evict(txID)
+
+		rawTxDB := prefixdb.New(txID[:], s.rewardUTXODB)
+		txDB := linkeddb.NewDefault(rawTxDB)
+		for {
+			utxoID, err := txDB.HeadKey()
+			if err == database.ErrNotFound {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read reward UTXO of %s: %w", txID, err)
+			}
+			if err := txDB.Delete(utxoID); err != nil {
+				return fmt.Errorf("failed to prune reward UTXO of %s: %w", txID, err)
+			}
+		}
+	}
+	return nil
+}
+
 func (s *state) AddRewardUTXO(txID ids.ID, utxo *avax.UTXO) {
 	s.addedRewardUTXOs[txID] = append(s.addedRewardUTXOs[txID], utxo)
+	s.numAddedRewardUTXOs++
+	if s.numAddedRewardUTXOs >= maxPendingRewardUTXOs && s.rewardUTXOsFlushErr == nil {
+		s.rewardUTXOsFlushErr = s.flushRewardUTXOs()
+	}
+}
+
+// flushRewardUTXOs writes [addedRewardUTXOs] to [rewardUTXODB] before they are
+// committed to bound the number of reward UTXOs held in memory. The flushed
+// reward UTXOs are discarded by Abort.
+func (s *state) flushRewardUTXOs() error {
+	for txID, utxos := range s.addedRewardUTXOs {
+		delete(s.addedRewardUTXOs, txID)
+		// The cached reward UTXOs of [txID] wouldn't include the flushed
+		// reward UTXOs.
+		s.rewardUTXOsCache.Evict(txID)
+		s.flushedRewardUTXOTxIDs.Add(txID)
+		if err := s.putRewardUTXOs(txID, utxos); err != nil {
+			return err
+		}
+	}
+	s.numAddedRewardUTXOs = 0
+	return nil
 }
 
 func (s *state) GetUTXO(utxoID ids.ID) (*avax.UTXO, error) {
+	defer s.readLock()()
+
 	if utxo, exists := s.modifiedUTXOs[utxoID]; exists {
 		if utxo == nil {
 			return nil, database.ErrNotFound
@@ -1056,43 +1705,135 @@ func (s *state) GetUTXO(utxoID ids.ID) (*avax.UTXO, error) {
 	return s.utxoState.GetUTXO(utxoID)
 }
 
+func (s *state) GetUTXOWithSource(utxoID ids.ID) (*avax.UTXO, UTXOSource, error) {
+	if utxo, exists := s.modifiedUTXOs[utxoID]; exists {
+		if utxo == nil {
+			return nil, UTXOSourcePending, database.ErrNotFound
+		}
+		return utxo, UTXOSourcePending, nil
+	}
+
+	utxo, cached, err := s.utxoState.GetUTXOWithCacheHit(utxoID)
+	if cached {
+		return utxo, UTXOSourceCache, err
+	}
+	return utxo, UTXOSourceDatabase, err
+}
+
 func (s *state) UTXOIDs(addr []byte, start ids.ID, limit int) ([]ids.ID, error) {
+	defer s.readLock()()
+
+	// The address index is owned by [s.utxoState], which creates and releases
+	// the iterator itself. The iteration also stops after [limit] IDs, which
+	// withIterator doesn't support.
 	return s.utxoState.UTXOIDs(addr, start, limit)
 }
 
 func (s *state) AddUTXO(utxo *avax.UTXO) {
+	defer s.writeLock()()
+
 	s.modifiedUTXOs[utxo.InputID()] = utxo
 }
 
-func (s *state) DeleteUTXO(utxoID ids.ID) {
-	s.modifiedUTXOs[utxoID] = nil
-}
+func (s *state) AddUTXOs(utxos []*avax.UTXO) {
+	defer s.writeLock()()
 
-func (s *state) GetStartTime(nodeID ids.NodeID, subnetID ids.ID) (time.Time, error) {
-	staker, err := s.currentStakers.GetValidator(subnetID, nodeID)
-	if err != nil {
-		return time.Time{}, err
+	if len(s.modifiedUTXOs) == 0 {
+		s.modifiedUTXOs = make(map[ids.ID]*avax.UTXO, len(utxos))
+	}
+	for _, utxo := range utxos {
+		s.modifiedUTXOs[utxo.InputID()] = utxo
 	}
-	return staker.StartTime, nil
 }
 
-func (s *state) GetTimestamp() time.Time {
-	return s.timestamp
+func (s *state) DeleteUTXO(utxoID ids.ID) {
+	defer s.writeLock()()
+
+	s.modifiedUTXOs[utxoID] = nil
+}
+
+func (s *state) DeleteUTXOsForAddress(addr []byte) (int, error) {
+	defer s.writeLock()()
+
+	deleted := set.Set[ids.ID]{}
+	start := ids.Empty
+	for {
+		utxoIDs, err := s.utxoState.UTXOIDs(addr, start, utxoIDsPageSize)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read UTXOs of address: %w", err)
+		}
+		for _, utxoID := range utxoIDs {
+			// UTXOs that are already pending deletion aren't counted.
+			if utxo, exists := s.modifiedUTXOs[utxoID]; exists && utxo == nil {
+				continue
+			}
+			deleted.Add(utxoID)
+		}
+		if len(utxoIDs) < utxoIDsPageSize {
+			break
+		}
+		start = utxoIDs[len(utxoIDs)-1]
+	}
+
+	// The address index only includes committed UTXOs, so the uncommitted
+	// UTXOs must be checked separately.
+	for utxoID, utxo := range s.modifiedUTXOs {
+		if utxo == nil {
+			continue
+		}
+		addressable, ok := utxo.Out.(avax.Addressable)
+		if !ok {
+			continue
+		}
+		for _, owner := range addressable.Addresses() {
+			if bytes.Equal(owner, addr) {
+				deleted.Add(utxoID)
+				break
+			}
+		}
+	}
+
+	for utxoID := range deleted {
+		s.modifiedUTXOs[utxoID] = nil
+	}
+	return deleted.Len(), nil
+}
+
+func (s *state) GetStartTime(nodeID ids.NodeID, subnetID ids.ID) (time.Time, error) {
+	staker, err := s.currentStakers.GetValidator(subnetID, nodeID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return staker.StartTime, nil
+}
+
+func (s *state) GetTimestamp() time.Time {
+	defer s.readLock()()
+
+	return s.timestamp
 }
 
 func (s *state) SetTimestamp(tm time.Time) {
+	defer s.writeLock()()
+
 	s.timestamp = tm
 }
 
 func (s *state) GetLastAccepted() ids.ID {
+	defer s.readLock()()
+
 	return s.lastAccepted
 }
 
 func (s *state) SetLastAccepted(lastAccepted ids.ID) {
+	defer s.writeLock()()
+
 	s.lastAccepted = lastAccepted
 }
 
 func (s *state) GetCurrentSupply(subnetID ids.ID) (uint64, error) {
+	defer s.readLock()()
+
 	if subnetID == constants.PrimaryNetworkID {
 		return s.currentSupply, nil
 	}
@@ -1101,7 +1842,12 @@ func (s *state) GetCurrentSupply(subnetID ids.ID) (uint64, error) {
 	if ok {
 		return supply, nil
 	}
+	return s.getPersistedSupply(subnetID)
+}
 
+// getPersistedSupply returns the supply of [subnetID] as of the last commit.
+// Returns [database.ErrNotFound] if the subnet has no persisted supply.
+func (s *state) getPersistedSupply(subnetID ids.ID) (uint64, error) {
 	cachedSupply, ok := s.supplyCache.Get(subnetID)
 	if ok {
 		if cachedSupply == nil {
@@ -1124,6 +1870,8 @@ func (s *state) GetCurrentSupply(subnetID ids.ID) (uint64, error) {
 }
 
 func (s *state) SetCurrentSupply(subnetID ids.ID, cs uint64) {
+	defer s.writeLock()()
+
 	if subnetID == constants.PrimaryNetworkID {
 		s.currentSupply = cs
 	} else {
@@ -1131,6 +1879,49 @@ func (s *state) SetCurrentSupply(subnetID ids.ID, cs uint64) {
 	}
 }
 
+func (s *state) GetPendingSupplyDelta(subnetID ids.ID) (int64, bool, error) {
+	if subnetID == constants.PrimaryNetworkID {
+		if s.currentSupply == s.persistedCurrentSupply {
+			return 0, false, nil
+		}
+		delta, err := supplyDelta(s.persistedCurrentSupply, s.currentSupply)
+		return delta, err == nil, err
+	}
+
+	pendingSupply, ok := s.modifiedSupplies[subnetID]
+	if !ok {
+		return 0, false, nil
+	}
+
+	// A subnet without a persisted supply is treated as having no supply.
+	persistedSupply, err := s.getPersistedSupply(subnetID)
+	if err != nil && err != database.ErrNotFound {
+		return 0, false, err
+	}
+	delta, err := supplyDelta(persistedSupply, pendingSupply)
+	return delta, err == nil, err
+}
+
+// supplyDelta returns the signed difference from [from] to [to], or an error
+// if the difference doesn't fit in an int64.
+func supplyDelta(from, to uint64) (int64, error) {
+	if to >= from {
+		diff := to - from
+		if diff > math.MaxInt64 {
+			return 0, fmt.Errorf("%w: supply increased by %d", safemath.ErrOverflow, diff)
+		}
+		return int64(diff), nil
+	}
+
+	diff := from - to
+	if diff > -math.MinInt64 {
+		return 0, fmt.Errorf("%w: supply decreased by %d", safemath.ErrUnderflow, diff)
+	}
+	// A decrease of exactly -[math.MinInt64] wraps to [math.MinInt64], which
+	// is the correct result.
+	return -int64(diff), nil
+}
+
 func (s *state) ApplyValidatorWeightDiffs(
 	ctx context.Context,
 	validators map[ids.NodeID]*validators.GetValidatorOutput,
@@ -1170,7 +1961,7 @@ func (s *state) ApplyValidatorWeightDiffs(
 		}
 
 		if err := applyWeightDiff(validators, nodeID, weightDiff); err != nil {
-			return err
+			return fmt.Errorf("failed to apply weight diff of %s at height %d: %w", nodeID, parsedHeight, err)
 		}
 	}
 	if err := diffIter.Error(); err != nil {
@@ -1184,41 +1975,151 @@ func (s *state) ApplyValidatorWeightDiffs(
 			return err
 		}
 
-		prefixStruct := heightWithSubnet{
-			Height:   height,
-			SubnetID: subnetID,
+		err := s.applyNestedValidatorWeightDiffs(validators, height, subnetID, applyWeightDiff)
+		if err != nil {
+			return err
 		}
-		prefixBytes, err := block.GenesisCodec.Marshal(block.Version, prefixStruct)
+	}
+
+	return nil
+}
+
+func (s *state) ApplyValidatorWeightDiffsForward(
+	ctx context.Context,
+	validators map[ids.NodeID]*validators.GetValidatorOutput,
+	startHeight uint64,
+	endHeight uint64,
+	subnetID ids.ID,
+) error {
+	if startHeight >= endHeight {
+		return nil
+	}
+
+	// Heights below [firstFlatHeight] are only recorded in the legacy nested
+	// diff index.
+	firstFlatHeight := endHeight + 1
+	if s.indexedHeights != nil && s.indexedHeights.LowerBound <= endHeight {
+		firstFlatHeight = safemath.Max(startHeight+1, s.indexedHeights.LowerBound)
+	}
+
+	// TODO: Remove this once it is assumed that all subnet validators have
+	// adopted the new indexing.
+	for height := startHeight + 1; height < firstFlatHeight; height++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err := s.applyNestedValidatorWeightDiffs(validators, height, subnetID, applyWeightDiffForward)
 		if err != nil {
 			return err
 		}
+	}
+	if firstFlatHeight > endHeight {
+		return nil
+	}
 
-		rawDiffDB := prefixdb.New(prefixBytes, s.nestedValidatorWeightDiffsDB)
-		diffDB := linkeddb.NewDefault(rawDiffDB)
-		diffIter := diffDB.NewIterator()
-		defer diffIter.Release()
+	// The flat diff index is ordered by descending height, so the diffs are
+	// collected before being applied in ascending height order.
+	type heightDiff struct {
+		height     uint64
+		nodeID     ids.NodeID
+		weightDiff *ValidatorWeightDiff
+	}
+	var diffs []heightDiff
 
-		for diffIter.Next() {
-			nodeID, err := ids.ToNodeID(diffIter.Key())
-			if err != nil {
-				return err
-			}
+	diffIter := s.flatValidatorWeightDiffsDB.NewIteratorWithStartAndPrefix(
+		marshalStartDiffKey(subnetID, endHeight),
+		subnetID[:],
+	)
+	defer diffIter.Release()
 
-			weightDiff := ValidatorWeightDiff{}
-			_, err = block.GenesisCodec.Unmarshal(diffIter.Value(), &weightDiff)
-			if err != nil {
-				return err
-			}
+	for diffIter.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 
-			if err := applyWeightDiff(validators, nodeID, &weightDiff); err != nil {
-				return err
-			}
+		_, parsedHeight, nodeID, err := unmarshalDiffKey(diffIter.Key())
+		if err != nil {
+			return err
 		}
+		if parsedHeight < firstFlatHeight {
+			break
+		}
+
+		weightDiff, err := unmarshalWeightDiff(diffIter.Value())
+		if err != nil {
+			return err
+		}
+		diffs = append(diffs, heightDiff{
+			height:     parsedHeight,
+			nodeID:     nodeID,
+			weightDiff: weightDiff,
+		})
+	}
+	if err := diffIter.Error(); err != nil {
+		return err
 	}
 
+	for i := len(diffs) - 1; i >= 0; i-- {
+		diff := diffs[i]
+		if err := applyWeightDiffForward(validators, diff.nodeID, diff.weightDiff); err != nil {
+			return fmt.Errorf("failed to apply weight diff of %s at height %d: %w", diff.nodeID, diff.height, err)
+		}
+	}
 	return nil
 }
 
+// applyNestedValidatorWeightDiffs applies the weight diffs of [subnetID] at
+// [height] that are stored in the legacy nested diff index using [apply].
+func (s *state) applyNestedValidatorWeightDiffs(
+	validators map[ids.NodeID]*validators.GetValidatorOutput,
+	height uint64,
+	subnetID ids.ID,
+	apply func(map[ids.NodeID]*validators.GetValidatorOutput, ids.NodeID, *ValidatorWeightDiff) error,
+) error {
+	prefixStruct := heightWithSubnet{
+		Height:   height,
+		SubnetID: subnetID,
+	}
+	prefixBytes, err := block.GenesisCodec.Marshal(block.Version, prefixStruct)
+	if err != nil {
+		return err
+	}
+
+	rawDiffDB := prefixdb.New(prefixBytes, s.nestedValidatorWeightDiffsDB)
+	diffDB := linkeddb.NewDefault(rawDiffDB)
+	return withIterator(diffDB.NewIterator(), func(nodeIDBytes, weightDiffBytes []byte) error {
+		nodeID, err := ids.ToNodeID(nodeIDBytes)
+		if err != nil {
+			return err
+		}
+
+		weightDiff := ValidatorWeightDiff{}
+		_, err = block.GenesisCodec.Unmarshal(weightDiffBytes, &weightDiff)
+		if err != nil {
+			return err
+		}
+
+		if err := apply(validators, nodeID, &weightDiff); err != nil {
+			return fmt.Errorf("failed to apply weight diff of %s at height %d: %w", nodeID, height, err)
+		}
+		return nil
+	})
+}
+
+// applyWeightDiffForward applies [weightDiff], which was recorded at some
+// block, to a validator set from before that block.
+func applyWeightDiffForward(
+	vdrs map[ids.NodeID]*validators.GetValidatorOutput,
+	nodeID ids.NodeID,
+	weightDiff *ValidatorWeightDiff,
+) error {
+	return applyWeightDiff(vdrs, nodeID, &ValidatorWeightDiff{
+		Decrease: !weightDiff.Decrease,
+		Amount:   weightDiff.Amount,
+	})
+}
+
 func applyWeightDiff(
 	vdrs map[ids.NodeID]*validators.GetValidatorOutput,
 	nodeID ids.NodeID,
@@ -1305,18 +2206,68 @@ func (s *state) ApplyValidatorPublicKeyDiffs(
 	return diffIter.Error()
 }
 
+func (s *state) OldestValidatorDiffHeight(subnetID ids.ID) (uint64, bool, error) {
+	// Heights are stored bit flipped, so diffs are iterated in order of
+	// decreasing height. Rather than iterating over every diff of the subnet,
+	// the oldest height is found by binary searching over the heights that
+	// iteration can start from.
+	oldestHeight, ok, err := s.validatorWeightDiffHeightAtOrBelow(subnetID, math.MaxUint64)
+	if err != nil || !ok {
+		return 0, false, err
+	}
+
+	// Invariant: [oldestHeight] has a diff and there are no diffs below
+	// [lowHeight].
+	lowHeight := uint64(0)
+	for lowHeight < oldestHeight {
+		midHeight := lowHeight + (oldestHeight-lowHeight)/2
+		diffHeight, ok, err := s.validatorWeightDiffHeightAtOrBelow(subnetID, midHeight)
+		if err != nil {
+			return 0, false, err
+		}
+		if ok {
+			oldestHeight = diffHeight
+		} else {
+			lowHeight = midHeight + 1
+		}
+	}
+	return oldestHeight, true, nil
+}
+
+// validatorWeightDiffHeightAtOrBelow returns the highest height that is less
+// than or equal to [height] with a validator weight diff of [subnetID]. Returns
+// false if there is no such height.
+func (s *state) validatorWeightDiffHeightAtOrBelow(subnetID ids.ID, height uint64) (uint64, bool, error) {
+	diffIter := s.flatValidatorWeightDiffsDB.NewIteratorWithStartAndPrefix(
+		marshalStartDiffKey(subnetID, height),
+		subnetID[:],
+	)
+	defer diffIter.Release()
+
+	if !diffIter.Next() {
+		return 0, false, diffIter.Error()
+	}
+	_, diffHeight, _, err := unmarshalDiffKey(diffIter.Key())
+	if err != nil {
+		return 0, false, err
+	}
+	return diffHeight, true, nil
+}
+
 func (s *state) syncGenesis(genesisBlk block.Block, genesis *genesis.Genesis) error {
 	genesisBlkID := genesisBlk.ID()
 	s.SetLastAccepted(genesisBlkID)
 	s.SetTimestamp(time.Unix(int64(genesis.Timestamp), 0))
 	s.SetCurrentSupply(constants.PrimaryNetworkID, genesis.InitialSupply)
-	s.AddStatelessBlock(genesisBlk)
+	s.addStatelessBlock(genesisBlk)
 
 	// Persist UTXOs that exist at genesis
-	for _, utxo := range genesis.UTXOs {
+	utxos := make([]*avax.UTXO, len(genesis.UTXOs))
+	for i, utxo := range genesis.UTXOs {
 		avaxUTXO := utxo.UTXO
-		s.AddUTXO(&avaxUTXO)
+		utxos[i] = &avaxUTXO
 	}
+	s.AddUTXOs(utxos)
 
 	// Persist primary network validator set at genesis
 	for _, vdrTx := range genesis.Validators {
@@ -1380,6 +2331,7 @@ func (s *state) load() error {
 		s.loadMetadata(),
 		s.loadCurrentValidators(),
 		s.loadPendingValidators(),
+		s.verifyStakers(),
 		s.initValidatorSets(),
 	)
 }
@@ -1390,20 +2342,21 @@ func (s *state) loadMetadata() error {
 		return err
 	}
 	s.persistedTimestamp = timestamp
-	s.SetTimestamp(timestamp)
+	s.timestamp = timestamp
 
 	currentSupply, err := database.GetUInt64(s.singletonDB, currentSupplyKey)
 	if err != nil {
 		return err
 	}
 	s.persistedCurrentSupply = currentSupply
-	s.SetCurrentSupply(constants.PrimaryNetworkID, currentSupply)
+	s.currentSupply = currentSupply
 
 	lastAccepted, err := database.GetID(s.singletonDB, lastAcceptedKey)
 	if err != nil {
 		return err
 	}
 	s.persistedLastAccepted = lastAccepted
+	s.flushedLastAccepted = lastAccepted
 	s.lastAccepted = lastAccepted
 
 	// Lookup the most recently indexed range on disk. If we haven't started
@@ -1424,7 +2377,7 @@ func (s *state) loadMetadata() error {
 
 	// If the indexed range is not up to date, then we will act as if the range
 	// doesn't exist.
-	lastAcceptedBlock, err := s.GetStatelessBlock(lastAccepted)
+	lastAcceptedBlock, err := s.getStatelessBlock(lastAccepted)
 	if err != nil {
 		return err
 	}
@@ -1436,22 +2389,18 @@ func (s *state) loadMetadata() error {
 }
 
 func (s *state) loadCurrentValidators() error {
-	s.currentStakers = newBaseStakers()
+	s.currentStakers = newBaseStakers(s.execCfg.DelegatorTreeDegree)
 
-	validatorIt := s.currentValidatorList.NewIterator()
-	defer validatorIt.Release()
-	for validatorIt.Next() {
-		txIDBytes := validatorIt.Key()
+	err := withIterator(s.currentValidatorList.NewIterator(), func(txIDBytes, metadataBytes []byte) error {
 		txID, err := ids.ToID(txIDBytes)
 		if err != nil {
 			return err
 		}
-		tx, _, err := s.GetTx(txID)
+		tx, _, err := s.getTx(txID)
 		if err != nil {
 			return err
 		}
 
-		metadataBytes := validatorIt.Value()
 		metadata := &validatorMetadata{
 			txID: txID,
 			// Note: we don't provide [LastUpdated] here because we expect it to
@@ -1471,23 +2420,21 @@ func (s *state) loadCurrentValidators() error {
 			return err
 		}
 
-		validator := s.currentStakers.getOrCreateValidator(staker.SubnetID, staker.NodeID)
-		validator.validator = staker
-
-		s.currentStakers.stakers.ReplaceOrInsert(staker)
+		s.currentStakers.LoadValidator(staker)
 
 		s.validatorState.LoadValidatorMetadata(staker.NodeID, staker.SubnetID, metadata)
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	subnetValidatorIt := s.currentSubnetValidatorList.NewIterator()
-	defer subnetValidatorIt.Release()
-	for subnetValidatorIt.Next() {
-		txIDBytes := subnetValidatorIt.Key()
+	err = withIterator(s.currentSubnetValidatorList.NewIterator(), func(txIDBytes, metadataBytes []byte) error {
 		txID, err := ids.ToID(txIDBytes)
 		if err != nil {
 			return err
 		}
-		tx, _, err := s.GetTx(txID)
+		tx, _, err := s.getTx(txID)
 		if err != nil {
 			return err
 		}
@@ -1497,7 +2444,6 @@ func (s *state) loadCurrentValidators() error {
 			return fmt.Errorf("expected tx type txs.Staker but got %T", tx.Unsigned)
 		}
 
-		metadataBytes := subnetValidatorIt.Value()
 		metadata := &validatorMetadata{
 			txID: txID,
 			// use the start time as the fallback value
@@ -1512,28 +2458,22 @@ func (s *state) loadCurrentValidators() error {
 		if err != nil {
 			return err
 		}
-		validator := s.currentStakers.getOrCreateValidator(staker.SubnetID, staker.NodeID)
-		validator.validator = staker
-
-		s.currentStakers.stakers.ReplaceOrInsert(staker)
+		s.currentStakers.LoadValidator(staker)
 
 		s.validatorState.LoadValidatorMetadata(staker.NodeID, staker.SubnetID, metadata)
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	delegatorIt := s.currentDelegatorList.NewIterator()
-	defer delegatorIt.Release()
-
-	subnetDelegatorIt := s.currentSubnetDelegatorList.NewIterator()
-	defer subnetDelegatorIt.Release()
-
-	for _, delegatorIt := range []database.Iterator{delegatorIt, subnetDelegatorIt} {
-		for delegatorIt.Next() {
-			txIDBytes := delegatorIt.Key()
+	for _, delegatorList := range []linkeddb.LinkedDB{s.currentDelegatorList, s.currentSubnetDelegatorList} {
+		err := withIterator(delegatorList.NewIterator(), func(txIDBytes, metadataBytes []byte) error {
 			txID, err := ids.ToID(txIDBytes)
 			if err != nil {
 				return err
 			}
-			tx, _, err := s.GetTx(txID)
+			tx, _, err := s.getTx(txID)
 			if err != nil {
 				return err
 			}
@@ -1541,7 +2481,7 @@ func (s *state) loadCurrentValidators() error {
 			metadata := &delegatorMetadata{
 				txID: txID,
 			}
-			err = parseDelegatorMetadata(delegatorIt.Value(), metadata)
+			err = parseDelegatorMetadata(metadataBytes, metadata)
 			if err != nil {
 				return err
 			}
@@ -1556,106 +2496,94 @@ func (s *state) loadCurrentValidators() error {
 				return err
 			}
 
-			validator := s.currentStakers.getOrCreateValidator(staker.SubnetID, staker.NodeID)
-			if validator.delegators == nil {
-				validator.delegators = btree.NewG(defaultTreeDegree, (*Staker).Less)
-			}
-			validator.delegators.ReplaceOrInsert(staker)
-
-			s.currentStakers.stakers.ReplaceOrInsert(staker)
+			s.currentStakers.LoadDelegator(staker)
+			return nil
+		})
+		if err != nil {
+			return err
 		}
 	}
-
-	return utils.Err(
-		validatorIt.Error(),
-		subnetValidatorIt.Error(),
-		delegatorIt.Error(),
-		subnetDelegatorIt.Error(),
-	)
+	return nil
 }
 
 func (s *state) loadPendingValidators() error {
-	s.pendingStakers = newBaseStakers()
-
-	validatorIt := s.pendingValidatorList.NewIterator()
-	defer validatorIt.Release()
-
-	subnetValidatorIt := s.pendingSubnetValidatorList.NewIterator()
-	defer subnetValidatorIt.Release()
+	s.pendingStakers = newBaseStakers(s.execCfg.DelegatorTreeDegree)
 
-	for _, validatorIt := range []database.Iterator{validatorIt, subnetValidatorIt} {
-		for validatorIt.Next() {
-			txIDBytes := validatorIt.Key()
-			txID, err := ids.ToID(txIDBytes)
-			if err != nil {
-				return err
-			}
-			tx, _, err := s.GetTx(txID)
+	for _, validatorList := range []linkeddb.LinkedDB{s.pendingValidatorList, s.pendingSubnetValidatorList} {
+		err := withIterator(validatorList.NewIterator(), func(txIDBytes, _ []byte) error {
+			staker, err := s.loadPendingStaker(txIDBytes)
 			if err != nil {
 				return err
 			}
 
-			stakerTx, ok := tx.Unsigned.(txs.Staker)
-			if !ok {
-				return fmt.Errorf("expected tx type txs.Staker but got %T", tx.Unsigned)
-			}
+			s.pendingStakers.LoadValidator(staker)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
 
-			staker, err := NewPendingStaker(txID, stakerTx)
+	for _, delegatorList := range []linkeddb.LinkedDB{s.pendingDelegatorList, s.pendingSubnetDelegatorList} {
+		err := withIterator(delegatorList.NewIterator(), func(txIDBytes, _ []byte) error {
+			staker, err := s.loadPendingStaker(txIDBytes)
 			if err != nil {
 				return err
 			}
 
-			validator := s.pendingStakers.getOrCreateValidator(staker.SubnetID, staker.NodeID)
-			validator.validator = staker
-
-			s.pendingStakers.stakers.ReplaceOrInsert(staker)
+			s.pendingStakers.LoadDelegator(staker)
+			return nil
+		})
+		if err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	delegatorIt := s.pendingDelegatorList.NewIterator()
-	defer delegatorIt.Release()
-
-	subnetDelegatorIt := s.pendingSubnetDelegatorList.NewIterator()
-	defer subnetDelegatorIt.Release()
-
-	for _, delegatorIt := range []database.Iterator{delegatorIt, subnetDelegatorIt} {
-		for delegatorIt.Next() {
-			txIDBytes := delegatorIt.Key()
-			txID, err := ids.ToID(txIDBytes)
-			if err != nil {
-				return err
-			}
-			tx, _, err := s.GetTx(txID)
-			if err != nil {
-				return err
-			}
-
-			stakerTx, ok := tx.Unsigned.(txs.Staker)
-			if !ok {
-				return fmt.Errorf("expected tx type txs.Staker but got %T", tx.Unsigned)
-			}
+func (s *state) loadPendingStaker(txIDBytes []byte) (*Staker, error) {
+	txID, err := ids.ToID(txIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	tx, _, err := s.getTx(txID)
+	if err != nil {
+		return nil, err
+	}
 
-			staker, err := NewPendingStaker(txID, stakerTx)
-			if err != nil {
-				return err
-			}
+	stakerTx, ok := tx.Unsigned.(txs.Staker)
+	if !ok {
+		return nil, fmt.Errorf("expected tx type txs.Staker but got %T", tx.Unsigned)
+	}
+	return NewPendingStaker(txID, stakerTx)
+}
 
-			validator := s.pendingStakers.getOrCreateValidator(staker.SubnetID, staker.NodeID)
-			if validator.delegators == nil {
-				validator.delegators = btree.NewG(defaultTreeDegree, (*Staker).Less)
-			}
-			validator.delegators.ReplaceOrInsert(staker)
+// withIterator calls [fn] with the key and value of each entry of [it] until
+// [fn] returns an error. [it] is released on every path.
+func withIterator(it database.Iterator, fn func(key, value []byte) error) error {
+	defer it.Release()
 
-			s.pendingStakers.stakers.ReplaceOrInsert(staker)
+	for it.Next() {
+		if err := fn(it.Key(), it.Value()); err != nil {
+			return err
 		}
 	}
+	return it.Error()
+}
 
-	return utils.Err(
-		validatorIt.Error(),
-		subnetValidatorIt.Error(),
-		delegatorIt.Error(),
-		subnetDelegatorIt.Error(),
-	)
+// verifyStakers checks the consistency of the loaded staker sets if enabled in
+// the execution config.
+func (s *state) verifyStakers() error {
+	if !s.execCfg.VerifyStakersOnLoad {
+		return nil
+	}
+	if err := s.currentStakers.Verify(); err != nil {
+		return fmt.Errorf("failed to verify current stakers: %w", err)
+	}
+	if err := s.pendingStakers.Verify(); err != nil {
+		return fmt.Errorf("failed to verify pending stakers: %w", err)
+	}
+	return nil
 }
 
 // Invariant: initValidatorSets requires loadCurrentValidators to have already
@@ -1670,15 +2598,17 @@ func (s *state) initValidatorSets() error {
 		for nodeID, validator := range validators {
 			validatorStaker := validator.validator
 			if err := s.validators.AddStaker(subnetID, nodeID, validatorStaker.PublicKey, validatorStaker.TxID, validatorStaker.Weight); err != nil {
-				return err
+				return fmt.Errorf("failed to add validator %s of subnet %s: %w", nodeID, subnetID, err)
 			}
 
+			// The validator set guards against the weight overflowing, which
+			// would be reported here.
 			delegatorIterator := NewTreeIterator(validator.delegators)
 			for delegatorIterator.Next() {
 				delegatorStaker := delegatorIterator.Value()
 				if err := s.validators.AddWeight(subnetID, nodeID, delegatorStaker.Weight); err != nil {
 					delegatorIterator.Release()
-					return err
+					return fmt.Errorf("failed to add delegator %s weight to validator %s of subnet %s: %w", delegatorStaker.TxID, nodeID, subnetID, err)
 				}
 			}
 			delegatorIterator.Release()
@@ -1699,7 +2629,7 @@ func (s *state) write(updateValidators bool, height uint64) error {
 		s.writeBlocks(),
 		s.writeCurrentStakers(updateValidators, height),
 		s.writePendingStakers(),
-		s.WriteValidatorMetadata(s.currentValidatorList, s.currentSubnetValidatorList), // Must be called after writeCurrentStakers
+		s.writeValidatorMetadata(), // Must be called after writeCurrentStakers
 		s.writeTXs(),
 		s.writeRewardUTXOs(),
 		s.writeUTXOs(),
@@ -1712,8 +2642,53 @@ func (s *state) write(updateValidators bool, height uint64) error {
 	)
 }
 
+// writeValidatorMetadata writes the staged validator metadata updates. Staged
+// uptime updates are only written once [UptimeFlushFrequency] has passed since
+// they were last written or once there are [MaxPendingUptimeUpdates] of them.
+// The uptimes of validators that are due to be rewarded are always written.
+func (s *state) writeValidatorMetadata() error {
+	err := s.WriteDelegateeRewards(s.currentValidatorList, s.currentSubnetValidatorList)
+	if err != nil {
+		return err
+	}
+
+	var (
+		now                = time.Now()
+		maxPendingUptimes  = s.execCfg.MaxPendingUptimeUpdates
+		uptimeFlushTimeout = s.execCfg.UptimeFlushFrequency
+	)
+	if uptimeFlushTimeout == 0 ||
+		now.Sub(s.lastUptimeFlushTime) >= uptimeFlushTimeout ||
+		(maxPendingUptimes > 0 && s.NumPendingUptimes() >= maxPendingUptimes) {
+		s.lastUptimeFlushTime = now
+		return s.WriteUptimes(s.currentValidatorList, s.currentSubnetValidatorList, nil)
+	}
+
+	// The write lock may be held, so the exported getters can't be used.
+	timestamp := s.timestamp
+	return s.WriteUptimes(
+		s.currentValidatorList,
+		s.currentSubnetValidatorList,
+		func(vdrID ids.NodeID, subnetID ids.ID) bool {
+			staker, err := s.currentStakers.GetValidator(subnetID, vdrID)
+			return err != nil || !staker.EndTime.After(timestamp)
+		},
+	)
+}
+
 func (s *state) Close() error {
+	// Persist the changes of any deferred commits and the staged uptime
+	// updates before closing.
+	var flushErr error
+	if s.numDeferredCommits > 0 || s.NumPendingUptimes() > 0 {
+		flushErr = utils.Err(
+			s.WriteUptimes(s.currentValidatorList, s.currentSubnetValidatorList, nil),
+			s.flushDeferredCommits(),
+		)
+	}
+	s.closeAcceptedSubscribers()
 	return utils.Err(
+		flushErr,
 		s.pendingSubnetValidatorBaseDB.Close(),
 		s.pendingSubnetDelegatorBaseDB.Close(),
 		s.pendingDelegatorBaseDB.Close(),
@@ -1758,6 +2733,17 @@ func (s *state) sync(genesis []byte) error {
 		}
 	}
 
+	if err := s.verifyGenesisHash(genesis); err != nil {
+		return err
+	}
+
+	if err := s.migrate(migrations); err != nil {
+		return fmt.Errorf(
+			"failed to migrate the database: %w",
+			err,
+		)
+	}
+
 	if err := s.load(); err != nil {
 		return fmt.Errorf(
 			"failed to load the database state: %w",
@@ -1781,24 +2767,123 @@ func (s *state) init(genesisBytes []byte) error {
 	if err != nil {
 		return err
 	}
+
+	startTime := time.Now()
 	if err := s.syncGenesis(genesisBlock, genesis); err != nil {
 		return err
 	}
+	syncDuration := time.Since(startTime)
+
+	if err := database.PutID(s.singletonDB, genesisHashKey, genesisID); err != nil {
+		return err
+	}
 
 	if err := s.doneInit(); err != nil {
 		return err
 	}
 
-	return s.Commit()
+	// The database was written with the current schema, so none of the
+	// migrations need to be run.
+	if err := s.putSchemaVersion(uint64(len(migrations))); err != nil {
+		return err
+	}
+
+	startTime = time.Now()
+	if err := s.flush(); err != nil {
+		return err
+	}
+	commitDuration := time.Since(startTime)
+
+	s.metrics.SetGenesisInitialization(
+		syncDuration,
+		commitDuration,
+		len(genesis.UTXOs),
+		len(genesis.Validators),
+		len(genesis.Chains),
+	)
+	s.ctx.Log.Info("initialized state from genesis",
+		zap.Duration("syncDuration", syncDuration),
+		zap.Duration("commitDuration", commitDuration),
+		zap.Int("numUTXOs", len(genesis.UTXOs)),
+		zap.Int("numValidators", len(genesis.Validators)),
+		zap.Int("numChains", len(genesis.Chains)),
+	)
+	return nil
 }
 
-func (s *state) AddStatelessBlock(block block.Block) {
+// verifyGenesisHash returns an error if the database was initialized with
+// genesis bytes other than [genesisBytes].
+//
+// Databases initialized before the genesis hash was recorded aren't verified.
+func (s *state) verifyGenesisHash(genesisBytes []byte) error {
+	expectedHash, err := database.GetID(s.singletonDB, genesisHashKey)
+	if err == database.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get genesis hash: %w", err)
+	}
+
+	genesisHash := hashing.ComputeHash256Array(genesisBytes)
+	if genesisHash != expectedHash {
+		return fmt.Errorf(
+			"%w: database was initialized with genesis %s but got genesis %s",
+			errGenesisMismatch,
+			expectedHash,
+			ids.ID(genesisHash),
+		)
+	}
+	return nil
+}
+
+func (s *state) AddStatelessBlock(block block.Block) error {
+	defer s.writeLock()()
+
+	if s.execCfg.VerifyBlockLinkage {
+		if err := s.verifyBlockLinkage(block); err != nil {
+			return err
+		}
+	}
+	s.addStatelessBlock(block)
+	return nil
+}
+
+// verifyBlockLinkage returns an error if the parent of [block] isn't known or
+// if the height of [block] doesn't immediately follow the parent's height.
+func (s *state) verifyBlockLinkage(block block.Block) error {
+	parentID := block.Parent()
+	parent, err := s.getStatelessBlock(parentID)
+	if err == database.ErrNotFound {
+		return fmt.Errorf("%w: %s of block %s", errUnknownParentBlock, parentID, block.ID())
+	}
+	if err != nil {
+		return err
+	}
+
+	if expectedHeight := parent.Height() + 1; block.Height() != expectedHeight {
+		return fmt.Errorf(
+			"%w: block %s has height %d but expected %d",
+			errUnexpectedBlockHeight,
+			block.ID(),
+			block.Height(),
+			expectedHeight,
+		)
+	}
+	return nil
+}
+
+// addStatelessBlock adds [block] without verifying its linkage. This is used
+// for the genesis block, which has no parent.
+func (s *state) addStatelessBlock(block block.Block) {
 	blkID := block.ID()
 	s.addedBlockIDs[block.Height()] = blkID
 	s.addedBlocks[blkID] = block
+	s.missingBlocks.Evict(blkID)
 }
 
 func (s *state) SetHeight(height uint64) {
+	defer s.writeLock()()
+
 	if s.indexedHeights == nil {
 		// If indexedHeights hasn't been created yet, then we are newly tracking
 		// the range. This means we should initialize the LowerBound to the
@@ -1813,8 +2898,70 @@ func (s *state) SetHeight(height uint64) {
 }
 
 func (s *state) Commit() error {
-	defer s.Abort()
-	batch, err := s.CommitBatch()
+	if err := s.commit(); err != nil {
+		return err
+	}
+	s.metrics.IncStateCommits()
+	return nil
+}
+
+func (s *state) commit() error {
+	defer s.writeLock()()
+
+	now := time.Now()
+	if !s.shouldDeferCommit(now) {
+		return s.flush()
+	}
+
+	// The writes are moved to [s.deferredDB], where they remain buffered until
+	// a later commit flushes them. If the node crashes before then, it
+	// restarts from the state of the last flushed commit.
+	if err := s.write(true /*=updateValidators*/, s.currentHeight); err != nil {
+		return err
+	}
+	if err := s.baseDB.Commit(); err != nil {
+		return err
+	}
+	s.resetUncommitted()
+	if s.numDeferredCommits == 0 {
+		s.firstDeferredCommitTime = now
+	}
+	s.numDeferredCommits++
+	return nil
+}
+
+// flushDeferredCommits writes the changes in [s.baseDB] and the changes of
+// any deferred commits to disk.
+func (s *state) flushDeferredCommits() error {
+	if err := s.baseDB.Commit(); err != nil {
+		return err
+	}
+	s.numDeferredCommits = 0
+	s.firstDeferredCommitTime = time.Time{}
+	if err := s.deferredDB.Commit(); err != nil {
+		return err
+	}
+	s.notifyAccepted()
+	return nil
+}
+
+// shouldDeferCommit returns true if the commit performed at [now] can be
+// buffered in memory rather than flushed to the base database.
+func (s *state) shouldDeferCommit(now time.Time) bool {
+	if s.numDeferredCommits >= s.execCfg.MaxDeferredCommits {
+		return false
+	}
+	maxDuration := s.execCfg.MaxDeferredCommitDuration
+	return s.numDeferredCommits == 0 ||
+		maxDuration == 0 ||
+		now.Sub(s.firstDeferredCommitTime) < maxDuration
+}
+
+// flush commits all pending changes, including the changes of any deferred
+// commits, to the base database.
+func (s *state) flush() error {
+	defer s.abort()
+	batch, err := s.commitBatch()
 	if err != nil {
 		return err
 	}
@@ -1822,7 +2969,98 @@ func (s *state) Commit() error {
 }
 
 func (s *state) Abort() {
+	defer s.writeLock()()
+
+	s.abort()
+}
+
+func (s *state) abort() {
 	s.baseDB.Abort()
+	if s.batchCommitted {
+		// Only the writes included in the batch are held by [deferredDB], as
+		// the deferred commits were flushed by CommitBatch.
+		s.deferredDB.Abort()
+
+		// The batch may have been written by the caller, possibly along with
+		// other writes, before aborting.
+		s.notifyAccepted()
+	}
+	s.metrics.IncStateAborts(s.batchCommitted)
+	s.batchCommitted = false
+	s.resetUncommitted()
+}
+
+// resetUncommitted resets the tracking of changes that were written to
+// [s.baseDB] before being committed. It is called once [s.baseDB] has been
+// committed or discarded.
+func (s *state) resetUncommitted() {
+	// Any reward UTXOs flushed to [baseDB] were committed or discarded along
+	// with it. The remaining reward UTXOs are discarded as well.
+	s.addedRewardUTXOs = make(map[ids.ID][]*avax.UTXO)
+	s.numAddedRewardUTXOs = 0
+	s.flushedRewardUTXOTxIDs = nil
+	s.rewardUTXOsFlushErr = nil
+
+	// The range of the imported validator diffs is applied when they are
+	// written.
+	s.pendingIndexedHeights = nil
+}
+
+func (s *state) AbortAndReload() error {
+	defer s.writeLock()()
+
+	s.abort()
+
+	// The deferred commits were accepted, so they are flushed rather than
+	// being reloaded from the in-memory database.
+	if s.numDeferredCommits > 0 {
+		if err := s.flushDeferredCommits(); err != nil {
+			return err
+		}
+	}
+
+	// The UTXO cache and checksum may include UTXOs written by a failed
+	// CommitBatch. The metered caches can't be registered twice, so the
+	// reloaded UTXO state isn't metered.
+	utxoState, err := avax.NewUTXOState(s.utxoDB, txs.GenesisCodec, s.execCfg.ChecksumsEnabled)
+	if err != nil {
+		return err
+	}
+	s.utxoState = utxoState
+
+	s.addedBlockIDs = make(map[uint64]ids.ID)
+	s.addedBlocks = make(map[ids.ID]block.Block)
+	s.addedTxs = make(map[ids.ID]*txAndStatus)
+	s.modifiedUTXOs = make(map[ids.ID]*avax.UTXO)
+	s.cachedSubnets = nil
+	s.addedSubnets = nil
+	s.subnetOwners = make(map[ids.ID]fx.Owner)
+	s.transformedSubnets = make(map[ids.ID]*txs.Tx)
+	s.modifiedSupplies = make(map[ids.ID]uint64)
+	s.addedChains = make(map[ids.ID][]*txs.Tx)
+
+	// The caches may include values that were read from the discarded
+	// changes.
+	s.blockIDCache.Flush()
+	s.blockCache.Flush()
+	s.missingBlocks.Flush()
+	s.txCache.Flush()
+	s.rewardUTXOsCache.Flush()
+	s.subnetOwnerCache.Flush()
+	s.transformedSubnetCache.Flush()
+	s.supplyCache.Flush()
+	s.chainCache.Flush()
+	s.chainDBCache.Flush()
+
+	// The validator metadata is reloaded along with the current validators.
+	s.validatorState = newValidatorState()
+	s.indexedHeights = nil
+	return utils.Err(
+		s.loadMetadata(),
+		s.loadCurrentValidators(),
+		s.loadPendingValidators(),
+		s.verifyStakers(),
+	)
 }
 
 func (s *state) Checksum() ids.ID {
@@ -1830,12 +3068,41 @@ func (s *state) Checksum() ids.ID {
 }
 
 func (s *state) CommitBatch() (database.Batch, error) {
+	defer s.writeLock()()
+
+	batch, err := s.commitBatch()
+	if err != nil {
+		return nil, err
+	}
+	s.metrics.IncStateCommits()
+	return batch, nil
+}
+
+func (s *state) commitBatch() (database.Batch, error) {
 	// updateValidators is set to true here so that the validator manager is
 	// kept up to date with the last accepted state.
 	if err := s.write(true /*=updateValidators*/, s.currentHeight); err != nil {
 		return nil, err
 	}
-	return s.baseDB.CommitBatch()
+
+	// The deferred commits are flushed first so that they aren't lost if the
+	// batch is never written.
+	if s.numDeferredCommits > 0 {
+		if err := s.deferredDB.Commit(); err != nil {
+			return nil, err
+		}
+		s.numDeferredCommits = 0
+		s.firstDeferredCommitTime = time.Time{}
+	}
+	if err := s.baseDB.Commit(); err != nil {
+		return nil, err
+	}
+	batch, err := s.deferredDB.CommitBatch()
+	if err != nil {
+		return nil, err
+	}
+	s.batchCommitted = true
+	return batch, nil
 }
 
 func (s *state) writeBlocks() error {
@@ -1856,7 +3123,11 @@ func (s *state) writeBlocks() error {
 		// referencing additional data (because of shared byte slices) that
 		// would not be properly accounted for in the cache sizing.
 		s.blockCache.Evict(blkID)
-		if err := s.blockDB.Put(blkID[:], blkBytes); err != nil {
+		storedBytes, err := s.encodeBlockBytes(blkBytes)
+		if err != nil {
+			return fmt.Errorf("failed to encode block %s: %w", blkID, err)
+		}
+		if err := s.blockDB.Put(blkID[:], storedBytes); err != nil {
 			return fmt.Errorf("failed to write block %s: %w", blkID, err)
 		}
 	}
@@ -1864,6 +3135,47 @@ func (s *state) writeBlocks() error {
 }
 
 func (s *state) GetStatelessBlock(blockID ids.ID) (block.Block, error) {
+	defer s.readLock()()
+
+	return s.getStatelessBlock(blockID)
+}
+
+func (s *state) HasBlock(blockID ids.ID) (bool, error) {
+	defer s.readLock()()
+
+	if _, exists := s.addedBlocks[blockID]; exists {
+		return true, nil
+	}
+	if blk, cached := s.blockCache.Get(blockID); cached {
+		return blk != nil, nil
+	}
+	if _, missing := s.missingBlocks.Get(blockID); missing {
+		return false, nil
+	}
+
+	has, err := s.blockDB.Has(blockID[:])
+	if err != nil {
+		return false, err
+	}
+	if !has {
+		s.missingBlocks.Put(blockID, struct{}{})
+		return false, nil
+	}
+
+	// Until [PruneAndIndex] finishes, the block may be stored in the legacy
+	// format, so its status must be checked.
+	pruned, err := s.singletonDB.Has(prunedKey)
+	if err != nil || pruned {
+		return pruned, err
+	}
+	_, err = s.getStatelessBlock(blockID)
+	if err == database.ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *state) getStatelessBlock(blockID ids.ID) (block.Block, error) {
 	if blk, exists := s.addedBlocks[blockID]; exists {
 		return blk, nil
 	}
@@ -1875,7 +3187,7 @@ func (s *state) GetStatelessBlock(blockID ids.ID) (block.Block, error) {
 		return blk, nil
 	}
 
-	blkBytes, err := s.blockDB.Get(blockID[:])
+	storedBytes, err := s.blockDB.Get(blockID[:])
 	if err == database.ErrNotFound {
 		s.blockCache.Put(blockID, nil)
 		return nil, database.ErrNotFound
@@ -1883,6 +3195,10 @@ func (s *state) GetStatelessBlock(blockID ids.ID) (block.Block, error) {
 	if err != nil {
 		return nil, err
 	}
+	blkBytes, err := s.decodeBlockBytes(storedBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode block %s: %w", blockID, err)
+	}
 
 	blk, status, _, err := parseStoredBlock(blkBytes)
 	if err != nil {
@@ -1898,7 +3214,66 @@ func (s *state) GetStatelessBlock(blockID ids.ID) (block.Block, error) {
 	return blk, nil
 }
 
+func (s *state) FindBlockIndexGaps(fromHeight, toHeight uint64) ([]uint64, error) {
+	if fromHeight > toHeight {
+		return nil, errInvalidHeightRange
+	}
+
+	defer s.readLock()()
+
+	toHeight = safemath.Min(toHeight, s.currentHeight)
+	if fromHeight > toHeight {
+		return nil, nil
+	}
+
+	it := s.blockIDDB.NewIteratorWithStart(database.PackUInt64(fromHeight))
+	defer it.Release()
+
+	var (
+		gaps []uint64
+		next = fromHeight // the lowest height that hasn't been checked
+	)
+	// Blocks that haven't been committed yet aren't gaps.
+	checkHeight := func(height uint64) {
+		if _, added := s.addedBlockIDs[height]; !added {
+			gaps = append(gaps, height)
+		}
+	}
+	for it.Next() {
+		height, err := database.ParseUInt64(it.Key())
+		if err != nil {
+			return nil, err
+		}
+		if height > toHeight {
+			break
+		}
+
+		for ; next < height; next++ {
+			checkHeight(next)
+		}
+		if height == toHeight {
+			return gaps, it.Error()
+		}
+		next = height + 1
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+
+	// Check the heights after the last indexed height. The loop ends
+	// explicitly because [toHeight] may be the maximum uint64.
+	for height := next; ; height++ {
+		checkHeight(height)
+		if height == toHeight {
+			break
+		}
+	}
+	return gaps, nil
+}
+
 func (s *state) GetBlockIDAtHeight(height uint64) (ids.ID, error) {
+	defer s.readLock()()
+
 	if blkID, exists := s.addedBlockIDs[height]; exists {
 		return blkID, nil
 	}
@@ -1925,6 +3300,19 @@ func (s *state) GetBlockIDAtHeight(height uint64) (ids.ID, error) {
 	return blkID, nil
 }
 
+func (s *state) GetGenesisBlockID() (ids.ID, error) {
+	if s.genesisBlockID != ids.Empty {
+		return s.genesisBlockID, nil
+	}
+
+	genesisBlockID, err := s.GetBlockIDAtHeight(0)
+	if err != nil {
+		return ids.Empty, err
+	}
+	s.genesisBlockID = genesisBlockID
+	return genesisBlockID, nil
+}
+
 func (s *state) writeCurrentStakers(updateValidators bool, height uint64) error {
 	heightBytes := database.PackUInt64(height)
 	rawNestedPublicKeyDiffDB := prefixdb.New(heightBytes, s.nestedValidatorPublicKeyDiffsDB)
@@ -1978,6 +3366,7 @@ func (s *state) writeCurrentStakers(updateValidators bool, height uint64) error
 					if err != nil {
 						return err
 					}
+					s.metrics.IncValidatorPublicKeyDiffs(constants.PrimaryNetworkID)
 				}
 
 				// The validator is being added.
@@ -2025,6 +3414,7 @@ func (s *state) writeCurrentStakers(updateValidators bool, height uint64) error
 					if err != nil {
 						return err
 					}
+					s.metrics.IncValidatorPublicKeyDiffs(constants.PrimaryNetworkID)
 
 					// TODO: Remove this once we no longer support version
 					// rollbacks.
@@ -2064,6 +3454,7 @@ func (s *state) writeCurrentStakers(updateValidators bool, height uint64) error
 			if err != nil {
 				return err
 			}
+			s.metrics.IncValidatorWeightDiffs(subnetID)
 
 			// TODO: Remove this once we no longer support version rollbacks.
 			weightDiffBytes, err := block.GenesisCodec.Marshal(block.Version, weightDiff)
@@ -2096,7 +3487,7 @@ func (s *state) writeCurrentStakers(updateValidators bool, height uint64) error
 				}
 			}
 			if err != nil {
-				return fmt.Errorf("failed to update validator weight: %w", err)
+				return fmt.Errorf("failed to update weight of validator %s of subnet %s: %w", nodeID, subnetID, err)
 			}
 		}
 	}
@@ -2243,28 +3634,51 @@ func (s *state) writeTXs() error {
 }
 
 func (s *state) writeRewardUTXOs() error {
+	if err := s.rewardUTXOsFlushErr; err != nil {
+		s.rewardUTXOsFlushErr = nil
+		return fmt.Errorf("failed to flush reward UTXOs: %w", err)
+	}
+
 	for txID, utxos := range s.addedRewardUTXOs {
 		delete(s.addedRewardUTXOs, txID)
-		s.rewardUTXOsCache.Put(txID, utxos)
-		rawTxDB := prefixdb.New(txID[:], s.rewardUTXODB)
-		txDB := linkeddb.NewDefault(rawTxDB)
+		// Only the unflushed reward UTXOs of a flushed tx are in memory, so
+		// they can't be cached.
+		if !s.flushedRewardUTXOTxIDs.Contains(txID) {
+			s.rewardUTXOsCache.Put(txID, utxos)
+		}
+		if err := s.putRewardUTXOs(txID, utxos); err != nil {
+			return err
+		}
+	}
+	s.numAddedRewardUTXOs = 0
+	s.flushedRewardUTXOTxIDs = nil
+	return nil
+}
 
-		for _, utxo := range utxos {
-			utxoBytes, err := txs.GenesisCodec.Marshal(txs.Version, utxo)
-			if err != nil {
-				return fmt.Errorf("failed to serialize reward UTXO: %w", err)
-			}
-			utxoID := utxo.InputID()
-			if err := txDB.Put(utxoID[:], utxoBytes); err != nil {
-				return fmt.Errorf("failed to add reward UTXO: %w", err)
-			}
+func (s *state) putRewardUTXOs(txID ids.ID, utxos []*avax.UTXO) error {
+	rawTxDB := prefixdb.New(txID[:], s.rewardUTXODB)
+	txDB := linkeddb.NewDefault(rawTxDB)
+
+	for _, utxo := range utxos {
+		utxoBytes, err := txs.GenesisCodec.Marshal(txs.Version, utxo)
+		if err != nil {
+			return fmt.Errorf("failed to serialize reward UTXO: %w", err)
+		}
+		utxoID := utxo.InputID()
+		if err := txDB.Put(utxoID[:], utxoBytes); err != nil {
+			return fmt.Errorf("failed to add reward UTXO: %w", err)
 		}
 	}
 	return nil
 }
 
 func (s *state) writeUTXOs() error {
-	for utxoID, utxo := range s.modifiedUTXOs {
+	// UTXOs are written in sorted order so that the resulting database
+	// operations are reproducible.
+	utxoIDs := maps.Keys(s.modifiedUTXOs)
+	utils.Sort(utxoIDs)
+	for _, utxoID := range utxoIDs {
+		utxo := s.modifiedUTXOs[utxoID]
 		delete(s.modifiedUTXOs, utxoID)
 
 		if utxo == nil {
@@ -2378,6 +3792,14 @@ func (s *state) writeMetadata() error {
 		s.persistedLastAccepted = s.lastAccepted
 	}
 
+	if pending := s.pendingIndexedHeights; pending != nil {
+		s.pendingIndexedHeights = nil
+		if s.indexedHeights == nil {
+			s.indexedHeights = pending
+		} else {
+			s.indexedHeights.LowerBound = safemath.Min(s.indexedHeights.LowerBound, pending.LowerBound)
+		}
+	}
 	if s.indexedHeights != nil {
 		indexedHeightsBytes, err := block.GenesisCodec.Marshal(block.Version, s.indexedHeights)
 		if err != nil {
@@ -2449,7 +3871,10 @@ func (s *state) PruneAndIndex(lock sync.Locker, log logging.Logger) error {
 	)
 
 	for blockIterator.Next() {
-		blkBytes := blockIterator.Value()
+		blkBytes, err := s.decodeBlockBytes(blockIterator.Value())
+		if err != nil {
+			return err
+		}
 
 		blk, status, isStateBlk, err := parseStoredBlock(blkBytes)
 		if err != nil {
@@ -2493,7 +3918,7 @@ func (s *state) PruneAndIndex(lock sync.Locker, log logging.Logger) error {
 			// accepted.
 			lock.Lock()
 			err := utils.Err(
-				s.Commit(),
+				s.flush(),
 				blockIterator.Error(),
 			)
 			lock.Unlock()
@@ -2572,5 +3997,5 @@ func (s *state) PruneAndIndex(lock sync.Locker, log logging.Logger) error {
 		zap.Duration("duration", time.Since(startTime)),
 	)
 
-	return s.Commit()
+	return s.flush()
 }
@@ -5,6 +5,7 @@ package state
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"testing"
 	"time"
@@ -15,14 +16,22 @@ import (
 
 	"go.uber.org/mock/gomock"
 
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/codec"
 	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/linkeddb"
 	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow"
 	"github.com/ava-labs/avalanchego/snow/choices"
 	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils"
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/utils/units"
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 	"github.com/ava-labs/avalanchego/vms/components/avax"
@@ -32,6 +41,7 @@ import (
 	"github.com/ava-labs/avalanchego/vms/platformvm/genesis"
 	"github.com/ava-labs/avalanchego/vms/platformvm/metrics"
 	"github.com/ava-labs/avalanchego/vms/platformvm/reward"
+	"github.com/ava-labs/avalanchego/vms/platformvm/status"
 	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
 	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
 
@@ -155,6 +165,56 @@ func newInitializedState(require *require.Assertions) (State, database.Database)
 	return s, db
 }
 
+func TestStateGenesisMismatch(t *testing.T) {
+	require := require.New(t)
+
+	newGenesisBytes := func(timestamp time.Time) []byte {
+		genesisBytes, err := genesis.Codec.Marshal(genesis.Version, &genesis.Genesis{
+			Timestamp:     uint64(timestamp.Unix()),
+			InitialSupply: units.Avax,
+		})
+		require.NoError(err)
+		return genesisBytes
+	}
+	newStateWithGenesis := func(db database.Database, genesisBytes []byte) (State, error) {
+		execCfg, err := config.GetExecutionConfig(nil)
+		require.NoError(err)
+		return New(
+			db,
+			genesisBytes,
+			prometheus.NewRegistry(),
+			validators.NewManager(),
+			execCfg,
+			&snow.Context{
+				Log: logging.NoLog{},
+			},
+			metrics.Noop,
+			reward.NewCalculator(reward.Config{
+				MaxConsumptionRate: .12 * reward.PercentDenominator,
+				MinConsumptionRate: .1 * reward.PercentDenominator,
+				MintingPeriod:      365 * 24 * time.Hour,
+				SupplyCap:          720 * units.MegaAvax,
+			}),
+		)
+	}
+
+	var (
+		db           = memdb.New()
+		genesisBytes = newGenesisBytes(initialTime)
+	)
+	s, err := newStateWithGenesis(db, genesisBytes)
+	require.NoError(err)
+	require.NoError(s.Close())
+
+	// Reopening the database with the same genesis should succeed.
+	s, err = newStateWithGenesis(db, genesisBytes)
+	require.NoError(err)
+	require.NoError(s.Close())
+
+	_, err = newStateWithGenesis(db, newGenesisBytes(initialTime.Add(time.Second)))
+	require.ErrorIs(err, errGenesisMismatch)
+}
+
 func newUninitializedState(require *require.Assertions) (State, database.Database) {
 	db := memdb.New()
 	return newStateFromDB(require, db), db
@@ -692,3 +752,2383 @@ func TestStateSubnetOwner(t *testing.T) {
 	require.NoError(err)
 	require.Equal(owner2, owner)
 }
+
+func TestStateSubnetOwnerNegativeCache(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+	state := s.(*state)
+
+	chainTx := &txs.Tx{Unsigned: &txs.CreateChainTx{
+		SubnetAuth: &secp256k1fx.Input{},
+	}}
+	require.NoError(chainTx.Initialize(txs.Codec))
+	state.AddTx(chainTx, status.Committed)
+	require.NoError(state.Commit())
+
+	chainID := chainTx.ID()
+	_, err := state.GetSubnetOwner(chainID)
+	require.ErrorIs(err, errIsNotSubnet)
+
+	// The negative result should be cached.
+	ownerAndSize, cached := state.subnetOwnerCache.Get(chainID)
+	require.True(cached)
+	require.True(ownerAndSize.isNotSubnet)
+
+	_, err = state.GetSubnetOwner(chainID)
+	require.ErrorIs(err, errIsNotSubnet)
+
+	owner := &secp256k1fx.OutputOwners{}
+	createSubnetTx := &txs.Tx{Unsigned: &txs.CreateSubnetTx{
+		Owner: owner,
+	}}
+	require.NoError(createSubnetTx.Initialize(txs.Codec))
+	subnetID := createSubnetTx.ID()
+
+	_, err = state.GetSubnetOwner(subnetID)
+	require.ErrorIs(err, database.ErrNotFound)
+
+	// Adding the subnet must invalidate the cached result.
+	state.AddTx(createSubnetTx, status.Committed)
+
+	subnetOwner, err := state.GetSubnetOwner(subnetID)
+	require.NoError(err)
+	require.Equal(owner, subnetOwner)
+}
+
+func TestStateGetChainsOrdering(t *testing.T) {
+	require := require.New(t)
+
+	s, db := newInitializedState(require)
+	subnetID := ids.GenerateTestID()
+
+	numChains := 0
+	addChains := func(count int) {
+		for i := 0; i < count; i++ {
+			createChainTx := &txs.Tx{
+				Unsigned: &txs.CreateChainTx{
+					SubnetID:   subnetID,
+					ChainName:  fmt.Sprintf("chain%d", numChains),
+					VMID:       constants.AVMID,
+					SubnetAuth: &secp256k1fx.Input{},
+				},
+			}
+			require.NoError(createChainTx.Initialize(txs.Codec))
+			s.AddChain(createChainTx)
+			s.AddTx(createChainTx, status.Committed)
+			numChains++
+		}
+	}
+
+	// Persist some of the chains and leave the rest pending.
+	addChains(5)
+	require.NoError(s.Commit())
+	addChains(5)
+
+	chains, err := s.GetChains(subnetID)
+	require.NoError(err)
+	require.Len(chains, numChains)
+	require.True(slices.IsSortedFunc(chains, func(a, b *txs.Tx) bool {
+		return a.ID().Less(b.ID())
+	}))
+
+	// Force a fresh iteration over the database.
+	s.(*state).chainCache.Flush()
+
+	uncachedChains, err := s.GetChains(subnetID)
+	require.NoError(err)
+	require.Equal(chainIDs(chains), chainIDs(uncachedChains))
+
+	// A state opened independently on the same database must return the same
+	// ordering.
+	require.NoError(s.Commit())
+	otherState := newStateFromDB(require, db)
+
+	otherChains, err := otherState.GetChains(subnetID)
+	require.NoError(err)
+	require.Equal(chainIDs(chains), chainIDs(otherChains))
+}
+
+func TestStateGetChainsReturnsCopy(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+	subnetID := ids.GenerateTestID()
+
+	newCreateChainTx := func(name string) *txs.Tx {
+		createChainTx := &txs.Tx{
+			Unsigned: &txs.CreateChainTx{
+				SubnetID:   subnetID,
+				ChainName:  name,
+				VMID:       constants.AVMID,
+				SubnetAuth: &secp256k1fx.Input{},
+			},
+		}
+		require.NoError(createChainTx.Initialize(txs.Codec))
+		return createChainTx
+	}
+
+	for i := 0; i < 4; i++ {
+		s.AddChain(newCreateChainTx(fmt.Sprintf("chain%d", i)))
+	}
+
+	chains, err := s.GetChains(subnetID)
+	require.NoError(err)
+	expectedIDs := chainIDs(chains)
+
+	// Neither modifying the returned slice nor adding a chain may modify a
+	// previously returned slice.
+	chains[0], chains[1] = chains[1], chains[0]
+	otherChains, err := s.GetChains(subnetID)
+	require.NoError(err)
+	require.Equal(expectedIDs, chainIDs(otherChains))
+
+	s.AddChain(newCreateChainTx("chain4"))
+	require.Equal(expectedIDs, chainIDs(otherChains))
+
+	allChains, err := s.GetChains(subnetID)
+	require.NoError(err)
+	require.Len(allChains, 5)
+}
+
+func TestStateGetAllChains(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+
+	numSubnets := 0
+	addSubnet := func() ids.ID {
+		createSubnetTx := &txs.Tx{
+			Unsigned: &txs.CreateSubnetTx{
+				Owner: &secp256k1fx.OutputOwners{
+					// Ensure each subnet has a unique ID
+					Locktime: uint64(numSubnets),
+				},
+			},
+		}
+		numSubnets++
+		require.NoError(createSubnetTx.Initialize(txs.Codec))
+		s.AddSubnet(createSubnetTx)
+		s.AddTx(createSubnetTx, status.Committed)
+		return createSubnetTx.ID()
+	}
+	addChain := func(subnetID ids.ID, name string) *txs.Tx {
+		createChainTx := &txs.Tx{
+			Unsigned: &txs.CreateChainTx{
+				SubnetID:   subnetID,
+				ChainName:  name,
+				VMID:       constants.AVMID,
+				SubnetAuth: &secp256k1fx.Input{},
+			},
+		}
+		require.NoError(createChainTx.Initialize(txs.Codec))
+		s.AddChain(createChainTx)
+		s.AddTx(createChainTx, status.Committed)
+		return createChainTx
+	}
+
+	primaryChains, err := s.GetChains(constants.PrimaryNetworkID)
+	require.NoError(err)
+
+	subnetID0 := addSubnet()
+	subnet0Chain := addChain(subnetID0, "chain0")
+	require.NoError(s.Commit())
+
+	// The chains of [subnetID1] are uncommitted.
+	subnetID1 := addSubnet()
+	subnet1Chain0 := addChain(subnetID1, "chain1")
+	subnet1Chain1 := addChain(subnetID1, "chain2")
+
+	// Subnets without chains are omitted.
+	_ = addSubnet()
+
+	chains, err := s.GetAllChains()
+	require.NoError(err)
+	require.Len(chains, 3)
+	require.Equal(chainIDs(primaryChains), chainIDs(chains[constants.PrimaryNetworkID]))
+	require.Equal([]ids.ID{subnet0Chain.ID()}, chainIDs(chains[subnetID0]))
+	require.ElementsMatch(
+		[]ids.ID{subnet1Chain0.ID(), subnet1Chain1.ID()},
+		chainIDs(chains[subnetID1]),
+	)
+}
+
+func chainIDs(chains []*txs.Tx) []ids.ID {
+	chainIDs := make([]ids.ID, len(chains))
+	for i, chain := range chains {
+		chainIDs[i] = chain.ID()
+	}
+	return chainIDs
+}
+
+// Verify that stakers loaded from disk are indexed identically to the stakers
+// that were originally inserted with the Put methods.
+func TestStateLoadStakersMatchesPut(t *testing.T) {
+	require := require.New(t)
+
+	s, db := newInitializedState(require)
+
+	nodeID := ids.GenerateTestNodeID()
+	validatorTx := &txs.Tx{Unsigned: &txs.AddValidatorTx{
+		Validator: txs.Validator{
+			NodeID: nodeID,
+			Start:  uint64(initialTime.Unix()),
+			End:    uint64(initialValidatorEndTime.Unix()),
+			Wght:   units.Avax,
+		},
+		StakeOuts: []*avax.TransferableOutput{
+			{
+				Asset: avax.Asset{ID: initialTxID},
+				Out: &secp256k1fx.TransferOutput{
+					Amt: units.Avax,
+				},
+			},
+		},
+		RewardsOwner:     &secp256k1fx.OutputOwners{},
+		DelegationShares: reward.PercentDenominator,
+	}}
+	require.NoError(validatorTx.Initialize(txs.Codec))
+
+	validator, err := NewCurrentStaker(
+		validatorTx.ID(),
+		validatorTx.Unsigned.(txs.Staker),
+		units.MilliAvax,
+	)
+	require.NoError(err)
+	s.PutCurrentValidator(validator)
+	s.AddTx(validatorTx, status.Committed)
+
+	delegatorTx := &txs.Tx{Unsigned: &txs.AddDelegatorTx{
+		Validator: txs.Validator{
+			NodeID: nodeID,
+			Start:  uint64(initialTime.Unix()),
+			End:    uint64(initialValidatorEndTime.Unix()),
+			Wght:   units.Avax,
+		},
+		StakeOuts: []*avax.TransferableOutput{
+			{
+				Asset: avax.Asset{ID: initialTxID},
+				Out: &secp256k1fx.TransferOutput{
+					Amt: units.Avax,
+				},
+			},
+		},
+		DelegationRewardsOwner: &secp256k1fx.OutputOwners{},
+	}}
+	require.NoError(delegatorTx.Initialize(txs.Codec))
+
+	delegator, err := NewCurrentStaker(
+		delegatorTx.ID(),
+		delegatorTx.Unsigned.(txs.Staker),
+		units.MilliAvax,
+	)
+	require.NoError(err)
+	s.PutCurrentDelegator(delegator)
+	s.AddTx(delegatorTx, status.Committed)
+
+	pendingValidatorTx := &txs.Tx{Unsigned: &txs.AddValidatorTx{
+		Validator: txs.Validator{
+			NodeID: ids.GenerateTestNodeID(),
+			Start:  uint64(initialValidatorEndTime.Unix()),
+			End:    uint64(initialValidatorEndTime.Add(24 * time.Hour).Unix()),
+			Wght:   units.Avax,
+		},
+		StakeOuts: []*avax.TransferableOutput{
+			{
+				Asset: avax.Asset{ID: initialTxID},
+				Out: &secp256k1fx.TransferOutput{
+					Amt: units.Avax,
+				},
+			},
+		},
+		RewardsOwner:     &secp256k1fx.OutputOwners{},
+		DelegationShares: reward.PercentDenominator,
+	}}
+	require.NoError(pendingValidatorTx.Initialize(txs.Codec))
+
+	pendingValidator, err := NewPendingStaker(
+		pendingValidatorTx.ID(),
+		pendingValidatorTx.Unsigned.(txs.Staker),
+	)
+	require.NoError(err)
+	s.PutPendingValidator(pendingValidator)
+	s.AddTx(pendingValidatorTx, status.Committed)
+
+	require.NoError(s.Commit())
+
+	reloaded := newStateFromDB(require, db).(*state)
+	require.NoError(reloaded.load())
+
+	// Loading must not record any diffs, otherwise the stakers would be
+	// re-written on the next commit.
+	require.Empty(reloaded.currentStakers.validatorDiffs)
+	require.Empty(reloaded.pendingStakers.validatorDiffs)
+
+	original := s.(*state)
+	for _, stakers := range []struct {
+		original *baseStakers
+		reloaded *baseStakers
+	}{
+		{
+			original: original.currentStakers,
+			reloaded: reloaded.currentStakers,
+		},
+		{
+			original: original.pendingStakers,
+			reloaded: reloaded.pendingStakers,
+		},
+	} {
+		require.NoError(stakers.reloaded.Verify())
+		require.Equal(
+			stakerTxIDs(stakers.original.GetStakerIterator()),
+			stakerTxIDs(stakers.reloaded.GetStakerIterator()),
+		)
+
+		for subnetID, nodes := range stakers.original.validators {
+			for nodeID := range nodes {
+				expectedValidator, expectedErr := stakers.original.GetValidator(subnetID, nodeID)
+				validator, err := stakers.reloaded.GetValidator(subnetID, nodeID)
+				require.Equal(expectedErr, err)
+				require.Equal(expectedValidator, validator)
+
+				require.Equal(
+					stakerTxIDs(stakers.original.GetDelegatorIterator(subnetID, nodeID)),
+					stakerTxIDs(stakers.reloaded.GetDelegatorIterator(subnetID, nodeID)),
+				)
+			}
+		}
+	}
+}
+
+func stakerTxIDs(it StakerIterator) []ids.ID {
+	defer it.Release()
+
+	var txIDs []ids.ID
+	for it.Next() {
+		txIDs = append(txIDs, it.Value().TxID)
+	}
+	return txIDs
+}
+
+func TestStatePruneRewardUTXOs(t *testing.T) {
+	require := require.New(t)
+
+	s, db := newInitializedState(require)
+
+	var (
+		prunedTxID = ids.GenerateTestID()
+		keptTxID   = ids.GenerateTestID()
+	)
+	newRewardUTXO := func(txID ids.ID, outputIndex uint32) *avax.UTXO {
+		return &avax.UTXO{
+			UTXOID: avax.UTXOID{
+				TxID:        txID,
+				OutputIndex: outputIndex,
+			},
+			Asset: avax.Asset{ID: initialTxID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: units.MilliAvax,
+			},
+		}
+	}
+
+	s.AddRewardUTXO(prunedTxID, newRewardUTXO(prunedTxID, 0))
+	s.AddRewardUTXO(prunedTxID, newRewardUTXO(prunedTxID, 1))
+	s.AddRewardUTXO(keptTxID, newRewardUTXO(keptTxID, 0))
+	require.NoError(s.Commit())
+
+	// Populate the cache to ensure pruning evicts it.
+	utxos, err := s.GetRewardUTXOs(prunedTxID)
+	require.NoError(err)
+	require.Len(utxos, 2)
+
+	require.NoError(s.PruneRewardUTXOs([]ids.ID{prunedTxID}))
+	require.NoError(s.Commit())
+
+	utxos, err = s.GetRewardUTXOs(prunedTxID)
+	require.NoError(err)
+	require.Empty(utxos)
+
+	utxos, err = s.GetRewardUTXOs(keptTxID)
+	require.NoError(err)
+	require.Len(utxos, 1)
+
+	// The pruned entries should be removed from disk.
+	reloaded := newStateFromDB(require, db)
+
+	utxos, err = reloaded.GetRewardUTXOs(prunedTxID)
+	require.NoError(err)
+	require.Empty(utxos)
+
+	utxos, err = reloaded.GetRewardUTXOs(keptTxID)
+	require.NoError(err)
+	require.Len(utxos, 1)
+}
+
+type releaseTrackingIterator struct {
+	database.Iterator
+	released bool
+}
+
+func (it *releaseTrackingIterator) Release() {
+	it.released = true
+	it.Iterator.Release()
+}
+
+func TestWithIteratorReleasesOnError(t *testing.T) {
+	require := require.New(t)
+
+	db := memdb.New()
+	utxoDB := linkeddb.NewDefault(db)
+
+	utxo := &avax.UTXO{
+		UTXOID: avax.UTXOID{
+			TxID: ids.GenerateTestID(),
+		},
+		Asset: avax.Asset{ID: ids.GenerateTestID()},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: units.Avax,
+		},
+	}
+	utxoBytes, err := txs.Codec.Marshal(txs.Version, utxo)
+	require.NoError(err)
+	require.NoError(utxoDB.Put([]byte{0}, utxoBytes))
+	// The codec version of this entry isn't registered.
+	require.NoError(utxoDB.Put([]byte{1}, []byte{0xff, 0xff}))
+
+	unmarshalUTXO := func(_, value []byte) error {
+		_, err := txs.Codec.Unmarshal(value, &avax.UTXO{})
+		return err
+	}
+
+	it := &releaseTrackingIterator{Iterator: utxoDB.NewIterator()}
+	err = withIterator(it, unmarshalUTXO)
+	require.ErrorIs(err, codec.ErrUnknownVersion)
+	require.True(it.released)
+
+	require.NoError(utxoDB.Delete([]byte{1}))
+
+	it = &releaseTrackingIterator{Iterator: utxoDB.NewIterator()}
+	require.NoError(withIterator(it, unmarshalUTXO))
+	require.True(it.released)
+}
+
+func TestStateGetRewardUTXOsInvalidEntry(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+	internalState := s.(*state)
+
+	txID := ids.GenerateTestID()
+	txDB := linkeddb.NewDefault(prefixdb.New(txID[:], internalState.rewardUTXODB))
+	require.NoError(txDB.Put([]byte{0}, []byte{0xff, 0xff}))
+
+	_, err := s.GetRewardUTXOs(txID)
+	require.ErrorIs(err, codec.ErrUnknownVersion)
+}
+
+func TestStateFlushRewardUTXOs(t *testing.T) {
+	require := require.New(t)
+
+	s, db := newInitializedState(require)
+
+	var (
+		txID     = ids.GenerateTestID()
+		numUTXOs = 2*maxPendingRewardUTXOs + 1
+	)
+	addRewardUTXOs := func() {
+		for i := 0; i < numUTXOs; i++ {
+			s.AddRewardUTXO(txID, &avax.UTXO{
+				UTXOID: avax.UTXOID{
+					TxID:        txID,
+					OutputIndex: uint32(i),
+				},
+				Asset: avax.Asset{ID: initialTxID},
+				Out: &secp256k1fx.TransferOutput{
+					Amt: units.MilliAvax,
+				},
+			})
+		}
+	}
+
+	addRewardUTXOs()
+	// Most of the reward UTXOs should have been flushed out of memory.
+	internalState := s.(*state)
+	require.Less(internalState.numAddedRewardUTXOs, maxPendingRewardUTXOs)
+	require.True(internalState.flushedRewardUTXOTxIDs.Contains(txID))
+
+	utxos, err := s.GetRewardUTXOs(txID)
+	require.NoError(err)
+	require.Len(utxos, numUTXOs)
+
+	// Aborting should discard both the flushed and the unflushed reward UTXOs.
+	s.Abort()
+
+	utxos, err = s.GetRewardUTXOs(txID)
+	require.NoError(err)
+	require.Empty(utxos)
+
+	addRewardUTXOs()
+	require.NoError(s.Commit())
+
+	utxos, err = s.GetRewardUTXOs(txID)
+	require.NoError(err)
+	require.Len(utxos, numUTXOs)
+
+	reloaded := newStateFromDB(require, db)
+
+	utxos, err = reloaded.GetRewardUTXOs(txID)
+	require.NoError(err)
+	require.Len(utxos, numUTXOs)
+}
+
+// Verify that every type of tx added with AddTx can be read back after it has
+// been committed, both from the committing state and from a reloaded state.
+func TestStateGetTxAfterCommit(t *testing.T) {
+	require := require.New(t)
+
+	s, db := newInitializedState(require)
+
+	owner := &secp256k1fx.OutputOwners{}
+	unsignedTxs := []txs.UnsignedTx{
+		&txs.BaseTx{},
+		&txs.AddValidatorTx{
+			RewardsOwner: owner,
+		},
+		&txs.AddSubnetValidatorTx{
+			SubnetAuth: &secp256k1fx.Input{},
+		},
+		&txs.AddDelegatorTx{
+			DelegationRewardsOwner: owner,
+		},
+		&txs.CreateChainTx{
+			SubnetAuth: &secp256k1fx.Input{},
+		},
+		&txs.CreateSubnetTx{
+			Owner: owner,
+		},
+		&txs.ImportTx{},
+		&txs.ExportTx{},
+		&txs.AdvanceTimeTx{},
+		&txs.RewardValidatorTx{},
+		&txs.RemoveSubnetValidatorTx{
+			SubnetAuth: &secp256k1fx.Input{},
+		},
+		&txs.TransferSubnetOwnershipTx{
+			SubnetAuth: &secp256k1fx.Input{},
+			Owner:      owner,
+		},
+	}
+
+	expectedTxs := make([]*txs.Tx, 0, len(unsignedTxs))
+	for _, unsignedTx := range unsignedTxs {
+		tx := &txs.Tx{Unsigned: unsignedTx}
+		require.NoError(tx.Initialize(txs.Codec), "%T", unsignedTx)
+
+		s.AddTx(tx, status.Committed)
+		expectedTxs = append(expectedTxs, tx)
+	}
+	require.NoError(s.Commit())
+
+	reloaded := newStateFromDB(require, db)
+	for _, state := range []State{s, reloaded} {
+		for _, expectedTx := range expectedTxs {
+			tx, txStatus, err := state.GetTx(expectedTx.ID())
+			require.NoError(err, "%T", expectedTx.Unsigned)
+			require.Equal(expectedTx.Bytes(), tx.Bytes())
+			require.Equal(status.Committed, txStatus)
+		}
+	}
+}
+
+// recordingUTXOState records the order of UTXO writes.
+type recordingUTXOState struct {
+	avax.UTXOState
+
+	ops []ids.ID
+}
+
+func (s *recordingUTXOState) PutUTXO(utxo *avax.UTXO) error {
+	s.ops = append(s.ops, utxo.InputID())
+	return s.UTXOState.PutUTXO(utxo)
+}
+
+func (s *recordingUTXOState) DeleteUTXO(utxoID ids.ID) error {
+	s.ops = append(s.ops, utxoID)
+	return s.UTXOState.DeleteUTXO(utxoID)
+}
+
+func TestStateWriteUTXOsOrdering(t *testing.T) {
+	require := require.New(t)
+
+	newUTXOs := func(n int) []*avax.UTXO {
+		utxos := make([]*avax.UTXO, n)
+		for i := range utxos {
+			utxos[i] = &avax.UTXO{
+				UTXOID: avax.UTXOID{
+					TxID:        ids.GenerateTestID(),
+					OutputIndex: uint32(i),
+				},
+				Asset: avax.Asset{ID: ids.GenerateTestID()},
+				Out: &secp256k1fx.TransferOutput{
+					Amt: units.Avax,
+				},
+			}
+		}
+		return utxos
+	}
+	var (
+		existingUTXOs = newUTXOs(32)
+		addedUTXOs    = newUTXOs(32)
+		expectedOps   []ids.ID
+	)
+	for i := 0; i < 5; i++ {
+		s, _ := newInitializedState(require)
+		for _, utxo := range existingUTXOs {
+			s.AddUTXO(utxo)
+		}
+		require.NoError(s.Commit())
+
+		recorder := &recordingUTXOState{
+			UTXOState: s.(*state).utxoState,
+		}
+		s.(*state).utxoState = recorder
+
+		for _, utxo := range addedUTXOs {
+			s.AddUTXO(utxo)
+		}
+		for _, utxo := range existingUTXOs {
+			s.DeleteUTXO(utxo.InputID())
+		}
+		require.NoError(s.Commit())
+
+		require.Len(recorder.ops, len(addedUTXOs)+len(existingUTXOs))
+		require.True(utils.IsSortedAndUnique(recorder.ops))
+		if expectedOps == nil {
+			expectedOps = recorder.ops
+		}
+		require.Equal(expectedOps, recorder.ops)
+	}
+}
+
+// diffMetrics records the number of validator diffs written per subnet.
+type diffMetrics struct {
+	metrics.Metrics
+
+	weightDiffs    map[ids.ID]int
+	publicKeyDiffs map[ids.ID]int
+}
+
+func (m *diffMetrics) IncValidatorWeightDiffs(subnetID ids.ID) {
+	m.weightDiffs[subnetID]++
+}
+
+func (m *diffMetrics) IncValidatorPublicKeyDiffs(subnetID ids.ID) {
+	m.publicKeyDiffs[subnetID]++
+}
+
+func TestStateValidatorDiffMetrics(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+	m := &diffMetrics{
+		Metrics:        metrics.Noop,
+		weightDiffs:    make(map[ids.ID]int),
+		publicKeyDiffs: make(map[ids.ID]int),
+	}
+	s.(*state).metrics = m
+
+	sk, err := bls.NewSecretKey()
+	require.NoError(err)
+
+	var (
+		nodeID   = ids.GenerateTestNodeID()
+		subnetID = ids.GenerateTestID()
+	)
+	s.PutCurrentValidator(&Staker{
+		TxID:      ids.GenerateTestID(),
+		NodeID:    nodeID,
+		PublicKey: bls.PublicFromSecretKey(sk),
+		SubnetID:  constants.PrimaryNetworkID,
+		Weight:    units.Avax,
+		StartTime: initialTime,
+		EndTime:   initialValidatorEndTime,
+		NextTime:  initialValidatorEndTime,
+		Priority:  txs.PrimaryNetworkValidatorCurrentPriority,
+	})
+	s.PutCurrentValidator(&Staker{
+		TxID:      ids.GenerateTestID(),
+		NodeID:    nodeID,
+		SubnetID:  subnetID,
+		Weight:    1,
+		StartTime: initialTime,
+		EndTime:   initialValidatorEndTime,
+		NextTime:  initialValidatorEndTime,
+		Priority:  txs.SubnetPermissionedValidatorCurrentPriority,
+	})
+	s.SetHeight(1)
+	require.NoError(s.Commit())
+
+	require.Equal(
+		map[ids.ID]int{
+			constants.PrimaryNetworkID: 1,
+			subnetID:                   1,
+		},
+		m.weightDiffs,
+	)
+	require.Equal(
+		map[ids.ID]int{
+			constants.PrimaryNetworkID: 1,
+		},
+		m.publicKeyDiffs,
+	)
+}
+
+func TestStateInitValidatorSetsWeightOverflow(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+	baseState := s.(*state)
+
+	var (
+		nodeID   = ids.GenerateTestNodeID()
+		subnetID = ids.GenerateTestID()
+	)
+	baseState.currentStakers.LoadValidator(&Staker{
+		TxID:      ids.GenerateTestID(),
+		NodeID:    nodeID,
+		SubnetID:  subnetID,
+		Weight:    math.MaxUint64 - 1,
+		StartTime: initialTime,
+		EndTime:   initialValidatorEndTime,
+		NextTime:  initialValidatorEndTime,
+		Priority:  txs.SubnetPermissionedValidatorCurrentPriority,
+	})
+	baseState.currentStakers.LoadDelegator(&Staker{
+		TxID:      ids.GenerateTestID(),
+		NodeID:    nodeID,
+		SubnetID:  subnetID,
+		Weight:    2,
+		StartTime: initialTime,
+		EndTime:   initialValidatorEndTime,
+		NextTime:  initialValidatorEndTime,
+		Priority:  txs.SubnetPermissionlessDelegatorCurrentPriority,
+	})
+
+	err := baseState.initValidatorSets()
+	require.ErrorIs(err, safemath.ErrOverflow)
+	require.ErrorContains(err, nodeID.String())
+	require.ErrorContains(err, subnetID.String())
+}
+
+func TestStateDeferredCommits(t *testing.T) {
+	require := require.New(t)
+
+	s, db := newInitializedState(require)
+	require.NoError(s.Commit())
+	genesisID := s.GetLastAccepted()
+	s.(*state).execCfg.MaxDeferredCommits = 2
+
+	// Simulates a crash by reading the state persisted in [db] while
+	// ignoring anything buffered in [s].
+	requireLastAcceptedAfterCrash := func(expected ids.ID) {
+		lastAccepted, err := database.GetID(prefixdb.New(singletonPrefix, db), lastAcceptedKey)
+		require.NoError(err)
+		require.Equal(expected, lastAccepted)
+	}
+
+	blkIDs := make([]ids.ID, 4)
+	for i := range blkIDs {
+		blkIDs[i] = ids.GenerateTestID()
+	}
+
+	// The first two commits are deferred.
+	s.SetLastAccepted(blkIDs[0])
+	require.NoError(s.Commit())
+	requireLastAcceptedAfterCrash(genesisID)
+
+	s.SetLastAccepted(blkIDs[1])
+	require.NoError(s.Commit())
+	requireLastAcceptedAfterCrash(genesisID)
+
+	// The deferred commits are flushed along with the third commit.
+	s.SetLastAccepted(blkIDs[2])
+	require.NoError(s.Commit())
+	requireLastAcceptedAfterCrash(blkIDs[2])
+
+	// CommitBatch includes the writes of deferred commits.
+	s.SetLastAccepted(blkIDs[3])
+	require.NoError(s.Commit())
+	requireLastAcceptedAfterCrash(blkIDs[2])
+
+	batch, err := s.CommitBatch()
+	require.NoError(err)
+	require.NoError(batch.Write())
+	s.Abort()
+	requireLastAcceptedAfterCrash(blkIDs[3])
+	require.Zero(s.(*state).numDeferredCommits)
+}
+
+func TestStateDeferredCommitsCrash(t *testing.T) {
+	require := require.New(t)
+
+	s, db := newInitializedState(require)
+	require.NoError(s.Commit())
+	genesisID := s.GetLastAccepted()
+	s.(*state).execCfg.MaxDeferredCommits = 2
+
+	newUTXO := func() *avax.UTXO {
+		return &avax.UTXO{
+			UTXOID: avax.UTXOID{TxID: ids.GenerateTestID()},
+			Asset:  avax.Asset{ID: ids.GenerateTestID()},
+			Out:    &secp256k1fx.TransferOutput{Amt: units.Avax},
+		}
+	}
+
+	// Simulates a crash by opening a new state on [db], which only includes
+	// the flushed writes, and checks that it includes exactly the first
+	// [numFlushedUTXOs] of [utxos].
+	requireStateAfterCrash := func(lastAccepted ids.ID, utxos []*avax.UTXO, numFlushedUTXOs int) {
+		lastAcceptedAfterCrash, err := database.GetID(prefixdb.New(singletonPrefix, db), lastAcceptedKey)
+		require.NoError(err)
+		require.Equal(lastAccepted, lastAcceptedAfterCrash)
+
+		crashed := newStateFromDB(require, db)
+		for i, utxo := range utxos {
+			_, err := crashed.GetUTXO(utxo.InputID())
+			if i < numFlushedUTXOs {
+				require.NoError(err)
+			} else {
+				require.ErrorIs(err, database.ErrNotFound)
+			}
+		}
+	}
+
+	var (
+		blkIDs = []ids.ID{ids.GenerateTestID(), ids.GenerateTestID()}
+		utxos  = []*avax.UTXO{newUTXO(), newUTXO()}
+	)
+
+	s.AddUTXO(utxos[0])
+	s.SetLastAccepted(blkIDs[0])
+	require.NoError(s.Commit())
+	require.Equal(1, s.(*state).numDeferredCommits)
+	requireStateAfterCrash(genesisID, utxos, 0)
+
+	// Aborting the changes of a failed execution must not discard the
+	// deferred commit.
+	s.AddUTXO(newUTXO())
+	s.Abort()
+	_, err := s.GetUTXO(utxos[0].InputID())
+	require.NoError(err)
+	require.Equal(blkIDs[0], s.GetLastAccepted())
+	require.Equal(1, s.(*state).numDeferredCommits)
+	requireStateAfterCrash(genesisID, utxos, 0)
+
+	// CommitBatch flushes the deferred commit before creating the batch.
+	s.AddUTXO(utxos[1])
+	s.SetLastAccepted(blkIDs[1])
+	batch, err := s.CommitBatch()
+	require.NoError(err)
+	require.Zero(s.(*state).numDeferredCommits)
+	require.Zero(s.(*state).firstDeferredCommitTime)
+	requireStateAfterCrash(blkIDs[0], utxos, 1)
+
+	require.NoError(batch.Write())
+	s.Abort()
+	requireStateAfterCrash(blkIDs[1], utxos, 2)
+}
+
+func TestStateDeferredCommitsDuration(t *testing.T) {
+	require := require.New(t)
+
+	s, db := newInitializedState(require)
+	execCfg := s.(*state).execCfg
+	execCfg.MaxDeferredCommits = 100
+	execCfg.MaxDeferredCommitDuration = time.Millisecond
+
+	blkID := ids.GenerateTestID()
+	s.SetLastAccepted(blkID)
+	require.NoError(s.Commit())
+	require.Equal(1, s.(*state).numDeferredCommits)
+
+	// Once the oldest deferred commit is older than the max duration, the
+	// next commit flushes.
+	time.Sleep(2 * execCfg.MaxDeferredCommitDuration)
+	require.NoError(s.Commit())
+	require.Zero(s.(*state).numDeferredCommits)
+
+	lastAccepted, err := database.GetID(prefixdb.New(singletonPrefix, db), lastAcceptedKey)
+	require.NoError(err)
+	require.Equal(blkID, lastAccepted)
+}
+
+func TestStateCloseFlushesDeferredCommits(t *testing.T) {
+	require := require.New(t)
+
+	s, db := newInitializedState(require)
+	s.(*state).execCfg.MaxDeferredCommits = 10
+
+	blkID := ids.GenerateTestID()
+	s.SetLastAccepted(blkID)
+	require.NoError(s.Commit())
+	require.NoError(s.Close())
+
+	lastAccepted, err := database.GetID(prefixdb.New(singletonPrefix, db), lastAcceptedKey)
+	require.NoError(err)
+	require.Equal(blkID, lastAccepted)
+}
+
+// sectionSizeMetrics records the reported size of each state section.
+type sectionSizeMetrics struct {
+	metrics.Metrics
+
+	sizes map[string]int64
+}
+
+func (m *sectionSizeMetrics) SetSectionSize(section string, size int64) {
+	m.sizes[section] = size
+}
+
+func TestStateSectionSizes(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+	require.NoError(s.Commit())
+	m := &sectionSizeMetrics{
+		Metrics: metrics.Noop,
+		sizes:   make(map[string]int64),
+	}
+	s.(*state).metrics = m
+
+	sizes, err := s.SectionSizes()
+	require.NoError(err)
+	require.Len(sizes, len(stateSections))
+	require.Equal(sizes, m.sizes)
+
+	// The genesis populates these sections
+	for _, section := range []string{"blocks", "currentStakers", "validatorDiffs", "txs", "utxos", "singletons"} {
+		require.Positive(sizes[section], section)
+	}
+	for _, section := range []string{"pendingStakers", "subnets"} {
+		require.Zero(sizes[section], section)
+	}
+
+	// The sizes are cached
+	s.AddUTXO(&avax.UTXO{
+		UTXOID: avax.UTXOID{TxID: ids.GenerateTestID()},
+		Asset:  avax.Asset{ID: ids.GenerateTestID()},
+		Out:    &secp256k1fx.TransferOutput{Amt: units.Avax},
+	})
+	require.NoError(s.Commit())
+
+	cachedSizes, err := s.SectionSizes()
+	require.NoError(err)
+	require.Equal(sizes, cachedSizes)
+
+	s.(*state).sectionSizesTime = time.Time{}
+	newSizes, err := s.SectionSizes()
+	require.NoError(err)
+	require.Greater(newSizes["utxos"], sizes["utxos"])
+	require.Equal(newSizes, m.sizes)
+
+	// The uncommitted changes aren't included
+	s.AddUTXO(&avax.UTXO{
+		UTXOID: avax.UTXOID{TxID: ids.GenerateTestID()},
+		Asset:  avax.Asset{ID: ids.GenerateTestID()},
+		Out:    &secp256k1fx.TransferOutput{Amt: units.Avax},
+	})
+	require.NoError(s.(*state).write(false /*=updateValidators*/, 0))
+
+	s.(*state).sectionSizesTime = time.Time{}
+	uncommittedSizes, err := s.SectionSizes()
+	require.NoError(err)
+	require.Equal(newSizes, uncommittedSizes)
+}
+
+func TestStateIsCurrentValidator(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+
+	var (
+		subnetID        = ids.GenerateTestID()
+		validatorNodeID = ids.GenerateTestNodeID()
+		delegatorNodeID = ids.GenerateTestNodeID()
+		absentNodeID    = ids.GenerateTestNodeID()
+
+		validator = &Staker{
+			TxID:     ids.GenerateTestID(),
+			NodeID:   validatorNodeID,
+			SubnetID: subnetID,
+			Weight:   units.Avax,
+		}
+		delegator = &Staker{
+			TxID:     ids.GenerateTestID(),
+			NodeID:   delegatorNodeID,
+			SubnetID: subnetID,
+			Weight:   units.Avax,
+		}
+	)
+	s.PutCurrentValidator(validator)
+	s.PutCurrentDelegator(delegator)
+
+	// The genesis validator is a primary network validator
+	require.True(s.IsCurrentValidator(constants.PrimaryNetworkID, initialNodeID))
+	require.False(s.IsCurrentValidator(subnetID, initialNodeID))
+
+	require.True(s.IsCurrentValidator(subnetID, validatorNodeID))
+	require.False(s.IsCurrentValidator(constants.PrimaryNetworkID, validatorNodeID))
+
+	// Delegators aren't validators
+	require.False(s.IsCurrentValidator(subnetID, delegatorNodeID))
+
+	require.False(s.IsCurrentValidator(subnetID, absentNodeID))
+	require.False(s.IsCurrentValidator(ids.GenerateTestID(), absentNodeID))
+
+	s.DeleteCurrentValidator(validator)
+	require.False(s.IsCurrentValidator(subnetID, validatorNodeID))
+}
+
+func TestStateAddUTXOs(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+	require.NoError(s.Commit())
+
+	utxos := []*avax.UTXO{
+		{
+			UTXOID: avax.UTXOID{TxID: ids.GenerateTestID()},
+			Asset:  avax.Asset{ID: ids.GenerateTestID()},
+			Out:    &secp256k1fx.TransferOutput{Amt: units.Avax},
+		},
+		{
+			UTXOID: avax.UTXOID{TxID: ids.GenerateTestID()},
+			Asset:  avax.Asset{ID: ids.GenerateTestID()},
+			Out:    &secp256k1fx.TransferOutput{Amt: 2 * units.Avax},
+		},
+	}
+	s.AddUTXOs(utxos)
+	for _, utxo := range utxos {
+		gotUTXO, err := s.GetUTXO(utxo.InputID())
+		require.NoError(err)
+		require.Equal(utxo, gotUTXO)
+	}
+
+	require.NoError(s.Commit())
+	for _, utxo := range utxos {
+		gotUTXO, err := s.GetUTXO(utxo.InputID())
+		require.NoError(err)
+		require.Equal(utxo.InputID(), gotUTXO.InputID())
+	}
+}
+
+func TestStateAbortAndReload(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+	require.NoError(s.Commit())
+
+	initialUptime, initialLastUpdated, err := s.GetUptime(initialNodeID, constants.PrimaryNetworkID)
+	require.NoError(err)
+
+	// Apply changes that are never committed
+	staker := &Staker{
+		TxID:      ids.GenerateTestID(),
+		NodeID:    ids.GenerateTestNodeID(),
+		SubnetID:  constants.PrimaryNetworkID,
+		Weight:    units.Avax,
+		StartTime: initialTime,
+		EndTime:   initialTime.Add(time.Hour),
+		NextTime:  initialTime.Add(time.Hour),
+		Priority:  txs.PrimaryNetworkValidatorCurrentPriority,
+	}
+	s.PutCurrentValidator(staker)
+	s.DeleteCurrentValidator(&Staker{
+		TxID:     initialTxID,
+		NodeID:   initialNodeID,
+		SubnetID: constants.PrimaryNetworkID,
+	})
+	utxo := &avax.UTXO{
+		UTXOID: avax.UTXOID{TxID: ids.GenerateTestID()},
+		Asset:  avax.Asset{ID: ids.GenerateTestID()},
+		Out:    &secp256k1fx.TransferOutput{Amt: units.Avax},
+	}
+	s.AddUTXO(utxo)
+	s.SetTimestamp(initialTime.Add(time.Hour))
+	s.SetCurrentSupply(constants.PrimaryNetworkID, 1)
+	require.NoError(s.SetUptime(initialNodeID, constants.PrimaryNetworkID, time.Hour, initialTime.Add(time.Hour)))
+
+	require.NoError(s.AbortAndReload())
+
+	_, err = s.GetCurrentValidator(constants.PrimaryNetworkID, staker.NodeID)
+	require.ErrorIs(err, database.ErrNotFound)
+	_, err = s.GetCurrentValidator(constants.PrimaryNetworkID, initialNodeID)
+	require.NoError(err)
+
+	_, err = s.GetUTXO(utxo.InputID())
+	require.ErrorIs(err, database.ErrNotFound)
+
+	require.Equal(initialTime, s.GetTimestamp())
+
+	supply, err := s.GetCurrentSupply(constants.PrimaryNetworkID)
+	require.NoError(err)
+	require.NotEqual(uint64(1), supply)
+
+	uptime, lastUpdated, err := s.GetUptime(initialNodeID, constants.PrimaryNetworkID)
+	require.NoError(err)
+	require.Equal(initialUptime, uptime)
+	require.Equal(initialLastUpdated, lastUpdated)
+
+	// Nothing is written by the next commit
+	require.NoError(s.Commit())
+	_, err = s.GetCurrentValidator(constants.PrimaryNetworkID, initialNodeID)
+	require.NoError(err)
+}
+
+func TestStateAbortAndReloadAfterCommitBatch(t *testing.T) {
+	require := require.New(t)
+
+	s, db := newInitializedState(require)
+	require.NoError(s.Commit())
+	s.(*state).execCfg.MaxDeferredCommits = 1
+
+	newUTXO := func() *avax.UTXO {
+		return &avax.UTXO{
+			UTXOID: avax.UTXOID{TxID: ids.GenerateTestID()},
+			Asset:  avax.Asset{ID: ids.GenerateTestID()},
+			Out:    &secp256k1fx.TransferOutput{Amt: units.Avax},
+		}
+	}
+
+	deferredUTXO := newUTXO()
+	s.AddUTXO(deferredUTXO)
+	require.NoError(s.Commit())
+	require.Equal(1, s.(*state).numDeferredCommits)
+
+	// The batch is never written, but the UTXO is cached by the UTXO state.
+	utxo := newUTXO()
+	s.AddUTXO(utxo)
+	_, err := s.CommitBatch()
+	require.NoError(err)
+
+	require.NoError(s.AbortAndReload())
+	require.Zero(s.(*state).numDeferredCommits)
+
+	_, err = s.GetUTXO(utxo.InputID())
+	require.ErrorIs(err, database.ErrNotFound)
+	_, err = s.GetUTXO(deferredUTXO.InputID())
+	require.NoError(err)
+
+	// The deferred commit was flushed to the database.
+	_, err = newStateFromDB(require, db).GetUTXO(deferredUTXO.InputID())
+	require.NoError(err)
+}
+
+func TestStateDeleteUTXOsForAddress(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+	require.NoError(s.Commit())
+
+	var (
+		addr      = ids.GenerateTestShortID()
+		otherAddr = ids.GenerateTestShortID()
+	)
+	newUTXO := func(owner ids.ShortID) *avax.UTXO {
+		return &avax.UTXO{
+			UTXOID: avax.UTXOID{TxID: ids.GenerateTestID()},
+			Asset:  avax.Asset{ID: ids.GenerateTestID()},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: units.Avax,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{owner},
+				},
+			},
+		}
+	}
+
+	committedUTXOs := []*avax.UTXO{
+		newUTXO(addr),
+		newUTXO(addr),
+	}
+	otherUTXO := newUTXO(otherAddr)
+	s.AddUTXOs(append(committedUTXOs, otherUTXO))
+	require.NoError(s.Commit())
+
+	// UTXOs that haven't been committed are deleted as well
+	pendingUTXO := newUTXO(addr)
+	s.AddUTXO(pendingUTXO)
+
+	numDeleted, err := s.DeleteUTXOsForAddress(addr[:])
+	require.NoError(err)
+	require.Equal(3, numDeleted)
+
+	// Deleting again doesn't count the UTXOs that are pending deletion
+	numDeleted, err = s.DeleteUTXOsForAddress(addr[:])
+	require.NoError(err)
+	require.Zero(numDeleted)
+
+	require.NoError(s.Commit())
+
+	utxoIDs, err := s.UTXOIDs(addr[:], ids.Empty, math.MaxInt)
+	require.NoError(err)
+	require.Empty(utxoIDs)
+
+	for _, utxo := range append(committedUTXOs, pendingUTXO) {
+		_, err := s.GetUTXO(utxo.InputID())
+		require.ErrorIs(err, database.ErrNotFound)
+	}
+
+	utxoIDs, err = s.UTXOIDs(otherAddr[:], ids.Empty, math.MaxInt)
+	require.NoError(err)
+	require.Equal([]ids.ID{otherUTXO.InputID()}, utxoIDs)
+}
+
+func TestStateGetNextPendingStaker(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+
+	_, err := s.GetNextPendingStaker()
+	require.ErrorIs(err, ErrNoPendingStakers)
+
+	newPendingStaker := func(txID ids.ID, startTime time.Time) *Staker {
+		return &Staker{
+			TxID:      txID,
+			NodeID:    ids.GenerateTestNodeID(),
+			SubnetID:  constants.PrimaryNetworkID,
+			Weight:    units.Avax,
+			StartTime: startTime,
+			EndTime:   startTime.Add(time.Hour),
+			NextTime:  startTime,
+			Priority:  txs.PrimaryNetworkValidatorPendingPriority,
+		}
+	}
+	var (
+		earliestStartTime = initialTime.Add(time.Minute)
+
+		late  = newPendingStaker(ids.ID{0}, earliestStartTime.Add(2*time.Minute))
+		early = newPendingStaker(ids.ID{2}, earliestStartTime)
+		// Starts at the same time as [early], but has a smaller tx ID.
+		earlyTie = newPendingStaker(ids.ID{1}, earliestStartTime)
+	)
+
+	s.PutPendingValidator(late)
+	next, err := s.GetNextPendingStaker()
+	require.NoError(err)
+	require.Equal(late, next)
+
+	s.PutPendingValidator(early)
+	next, err = s.GetNextPendingStaker()
+	require.NoError(err)
+	require.Equal(early, next)
+
+	s.PutPendingValidator(earlyTie)
+	next, err = s.GetNextPendingStaker()
+	require.NoError(err)
+	require.Equal(earlyTie, next)
+
+	s.DeletePendingValidator(earlyTie)
+	s.DeletePendingValidator(early)
+	next, err = s.GetNextPendingStaker()
+	require.NoError(err)
+	require.Equal(late, next)
+}
+
+func TestStateGetValidatorRewardInfo(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+
+	_, _, err := s.GetValidatorRewardInfo(constants.PrimaryNetworkID, ids.GenerateTestNodeID())
+	require.ErrorIs(err, database.ErrNotFound)
+
+	staker, err := s.GetCurrentValidator(constants.PrimaryNetworkID, initialNodeID)
+	require.NoError(err)
+
+	const delegateeReward = 12345
+	require.NoError(s.SetDelegateeReward(constants.PrimaryNetworkID, initialNodeID, delegateeReward))
+
+	potential, delegatee, err := s.GetValidatorRewardInfo(constants.PrimaryNetworkID, initialNodeID)
+	require.NoError(err)
+	require.Equal(staker.PotentialReward, potential)
+	require.Equal(uint64(delegateeReward), delegatee)
+}
+
+func TestStateOldestValidatorDiffHeight(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+	internalState := s.(*state)
+
+	// The genesis validators are added at height 0.
+	height, ok, err := s.OldestValidatorDiffHeight(constants.PrimaryNetworkID)
+	require.NoError(err)
+	require.True(ok)
+	require.Zero(height)
+
+	subnetID := ids.GenerateTestID()
+	_, ok, err = s.OldestValidatorDiffHeight(subnetID)
+	require.NoError(err)
+	require.False(ok)
+
+	diff := marshalWeightDiff(&ValidatorWeightDiff{
+		Amount: units.Avax,
+	})
+	for _, height := range []uint64{100, 7, 12345, 8} {
+		require.NoError(internalState.flatValidatorWeightDiffsDB.Put(
+			marshalDiffKey(subnetID, height, ids.GenerateTestNodeID()),
+			diff,
+		))
+	}
+
+	height, ok, err = s.OldestValidatorDiffHeight(subnetID)
+	require.NoError(err)
+	require.True(ok)
+	require.Equal(uint64(7), height)
+
+	// Diffs of other subnets shouldn't be reported.
+	height, ok, err = s.OldestValidatorDiffHeight(constants.PrimaryNetworkID)
+	require.NoError(err)
+	require.True(ok)
+	require.Zero(height)
+}
+
+func TestStateVerifyValidatorDiffChain(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+	internalState := s.(*state)
+
+	parentID := s.GetLastAccepted()
+	for height := uint64(1); height <= 3; height++ {
+		blk, err := block.NewApricotCommitBlock(parentID, height)
+		require.NoError(err)
+
+		require.NoError(s.AddStatelessBlock(blk))
+		s.SetLastAccepted(blk.ID())
+		s.SetHeight(height)
+		require.NoError(s.Commit())
+		parentID = blk.ID()
+	}
+
+	var (
+		subnetID = ids.GenerateTestID()
+		nodeID0  = ids.GenerateTestNodeID()
+		nodeID1  = ids.GenerateTestNodeID()
+	)
+
+	// [nodeID0] was removed at height 3.
+	require.NoError(internalState.flatValidatorWeightDiffsDB.Put(
+		marshalDiffKey(subnetID, 3, nodeID0),
+		marshalWeightDiff(&ValidatorWeightDiff{
+			Decrease: true,
+			Amount:   units.Avax,
+		}),
+	))
+	require.NoError(s.VerifyValidatorDiffChain(context.Background(), subnetID, 3, 0))
+
+	// [nodeID1] is reported to have been added at height 2, but it was never
+	// in the validator set.
+	require.NoError(internalState.flatValidatorWeightDiffsDB.Put(
+		marshalDiffKey(subnetID, 2, nodeID1),
+		marshalWeightDiff(&ValidatorWeightDiff{
+			Decrease: false,
+			Amount:   units.Avax,
+		}),
+	))
+	require.NoError(s.VerifyValidatorDiffChain(context.Background(), subnetID, 3, 2))
+
+	err := s.VerifyValidatorDiffChain(context.Background(), subnetID, 3, 0)
+	require.ErrorIs(err, errInconsistentValidatorDiffs)
+	require.ErrorIs(err, safemath.ErrUnderflow)
+
+	// The diffs above [startHeight] are applied before verifying the
+	// validator sets from [startHeight] down.
+	err = s.VerifyValidatorDiffChain(context.Background(), subnetID, 2, 0)
+	require.ErrorIs(err, errInconsistentValidatorDiffs)
+	require.ErrorIs(err, safemath.ErrUnderflow)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = s.VerifyValidatorDiffChain(ctx, subnetID, 3, 0)
+	require.ErrorIs(err, context.Canceled)
+
+	err = s.VerifyValidatorDiffChain(context.Background(), subnetID, 4, 0)
+	require.ErrorIs(err, errInvalidValidatorDiffsRange)
+
+	err = s.VerifyValidatorDiffChain(context.Background(), subnetID, 1, 2)
+	require.ErrorIs(err, errInvalidValidatorDiffsRange)
+}
+
+func TestStateGetGenesisBlockID(t *testing.T) {
+	require := require.New(t)
+
+	s, db := newInitializedState(require)
+	genesisBlkID := s.GetLastAccepted()
+
+	blkID, err := s.GetGenesisBlockID()
+	require.NoError(err)
+	require.Equal(genesisBlkID, blkID)
+
+	blk, err := block.NewApricotCommitBlock(genesisBlkID, 1)
+	require.NoError(err)
+	require.NoError(s.AddStatelessBlock(blk))
+	s.SetLastAccepted(blk.ID())
+	s.SetHeight(1)
+	require.NoError(s.Commit())
+
+	blkID, err = s.GetGenesisBlockID()
+	require.NoError(err)
+	require.Equal(genesisBlkID, blkID)
+
+	// The genesis block ID is read from disk after a restart.
+	s = newStateFromDB(require, db)
+	blkID, err = s.GetGenesisBlockID()
+	require.NoError(err)
+	require.Equal(genesisBlkID, blkID)
+}
+
+func TestStateGetSubnet(t *testing.T) {
+	require := require.New(t)
+
+	s, db := newInitializedState(require)
+
+	newCreateSubnetTx := func(i uint64) *txs.Tx {
+		createSubnetTx := &txs.Tx{Unsigned: &txs.CreateSubnetTx{
+			Owner: &secp256k1fx.OutputOwners{Threshold: 1, Locktime: i},
+		}}
+		require.NoError(createSubnetTx.Initialize(txs.Codec))
+		return createSubnetTx
+	}
+
+	var (
+		committedSubnetTx = newCreateSubnetTx(0)
+		addedSubnetTx     = newCreateSubnetTx(1)
+	)
+	s.AddSubnet(committedSubnetTx)
+	s.AddTx(committedSubnetTx, status.Committed)
+	require.NoError(s.Commit())
+
+	s = newStateFromDB(require, db)
+	s.AddSubnet(addedSubnetTx)
+	s.AddTx(addedSubnetTx, status.Committed)
+
+	subnets, err := s.GetSubnets()
+	require.NoError(err)
+	for _, subnetTx := range subnets {
+		gotSubnetTx, err := s.GetSubnet(subnetTx.ID())
+		require.NoError(err)
+		require.Equal(subnetTx, gotSubnetTx)
+	}
+
+	// Unknown subnets aren't found
+	_, err = s.GetSubnet(ids.GenerateTestID())
+	require.ErrorIs(err, database.ErrNotFound)
+
+	// Txs that aren't subnets aren't found
+	chainTx := &txs.Tx{Unsigned: &txs.CreateChainTx{
+		SubnetID:   committedSubnetTx.ID(),
+		SubnetAuth: &secp256k1fx.Input{},
+	}}
+	require.NoError(chainTx.Initialize(txs.Codec))
+	s.AddTx(chainTx, status.Committed)
+	require.NoError(s.Commit())
+
+	_, err = s.GetSubnet(chainTx.ID())
+	require.ErrorIs(err, database.ErrNotFound)
+}
+
+// BenchmarkStateGetSubnet compares looking up a single subnet with GetSubnet
+// against scanning the result of GetSubnets. Every iteration uses a new state
+// so that no lookups are served from the caches.
+func BenchmarkStateGetSubnet(b *testing.B) {
+	const numSubnets = 1000
+
+	require := require.New(b)
+
+	s, db := newInitializedState(require)
+	var subnetID ids.ID
+	for i := 0; i < numSubnets; i++ {
+		createSubnetTx := &txs.Tx{Unsigned: &txs.CreateSubnetTx{
+			Owner: &secp256k1fx.OutputOwners{Threshold: 1, Locktime: uint64(i)},
+		}}
+		require.NoError(createSubnetTx.Initialize(txs.Codec))
+		s.AddSubnet(createSubnetTx)
+		s.AddTx(createSubnetTx, status.Committed)
+		subnetID = createSubnetTx.ID()
+	}
+	require.NoError(s.Commit())
+
+	b.Run("GetSubnet", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			s := newStateFromDB(require, db)
+			b.StartTimer()
+
+			_, err := s.GetSubnet(subnetID)
+			require.NoError(err)
+		}
+	})
+	b.Run("GetSubnets", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			s := newStateFromDB(require, db)
+			b.StartTimer()
+
+			subnets, err := s.GetSubnets()
+			require.NoError(err)
+
+			var found bool
+			for _, subnetTx := range subnets {
+				if subnetTx.ID() == subnetID {
+					found = true
+					break
+				}
+			}
+			require.True(found)
+		}
+	})
+}
+
+func TestStateUptimeFlushFrequency(t *testing.T) {
+	require := require.New(t)
+
+	s, db := newInitializedState(require)
+	s.(*state).execCfg.UptimeFlushFrequency = time.Hour
+
+	loadUptime := func() time.Duration {
+		reloaded := newStateFromDB(require, db).(*state)
+		require.NoError(reloaded.load())
+		upDuration, _, err := reloaded.GetUptime(initialNodeID, constants.PrimaryNetworkID)
+		require.NoError(err)
+		return upDuration
+	}
+
+	// The uptimes were written when the genesis was committed, so uptime
+	// updates are deferred.
+	require.NoError(s.SetUptime(initialNodeID, constants.PrimaryNetworkID, 2*time.Minute, initialTime))
+	require.NoError(s.Commit())
+	require.Zero(loadUptime())
+
+	// Unless there are too many pending uptime updates.
+	s.(*state).execCfg.MaxPendingUptimeUpdates = 1
+	require.NoError(s.Commit())
+	require.Equal(2*time.Minute, loadUptime())
+	s.(*state).execCfg.MaxPendingUptimeUpdates = 0
+
+	// The uptime of a validator that is due to be rewarded is always written.
+	require.NoError(s.SetUptime(initialNodeID, constants.PrimaryNetworkID, 3*time.Minute, initialTime))
+	s.SetTimestamp(initialValidatorEndTime)
+	require.NoError(s.Commit())
+	require.Equal(3*time.Minute, loadUptime())
+
+	// Closing the state writes the pending uptime updates.
+	s.SetTimestamp(initialTime)
+	require.NoError(s.Commit())
+	require.NoError(s.SetUptime(initialNodeID, constants.PrimaryNetworkID, 4*time.Minute, initialTime))
+	require.NoError(s.Commit())
+	require.Equal(3*time.Minute, loadUptime())
+
+	require.NoError(s.Close())
+	require.Equal(4*time.Minute, loadUptime())
+}
+
+// countingLinkedDB counts the number of writes to the wrapped database.
+type countingLinkedDB struct {
+	linkeddb.LinkedDB
+
+	numPuts int
+}
+
+func (db *countingLinkedDB) Put(key, value []byte) error {
+	db.numPuts++
+	return db.LinkedDB.Put(key, value)
+}
+
+// BenchmarkStateUptimeWrites reports the number of validator metadata writes
+// per commit when an uptime is updated on every commit.
+func BenchmarkStateUptimeWrites(b *testing.B) {
+	tests := map[string]time.Duration{
+		"every commit": 0,
+		"deferred":     time.Minute,
+	}
+	for name, uptimeFlushFrequency := range tests {
+		b.Run(name, func(b *testing.B) {
+			require := require.New(b)
+
+			s, _ := newInitializedState(require)
+			st := s.(*state)
+			st.execCfg.UptimeFlushFrequency = uptimeFlushFrequency
+
+			db := &countingLinkedDB{
+				LinkedDB: st.currentValidatorList,
+			}
+			st.currentValidatorList = db
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				require.NoError(s.SetUptime(
+					initialNodeID,
+					constants.PrimaryNetworkID,
+					time.Duration(i),
+					initialTime,
+				))
+				require.NoError(s.Commit())
+			}
+			b.StopTimer()
+
+			b.ReportMetric(float64(db.numPuts)/float64(b.N), "puts/op")
+		})
+	}
+}
+
+func TestStateCurrentStakerIteratorSameEndTime(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	const numStakers = 10
+
+	state, _ := newInitializedState(require)
+
+	lastAcceptedID := ids.GenerateTestID()
+	versions := NewMockVersions(ctrl)
+	versions.EXPECT().GetState(lastAcceptedID).AnyTimes().Return(state, true)
+
+	d, err := NewDiff(lastAcceptedID, versions)
+	require.NoError(err)
+
+	var (
+		startTime = initialTime
+		endTime   = initialValidatorEndTime.Add(time.Hour)
+	)
+	for i := 0; i < numStakers; i++ {
+		staker := &Staker{
+			TxID:      ids.GenerateTestID(),
+			NodeID:    ids.GenerateTestNodeID(),
+			SubnetID:  constants.PrimaryNetworkID,
+			Weight:    1,
+			StartTime: startTime,
+			EndTime:   endTime,
+			NextTime:  endTime,
+			Priority:  txs.PrimaryNetworkValidatorCurrentPriority,
+		}
+
+		// Split the stakers between the base state and the diff to ensure the
+		// ordering is preserved when the iterators are merged.
+		if i%2 == 0 {
+			state.PutCurrentValidator(staker)
+		} else {
+			d.PutCurrentValidator(staker)
+		}
+	}
+
+	sameEndTimeTxIDs := func(chain Chain) []ids.ID {
+		it, err := chain.GetCurrentStakerIterator()
+		require.NoError(err)
+		defer it.Release()
+
+		var txIDs []ids.ID
+		for it.Next() {
+			staker := it.Value()
+			if staker.EndTime.Equal(endTime) {
+				txIDs = append(txIDs, staker.TxID)
+			}
+		}
+		return txIDs
+	}
+
+	stateTxIDs := sameEndTimeTxIDs(state)
+	require.Len(stateTxIDs, numStakers/2)
+	require.True(utils.IsSortedAndUnique(stateTxIDs))
+
+	diffTxIDs := sameEndTimeTxIDs(d)
+	require.Len(diffTxIDs, numStakers)
+	require.True(utils.IsSortedAndUnique(diffTxIDs))
+
+	// Iterating again must yield the same order.
+	require.Equal(diffTxIDs, sameEndTimeTxIDs(d))
+}
+
+func TestStatePreviewReward(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+
+	const (
+		stakeAmount   = 2 * units.KiloAvax
+		stakeDuration = 365 * 24 * time.Hour
+	)
+	currentSupply, err := s.GetCurrentSupply(constants.PrimaryNetworkID)
+	require.NoError(err)
+	expectedReward := s.(*state).rewards.Calculate(stakeDuration, stakeAmount, currentSupply)
+	require.Positive(expectedReward)
+
+	previewedReward, err := s.PreviewReward(stakeAmount, stakeDuration, constants.PrimaryNetworkID)
+	require.NoError(err)
+	require.Equal(expectedReward, previewedReward)
+
+	// The reward depends on the current supply
+	s.SetCurrentSupply(constants.PrimaryNetworkID, currentSupply+units.MegaAvax)
+	newReward, err := s.PreviewReward(stakeAmount, stakeDuration, constants.PrimaryNetworkID)
+	require.NoError(err)
+	require.Less(newReward, previewedReward)
+
+	_, err = s.PreviewReward(stakeAmount, stakeDuration, ids.GenerateTestID())
+	require.ErrorIs(err, errUnsupportedRewardSubnet)
+}
+
+func TestStateGetTotalPotentialReward(t *testing.T) {
+	require := require.New(t)
+
+	state, _ := newInitializedState(require)
+
+	// The genesis validator may have a potential reward.
+	initialReward, err := state.GetTotalPotentialReward(constants.PrimaryNetworkID)
+	require.NoError(err)
+
+	var (
+		subnetID = ids.GenerateTestID()
+		nodeID   = ids.GenerateTestNodeID()
+	)
+	newStaker := func(subnetID ids.ID, priority txs.Priority, potentialReward uint64) *Staker {
+		return &Staker{
+			TxID:            ids.GenerateTestID(),
+			NodeID:          nodeID,
+			SubnetID:        subnetID,
+			Weight:          1,
+			StartTime:       initialTime,
+			EndTime:         initialValidatorEndTime,
+			PotentialReward: potentialReward,
+			NextTime:        initialValidatorEndTime,
+			Priority:        priority,
+		}
+	}
+
+	state.PutCurrentValidator(newStaker(constants.PrimaryNetworkID, txs.PrimaryNetworkValidatorCurrentPriority, 10))
+	state.PutCurrentDelegator(newStaker(constants.PrimaryNetworkID, txs.PrimaryNetworkDelegatorCurrentPriority, 5))
+	state.PutCurrentValidator(newStaker(subnetID, txs.SubnetPermissionlessValidatorCurrentPriority, 7))
+
+	primaryReward, err := state.GetTotalPotentialReward(constants.PrimaryNetworkID)
+	require.NoError(err)
+	require.Equal(initialReward+15, primaryReward)
+
+	subnetReward, err := state.GetTotalPotentialReward(subnetID)
+	require.NoError(err)
+	require.Equal(uint64(7), subnetReward)
+
+	emptyReward, err := state.GetTotalPotentialReward(ids.GenerateTestID())
+	require.NoError(err)
+	require.Zero(emptyReward)
+
+	state.PutCurrentDelegator(newStaker(subnetID, txs.SubnetPermissionlessDelegatorCurrentPriority, math.MaxUint64))
+
+	_, err = state.GetTotalPotentialReward(subnetID)
+	require.ErrorIs(err, safemath.ErrOverflow)
+}
+
+func TestStateGetPendingSupplyDelta(t *testing.T) {
+	require := require.New(t)
+
+	state, _ := newInitializedState(require)
+
+	_, ok, err := state.GetPendingSupplyDelta(constants.PrimaryNetworkID)
+	require.NoError(err)
+	require.False(ok)
+
+	primarySupply, err := state.GetCurrentSupply(constants.PrimaryNetworkID)
+	require.NoError(err)
+	state.SetCurrentSupply(constants.PrimaryNetworkID, primarySupply-10)
+
+	delta, ok, err := state.GetPendingSupplyDelta(constants.PrimaryNetworkID)
+	require.NoError(err)
+	require.True(ok)
+	require.Equal(int64(-10), delta)
+
+	// A subnet without a persisted supply is compared against a zero supply.
+	subnetID := ids.GenerateTestID()
+	_, ok, err = state.GetPendingSupplyDelta(subnetID)
+	require.NoError(err)
+	require.False(ok)
+
+	state.SetCurrentSupply(subnetID, 100)
+
+	delta, ok, err = state.GetPendingSupplyDelta(subnetID)
+	require.NoError(err)
+	require.True(ok)
+	require.Equal(int64(100), delta)
+
+	require.NoError(state.Commit())
+
+	_, ok, err = state.GetPendingSupplyDelta(constants.PrimaryNetworkID)
+	require.NoError(err)
+	require.False(ok)
+
+	_, ok, err = state.GetPendingSupplyDelta(subnetID)
+	require.NoError(err)
+	require.False(ok)
+
+	state.SetCurrentSupply(subnetID, 90)
+
+	delta, ok, err = state.GetPendingSupplyDelta(subnetID)
+	require.NoError(err)
+	require.True(ok)
+	require.Equal(int64(-10), delta)
+
+	// Subnet supplies may exceed the range of an int64.
+	state.SetCurrentSupply(subnetID, math.MaxUint64)
+
+	_, ok, err = state.GetPendingSupplyDelta(subnetID)
+	require.ErrorIs(err, safemath.ErrOverflow)
+	require.False(ok)
+
+	require.NoError(state.Commit())
+	state.SetCurrentSupply(subnetID, 0)
+
+	_, ok, err = state.GetPendingSupplyDelta(subnetID)
+	require.ErrorIs(err, safemath.ErrUnderflow)
+	require.False(ok)
+
+	state.SetCurrentSupply(subnetID, math.MaxUint64+math.MinInt64)
+
+	delta, ok, err = state.GetPendingSupplyDelta(subnetID)
+	require.NoError(err)
+	require.True(ok)
+	require.Equal(int64(math.MinInt64), delta)
+}
+
+func TestStateGetUTXOWithSource(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+
+	utxo := &avax.UTXO{
+		UTXOID: avax.UTXOID{
+			TxID: ids.GenerateTestID(),
+		},
+		Asset: avax.Asset{ID: initialTxID},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: units.MilliAvax,
+		},
+	}
+	utxoID := utxo.InputID()
+
+	// The first lookup of a missing UTXO reaches the database and caches the
+	// miss.
+	_, source, err := s.GetUTXOWithSource(utxoID)
+	require.ErrorIs(err, database.ErrNotFound)
+	require.Equal(UTXOSourceDatabase, source)
+
+	_, source, err = s.GetUTXOWithSource(utxoID)
+	require.ErrorIs(err, database.ErrNotFound)
+	require.Equal(UTXOSourceCache, source)
+
+	s.AddUTXO(utxo)
+	fetchedUTXO, source, err := s.GetUTXOWithSource(utxoID)
+	require.NoError(err)
+	require.Equal(UTXOSourcePending, source)
+	require.Equal(utxo, fetchedUTXO)
+
+	require.NoError(s.Commit())
+	fetchedUTXO, source, err = s.GetUTXOWithSource(utxoID)
+	require.NoError(err)
+	require.Equal(UTXOSourceCache, source)
+	require.Equal(utxo, fetchedUTXO)
+
+	s.DeleteUTXO(utxoID)
+	_, source, err = s.GetUTXOWithSource(utxoID)
+	require.ErrorIs(err, database.ErrNotFound)
+	require.Equal(UTXOSourcePending, source)
+}
+
+func TestStateAddStatelessBlockVerifyLinkage(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+	s.(*state).execCfg.VerifyBlockLinkage = true
+
+	genesisBlkID := s.GetLastAccepted()
+
+	orphan, err := block.NewApricotCommitBlock(ids.GenerateTestID(), 1)
+	require.NoError(err)
+	err = s.AddStatelessBlock(orphan)
+	require.ErrorIs(err, errUnknownParentBlock)
+
+	heightGap, err := block.NewApricotCommitBlock(genesisBlkID, 2)
+	require.NoError(err)
+	err = s.AddStatelessBlock(heightGap)
+	require.ErrorIs(err, errUnexpectedBlockHeight)
+
+	// Rejected blocks aren't added.
+	_, err = s.GetStatelessBlock(orphan.ID())
+	require.ErrorIs(err, database.ErrNotFound)
+	_, err = s.GetStatelessBlock(heightGap.ID())
+	require.ErrorIs(err, database.ErrNotFound)
+
+	blk, err := block.NewApricotCommitBlock(genesisBlkID, 1)
+	require.NoError(err)
+	require.NoError(s.AddStatelessBlock(blk))
+
+	// The parent may be a block that hasn't been committed yet.
+	child, err := block.NewApricotCommitBlock(blk.ID(), 2)
+	require.NoError(err)
+	require.NoError(s.AddStatelessBlock(child))
+}
+
+func TestStateHasBlock(t *testing.T) {
+	require := require.New(t)
+
+	s, db := newInitializedState(require)
+	genesisBlkID := s.GetLastAccepted()
+
+	has, err := s.HasBlock(genesisBlkID)
+	require.NoError(err)
+	require.True(has)
+
+	blk, err := block.NewApricotCommitBlock(genesisBlkID, 1)
+	require.NoError(err)
+	blkID := blk.ID()
+
+	// The block is remembered as missing.
+	has, err = s.HasBlock(blkID)
+	require.NoError(err)
+	require.False(has)
+	_, missing := s.(*state).missingBlocks.Get(blkID)
+	require.True(missing)
+
+	// Adding the block must replace the missing entry.
+	require.NoError(s.AddStatelessBlock(blk))
+	s.SetHeight(1)
+	require.NoError(s.Commit())
+
+	has, err = s.HasBlock(blkID)
+	require.NoError(err)
+	require.True(has)
+
+	// The blocks should be found in the database.
+	s = newStateFromDB(require, db)
+	for _, blkID := range []ids.ID{genesisBlkID, blkID} {
+		has, err := s.HasBlock(blkID)
+		require.NoError(err)
+		require.True(has)
+	}
+
+	has, err = s.HasBlock(ids.GenerateTestID())
+	require.NoError(err)
+	require.False(has)
+}
+
+func TestStateHasBlockLegacyFormat(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+	internalState := s.(*state)
+	genesisBlkID := s.GetLastAccepted()
+
+	// Blocks may be stored in the legacy format until pruning finishes.
+	require.NoError(internalState.singletonDB.Delete(prunedKey))
+
+	for _, status := range []choices.Status{choices.Processing, choices.Rejected, choices.Accepted} {
+		blk, err := block.NewApricotCommitBlock(genesisBlkID, uint64(status))
+		require.NoError(err)
+		blkID := blk.ID()
+
+		stBlkBytes, err := block.GenesisCodec.Marshal(block.Version, &stateBlk{
+			Bytes:  blk.Bytes(),
+			Status: status,
+		})
+		require.NoError(err)
+		require.NoError(internalState.blockDB.Put(blkID[:], stBlkBytes))
+
+		has, err := s.HasBlock(blkID)
+		require.NoError(err)
+		require.Equal(status == choices.Accepted, has)
+	}
+}
+
+func TestStateFindBlockIndexGaps(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+	internalState := s.(*state)
+	internalState.currentHeight = math.MaxUint64
+
+	// The genesis block at height 0 hasn't been committed, but isn't a gap.
+	for _, height := range []uint64{1, 2, 5, math.MaxUint64} {
+		require.NoError(database.PutID(internalState.blockIDDB, database.PackUInt64(height), ids.GenerateTestID()))
+	}
+
+	tests := []struct {
+		name         string
+		fromHeight   uint64
+		toHeight     uint64
+		expectedGaps []uint64
+	}{
+		{
+			name:         "gaps between and after indexed heights",
+			fromHeight:   0,
+			toHeight:     7,
+			expectedGaps: []uint64{3, 4, 6, 7},
+		},
+		{
+			name:       "contiguous range",
+			fromHeight: 0,
+			toHeight:   2,
+		},
+		{
+			name:       "single indexed height",
+			fromHeight: 5,
+			toHeight:   5,
+		},
+		{
+			name:         "single missing height",
+			fromHeight:   6,
+			toHeight:     6,
+			expectedGaps: []uint64{6},
+		},
+		{
+			name:         "range ending at the maximum height",
+			fromHeight:   math.MaxUint64 - 2,
+			toHeight:     math.MaxUint64,
+			expectedGaps: []uint64{math.MaxUint64 - 2, math.MaxUint64 - 1},
+		},
+		{
+			name:         "range missing the maximum height",
+			fromHeight:   math.MaxUint64 - 1,
+			toHeight:     math.MaxUint64 - 1,
+			expectedGaps: []uint64{math.MaxUint64 - 1},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gaps, err := s.FindBlockIndexGaps(test.fromHeight, test.toHeight)
+			require.NoError(err)
+			require.Equal(test.expectedGaps, gaps)
+		})
+	}
+
+	// Uncommitted blocks fill gaps.
+	internalState.addedBlockIDs[3] = ids.GenerateTestID()
+	gaps, err := s.FindBlockIndexGaps(0, 7)
+	require.NoError(err)
+	require.Equal([]uint64{4, 6, 7}, gaps)
+
+	// Heights above the current height aren't gaps.
+	internalState.currentHeight = 7
+	gaps, err = s.FindBlockIndexGaps(0, math.MaxUint64)
+	require.NoError(err)
+	require.Equal([]uint64{4, 6, 7}, gaps)
+
+	gaps, err = s.FindBlockIndexGaps(8, math.MaxUint64)
+	require.NoError(err)
+	require.Empty(gaps)
+
+	_, err = s.FindBlockIndexGaps(2, 1)
+	require.ErrorIs(err, errInvalidHeightRange)
+}
+
+func TestStateGetSubnetsToTrack(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+
+	var (
+		subnetID          = ids.GenerateTestID()
+		unrelatedSubnetID = ids.GenerateTestID()
+	)
+	newStaker := func(nodeID ids.NodeID, subnetID ids.ID) *Staker {
+		return &Staker{
+			TxID:      ids.GenerateTestID(),
+			NodeID:    nodeID,
+			SubnetID:  subnetID,
+			Weight:    1,
+			StartTime: initialTime,
+			EndTime:   initialValidatorEndTime,
+			NextTime:  initialValidatorEndTime,
+		}
+	}
+	s.PutCurrentValidator(newStaker(initialNodeID, subnetID))
+	s.PutCurrentValidator(newStaker(ids.GenerateTestNodeID(), unrelatedSubnetID))
+
+	subnetIDs, err := s.GetSubnetsToTrack(initialNodeID)
+	require.NoError(err)
+	require.Equal(set.Of(constants.PrimaryNetworkID, subnetID), subnetIDs)
+
+	// A node that isn't validating only tracks the primary network.
+	subnetIDs, err = s.GetSubnetsToTrack(ids.GenerateTestNodeID())
+	require.NoError(err)
+	require.Equal(set.Of(constants.PrimaryNetworkID), subnetIDs)
+}
+
+func TestStateGetSubnetsWithPendingStakers(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+
+	subnetIDs, err := s.GetSubnetsWithPendingStakers()
+	require.NoError(err)
+	require.Empty(subnetIDs)
+
+	var (
+		subnetID0 = ids.GenerateTestID()
+		subnetID1 = ids.GenerateTestID()
+	)
+	newStaker := func(subnetID ids.ID) *Staker {
+		return &Staker{
+			TxID:      ids.GenerateTestID(),
+			NodeID:    ids.GenerateTestNodeID(),
+			SubnetID:  subnetID,
+			Weight:    1,
+			StartTime: initialValidatorEndTime,
+			EndTime:   initialValidatorEndTime.Add(time.Hour),
+			NextTime:  initialValidatorEndTime,
+			Priority:  txs.SubnetPermissionedValidatorPendingPriority,
+		}
+	}
+	staker0 := newStaker(subnetID0)
+	s.PutPendingValidator(staker0)
+	s.PutPendingValidator(newStaker(subnetID1))
+	// Current stakers aren't reported.
+	s.PutCurrentValidator(newStaker(ids.GenerateTestID()))
+
+	expectedSubnetIDs := []ids.ID{subnetID0, subnetID1}
+	utils.Sort(expectedSubnetIDs)
+	subnetIDs, err = s.GetSubnetsWithPendingStakers()
+	require.NoError(err)
+	require.Equal(expectedSubnetIDs, subnetIDs)
+
+	s.DeletePendingValidator(staker0)
+	subnetIDs, err = s.GetSubnetsWithPendingStakers()
+	require.NoError(err)
+	require.Equal([]ids.ID{subnetID1}, subnetIDs)
+}
+
+func TestStateGetAggregatePublicKey(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+
+	// The genesis validator doesn't have a public key.
+	pk, weight, excludedWeight, err := s.GetAggregatePublicKey(constants.PrimaryNetworkID)
+	require.NoError(err)
+	require.Nil(pk)
+	require.Zero(weight)
+	require.Equal(units.Avax, excludedWeight)
+
+	sk0, err := bls.NewSecretKey()
+	require.NoError(err)
+	sk1, err := bls.NewSecretKey()
+	require.NoError(err)
+
+	var (
+		subnetID = ids.GenerateTestID()
+		nodeID0  = ids.GenerateTestNodeID()
+		nodeID1  = ids.GenerateTestNodeID()
+		pk0      = bls.PublicFromSecretKey(sk0)
+		pk1      = bls.PublicFromSecretKey(sk1)
+	)
+	newStaker := func(nodeID ids.NodeID, subnetID ids.ID, pk *bls.PublicKey, weight uint64) *Staker {
+		return &Staker{
+			TxID:      ids.GenerateTestID(),
+			NodeID:    nodeID,
+			PublicKey: pk,
+			SubnetID:  subnetID,
+			Weight:    weight,
+			StartTime: initialTime,
+			EndTime:   initialValidatorEndTime,
+			NextTime:  initialValidatorEndTime,
+		}
+	}
+	s.PutCurrentValidator(newStaker(nodeID0, constants.PrimaryNetworkID, pk0, 10))
+	s.PutCurrentDelegator(newStaker(nodeID0, constants.PrimaryNetworkID, nil, 5))
+	s.PutCurrentValidator(newStaker(nodeID1, constants.PrimaryNetworkID, pk1, 20))
+
+	// Subnet validators use the public keys registered on the primary network.
+	s.PutCurrentValidator(newStaker(nodeID0, subnetID, nil, 1))
+	s.PutCurrentValidator(newStaker(initialNodeID, subnetID, nil, 2))
+
+	expectedPrimaryPK, err := bls.AggregatePublicKeys([]*bls.PublicKey{pk0, pk1})
+	require.NoError(err)
+
+	pk, weight, excludedWeight, err = s.GetAggregatePublicKey(constants.PrimaryNetworkID)
+	require.NoError(err)
+	require.Equal(bls.PublicKeyToBytes(expectedPrimaryPK), bls.PublicKeyToBytes(pk))
+	require.Equal(uint64(35), weight)
+	require.Equal(units.Avax, excludedWeight)
+
+	pk, weight, excludedWeight, err = s.GetAggregatePublicKey(subnetID)
+	require.NoError(err)
+	require.Equal(bls.PublicKeyToBytes(pk0), bls.PublicKeyToBytes(pk))
+	require.Equal(uint64(1), weight)
+	require.Equal(uint64(2), excludedWeight)
+}
+
+func TestStateGetStakerTxBytes(t *testing.T) {
+	require := require.New(t)
+
+	s, db := newInitializedState(require)
+
+	staker, err := s.GetCurrentValidator(constants.PrimaryNetworkID, initialNodeID)
+	require.NoError(err)
+
+	tx, _, err := s.GetTx(staker.TxID)
+	require.NoError(err)
+
+	txBytes, err := s.GetStakerTxBytes(staker.TxID)
+	require.NoError(err)
+	require.Equal(tx.Bytes(), txBytes)
+
+	// The bytes are read from disk when the tx isn't in memory. Loading the
+	// stakers caches their txs, so the cache is flushed first.
+	require.NoError(s.Commit())
+	s = newStateFromDB(require, db)
+	internalState := s.(*state)
+	internalState.txCache.Flush()
+	txBytes, err = s.GetStakerTxBytes(staker.TxID)
+	require.NoError(err)
+	require.Equal(tx.Bytes(), txBytes)
+
+	// Reading the bytes from disk doesn't populate the cache.
+	_, cached := internalState.txCache.Get(staker.TxID)
+	require.False(cached)
+
+	_, err = s.GetStakerTxBytes(ids.GenerateTestID())
+	require.ErrorIs(err, database.ErrNotFound)
+}
+
+// commitMetrics counts the commits and aborts of the state.
+type commitMetrics struct {
+	metrics.Metrics
+
+	numCommits            int
+	numAbortsAfterCommit  int
+	numAbortsWithRollback int
+}
+
+func (m *commitMetrics) IncStateCommits() {
+	m.numCommits++
+}
+
+func (m *commitMetrics) IncStateAborts(afterCommit bool) {
+	if afterCommit {
+		m.numAbortsAfterCommit++
+	} else {
+		m.numAbortsWithRollback++
+	}
+}
+
+func TestStateCommitAbortMetrics(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+	m := &commitMetrics{
+		Metrics: metrics.Noop,
+	}
+	s.(*state).metrics = m
+
+	// A successful commit releases its batch, which isn't a rollback.
+	s.SetHeight(1)
+	require.NoError(s.Commit())
+	require.Equal(1, m.numCommits)
+	require.Equal(1, m.numAbortsAfterCommit)
+	require.Zero(m.numAbortsWithRollback)
+
+	// Aborting changes that were never committed is a rollback.
+	s.SetHeight(2)
+	s.Abort()
+	require.Equal(1, m.numCommits)
+	require.Equal(1, m.numAbortsAfterCommit)
+	require.Equal(1, m.numAbortsWithRollback)
+
+	// Aborting after CommitBatch releases the batch.
+	s.SetHeight(3)
+	batch, err := s.CommitBatch()
+	require.NoError(err)
+	require.NoError(batch.Write())
+	s.Abort()
+	require.Equal(2, m.numCommits)
+	require.Equal(2, m.numAbortsAfterCommit)
+	require.Equal(1, m.numAbortsWithRollback)
+}
+
+// genesisMetrics records the reported genesis initialization.
+type genesisMetrics struct {
+	metrics.Metrics
+
+	numCalls      int
+	numUTXOs      int
+	numValidators int
+	numChains     int
+}
+
+func (m *genesisMetrics) SetGenesisInitialization(_, _ time.Duration, numUTXOs, numValidators, numChains int) {
+	m.numCalls++
+	m.numUTXOs = numUTXOs
+	m.numValidators = numValidators
+	m.numChains = numChains
+}
+
+func TestStateGenesisInitializationMetrics(t *testing.T) {
+	require := require.New(t)
+
+	genesisBytes, err := genesis.Codec.Marshal(genesis.Version, &genesis.Genesis{
+		UTXOs: []*genesis.UTXO{
+			{
+				UTXO: avax.UTXO{
+					UTXOID: avax.UTXOID{
+						TxID: initialTxID,
+					},
+					Asset: avax.Asset{ID: initialTxID},
+					Out: &secp256k1fx.TransferOutput{
+						Amt: units.Avax,
+					},
+				},
+			},
+		},
+		Timestamp:     uint64(initialTime.Unix()),
+		InitialSupply: units.Avax,
+	})
+	require.NoError(err)
+
+	newStateWithMetrics := func(db database.Database, m metrics.Metrics) {
+		execCfg, err := config.GetExecutionConfig(nil)
+		require.NoError(err)
+		s, err := New(
+			db,
+			genesisBytes,
+			prometheus.NewRegistry(),
+			validators.NewManager(),
+			execCfg,
+			&snow.Context{
+				Log: logging.NoLog{},
+			},
+			m,
+			reward.NewCalculator(reward.Config{
+				MaxConsumptionRate: .12 * reward.PercentDenominator,
+				MinConsumptionRate: .1 * reward.PercentDenominator,
+				MintingPeriod:      365 * 24 * time.Hour,
+				SupplyCap:          720 * units.MegaAvax,
+			}),
+		)
+		require.NoError(err)
+		require.NoError(s.Close())
+	}
+
+	var (
+		db = memdb.New()
+		m  = &genesisMetrics{
+			Metrics: metrics.Noop,
+		}
+	)
+	newStateWithMetrics(db, m)
+	require.Equal(1, m.numCalls)
+	require.Equal(1, m.numUTXOs)
+	require.Zero(m.numValidators)
+	require.Zero(m.numChains)
+
+	// Reopening an initialized database doesn't process the genesis again.
+	newStateWithMetrics(db, m)
+	require.Equal(1, m.numCalls)
+}
@@ -0,0 +1,35 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import "fmt"
+
+var _ fmt.Stringer = UTXOSource(0)
+
+// UTXOSource describes where a UTXO returned by GetUTXOWithSource was read
+// from.
+type UTXOSource uint8
+
+const (
+	// UTXOSourcePending is used for UTXOs that were added or removed but not
+	// yet committed.
+	UTXOSourcePending UTXOSource = iota
+	// UTXOSourceCache is used for committed UTXOs served from the UTXO cache.
+	UTXOSourceCache
+	// UTXOSourceDatabase is used for committed UTXOs read from the database.
+	UTXOSourceDatabase
+)
+
+func (s UTXOSource) String() string {
+	switch s {
+	case UTXOSourcePending:
+		return "Pending"
+	case UTXOSourceCache:
+		return "Cache"
+	case UTXOSourceDatabase:
+		return "Database"
+	default:
+		return "Unknown"
+	}
+}
@@ -0,0 +1,350 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/math"
+	"github.com/ava-labs/avalanchego/vms/platformvm/block"
+)
+
+var (
+	errInvalidValidatorDiffsRange  = errors.New("start height is less than end height")
+	errNonContiguousValidatorDiffs = errors.New("validator diffs are not contiguous with the indexed heights")
+	errInconsistentValidatorDiffs  = errors.New("inconsistent validator diffs")
+)
+
+// HeightWeightDiff is the change to a validator's weight at a height.
+type HeightWeightDiff struct {
+	Height uint64
+	Diff   ValidatorWeightDiff
+}
+
+// validatorDiffs are the validator diffs of a subnet for the heights in
+// [EndHeight, StartHeight].
+type validatorDiffs struct {
+	SubnetID    ids.ID `serialize:"true"`
+	StartHeight uint64 `serialize:"true"`
+	EndHeight   uint64 `serialize:"true"`
+	// Sorted by decreasing height. Heights without any diffs are omitted.
+	Heights []validatorDiffsAtHeight `serialize:"true"`
+}
+
+type validatorDiffsAtHeight struct {
+	Height         uint64                   `serialize:"true"`
+	WeightDiffs    []validatorWeightDiff    `serialize:"true"`
+	PublicKeyDiffs []validatorPublicKeyDiff `serialize:"true"`
+}
+
+type validatorWeightDiff struct {
+	NodeID ids.NodeID          `serialize:"true"`
+	Diff   ValidatorWeightDiff `serialize:"true"`
+}
+
+type validatorPublicKeyDiff struct {
+	NodeID ids.NodeID `serialize:"true"`
+	// Uncompressed public key of the validator prior to the height. Empty if
+	// the validator didn't have a public key.
+	PublicKey []byte `serialize:"true"`
+}
+
+func (s *state) ExportValidatorDiffs(subnetID ids.ID, startHeight, endHeight uint64) ([]byte, error) {
+	if startHeight < endHeight {
+		return nil, errInvalidValidatorDiffsRange
+	}
+
+	diffs := validatorDiffs{
+		SubnetID:    subnetID,
+		StartHeight: startHeight,
+		EndHeight:   endHeight,
+	}
+	// atHeight returns the entry for [height], appending it if needed. Diffs
+	// are iterated in order of decreasing height.
+	atHeight := func(height uint64) *validatorDiffsAtHeight {
+		if numHeights := len(diffs.Heights); numHeights == 0 || diffs.Heights[numHeights-1].Height != height {
+			diffs.Heights = append(diffs.Heights, validatorDiffsAtHeight{
+				Height: height,
+			})
+		}
+		return &diffs.Heights[len(diffs.Heights)-1]
+	}
+
+	weightIter := s.flatValidatorWeightDiffsDB.NewIteratorWithStartAndPrefix(
+		marshalStartDiffKey(subnetID, startHeight),
+		subnetID[:],
+	)
+	defer weightIter.Release()
+
+	for weightIter.Next() {
+		_, height, nodeID, err := unmarshalDiffKey(weightIter.Key())
+		if err != nil {
+			return nil, err
+		}
+		if height < endHeight {
+			break
+		}
+
+		weightDiff, err := unmarshalWeightDiff(weightIter.Value())
+		if err != nil {
+			return nil, err
+		}
+
+		entry := atHeight(height)
+		entry.WeightDiffs = append(entry.WeightDiffs, validatorWeightDiff{
+			NodeID: nodeID,
+			Diff:   *weightDiff,
+		})
+	}
+	if err := weightIter.Error(); err != nil {
+		return nil, err
+	}
+
+	// Invariant: Only the Primary Network contains public key diffs.
+	if subnetID == constants.PrimaryNetworkID {
+		if err := s.exportPublicKeyDiffs(&diffs); err != nil {
+			return nil, err
+		}
+	}
+
+	return block.GenesisCodec.Marshal(block.Version, &diffs)
+}
+
+func (s *state) GetNodeWeightHistory(
+	subnetID ids.ID,
+	nodeID ids.NodeID,
+	startHeight uint64,
+	endHeight uint64,
+) ([]HeightWeightDiff, error) {
+	if startHeight < endHeight {
+		return nil, errInvalidValidatorDiffsRange
+	}
+
+	diffIter := s.flatValidatorWeightDiffsDB.NewIteratorWithStartAndPrefix(
+		marshalStartDiffKey(subnetID, startHeight),
+		subnetID[:],
+	)
+	defer diffIter.Release()
+
+	// The node ID is the last part of the key, so the diffs of every node at
+	// each height are iterated.
+	var history []HeightWeightDiff
+	for diffIter.Next() {
+		_, height, diffNodeID, err := unmarshalDiffKey(diffIter.Key())
+		if err != nil {
+			return nil, err
+		}
+		if height < endHeight {
+			break
+		}
+		if diffNodeID != nodeID {
+			continue
+		}
+
+		weightDiff, err := unmarshalWeightDiff(diffIter.Value())
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, HeightWeightDiff{
+			Height: height,
+			Diff:   *weightDiff,
+		})
+	}
+	return history, diffIter.Error()
+}
+
+// exportPublicKeyDiffs adds the public key diffs in the height range of
+// [diffs] to [diffs].
+//
+// Invariant: [diffs.Heights] is sorted by decreasing height.
+func (s *state) exportPublicKeyDiffs(diffs *validatorDiffs) error {
+	pkIter := s.flatValidatorPublicKeyDiffsDB.NewIteratorWithStartAndPrefix(
+		marshalStartDiffKey(constants.PrimaryNetworkID, diffs.StartHeight),
+		constants.PrimaryNetworkID[:],
+	)
+	defer pkIter.Release()
+
+	// Public key diffs are merged into the heights recorded by the weight
+	// diffs, which are sorted in the same order.
+	var (
+		merged = make([]validatorDiffsAtHeight, 0, len(diffs.Heights))
+		i      int
+	)
+	for pkIter.Next() {
+		_, height, nodeID, err := unmarshalDiffKey(pkIter.Key())
+		if err != nil {
+			return err
+		}
+		if height < diffs.EndHeight {
+			break
+		}
+
+		for i < len(diffs.Heights) && diffs.Heights[i].Height > height {
+			merged = append(merged, diffs.Heights[i])
+			i++
+		}
+		if i < len(diffs.Heights) && diffs.Heights[i].Height == height {
+			merged = append(merged, diffs.Heights[i])
+			i++
+		} else if len(merged) == 0 || merged[len(merged)-1].Height != height {
+			merged = append(merged, validatorDiffsAtHeight{
+				Height: height,
+			})
+		}
+
+		entry := &merged[len(merged)-1]
+		entry.PublicKeyDiffs = append(entry.PublicKeyDiffs, validatorPublicKeyDiff{
+			NodeID:    nodeID,
+			PublicKey: pkIter.Value(),
+		})
+	}
+	if err := pkIter.Error(); err != nil {
+		return err
+	}
+
+	diffs.Heights = append(merged, diffs.Heights[i:]...)
+	return nil
+}
+
+func (s *state) ImportValidatorDiffs(b []byte) error {
+	var diffs validatorDiffs
+	if _, err := block.GenesisCodec.Unmarshal(b, &diffs); err != nil {
+		return fmt.Errorf("failed to parse validator diffs: %w", err)
+	}
+	if diffs.StartHeight < diffs.EndHeight {
+		return errInvalidValidatorDiffsRange
+	}
+
+	// The imported diffs can only be used if there is no gap between them and
+	// the diffs that are already indexed, or the last accepted block if no
+	// diffs are indexed.
+	//
+	// The indexed range is only updated once the imported diffs are
+	// committed, so the range staged by a previous import is used if there
+	// is one.
+	var indexedHeights heightRange
+	switch {
+	case s.pendingIndexedHeights != nil:
+		indexedHeights = *s.pendingIndexedHeights
+	case s.indexedHeights != nil:
+		indexedHeights = *s.indexedHeights
+	default:
+		lastAccepted, err := s.GetStatelessBlock(s.lastAccepted)
+		if err != nil {
+			return fmt.Errorf("failed to get last accepted block: %w", err)
+		}
+		lastAcceptedHeight := lastAccepted.Height()
+		indexedHeights = heightRange{
+			LowerBound: lastAcceptedHeight + 1,
+			UpperBound: lastAcceptedHeight,
+		}
+	}
+	if diffs.StartHeight+1 < indexedHeights.LowerBound {
+		return errNonContiguousValidatorDiffs
+	}
+
+	for _, diffsAtHeight := range diffs.Heights {
+		height := diffsAtHeight.Height
+		for _, weightDiff := range diffsAtHeight.WeightDiffs {
+			weightDiff := weightDiff
+			err := s.flatValidatorWeightDiffsDB.Put(
+				marshalDiffKey(diffs.SubnetID, height, weightDiff.NodeID),
+				marshalWeightDiff(&weightDiff.Diff),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to write validator weight diff: %w", err)
+			}
+		}
+		for _, pkDiff := range diffsAtHeight.PublicKeyDiffs {
+			err := s.flatValidatorPublicKeyDiffsDB.Put(
+				marshalDiffKey(constants.PrimaryNetworkID, height, pkDiff.NodeID),
+				pkDiff.PublicKey,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to write validator public key diff: %w", err)
+			}
+		}
+	}
+
+	indexedHeights.LowerBound = math.Min(indexedHeights.LowerBound, diffs.EndHeight)
+	s.pendingIndexedHeights = &indexedHeights
+	return nil
+}
+
+func (s *state) VerifyValidatorDiffChain(ctx context.Context, subnetID ids.ID, startHeight, endHeight uint64) error {
+	lastAccepted, err := s.GetStatelessBlock(s.lastAccepted)
+	if err != nil {
+		return fmt.Errorf("failed to get last accepted block: %w", err)
+	}
+	currentHeight := lastAccepted.Height()
+	if startHeight < endHeight || currentHeight < startHeight {
+		return errInvalidValidatorDiffsRange
+	}
+
+	vdrs := s.validators.GetMap(subnetID)
+	if startHeight < currentHeight {
+		// Reconstruct the validator set at [startHeight] in a single pass.
+		err := s.ApplyValidatorWeightDiffs(
+			ctx,
+			vdrs,
+			currentHeight,
+			startHeight+1,
+			subnetID,
+		)
+		if err := wrapInconsistentValidatorDiffs(err); err != nil {
+			return err
+		}
+		if err := verifyTotalWeight(vdrs, startHeight); err != nil {
+			return err
+		}
+	}
+
+	for height := startHeight; height > endHeight; height-- {
+		// Diffs are applied one height at a time to detect the height at which
+		// the validator set becomes invalid. Applying the diffs at [height]
+		// results in the validator set at [height - 1].
+		err := s.ApplyValidatorWeightDiffs(
+			ctx,
+			vdrs,
+			height,
+			height,
+			subnetID,
+		)
+		if err := wrapInconsistentValidatorDiffs(err); err != nil {
+			return err
+		}
+		if err := verifyTotalWeight(vdrs, height-1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func wrapInconsistentValidatorDiffs(err error) error {
+	if errors.Is(err, math.ErrOverflow) || errors.Is(err, math.ErrUnderflow) {
+		return fmt.Errorf("%w: %w", errInconsistentValidatorDiffs, err)
+	}
+	return err
+}
+
+// verifyTotalWeight returns an error if the total weight of [vdrs], the
+// validator set at [height], overflows.
+func verifyTotalWeight(vdrs map[ids.NodeID]*validators.GetValidatorOutput, height uint64) error {
+	var totalWeight uint64
+	for nodeID, vdr := range vdrs {
+		var err error
+		totalWeight, err = math.Add64(totalWeight, vdr.Weight)
+		if err != nil {
+			return fmt.Errorf("%w: total weight overflows at height %d after adding %s: %w",
+				errInconsistentValidatorDiffs, height, nodeID, err,
+			)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,416 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/vms/platformvm/block"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+)
+
+// newValidatorDiffsState returns a state with the following validator
+// changes:
+//
+//	height 1: [nodeID0] starts validating the primary network and [subnetID]
+//	height 2: [nodeID1] starts validating the primary network
+//	height 3: [nodeID0] stops validating the primary network
+func newValidatorDiffsState(
+	require *require.Assertions,
+	subnetID ids.ID,
+	nodeID0 ids.NodeID,
+	nodeID1 ids.NodeID,
+	pk *bls.PublicKey,
+) State {
+	s, _ := newInitializedState(require)
+
+	primaryValidator0 := &Staker{
+		TxID:      ids.GenerateTestID(),
+		NodeID:    nodeID0,
+		PublicKey: pk,
+		SubnetID:  constants.PrimaryNetworkID,
+		Weight:    10,
+		StartTime: initialTime,
+		EndTime:   initialValidatorEndTime,
+		NextTime:  initialValidatorEndTime,
+		Priority:  txs.PrimaryNetworkValidatorCurrentPriority,
+	}
+	s.PutCurrentValidator(primaryValidator0)
+	s.PutCurrentValidator(&Staker{
+		TxID:      ids.GenerateTestID(),
+		NodeID:    nodeID0,
+		SubnetID:  subnetID,
+		Weight:    5,
+		StartTime: initialTime,
+		EndTime:   initialValidatorEndTime,
+		NextTime:  initialValidatorEndTime,
+		Priority:  txs.SubnetPermissionedValidatorCurrentPriority,
+	})
+	s.SetHeight(1)
+	require.NoError(s.Commit())
+
+	s.PutCurrentValidator(&Staker{
+		TxID:      ids.GenerateTestID(),
+		NodeID:    nodeID1,
+		SubnetID:  constants.PrimaryNetworkID,
+		Weight:    20,
+		StartTime: initialTime,
+		EndTime:   initialValidatorEndTime,
+		NextTime:  initialValidatorEndTime,
+		Priority:  txs.PrimaryNetworkValidatorCurrentPriority,
+	})
+	s.SetHeight(2)
+	require.NoError(s.Commit())
+
+	s.DeleteCurrentValidator(primaryValidator0)
+	s.SetHeight(3)
+	require.NoError(s.Commit())
+	return s
+}
+
+func TestStateExportImportValidatorDiffs(t *testing.T) {
+	require := require.New(t)
+
+	sk, err := bls.NewSecretKey()
+	require.NoError(err)
+
+	var (
+		subnetID = ids.GenerateTestID()
+		nodeID0  = ids.GenerateTestNodeID()
+		nodeID1  = ids.GenerateTestNodeID()
+		s        = newValidatorDiffsState(require, subnetID, nodeID0, nodeID1, bls.PublicFromSecretKey(sk))
+	)
+
+	for _, subnetID := range []ids.ID{constants.PrimaryNetworkID, subnetID} {
+		diffBytes, err := s.ExportValidatorDiffs(subnetID, 3, 1)
+		require.NoError(err)
+
+		imported, _ := newInitializedState(require)
+		require.NoError(imported.ImportValidatorDiffs(diffBytes))
+		require.NoError(imported.Commit())
+
+		reexportedBytes, err := imported.ExportValidatorDiffs(subnetID, 3, 1)
+		require.NoError(err)
+		require.Equal(diffBytes, reexportedBytes)
+	}
+
+	// Only the diffs in the requested range are exported.
+	diffBytes, err := s.ExportValidatorDiffs(constants.PrimaryNetworkID, 2, 2)
+	require.NoError(err)
+
+	var diffs validatorDiffs
+	_, err = block.GenesisCodec.Unmarshal(diffBytes, &diffs)
+	require.NoError(err)
+	require.Equal(
+		[]validatorDiffsAtHeight{
+			{
+				Height: 2,
+				WeightDiffs: []validatorWeightDiff{
+					{
+						NodeID: nodeID1,
+						Diff: ValidatorWeightDiff{
+							Amount: 20,
+						},
+					},
+				},
+				PublicKeyDiffs: []validatorPublicKeyDiff{},
+			},
+		},
+		diffs.Heights,
+	)
+
+	_, err = s.ExportValidatorDiffs(constants.PrimaryNetworkID, 1, 2)
+	require.ErrorIs(err, errInvalidValidatorDiffsRange)
+}
+
+func TestStateImportValidatorDiffsApply(t *testing.T) {
+	require := require.New(t)
+
+	sk, err := bls.NewSecretKey()
+	require.NoError(err)
+
+	var (
+		subnetID = ids.GenerateTestID()
+		nodeID0  = ids.GenerateTestNodeID()
+		nodeID1  = ids.GenerateTestNodeID()
+		pk       = bls.PublicFromSecretKey(sk)
+		s        = newValidatorDiffsState(require, subnetID, nodeID0, nodeID1, pk)
+	)
+
+	imported, _ := newInitializedState(require)
+	for _, subnetID := range []ids.ID{constants.PrimaryNetworkID, subnetID} {
+		diffBytes, err := s.ExportValidatorDiffs(subnetID, 3, 1)
+		require.NoError(err)
+		require.NoError(imported.ImportValidatorDiffs(diffBytes))
+	}
+	require.NoError(imported.Commit())
+
+	// The validator sets at height 3
+	currentValidators := map[ids.ID]map[ids.NodeID]*validators.GetValidatorOutput{
+		constants.PrimaryNetworkID: {
+			nodeID1: {
+				NodeID: nodeID1,
+				Weight: 20,
+			},
+		},
+		subnetID: {
+			nodeID0: {
+				NodeID: nodeID0,
+				Weight: 5,
+			},
+		},
+	}
+	// The validator sets at height 1
+	expectedValidators := map[ids.ID]map[ids.NodeID]*validators.GetValidatorOutput{
+		constants.PrimaryNetworkID: {
+			nodeID0: {
+				NodeID:    nodeID0,
+				PublicKey: pk,
+				Weight:    10,
+			},
+		},
+		subnetID: {
+			nodeID0: {
+				NodeID: nodeID0,
+				Weight: 5,
+			},
+		},
+	}
+
+	for subnetID, current := range currentValidators {
+		for _, state := range []State{s, imported} {
+			vdrs := make(map[ids.NodeID]*validators.GetValidatorOutput, len(current))
+			for nodeID, vdr := range current {
+				vdrCopy := *vdr
+				vdrs[nodeID] = &vdrCopy
+			}
+
+			require.NoError(state.ApplyValidatorWeightDiffs(
+				context.Background(),
+				vdrs,
+				3,
+				2,
+				subnetID,
+			))
+			if subnetID == constants.PrimaryNetworkID {
+				require.NoError(state.ApplyValidatorPublicKeyDiffs(
+					context.Background(),
+					vdrs,
+					3,
+					2,
+				))
+			}
+			require.Equal(expectedValidators[subnetID], vdrs)
+		}
+	}
+}
+
+func TestStateImportValidatorDiffsAbort(t *testing.T) {
+	require := require.New(t)
+
+	sk, err := bls.NewSecretKey()
+	require.NoError(err)
+
+	var (
+		subnetID = ids.GenerateTestID()
+		nodeID0  = ids.GenerateTestNodeID()
+		nodeID1  = ids.GenerateTestNodeID()
+		s        = newValidatorDiffsState(require, subnetID, nodeID0, nodeID1, bls.PublicFromSecretKey(sk))
+	)
+	diffBytes, err := s.ExportValidatorDiffs(constants.PrimaryNetworkID, 3, 1)
+	require.NoError(err)
+
+	imported, _ := newInitializedState(require)
+	require.NoError(imported.Commit())
+	internalState := imported.(*state)
+	indexedHeights := internalState.indexedHeights
+
+	// The indexed range isn't modified until the diffs are committed.
+	require.NoError(imported.ImportValidatorDiffs(diffBytes))
+	require.Equal(indexedHeights, internalState.indexedHeights)
+
+	imported.Abort()
+	require.Nil(internalState.pendingIndexedHeights)
+	require.Equal(indexedHeights, internalState.indexedHeights)
+
+	// The diffs can be imported again after the failed import.
+	require.NoError(imported.ImportValidatorDiffs(diffBytes))
+	require.NoError(imported.Commit())
+	require.Nil(internalState.pendingIndexedHeights)
+	require.Equal(uint64(1), internalState.indexedHeights.LowerBound)
+
+	reexportedBytes, err := imported.ExportValidatorDiffs(constants.PrimaryNetworkID, 3, 1)
+	require.NoError(err)
+	require.Equal(diffBytes, reexportedBytes)
+}
+
+func TestStateApplyValidatorWeightDiffsForward(t *testing.T) {
+	require := require.New(t)
+
+	sk, err := bls.NewSecretKey()
+	require.NoError(err)
+
+	var (
+		subnetID = ids.GenerateTestID()
+		nodeID0  = ids.GenerateTestNodeID()
+		nodeID1  = ids.GenerateTestNodeID()
+		pk       = bls.PublicFromSecretKey(sk)
+		s        = newValidatorDiffsState(require, subnetID, nodeID0, nodeID1, pk)
+	)
+
+	// The primary network validator set at height 1
+	vdrs := map[ids.NodeID]*validators.GetValidatorOutput{
+		nodeID0: {
+			NodeID:    nodeID0,
+			PublicKey: pk,
+			Weight:    10,
+		},
+	}
+
+	// No diffs are applied if [startHeight] isn't below [endHeight].
+	require.NoError(s.ApplyValidatorWeightDiffsForward(
+		context.Background(),
+		vdrs,
+		1,
+		1,
+		constants.PrimaryNetworkID,
+	))
+	require.Len(vdrs, 1)
+
+	require.NoError(s.ApplyValidatorWeightDiffsForward(
+		context.Background(),
+		vdrs,
+		1,
+		2,
+		constants.PrimaryNetworkID,
+	))
+	require.Equal(
+		map[ids.NodeID]*validators.GetValidatorOutput{
+			nodeID0: {
+				NodeID:    nodeID0,
+				PublicKey: pk,
+				Weight:    10,
+			},
+			nodeID1: {
+				NodeID: nodeID1,
+				Weight: 20,
+			},
+		},
+		vdrs,
+	)
+
+	require.NoError(s.ApplyValidatorWeightDiffsForward(
+		context.Background(),
+		vdrs,
+		2,
+		3,
+		constants.PrimaryNetworkID,
+	))
+	require.Equal(
+		map[ids.NodeID]*validators.GetValidatorOutput{
+			nodeID1: {
+				NodeID: nodeID1,
+				Weight: 20,
+			},
+		},
+		vdrs,
+	)
+
+	// Rolling back to height 1 restores the weights of the original set.
+	require.NoError(s.ApplyValidatorWeightDiffs(
+		context.Background(),
+		vdrs,
+		3,
+		2,
+		constants.PrimaryNetworkID,
+	))
+	require.Equal(
+		map[ids.NodeID]*validators.GetValidatorOutput{
+			nodeID0: {
+				NodeID: nodeID0,
+				Weight: 10,
+			},
+		},
+		vdrs,
+	)
+
+	// The subnet validator set doesn't change after height 1.
+	subnetVdrs := map[ids.NodeID]*validators.GetValidatorOutput{
+		nodeID0: {
+			NodeID: nodeID0,
+			Weight: 5,
+		},
+	}
+	require.NoError(s.ApplyValidatorWeightDiffsForward(
+		context.Background(),
+		subnetVdrs,
+		1,
+		3,
+		subnetID,
+	))
+	require.Equal(
+		map[ids.NodeID]*validators.GetValidatorOutput{
+			nodeID0: {
+				NodeID: nodeID0,
+				Weight: 5,
+			},
+		},
+		subnetVdrs,
+	)
+}
+
+func TestStateGetNodeWeightHistory(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		subnetID = ids.GenerateTestID()
+		nodeID0  = ids.GenerateTestNodeID()
+		nodeID1  = ids.GenerateTestNodeID()
+	)
+	s := newValidatorDiffsState(require, subnetID, nodeID0, nodeID1, nil)
+
+	// The diffs of [nodeID1] at height 2 are filtered out.
+	history, err := s.GetNodeWeightHistory(constants.PrimaryNetworkID, nodeID0, 3, 1)
+	require.NoError(err)
+	require.Equal(
+		[]HeightWeightDiff{
+			{
+				Height: 3,
+				Diff: ValidatorWeightDiff{
+					Decrease: true,
+					Amount:   10,
+				},
+			},
+			{
+				Height: 1,
+				Diff: ValidatorWeightDiff{
+					Decrease: false,
+					Amount:   10,
+				},
+			},
+		},
+		history,
+	)
+
+	// Diffs outside of the range aren't returned.
+	history, err = s.GetNodeWeightHistory(constants.PrimaryNetworkID, nodeID0, 2, 2)
+	require.NoError(err)
+	require.Empty(history)
+
+	// The diffs of other subnets aren't returned.
+	history, err = s.GetNodeWeightHistory(subnetID, nodeID0, 3, 1)
+	require.NoError(err)
+	require.Len(history, 1)
+	require.Equal(uint64(1), history[0].Height)
+
+	_, err = s.GetNodeWeightHistory(constants.PrimaryNetworkID, nodeID0, 1, 3)
+	require.ErrorIs(err, errInvalidValidatorDiffsRange)
+}
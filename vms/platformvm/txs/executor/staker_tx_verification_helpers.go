@@ -166,8 +166,6 @@ func overDelegated(
 
 // GetMaxWeight returns the maximum total weight of the [validator], including
 // its own weight, between [startTime] and [endTime].
-// The weight changes are applied in the order they will be applied as chain
-// time advances.
 // Invariant:
 // - [validator.StartTime] <= [startTime] < [endTime] <= [validator.EndTime]
 func GetMaxWeight(
@@ -176,77 +174,7 @@ func GetMaxWeight(
 	startTime time.Time,
 	endTime time.Time,
 ) (uint64, error) {
-	currentDelegatorIterator, err := chainState.GetCurrentDelegatorIterator(validator.SubnetID, validator.NodeID)
-	if err != nil {
-		return 0, err
-	}
-
-	// TODO: We can optimize this by moving the current total weight to be
-	//       stored in the validator state.
-	//
-	// Calculate the current total weight on this validator, including the
-	// weight of the actual validator and the sum of the weights of all of the
-	// currently active delegators.
-	currentWeight := validator.Weight
-	for currentDelegatorIterator.Next() {
-		currentDelegator := currentDelegatorIterator.Value()
-
-		currentWeight, err = math.Add64(currentWeight, currentDelegator.Weight)
-		if err != nil {
-			currentDelegatorIterator.Release()
-			return 0, err
-		}
-	}
-	currentDelegatorIterator.Release()
-
-	currentDelegatorIterator, err = chainState.GetCurrentDelegatorIterator(validator.SubnetID, validator.NodeID)
-	if err != nil {
-		return 0, err
-	}
-	pendingDelegatorIterator, err := chainState.GetPendingDelegatorIterator(validator.SubnetID, validator.NodeID)
-	if err != nil {
-		currentDelegatorIterator.Release()
-		return 0, err
-	}
-	delegatorChangesIterator := state.NewStakerDiffIterator(currentDelegatorIterator, pendingDelegatorIterator)
-	defer delegatorChangesIterator.Release()
-
-	// Iterate over the future stake weight changes and calculate the maximum
-	// total weight on the validator, only including the points in the time
-	// range [startTime, endTime].
-	var currentMax uint64
-	for delegatorChangesIterator.Next() {
-		delegator, isAdded := delegatorChangesIterator.Value()
-		// [delegator.NextTime] > [endTime]
-		if delegator.NextTime.After(endTime) {
-			// This delegation change (and all following changes) occurs after
-			// [endTime]. Since we're calculating the max amount staked in
-			// [startTime, endTime], we can stop.
-			break
-		}
-
-		// [delegator.NextTime] >= [startTime]
-		if !delegator.NextTime.Before(startTime) {
-			// We have advanced time to be at the inside of the delegation
-			// window. Make sure that the max weight is updated accordingly.
-			currentMax = math.Max(currentMax, currentWeight)
-		}
-
-		var op func(uint64, uint64) (uint64, error)
-		if isAdded {
-			op = math.Add64
-		} else {
-			op = math.Sub[uint64]
-		}
-		currentWeight, err = op(currentWeight, delegator.Weight)
-		if err != nil {
-			return 0, err
-		}
-	}
-	// Because we assume [startTime] < [endTime], we have advanced time to
-	// be at the end of the delegation window. Make sure that the max weight is
-	// updated accordingly.
-	return math.Max(currentMax, currentWeight), nil
+	return state.GetMaxWeight(chainState, validator, startTime, endTime)
 }
 
 func GetTransformSubnetTx(chain state.Chain, subnetID ids.ID) (*txs.TransformSubnetTx, error) {
@@ -190,7 +190,7 @@ func (e *StandardTxExecutor) ImportTx(tx *txs.ImportTx) error {
 	// Consume the UTXOS
 	avax.Consume(e.State, tx.Ins)
 	// Produce the UTXOS
-	avax.Produce(e.State, txID, tx.Outs)
+	e.State.AddUTXOs(avax.ProducedUTXOs(txID, tx.Outs))
 
 	// Note: We apply atomic requests even if we are not verifying atomic
 	// requests to ensure the shared state will be correct if we later start
@@ -429,7 +429,9 @@ func (e *StandardTxExecutor) TransformSubnetTx(tx *txs.TransformSubnetTx) error
 	// Produce the UTXOS
 	avax.Produce(e.State, txID, tx.Outs)
 	// Transform the new subnet in the database
-	e.State.AddSubnetTransformation(e.Tx)
+	if err := e.State.AddSubnetTransformation(e.Tx); err != nil {
+		return err
+	}
 	e.State.SetCurrentSupply(tx.Subnet, tx.InitialSupply)
 	return nil
 }
@@ -0,0 +1,137 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validators
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/codec/linearcodec"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+const (
+	exportCodecVersion = 0
+
+	// maxExportedValidatorSetSize bounds the size of an exported validator
+	// set, so that importing a malformed payload can't force large
+	// allocations.
+	maxExportedValidatorSetSize = 2 * units.MiB
+
+	// Each exported validator is encoded with at least a node ID, a public
+	// key length and a weight.
+	minExportedValidatorSize = ids.NodeIDLen + wrappers.IntLen + wrappers.LongLen
+
+	// Note: Modifying this variable can have subtle implications on memory
+	// usage when parsing malformed payloads.
+	maxExportedValidators = maxExportedValidatorSetSize / minExportedValidatorSize
+)
+
+var (
+	exportCodec codec.Manager
+
+	errNonCanonicalValidatorSet = errors.New("validators are not sorted by unique node ID")
+)
+
+func init() {
+	exportCodec = codec.NewManager(maxExportedValidatorSetSize)
+	lc := linearcodec.NewCustomMaxLength(maxExportedValidators)
+	if err := exportCodec.RegisterCodec(exportCodecVersion, lc); err != nil {
+		panic(err)
+	}
+}
+
+// ValidatorSetSnapshot is the validator set of a subnet at a height.
+type ValidatorSetSnapshot struct {
+	Height     uint64
+	SubnetID   ids.ID
+	Validators map[ids.NodeID]*validators.GetValidatorOutput
+}
+
+// exportedValidatorSet is the canonical serialized form of a
+// [ValidatorSetSnapshot].
+type exportedValidatorSet struct {
+	Height   uint64 `serialize:"true"`
+	SubnetID ids.ID `serialize:"true"`
+	// Sorted by node ID.
+	Validators []exportedValidator `serialize:"true"`
+}
+
+type exportedValidator struct {
+	NodeID ids.NodeID `serialize:"true"`
+	// Compressed public key. Empty if the validator didn't register a key.
+	PublicKey []byte `serialize:"true"`
+	Weight    uint64 `serialize:"true"`
+}
+
+// exportValidatorSet returns the canonical encoding of [vdrs], the validator
+// set of [subnetID] at [height]. Equal validator sets always result in the
+// same bytes.
+func exportValidatorSet(
+	height uint64,
+	subnetID ids.ID,
+	vdrs map[ids.NodeID]*validators.GetValidatorOutput,
+) ([]byte, error) {
+	nodeIDs := maps.Keys(vdrs)
+	slices.SortFunc(nodeIDs, ids.NodeID.Less)
+
+	exported := exportedValidatorSet{
+		Height:     height,
+		SubnetID:   subnetID,
+		Validators: make([]exportedValidator, len(nodeIDs)),
+	}
+	for i, nodeID := range nodeIDs {
+		vdr := vdrs[nodeID]
+		exported.Validators[i] = exportedValidator{
+			NodeID: nodeID,
+			Weight: vdr.Weight,
+		}
+		if vdr.PublicKey != nil {
+			exported.Validators[i].PublicKey = bls.PublicKeyToBytes(vdr.PublicKey)
+		}
+	}
+	return exportCodec.Marshal(exportCodecVersion, &exported)
+}
+
+// ImportValidatorSet parses a validator set that was encoded with
+// Manager.ExportValidatorSet.
+func ImportValidatorSet(b []byte) (*ValidatorSetSnapshot, error) {
+	var exported exportedValidatorSet
+	if _, err := exportCodec.Unmarshal(b, &exported); err != nil {
+		return nil, err
+	}
+
+	snapshot := &ValidatorSetSnapshot{
+		Height:     exported.Height,
+		SubnetID:   exported.SubnetID,
+		Validators: make(map[ids.NodeID]*validators.GetValidatorOutput, len(exported.Validators)),
+	}
+	for i, vdr := range exported.Validators {
+		if i > 0 && !exported.Validators[i-1].NodeID.Less(vdr.NodeID) {
+			return nil, errNonCanonicalValidatorSet
+		}
+
+		output := &validators.GetValidatorOutput{
+			NodeID: vdr.NodeID,
+			Weight: vdr.Weight,
+		}
+		if len(vdr.PublicKey) > 0 {
+			pk, err := bls.PublicKeyFromBytes(vdr.PublicKey)
+			if err != nil {
+				return nil, fmt.Errorf("invalid public key of %s: %w", vdr.NodeID, err)
+			}
+			output.PublicKey = pk
+		}
+		snapshot.Validators[vdr.NodeID] = output
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,135 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+func TestExportImportValidatorSet(t *testing.T) {
+	sk, err := bls.NewSecretKey()
+	require.NoError(t, err)
+	pk := bls.PublicFromSecretKey(sk)
+
+	var (
+		nodeID0 = ids.GenerateTestNodeID()
+		nodeID1 = ids.GenerateTestNodeID()
+		nodeID2 = ids.GenerateTestNodeID()
+	)
+
+	tests := map[string]map[ids.NodeID]*validators.GetValidatorOutput{
+		"empty": {},
+		"without public keys": {
+			nodeID0: {
+				NodeID: nodeID0,
+				Weight: 1,
+			},
+			nodeID1: {
+				NodeID: nodeID1,
+				Weight: 2,
+			},
+		},
+		"with public keys": {
+			nodeID0: {
+				NodeID:    nodeID0,
+				PublicKey: pk,
+				Weight:    1,
+			},
+			nodeID1: {
+				NodeID: nodeID1,
+				Weight: 2,
+			},
+			nodeID2: {
+				NodeID:    nodeID2,
+				PublicKey: pk,
+				Weight:    3,
+			},
+		},
+	}
+	for name, vdrs := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			subnetID := ids.GenerateTestID()
+			vdrBytes, err := exportValidatorSet(10, subnetID, vdrs)
+			require.NoError(err)
+
+			snapshot, err := ImportValidatorSet(vdrBytes)
+			require.NoError(err)
+			require.Equal(uint64(10), snapshot.Height)
+			require.Equal(subnetID, snapshot.SubnetID)
+			require.Len(snapshot.Validators, len(vdrs))
+			for nodeID, expectedVdr := range vdrs {
+				vdr := snapshot.Validators[nodeID]
+				require.NotNil(vdr)
+				require.Equal(expectedVdr.NodeID, vdr.NodeID)
+				require.Equal(expectedVdr.Weight, vdr.Weight)
+				if expectedVdr.PublicKey == nil {
+					require.Nil(vdr.PublicKey)
+				} else {
+					require.Equal(
+						bls.PublicKeyToBytes(expectedVdr.PublicKey),
+						bls.PublicKeyToBytes(vdr.PublicKey),
+					)
+				}
+			}
+
+			// Exporting the same set again must result in the same bytes.
+			reexportedBytes, err := exportValidatorSet(10, subnetID, snapshot.Validators)
+			require.NoError(err)
+			require.Equal(vdrBytes, reexportedBytes)
+		})
+	}
+}
+
+func TestImportValidatorSetNonCanonical(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		nodeID0 = ids.NodeID{0}
+		nodeID1 = ids.NodeID{1}
+	)
+	vdrBytes, err := exportCodec.Marshal(exportCodecVersion, &exportedValidatorSet{
+		Validators: []exportedValidator{
+			{
+				NodeID: nodeID1,
+				Weight: 1,
+			},
+			{
+				NodeID: nodeID0,
+				Weight: 1,
+			},
+		},
+	})
+	require.NoError(err)
+
+	_, err = ImportValidatorSet(vdrBytes)
+	require.ErrorIs(err, errNonCanonicalValidatorSet)
+}
+
+func TestImportValidatorSetTooLarge(t *testing.T) {
+	require := require.New(t)
+
+	// The payload claims more validators than can fit in an exported set.
+	p := wrappers.Packer{MaxSize: 64}
+	p.PackShort(exportCodecVersion)
+	p.PackLong(10)
+	p.PackFixedBytes(ids.Empty[:])
+	p.PackInt(maxExportedValidators + 1)
+	require.NoError(p.Err)
+
+	_, err := ImportValidatorSet(p.Bytes)
+	require.ErrorIs(err, codec.ErrMaxSliceLenExceeded)
+
+	_, err = ImportValidatorSet(make([]byte, maxExportedValidatorSetSize+1))
+	require.ErrorIs(err, codec.ErrUnmarshalTooBig)
+}
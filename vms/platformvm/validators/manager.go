@@ -5,17 +5,27 @@ package validators
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"go.uber.org/zap"
+
+	"golang.org/x/exp/maps"
+
 	"github.com/ava-labs/avalanchego/cache"
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils"
 	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
 	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/math"
+	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/utils/timer/mockable"
 	"github.com/ava-labs/avalanchego/utils/window"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
 	"github.com/ava-labs/avalanchego/vms/platformvm/block"
 	"github.com/ava-labs/avalanchego/vms/platformvm/config"
 	"github.com/ava-labs/avalanchego/vms/platformvm/metrics"
@@ -25,18 +35,79 @@ import (
 
 const (
 	validatorSetsCacheSize        = 64
+	defaultSubnetIDCacheSize      = 1024
 	maxRecentlyAcceptedWindowSize = 64
 	minRecentlyAcceptedWindowSize = 16
 	recentlyAcceptedWindowTTL     = 2 * time.Minute
 )
 
-var _ validators.State = (*manager)(nil)
+var (
+	_ validators.State = (*manager)(nil)
+
+	errMissingPublicKey = errors.New("validator is missing a public key")
+
+	ErrInvalidRecentlyAcceptedWindowSize = errors.New("recently accepted window size must be positive")
+	ErrInvalidRecentlyAcceptedWindowTTL  = errors.New("recently accepted window TTL must be non-negative")
+	ErrInvalidValidatorSetsCacheBytes    = errors.New("validator sets cache bytes must be non-negative")
+)
 
 // Manager adds the ability to introduce newly accepted blocks IDs to the State
 // interface.
 type Manager interface {
 	validators.State
 
+	// ExportValidatorSet returns the canonical encoding of the validator set
+	// of [subnetID] at [height]. Validators are ordered by node ID, so equal
+	// validator sets always result in the same bytes. The result can be
+	// decoded with ImportValidatorSet. An error is returned if the encoding
+	// would exceed the size that ImportValidatorSet accepts.
+	ExportValidatorSet(ctx context.Context, height uint64, subnetID ids.ID) ([]byte, error)
+
+	// GetValidatorSetAggregatePublicKey returns the aggregate BLS public key
+	// and the total weight of the [signers] that are validators of
+	// [subnetID] at [height]. Signers that aren't validators at [height] are
+	// excluded from both the key and the weight. An error is returned if a
+	// signer that is a validator doesn't have a BLS public key.
+	GetValidatorSetAggregatePublicKey(
+		ctx context.Context,
+		height uint64,
+		subnetID ids.ID,
+		signers set.Set[ids.NodeID],
+	) (*bls.PublicKey, uint64, error)
+
+	// GetValidatorSetDelta returns the changes to the validator set of
+	// [subnetID] from [fromHeight] to [toHeight]. [added] contains the
+	// validators at [toHeight] that weren't validators at [fromHeight].
+	// [removed] contains the validators at [fromHeight] that aren't validators
+	// at [toHeight]. [weightChanges] contains the signed change in weight of
+	// the other validators whose weight changed.
+	//
+	// [fromHeight] may be greater than [toHeight], in which case the changes
+	// are reported in the reverse direction.
+	GetValidatorSetDelta(
+		ctx context.Context,
+		fromHeight uint64,
+		toHeight uint64,
+		subnetID ids.ID,
+	) (
+		added map[ids.NodeID]*validators.GetValidatorOutput,
+		removed map[ids.NodeID]*validators.GetValidatorOutput,
+		weightChanges map[ids.NodeID]int64,
+		err error,
+	)
+
+	// CachedSubnets returns the sorted IDs of the subnets that currently have a
+	// validator set cache. Only the primary network and tracked subnets are
+	// cached, and a cache is only created once a validator set of the subnet
+	// has been requested.
+	CachedSubnets() []ids.ID
+
+	// RefreshSubnet drops the cached validator sets of [subnetID] and caches
+	// the validator set of [subnetID] at the current height. This is a no-op
+	// if validator sets of [subnetID] aren't cached because the subnet isn't
+	// tracked.
+	RefreshSubnet(ctx context.Context, subnetID ids.ID) error
+
 	// OnAcceptedBlockID registers the ID of the latest accepted block.
 	// It is used to update the [recentlyAccepted] sliding window.
 	OnAcceptedBlockID(blkID ids.ID)
@@ -84,6 +155,44 @@ type State interface {
 	) error
 }
 
+// VerifyConfig returns an error if the parameters of [cfg] used by the manager
+// are invalid. Zero values are valid and are replaced with defaults.
+func VerifyConfig(cfg config.Config) error {
+	if cfg.RecentlyAcceptedWindowSize < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidRecentlyAcceptedWindowSize, cfg.RecentlyAcceptedWindowSize)
+	}
+	if ttl := cfg.RecentlyAcceptedWindowTTL; ttl != nil && *ttl < 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidRecentlyAcceptedWindowTTL, *ttl)
+	}
+	if cfg.ValidatorSetsCacheBytes < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidValidatorSetsCacheBytes, cfg.ValidatorSetsCacheBytes)
+	}
+	return nil
+}
+
+// recentlyAcceptedWindowConfig returns the configuration of the window of
+// recently accepted blocks, falling back to the defaults for unset values.
+func recentlyAcceptedWindowConfig(cfg config.Config, clk *mockable.Clock) window.Config {
+	maxSize := cfg.RecentlyAcceptedWindowSize
+	if maxSize <= 0 {
+		maxSize = maxRecentlyAcceptedWindowSize
+	}
+	ttl := recentlyAcceptedWindowTTL
+	if cfg.RecentlyAcceptedWindowTTL != nil {
+		ttl = *cfg.RecentlyAcceptedWindowTTL
+	}
+	minSize := minRecentlyAcceptedWindowSize
+	if minSize > maxSize {
+		minSize = maxSize
+	}
+	return window.Config{
+		Clock:   clk,
+		MaxSize: maxSize,
+		MinSize: minSize,
+		TTL:     ttl,
+	}
+}
+
 func NewManager(
 	log logging.Logger,
 	cfg config.Config,
@@ -91,6 +200,10 @@ func NewManager(
 	metrics metrics.Metrics,
 	clk *mockable.Clock,
 ) Manager {
+	subnetIDCacheSize := cfg.SubnetIDCacheSize
+	if subnetIDCacheSize <= 0 {
+		subnetIDCacheSize = defaultSubnetIDCacheSize
+	}
 	return &manager{
 		log:     log,
 		cfg:     cfg,
@@ -98,14 +211,10 @@ func NewManager(
 		metrics: metrics,
 		clk:     clk,
 		caches:  make(map[ids.ID]cache.Cacher[uint64, map[ids.NodeID]*validators.GetValidatorOutput]),
-		recentlyAccepted: window.New[ids.ID](
-			window.Config{
-				Clock:   clk,
-				MaxSize: maxRecentlyAcceptedWindowSize,
-				MinSize: minRecentlyAcceptedWindowSize,
-				TTL:     recentlyAcceptedWindowTTL,
-			},
-		),
+		subnetIDs: &cache.LRU[ids.ID, ids.ID]{
+			Size: subnetIDCacheSize,
+		},
+		recentlyAccepted: window.New[ids.ID](recentlyAcceptedWindowConfig(cfg, clk)),
 	}
 }
 
@@ -123,6 +232,12 @@ type manager struct {
 	// Value: cache mapping height -> validator set map
 	caches map[ids.ID]cache.Cacher[uint64, map[ids.NodeID]*validators.GetValidatorOutput]
 
+	// Caches the subnet that validates each chain. A chain's subnet can't
+	// change, so entries never need to be invalidated.
+	// Key: Chain ID
+	// Value: Subnet ID
+	subnetIDs cache.Cacher[ids.ID, ids.ID]
+
 	// sliding window of blocks that were recently accepted
 	recentlyAccepted window.Window[ids.ID]
 }
@@ -139,7 +254,8 @@ type manager struct {
 // guaranteed to be sufficiently stale). If we haven't expired an element yet
 // in the case of a process restart, we default to the lastAccepted block's
 // height which is likely (but not guaranteed) to also be older than the
-// window's configured TTL.
+// window's configured TTL. The lastAccepted block's height is also returned if
+// the oldest element in the window can't be loaded.
 //
 // If [UseCurrentHeight] is true, we override the block selection policy
 // described above and we will always return the last accepted block height
@@ -156,7 +272,13 @@ func (m *manager) GetMinimumHeight(ctx context.Context) (uint64, error) {
 
 	blk, err := m.state.GetStatelessBlock(oldest)
 	if err != nil {
-		return 0, err
+		// The current height is always a safe minimum height, so failing to
+		// load the oldest block shouldn't be fatal to the caller.
+		m.log.Warn("failed to load oldest recently accepted block, falling back to the current height",
+			zap.Stringer("blkID", oldest),
+			zap.Error(err),
+		)
+		return m.getCurrentHeight(ctx)
 	}
 
 	// We subtract 1 from the height of [oldest] because we want the height of
@@ -220,6 +342,176 @@ func (m *manager) GetValidatorSet(
 	return validatorSet, nil
 }
 
+func (m *manager) ExportValidatorSet(
+	ctx context.Context,
+	height uint64,
+	subnetID ids.ID,
+) ([]byte, error) {
+	validatorSet, err := m.GetValidatorSet(ctx, height, subnetID)
+	if err != nil {
+		return nil, err
+	}
+	return exportValidatorSet(height, subnetID, validatorSet)
+}
+
+func (m *manager) GetValidatorSetAggregatePublicKey(
+	ctx context.Context,
+	height uint64,
+	subnetID ids.ID,
+	signers set.Set[ids.NodeID],
+) (*bls.PublicKey, uint64, error) {
+	validatorSet, err := m.GetValidatorSet(ctx, height, subnetID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var (
+		publicKeys = make([]*bls.PublicKey, 0, signers.Len())
+		weight     uint64
+	)
+	for nodeID := range signers {
+		vdr, ok := validatorSet[nodeID]
+		if !ok {
+			continue
+		}
+		if vdr.PublicKey == nil {
+			return nil, 0, fmt.Errorf("%w: %s", errMissingPublicKey, nodeID)
+		}
+
+		publicKeys = append(publicKeys, vdr.PublicKey)
+		weight, err = math.Add64(weight, vdr.Weight)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	aggregatePublicKey, err := bls.AggregatePublicKeys(publicKeys)
+	if err != nil {
+		return nil, 0, err
+	}
+	return aggregatePublicKey, weight, nil
+}
+
+func (m *manager) GetValidatorSetDelta(
+	ctx context.Context,
+	fromHeight uint64,
+	toHeight uint64,
+	subnetID ids.ID,
+) (
+	map[ids.NodeID]*validators.GetValidatorOutput,
+	map[ids.NodeID]*validators.GetValidatorOutput,
+	map[ids.NodeID]int64,
+	error,
+) {
+	lowHeight, highHeight := fromHeight, toHeight
+	if fromHeight > toHeight {
+		lowHeight, highHeight = toHeight, fromHeight
+	}
+
+	highValidatorSet, err := m.GetValidatorSet(ctx, highHeight, subnetID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	lowValidatorSet, err := m.applyDiffs(ctx, highValidatorSet, highHeight, lowHeight, subnetID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var (
+		added         = make(map[ids.NodeID]*validators.GetValidatorOutput)
+		removed       = make(map[ids.NodeID]*validators.GetValidatorOutput)
+		weightChanges = make(map[ids.NodeID]int64)
+	)
+	for nodeID, highVdr := range highValidatorSet {
+		lowVdr, ok := lowValidatorSet[nodeID]
+		if !ok {
+			added[nodeID] = highVdr
+			continue
+		}
+		if highVdr.Weight != lowVdr.Weight {
+			weightChanges[nodeID] = weightDelta(lowVdr.Weight, highVdr.Weight)
+		}
+	}
+	for nodeID, lowVdr := range lowValidatorSet {
+		if _, ok := highValidatorSet[nodeID]; !ok {
+			removed[nodeID] = lowVdr
+		}
+	}
+
+	if fromHeight <= toHeight {
+		return added, removed, weightChanges, nil
+	}
+
+	// The changes were calculated from [toHeight] to [fromHeight], so they
+	// must be reversed.
+	for nodeID, change := range weightChanges {
+		weightChanges[nodeID] = -change
+	}
+	return removed, added, weightChanges, nil
+}
+
+// applyDiffs returns the validator set of [subnetID] at [targetHeight] by
+// applying the diffs to a copy of [validatorSet], which is the validator set at
+// [height]. [validatorSet] isn't modified.
+func (m *manager) applyDiffs(
+	ctx context.Context,
+	validatorSet map[ids.NodeID]*validators.GetValidatorOutput,
+	height uint64,
+	targetHeight uint64,
+	subnetID ids.ID,
+) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+	targetValidatorSet := make(map[ids.NodeID]*validators.GetValidatorOutput, len(validatorSet))
+	for nodeID, vdr := range validatorSet {
+		vdrCopy := *vdr
+		targetValidatorSet[nodeID] = &vdrCopy
+	}
+	if height == targetHeight {
+		return targetValidatorSet, nil
+	}
+
+	// Because the state interface is implemented to be inclusive, the diffs in
+	// [targetHeight + 1, height] are applied.
+	lastDiffHeight := targetHeight + 1
+	err := m.state.ApplyValidatorWeightDiffs(
+		ctx,
+		targetValidatorSet,
+		height,
+		lastDiffHeight,
+		subnetID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if subnetID != constants.PrimaryNetworkID {
+		// Validators that were removed from the subnet may still be primary
+		// network validators, so their keys at [height] are looked up before
+		// the public key diffs are applied.
+		primaryValidatorSet, err := m.GetValidatorSet(ctx, height, constants.PrimaryNetworkID)
+		if err != nil {
+			return nil, err
+		}
+		setPrimaryPublicKeys(targetValidatorSet, primaryValidatorSet)
+	}
+
+	err = m.state.ApplyValidatorPublicKeyDiffs(
+		ctx,
+		targetValidatorSet,
+		height,
+		lastDiffHeight,
+	)
+	return targetValidatorSet, err
+}
+
+// weightDelta returns the signed difference from [from] to [to].
+func weightDelta(from, to uint64) int64 {
+	if to >= from {
+		return int64(to - from)
+	}
+	return -int64(from - to)
+}
+
 func (m *manager) getValidatorSetCache(subnetID ids.ID) cache.Cacher[uint64, map[ids.NodeID]*validators.GetValidatorOutput] {
 	// Only cache tracked subnets
 	if subnetID != constants.PrimaryNetworkID && !m.cfg.TrackedSubnets.Contains(subnetID) {
@@ -231,13 +523,55 @@ func (m *manager) getValidatorSetCache(subnetID ids.ID) cache.Cacher[uint64, map
 		return validatorSetsCache
 	}
 
-	validatorSetsCache = &cache.LRU[uint64, map[ids.NodeID]*validators.GetValidatorOutput]{
-		Size: validatorSetsCacheSize,
+	if m.cfg.ValidatorSetsCacheBytes > 0 {
+		validatorSetsCache = cache.NewSizedLRU[uint64, map[ids.NodeID]*validators.GetValidatorOutput](
+			m.cfg.ValidatorSetsCacheBytes,
+			validatorSetSize,
+		)
+	} else {
+		validatorSetsCache = &cache.LRU[uint64, map[ids.NodeID]*validators.GetValidatorOutput]{
+			Size: validatorSetsCacheSize,
+		}
 	}
 	m.caches[subnetID] = validatorSetsCache
 	return validatorSetsCache
 }
 
+// validatorSetSize returns the estimated number of bytes used by [vdrs] when
+// cached at a height.
+func validatorSetSize(_ uint64, vdrs map[ids.NodeID]*validators.GetValidatorOutput) int {
+	size := wrappers.LongLen + constants.PointerOverhead
+	for _, vdr := range vdrs {
+		// The node ID is stored in both the key and the value.
+		size += 2*ids.NodeIDLen + wrappers.LongLen + 2*constants.PointerOverhead
+		if vdr.PublicKey != nil {
+			size += bls.PublicKeyLen
+		}
+	}
+	return size
+}
+
+func (m *manager) CachedSubnets() []ids.ID {
+	subnetIDs := maps.Keys(m.caches)
+	utils.Sort(subnetIDs)
+	return subnetIDs
+}
+
+func (m *manager) RefreshSubnet(ctx context.Context, subnetID ids.ID) error {
+	if subnetID != constants.PrimaryNetworkID && !m.cfg.TrackedSubnets.Contains(subnetID) {
+		return nil
+	}
+
+	delete(m.caches, subnetID)
+
+	currentHeight, err := m.getCurrentHeight(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = m.GetValidatorSet(ctx, currentHeight, subnetID)
+	return err
+}
+
 func (m *manager) makePrimaryNetworkValidatorSet(
 	ctx context.Context,
 	targetHeight uint64,
@@ -249,6 +583,11 @@ func (m *manager) makePrimaryNetworkValidatorSet(
 	if currentHeight < targetHeight {
 		return nil, 0, database.ErrNotFound
 	}
+	if currentHeight == targetHeight {
+		// The current validator set is requested, so there are no diffs to
+		// apply.
+		return validatorSet, currentHeight, nil
+	}
 
 	// Rebuild primary network validators at [targetHeight]
 	//
@@ -297,6 +636,12 @@ func (m *manager) makeSubnetValidatorSet(
 	if currentHeight < targetHeight {
 		return nil, 0, database.ErrNotFound
 	}
+	if currentHeight == targetHeight {
+		// The current validator set is requested, so there are no diffs to
+		// apply.
+		setPrimaryPublicKeys(subnetValidatorSet, primaryValidatorSet)
+		return subnetValidatorSet, currentHeight, nil
+	}
 
 	// Rebuild subnet validators at [targetHeight]
 	//
@@ -321,13 +666,7 @@ func (m *manager) makeSubnetValidatorSet(
 	// these keys to represent the public keys at [targetHeight]. If the subnet
 	// validator is not currently a primary network validator, it doesn't have a
 	// key at [currentHeight].
-	for nodeID, vdr := range subnetValidatorSet {
-		if primaryVdr, ok := primaryValidatorSet[nodeID]; ok {
-			vdr.PublicKey = primaryVdr.PublicKey
-		} else {
-			vdr.PublicKey = nil
-		}
-	}
+	setPrimaryPublicKeys(subnetValidatorSet, primaryValidatorSet)
 
 	err = m.state.ApplyValidatorPublicKeyDiffs(
 		ctx,
@@ -338,6 +677,21 @@ func (m *manager) makeSubnetValidatorSet(
 	return subnetValidatorSet, currentHeight, err
 }
 
+// setPrimaryPublicKeys sets the public key of each validator in
+// [subnetValidatorSet] to its key in [primaryValidatorSet], if any.
+func setPrimaryPublicKeys(
+	subnetValidatorSet map[ids.NodeID]*validators.GetValidatorOutput,
+	primaryValidatorSet map[ids.NodeID]*validators.GetValidatorOutput,
+) {
+	for nodeID, vdr := range subnetValidatorSet {
+		if primaryVdr, ok := primaryValidatorSet[nodeID]; ok {
+			vdr.PublicKey = primaryVdr.PublicKey
+		} else {
+			vdr.PublicKey = nil
+		}
+	}
+}
+
 func (m *manager) getCurrentValidatorSets(
 	ctx context.Context,
 	subnetID ids.ID,
@@ -353,6 +707,10 @@ func (m *manager) GetSubnetID(_ context.Context, chainID ids.ID) (ids.ID, error)
 		return constants.PrimaryNetworkID, nil
 	}
 
+	if subnetID, ok := m.subnetIDs.Get(chainID); ok {
+		return subnetID, nil
+	}
+
 	chainTx, _, err := m.state.GetTx(chainID)
 	if err != nil {
 		return ids.Empty, fmt.Errorf(
@@ -365,6 +723,8 @@ func (m *manager) GetSubnetID(_ context.Context, chainID ids.ID) (ids.ID, error)
 	if !ok {
 		return ids.Empty, fmt.Errorf("%q is not a blockchain", chainID)
 	}
+
+	m.subnetIDs.Put(chainID, chain.SubnetID)
 	return chain.SubnetID, nil
 }
 
@@ -204,7 +204,9 @@ func addPrimaryValidator(
 		return ids.EmptyNodeID, err
 	}
 
-	s.AddStatelessBlock(blk)
+	if err := s.AddStatelessBlock(blk); err != nil {
+		return ids.EmptyNodeID, err
+	}
 	s.SetHeight(height)
 	return nodeID, s.Commit()
 }
@@ -234,7 +236,9 @@ func addSubnetValidator(
 		return err
 	}
 
-	s.AddStatelessBlock(blk)
+	if err := s.AddStatelessBlock(blk); err != nil {
+		return err
+	}
 	s.SetHeight(height)
 	return s.Commit()
 }
@@ -266,7 +270,9 @@ func addSubnetDelegator(
 		return err
 	}
 
-	s.AddStatelessBlock(blk)
+	if err := s.AddStatelessBlock(blk); err != nil {
+		return err
+	}
 	s.SetLastAccepted(blk.ID())
 	s.SetHeight(height)
 	return s.Commit()
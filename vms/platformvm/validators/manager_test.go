@@ -0,0 +1,689 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validators
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/utils/timer/mockable"
+	"github.com/ava-labs/avalanchego/vms/platformvm/block"
+	"github.com/ava-labs/avalanchego/vms/platformvm/config"
+	"github.com/ava-labs/avalanchego/vms/platformvm/metrics"
+	"github.com/ava-labs/avalanchego/vms/platformvm/state"
+	"github.com/ava-labs/avalanchego/vms/platformvm/status"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+)
+
+func TestCachedSubnets(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		trackedSubnetID   = ids.GenerateTestID()
+		untrackedSubnetID = ids.GenerateTestID()
+	)
+	m := NewManager(
+		logging.NoLog{},
+		config.Config{
+			TrackedSubnets: set.Of(trackedSubnetID),
+		},
+		nil,
+		metrics.Noop,
+		&mockable.Clock{},
+	)
+	require.Empty(m.CachedSubnets())
+
+	// Caches are created lazily, and only for tracked subnets
+	mgr := m.(*manager)
+	_ = mgr.getValidatorSetCache(untrackedSubnetID)
+	require.Empty(m.CachedSubnets())
+
+	_ = mgr.getValidatorSetCache(trackedSubnetID)
+	require.Equal([]ids.ID{trackedSubnetID}, m.CachedSubnets())
+
+	// The primary network ID is the empty ID, so it is sorted first
+	_ = mgr.getValidatorSetCache(constants.PrimaryNetworkID)
+	require.Equal([]ids.ID{constants.PrimaryNetworkID, trackedSubnetID}, m.CachedSubnets())
+}
+
+func TestGetValidatorSetAggregatePublicKey(t *testing.T) {
+	const height = 10
+
+	newPublicKey := func() *bls.PublicKey {
+		sk, err := bls.NewSecretKey()
+		require.NoError(t, err)
+		return bls.PublicFromSecretKey(sk)
+	}
+
+	var (
+		nodeID0 = ids.GenerateTestNodeID()
+		nodeID1 = ids.GenerateTestNodeID()
+		nodeID2 = ids.GenerateTestNodeID()
+		nodeID3 = ids.GenerateTestNodeID()
+
+		pk0 = newPublicKey()
+		pk1 = newPublicKey()
+		pk2 = newPublicKey()
+
+		validatorSet = map[ids.NodeID]*validators.GetValidatorOutput{
+			nodeID0: {
+				NodeID:    nodeID0,
+				PublicKey: pk0,
+				Weight:    1,
+			},
+			nodeID1: {
+				NodeID:    nodeID1,
+				PublicKey: pk1,
+				Weight:    2,
+			},
+			nodeID2: {
+				NodeID:    nodeID2,
+				PublicKey: pk2,
+				Weight:    4,
+			},
+			nodeID3: {
+				NodeID: nodeID3,
+				Weight: 8,
+			},
+		}
+	)
+
+	tests := map[string]struct {
+		signers            set.Set[ids.NodeID]
+		expectedPublicKeys []*bls.PublicKey
+		expectedWeight     uint64
+		expectedErr        error
+	}{
+		"subset of signers": {
+			signers:            set.Of(nodeID0, nodeID2),
+			expectedPublicKeys: []*bls.PublicKey{pk0, pk2},
+			expectedWeight:     5,
+		},
+		"signer not in the validator set": {
+			signers:            set.Of(nodeID1, ids.GenerateTestNodeID()),
+			expectedPublicKeys: []*bls.PublicKey{pk1},
+			expectedWeight:     2,
+		},
+		"signer without a public key": {
+			signers:     set.Of(nodeID0, nodeID3),
+			expectedErr: errMissingPublicKey,
+		},
+		"no signers in the validator set": {
+			signers:     set.Of(ids.GenerateTestNodeID()),
+			expectedErr: bls.ErrNoPublicKeys,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			m := NewManager(
+				logging.NoLog{},
+				config.Config{},
+				nil,
+				metrics.Noop,
+				&mockable.Clock{},
+			)
+
+			// The state isn't provided, so the validator set must be served
+			// from the cache.
+			m.(*manager).getValidatorSetCache(constants.PrimaryNetworkID).Put(height, validatorSet)
+
+			pk, weight, err := m.GetValidatorSetAggregatePublicKey(
+				context.Background(),
+				height,
+				constants.PrimaryNetworkID,
+				test.signers,
+			)
+			require.ErrorIs(err, test.expectedErr)
+			if test.expectedErr != nil {
+				return
+			}
+
+			expectedPK, err := bls.AggregatePublicKeys(test.expectedPublicKeys)
+			require.NoError(err)
+			require.Equal(bls.PublicKeyToBytes(expectedPK), bls.PublicKeyToBytes(pk))
+			require.Equal(test.expectedWeight, weight)
+		})
+	}
+}
+
+func TestRefreshSubnet(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	const currentHeight = 5
+
+	var (
+		trackedSubnetID   = ids.GenerateTestID()
+		untrackedSubnetID = ids.GenerateTestID()
+		nodeID            = ids.GenerateTestNodeID()
+		lastAcceptedID    = ids.GenerateTestID()
+
+		vdrs = validators.NewManager()
+	)
+	require.NoError(vdrs.AddStaker(trackedSubnetID, nodeID, nil, ids.Empty, 1))
+
+	lastAccepted := block.NewMockBlock(ctrl)
+	lastAccepted.EXPECT().Height().Return(uint64(currentHeight)).AnyTimes()
+
+	s := state.NewMockState(ctrl)
+	s.EXPECT().GetLastAccepted().Return(lastAcceptedID).AnyTimes()
+	s.EXPECT().GetStatelessBlock(lastAcceptedID).Return(lastAccepted, nil).AnyTimes()
+
+	m := NewManager(
+		logging.NoLog{},
+		config.Config{
+			Validators:     vdrs,
+			TrackedSubnets: set.Of(trackedSubnetID),
+		},
+		s,
+		metrics.Noop,
+		&mockable.Clock{},
+	)
+
+	// Populate the cache with stale validator sets
+	staleValidatorSet := map[ids.NodeID]*validators.GetValidatorOutput{}
+	mgr := m.(*manager)
+	mgr.getValidatorSetCache(trackedSubnetID).Put(currentHeight-1, staleValidatorSet)
+	mgr.getValidatorSetCache(trackedSubnetID).Put(currentHeight, staleValidatorSet)
+
+	require.NoError(m.RefreshSubnet(context.Background(), trackedSubnetID))
+
+	validatorSetsCache := mgr.getValidatorSetCache(trackedSubnetID)
+	_, ok := validatorSetsCache.Get(currentHeight - 1)
+	require.False(ok)
+
+	validatorSet, ok := validatorSetsCache.Get(currentHeight)
+	require.True(ok)
+	require.Equal(
+		map[ids.NodeID]*validators.GetValidatorOutput{
+			nodeID: {
+				NodeID: nodeID,
+				Weight: 1,
+			},
+		},
+		validatorSet,
+	)
+
+	// Refreshing an untracked subnet must not create a cache
+	require.NoError(m.RefreshSubnet(context.Background(), untrackedSubnetID))
+	require.Equal([]ids.ID{trackedSubnetID}, m.CachedSubnets())
+}
+
+func TestGetValidatorSetAtCurrentHeight(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	const currentHeight = 5
+
+	sk, err := bls.NewSecretKey()
+	require.NoError(err)
+
+	var (
+		subnetID       = ids.GenerateTestID()
+		nodeID         = ids.GenerateTestNodeID()
+		lastAcceptedID = ids.GenerateTestID()
+		pk             = bls.PublicFromSecretKey(sk)
+
+		vdrs = validators.NewManager()
+	)
+	require.NoError(vdrs.AddStaker(constants.PrimaryNetworkID, nodeID, pk, ids.Empty, 2))
+	require.NoError(vdrs.AddStaker(subnetID, nodeID, nil, ids.Empty, 1))
+
+	lastAccepted := block.NewMockBlock(ctrl)
+	lastAccepted.EXPECT().Height().Return(uint64(currentHeight)).AnyTimes()
+
+	// The diffs must not be applied when the current validator set is
+	// requested.
+	s := state.NewMockState(ctrl)
+	s.EXPECT().GetLastAccepted().Return(lastAcceptedID).AnyTimes()
+	s.EXPECT().GetStatelessBlock(lastAcceptedID).Return(lastAccepted, nil).AnyTimes()
+
+	m := NewManager(
+		logging.NoLog{},
+		config.Config{
+			Validators:     vdrs,
+			TrackedSubnets: set.Of(subnetID),
+		},
+		s,
+		metrics.Noop,
+		&mockable.Clock{},
+	)
+
+	primaryValidatorSet, err := m.GetValidatorSet(context.Background(), currentHeight, constants.PrimaryNetworkID)
+	require.NoError(err)
+	require.Equal(
+		map[ids.NodeID]*validators.GetValidatorOutput{
+			nodeID: {
+				NodeID:    nodeID,
+				PublicKey: pk,
+				Weight:    2,
+			},
+		},
+		primaryValidatorSet,
+	)
+
+	subnetValidatorSet, err := m.GetValidatorSet(context.Background(), currentHeight, subnetID)
+	require.NoError(err)
+	require.Equal(
+		map[ids.NodeID]*validators.GetValidatorOutput{
+			nodeID: {
+				NodeID:    nodeID,
+				PublicKey: pk,
+				Weight:    1,
+			},
+		},
+		subnetValidatorSet,
+	)
+
+	validatorSet, ok := m.(*manager).getValidatorSetCache(subnetID).Get(currentHeight)
+	require.True(ok)
+	require.Equal(subnetValidatorSet, validatorSet)
+
+	_, err = m.GetValidatorSet(context.Background(), currentHeight+1, subnetID)
+	require.ErrorIs(err, database.ErrNotFound)
+}
+
+func TestGetMinimumHeightMissingOldestBlock(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	const currentHeight = 5
+
+	var (
+		oldestID       = ids.GenerateTestID()
+		lastAcceptedID = ids.GenerateTestID()
+	)
+
+	lastAccepted := block.NewMockBlock(ctrl)
+	lastAccepted.EXPECT().Height().Return(uint64(currentHeight))
+
+	// The oldest block in the window can't be loaded, e.g. because it was
+	// pruned.
+	s := state.NewMockState(ctrl)
+	s.EXPECT().GetStatelessBlock(oldestID).Return(nil, database.ErrNotFound)
+	s.EXPECT().GetLastAccepted().Return(lastAcceptedID)
+	s.EXPECT().GetStatelessBlock(lastAcceptedID).Return(lastAccepted, nil)
+
+	m := NewManager(
+		logging.NoLog{},
+		config.Config{
+			Validators: validators.NewManager(),
+		},
+		s,
+		metrics.Noop,
+		&mockable.Clock{},
+	)
+	m.OnAcceptedBlockID(oldestID)
+
+	height, err := m.GetMinimumHeight(context.Background())
+	require.NoError(err)
+	require.Equal(uint64(currentHeight), height)
+}
+
+func TestGetValidatorSetDelta(t *testing.T) {
+	const (
+		lowHeight  = 5
+		highHeight = 10
+	)
+
+	newPublicKey := func() *bls.PublicKey {
+		sk, err := bls.NewSecretKey()
+		require.NoError(t, err)
+		return bls.PublicFromSecretKey(sk)
+	}
+
+	var (
+		joinedNodeID  = ids.GenerateTestNodeID()
+		leftNodeID    = ids.GenerateTestNodeID()
+		changedNodeID = ids.GenerateTestNodeID()
+		stableNodeID  = ids.GenerateTestNodeID()
+
+		joinedPK  = newPublicKey()
+		leftPK    = newPublicKey()
+		changedPK = newPublicKey()
+		stablePK  = newPublicKey()
+	)
+
+	tests := map[string]struct {
+		fromHeight            uint64
+		toHeight              uint64
+		expectedAdded         map[ids.NodeID]*validators.GetValidatorOutput
+		expectedRemoved       map[ids.NodeID]*validators.GetValidatorOutput
+		expectedWeightChanges map[ids.NodeID]int64
+	}{
+		"increasing heights": {
+			fromHeight: lowHeight,
+			toHeight:   highHeight,
+			expectedAdded: map[ids.NodeID]*validators.GetValidatorOutput{
+				joinedNodeID: {
+					NodeID:    joinedNodeID,
+					PublicKey: joinedPK,
+					Weight:    1,
+				},
+			},
+			expectedRemoved: map[ids.NodeID]*validators.GetValidatorOutput{
+				leftNodeID: {
+					NodeID:    leftNodeID,
+					PublicKey: leftPK,
+					Weight:    3,
+				},
+			},
+			expectedWeightChanges: map[ids.NodeID]int64{
+				changedNodeID: 3,
+			},
+		},
+		"decreasing heights": {
+			fromHeight: highHeight,
+			toHeight:   lowHeight,
+			expectedAdded: map[ids.NodeID]*validators.GetValidatorOutput{
+				leftNodeID: {
+					NodeID:    leftNodeID,
+					PublicKey: leftPK,
+					Weight:    3,
+				},
+			},
+			expectedRemoved: map[ids.NodeID]*validators.GetValidatorOutput{
+				joinedNodeID: {
+					NodeID:    joinedNodeID,
+					PublicKey: joinedPK,
+					Weight:    1,
+				},
+			},
+			expectedWeightChanges: map[ids.NodeID]int64{
+				changedNodeID: -3,
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			ctrl := gomock.NewController(t)
+
+			highValidatorSet := map[ids.NodeID]*validators.GetValidatorOutput{
+				joinedNodeID: {
+					NodeID:    joinedNodeID,
+					PublicKey: joinedPK,
+					Weight:    1,
+				},
+				changedNodeID: {
+					NodeID:    changedNodeID,
+					PublicKey: changedPK,
+					Weight:    5,
+				},
+				stableNodeID: {
+					NodeID:    stableNodeID,
+					PublicKey: stablePK,
+					Weight:    7,
+				},
+			}
+
+			s := state.NewMockState(ctrl)
+			s.EXPECT().ApplyValidatorWeightDiffs(
+				gomock.Any(),
+				gomock.Any(),
+				uint64(highHeight),
+				uint64(lowHeight+1),
+				constants.PrimaryNetworkID,
+			).DoAndReturn(func(_ context.Context, vdrs map[ids.NodeID]*validators.GetValidatorOutput, _, _ uint64, _ ids.ID) error {
+				delete(vdrs, joinedNodeID)
+				vdrs[leftNodeID] = &validators.GetValidatorOutput{
+					NodeID: leftNodeID,
+					Weight: 3,
+				}
+				vdrs[changedNodeID].Weight = 2
+				return nil
+			})
+			s.EXPECT().ApplyValidatorPublicKeyDiffs(
+				gomock.Any(),
+				gomock.Any(),
+				uint64(highHeight),
+				uint64(lowHeight+1),
+			).DoAndReturn(func(_ context.Context, vdrs map[ids.NodeID]*validators.GetValidatorOutput, _, _ uint64) error {
+				vdrs[leftNodeID].PublicKey = leftPK
+				return nil
+			})
+
+			m := NewManager(
+				logging.NoLog{},
+				config.Config{},
+				s,
+				metrics.Noop,
+				&mockable.Clock{},
+			)
+			m.(*manager).getValidatorSetCache(constants.PrimaryNetworkID).Put(highHeight, highValidatorSet)
+
+			added, removed, weightChanges, err := m.GetValidatorSetDelta(
+				context.Background(),
+				test.fromHeight,
+				test.toHeight,
+				constants.PrimaryNetworkID,
+			)
+			require.NoError(err)
+			require.Equal(test.expectedAdded, added)
+			require.Equal(test.expectedRemoved, removed)
+			require.Equal(test.expectedWeightChanges, weightChanges)
+
+			// The cached validator set must not be modified.
+			require.Len(highValidatorSet, 3)
+			require.Equal(uint64(5), highValidatorSet[changedNodeID].Weight)
+		})
+	}
+}
+
+// countingState counts the number of times a tx is loaded.
+type countingState struct {
+	State
+
+	txs       map[ids.ID]*txs.Tx
+	numGetTxs int
+}
+
+func (s *countingState) GetTx(txID ids.ID) (*txs.Tx, status.Status, error) {
+	s.numGetTxs++
+	tx, ok := s.txs[txID]
+	if !ok {
+		return nil, status.Unknown, database.ErrNotFound
+	}
+	return tx, status.Committed, nil
+}
+
+func TestGetSubnetIDCached(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		chainID  = ids.GenerateTestID()
+		subnetID = ids.GenerateTestID()
+		s        = &countingState{
+			txs: map[ids.ID]*txs.Tx{
+				chainID: {
+					Unsigned: &txs.CreateChainTx{
+						SubnetID: subnetID,
+					},
+				},
+			},
+		}
+	)
+	m := NewManager(
+		logging.NoLog{},
+		config.Config{},
+		s,
+		metrics.Noop,
+		&mockable.Clock{},
+	)
+
+	// The P-chain is handled without loading a tx
+	gotSubnetID, err := m.GetSubnetID(context.Background(), constants.PlatformChainID)
+	require.NoError(err)
+	require.Equal(constants.PrimaryNetworkID, gotSubnetID)
+	require.Zero(s.numGetTxs)
+
+	gotSubnetID, err = m.GetSubnetID(context.Background(), chainID)
+	require.NoError(err)
+	require.Equal(subnetID, gotSubnetID)
+	require.Equal(1, s.numGetTxs)
+
+	gotSubnetID, err = m.GetSubnetID(context.Background(), chainID)
+	require.NoError(err)
+	require.Equal(subnetID, gotSubnetID)
+	require.Equal(1, s.numGetTxs)
+
+	// Failed lookups aren't cached
+	unknownChainID := ids.GenerateTestID()
+	_, err = m.GetSubnetID(context.Background(), unknownChainID)
+	require.ErrorIs(err, database.ErrNotFound)
+	_, err = m.GetSubnetID(context.Background(), unknownChainID)
+	require.ErrorIs(err, database.ErrNotFound)
+	require.Equal(3, s.numGetTxs)
+}
+
+func TestVerifyConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         config.Config
+		expectedErr error
+	}{
+		{
+			name: "defaults",
+		},
+		{
+			name: "custom",
+			cfg: config.Config{
+				RecentlyAcceptedWindowSize: 8,
+				RecentlyAcceptedWindowTTL:  durationPtr(time.Minute),
+			},
+		},
+		{
+			name: "negative size",
+			cfg: config.Config{
+				RecentlyAcceptedWindowSize: -1,
+			},
+			expectedErr: ErrInvalidRecentlyAcceptedWindowSize,
+		},
+		{
+			name: "negative ttl",
+			cfg: config.Config{
+				RecentlyAcceptedWindowTTL: durationPtr(-time.Second),
+			},
+			expectedErr: ErrInvalidRecentlyAcceptedWindowTTL,
+		},
+		{
+			name: "negative validator sets cache bytes",
+			cfg: config.Config{
+				ValidatorSetsCacheBytes: -1,
+			},
+			expectedErr: ErrInvalidValidatorSetsCacheBytes,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := VerifyConfig(test.cfg)
+			require.ErrorIs(t, err, test.expectedErr)
+		})
+	}
+}
+
+func TestValidatorSetsCacheBytes(t *testing.T) {
+	require := require.New(t)
+
+	newValidatorSet := func(numValidators int) map[ids.NodeID]*validators.GetValidatorOutput {
+		vdrs := make(map[ids.NodeID]*validators.GetValidatorOutput, numValidators)
+		for i := 0; i < numValidators; i++ {
+			nodeID := ids.GenerateTestNodeID()
+			vdrs[nodeID] = &validators.GetValidatorOutput{
+				NodeID: nodeID,
+				Weight: 1,
+			}
+		}
+		return vdrs
+	}
+
+	var (
+		largeSet  = newValidatorSet(100)
+		smallSets = []map[ids.NodeID]*validators.GetValidatorOutput{
+			newValidatorSet(1),
+			newValidatorSet(1),
+			newValidatorSet(1),
+		}
+		largeSize = validatorSetSize(0, largeSet)
+		smallSize = validatorSetSize(0, smallSets[0])
+	)
+	require.Greater(largeSize, smallSize)
+
+	m := NewManager(
+		logging.NoLog{},
+		config.Config{
+			Validators: validators.NewManager(),
+			// Not enough room for all of the sets.
+			ValidatorSetsCacheBytes: largeSize + len(smallSets)*smallSize - 1,
+		},
+		nil,
+		metrics.Noop,
+		&mockable.Clock{},
+	)
+	c := m.(*manager).getValidatorSetCache(constants.PrimaryNetworkID)
+
+	// The large set is the oldest, so it is evicted first even though the
+	// small sets would fit.
+	c.Put(1, largeSet)
+	for i, smallSet := range smallSets {
+		c.Put(uint64(i+2), smallSet)
+	}
+
+	_, ok := c.Get(1)
+	require.False(ok)
+	for i, smallSet := range smallSets {
+		cachedSet, ok := c.Get(uint64(i + 2))
+		require.True(ok)
+		require.Equal(smallSet, cachedSet)
+	}
+}
+
+func TestRecentlyAcceptedWindowConfig(t *testing.T) {
+	require := require.New(t)
+
+	clk := &mockable.Clock{}
+	defaultCfg := recentlyAcceptedWindowConfig(config.Config{}, clk)
+	require.Equal(maxRecentlyAcceptedWindowSize, defaultCfg.MaxSize)
+	require.Equal(minRecentlyAcceptedWindowSize, defaultCfg.MinSize)
+	require.Equal(recentlyAcceptedWindowTTL, defaultCfg.TTL)
+
+	// The minimum size is capped by a smaller maximum size.
+	customCfg := recentlyAcceptedWindowConfig(
+		config.Config{
+			RecentlyAcceptedWindowSize: 4,
+			RecentlyAcceptedWindowTTL:  durationPtr(time.Minute),
+		},
+		clk,
+	)
+	require.Equal(4, customCfg.MaxSize)
+	require.Equal(4, customCfg.MinSize)
+	require.Equal(time.Minute, customCfg.TTL)
+
+	// A TTL of 0 isn't replaced by the default.
+	zeroTTLCfg := recentlyAcceptedWindowConfig(
+		config.Config{
+			RecentlyAcceptedWindowTTL: durationPtr(0),
+		},
+		clk,
+	)
+	require.Zero(zeroTTLCfg.TTL)
+}
+
+func durationPtr(d time.Duration) *time.Duration {
+	return &d
+}
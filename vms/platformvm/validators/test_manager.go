@@ -8,6 +8,8 @@ import (
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/set"
 )
 
 var TestManager Manager = testManager{}
@@ -30,4 +32,29 @@ func (testManager) GetValidatorSet(context.Context, uint64, ids.ID) (map[ids.Nod
 	return nil, nil
 }
 
+func (testManager) ExportValidatorSet(context.Context, uint64, ids.ID) ([]byte, error) {
+	return nil, nil
+}
+
+func (testManager) GetValidatorSetAggregatePublicKey(context.Context, uint64, ids.ID, set.Set[ids.NodeID]) (*bls.PublicKey, uint64, error) {
+	return nil, 0, nil
+}
+
+func (testManager) GetValidatorSetDelta(context.Context, uint64, uint64, ids.ID) (
+	map[ids.NodeID]*validators.GetValidatorOutput,
+	map[ids.NodeID]*validators.GetValidatorOutput,
+	map[ids.NodeID]int64,
+	error,
+) {
+	return nil, nil, nil, nil
+}
+
+func (testManager) CachedSubnets() []ids.ID {
+	return nil
+}
+
+func (testManager) RefreshSubnet(context.Context, ids.ID) error {
+	return nil
+}
+
 func (testManager) OnAcceptedBlockID(ids.ID) {}
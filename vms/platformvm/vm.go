@@ -113,6 +113,10 @@ func (vm *VM) Initialize(
 	}
 	chainCtx.Log.Info("using VM execution config", zap.Reflect("config", execConfig))
 
+	if err := pvalidators.VerifyConfig(vm.Config); err != nil {
+		return err
+	}
+
 	registerer := prometheus.NewRegistry()
 	if err := chainCtx.Metrics.Register(registerer); err != nil {
 		return err
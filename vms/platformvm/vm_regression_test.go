@@ -644,9 +644,10 @@ func TestRejectedStateRegressionInvalidValidatorTimestamp(t *testing.T) {
 	// Force a reload of the state from the database.
 	vm.Config.Validators = validators.NewManager()
 	execCfg, _ := config.GetExecutionConfig(nil)
+	_, genesisBytes := defaultGenesis(t)
 	newState, err := state.New(
 		vm.db,
-		nil,
+		genesisBytes,
 		prometheus.NewRegistry(),
 		vm.Config.Validators,
 		execCfg,
@@ -951,9 +952,10 @@ func TestRejectedStateRegressionInvalidValidatorReward(t *testing.T) {
 	// Force a reload of the state from the database.
 	vm.Config.Validators = validators.NewManager()
 	execCfg, _ := config.GetExecutionConfig(nil)
+	_, genesisBytes := defaultGenesis(t)
 	newState, err := state.New(
 		vm.db,
-		nil,
+		genesisBytes,
 		prometheus.NewRegistry(),
 		vm.Config.Validators,
 		execCfg,
@@ -62,6 +62,7 @@ type ChangeProofer interface {
 	// GetChangeProof returns a proof for a subset of the key/value changes in key range
 	// [start, end] that occurred between [startRootID] and [endRootID].
 	// Returns at most [maxLength] key/value pairs.
+	// The size of the proof is further limited by [config].
 	// Returns [ErrInsufficientHistory] if this node has insufficient history
 	// to generate the proof.
 	GetChangeProof(
@@ -71,6 +72,7 @@ type ChangeProofer interface {
 		start maybe.Maybe[[]byte],
 		end maybe.Maybe[[]byte],
 		maxLength int,
+		config ProofConfig,
 	) (*ChangeProof, error)
 
 	// Returns nil iff all the following hold:
@@ -100,12 +102,15 @@ type RangeProofer interface {
 	// [start, end] when the root of the trie was [rootID].
 	// If [start] is Nothing, there's no lower bound on the range.
 	// If [end] is Nothing, there's no upper bound on the range.
+	// Returns at most [maxLength] key/value pairs.
+	// The size of the proof is further limited by [config].
 	GetRangeProofAtRoot(
 		ctx context.Context,
 		rootID ids.ID,
 		start maybe.Maybe[[]byte],
 		end maybe.Maybe[[]byte],
 		maxLength int,
+		config ProofConfig,
 	) (*RangeProof, error)
 
 	// CommitRangeProof commits the key/value pairs within the [proof] to the db.
@@ -619,7 +624,7 @@ func (db *merkleDB) GetRangeProof(
 	db.commitLock.RLock()
 	defer db.commitLock.RUnlock()
 
-	return db.getRangeProofAtRoot(ctx, db.getMerkleRoot(), start, end, maxLength)
+	return db.getRangeProofAtRoot(ctx, db.getMerkleRoot(), start, end, maxLength, DefaultProofConfig)
 }
 
 func (db *merkleDB) GetRangeProofAtRoot(
@@ -628,11 +633,12 @@ func (db *merkleDB) GetRangeProofAtRoot(
 	start maybe.Maybe[[]byte],
 	end maybe.Maybe[[]byte],
 	maxLength int,
+	config ProofConfig,
 ) (*RangeProof, error) {
 	db.commitLock.RLock()
 	defer db.commitLock.RUnlock()
 
-	return db.getRangeProofAtRoot(ctx, rootID, start, end, maxLength)
+	return db.getRangeProofAtRoot(ctx, rootID, start, end, maxLength, config)
 }
 
 // Assumes [db.commitLock] is read locked.
@@ -643,6 +649,7 @@ func (db *merkleDB) getRangeProofAtRoot(
 	start maybe.Maybe[[]byte],
 	end maybe.Maybe[[]byte],
 	maxLength int,
+	config ProofConfig,
 ) (*RangeProof, error) {
 	if db.closed {
 		return nil, database.ErrClosed
@@ -655,7 +662,19 @@ func (db *merkleDB) getRangeProofAtRoot(
 	if err != nil {
 		return nil, err
 	}
-	return historicalView.GetRangeProof(ctx, start, end, maxLength)
+
+	for {
+		proof, err := historicalView.getRangeProof(ctx, start, end, maxLength, config)
+		if err != nil {
+			return nil, err
+		}
+
+		numKeyValues := len(proof.KeyValues)
+		if numKeyValues <= 1 || !config.exceedsMaxProofNodes(len(proof.StartProof)+len(proof.EndProof)) {
+			return proof, nil
+		}
+		maxLength = numKeyValues / 2
+	}
 }
 
 func (db *merkleDB) GetChangeProof(
@@ -665,6 +684,7 @@ func (db *merkleDB) GetChangeProof(
 	start maybe.Maybe[[]byte],
 	end maybe.Maybe[[]byte],
 	maxLength int,
+	config ProofConfig,
 ) (*ChangeProof, error) {
 	if start.HasValue() && end.HasValue() && bytes.Compare(start.Value(), end.Value()) == 1 {
 		return nil, ErrStartAfterEnd
@@ -691,20 +711,50 @@ func (db *merkleDB) GetChangeProof(
 	changedKeys := maps.Keys(changes.values)
 	utils.Sort(changedKeys)
 
-	result := &ChangeProof{
-		KeyChanges: make([]KeyChange, 0, len(changedKeys)),
-	}
-
+	keyChanges := make([]KeyChange, 0, len(changedKeys))
 	for _, key := range changedKeys {
 		change := changes.values[key]
+		if !config.includeValue(len(keyChanges), change.after.Value()) {
+			break
+		}
 
-		result.KeyChanges = append(result.KeyChanges, KeyChange{
+		keyChanges = append(keyChanges, KeyChange{
 			Key: key.Bytes(),
 			// create a copy so edits of the []byte don't affect the db
 			Value: maybe.Bind(change.after, slices.Clone[[]byte]),
 		})
 	}
 
+	for {
+		result, err := db.getChangeProof(ctx, endRootID, start, end, keyChanges)
+		if err != nil {
+			return nil, err
+		}
+
+		numKeyChanges := len(result.KeyChanges)
+		if numKeyChanges <= 1 || !config.exceedsMaxProofNodes(len(result.StartProof)+len(result.EndProof)) {
+			return result, nil
+		}
+		keyChanges = keyChanges[:numKeyChanges/2]
+	}
+}
+
+// getChangeProof returns the change proof of [keyChanges] in the trie at
+// [endRootID].
+//
+// Assumes [db.commitLock] is read locked.
+// Assumes [db.lock] is not held
+func (db *merkleDB) getChangeProof(
+	ctx context.Context,
+	endRootID ids.ID,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	keyChanges []KeyChange,
+) (*ChangeProof, error) {
+	result := &ChangeProof{
+		KeyChanges: keyChanges,
+	}
+
 	largestKey := end
 	if len(result.KeyChanges) > 0 {
 		largestKey = maybe.Some(result.KeyChanges[len(result.KeyChanges)-1].Key)
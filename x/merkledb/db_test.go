@@ -947,7 +947,7 @@ func runRandDBTest(require *require.Assertions, r *rand.Rand, rt randTest, token
 				end = maybe.Some(step.value)
 			}
 
-			rangeProof, err := db.GetRangeProofAtRoot(context.Background(), root, start, end, maxProofLen)
+			rangeProof, err := db.GetRangeProofAtRoot(context.Background(), root, start, end, maxProofLen, DefaultProofConfig)
 			require.NoError(err)
 			require.LessOrEqual(len(rangeProof.KeyValues), maxProofLen)
 
@@ -976,7 +976,7 @@ func runRandDBTest(require *require.Assertions, r *rand.Rand, rt randTest, token
 				end = maybe.Some(step.value)
 			}
 
-			changeProof, err := db.GetChangeProof(context.Background(), startRoot, root, start, end, maxProofLen)
+			changeProof, err := db.GetChangeProof(context.Background(), startRoot, root, start, end, maxProofLen, DefaultProofConfig)
 			if startRoot == root {
 				require.ErrorIs(err, errSameRoot)
 				continue
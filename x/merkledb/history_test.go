@@ -43,7 +43,7 @@ func Test_History_Simple(t *testing.T) {
 	batch = db.NewBatch()
 	require.NoError(batch.Put([]byte("key"), []byte("value0")))
 	require.NoError(batch.Write())
-	newProof, err := db.GetRangeProofAtRoot(context.Background(), origRootID, maybe.Some([]byte("k")), maybe.Some([]byte("key3")), 10)
+	newProof, err := db.GetRangeProofAtRoot(context.Background(), origRootID, maybe.Some([]byte("k")), maybe.Some([]byte("key3")), 10, DefaultProofConfig)
 	require.NoError(err)
 	require.NotNil(newProof)
 	require.NoError(newProof.Verify(context.Background(), maybe.Some([]byte("k")), maybe.Some([]byte("key3")), origRootID, db.tokenSize))
@@ -52,7 +52,7 @@ func Test_History_Simple(t *testing.T) {
 	require.NoError(batch.Put([]byte("key1"), []byte("value1")))
 	require.NoError(batch.Put([]byte("key8"), []byte("value8")))
 	require.NoError(batch.Write())
-	newProof, err = db.GetRangeProofAtRoot(context.Background(), origRootID, maybe.Some([]byte("k")), maybe.Some([]byte("key3")), 10)
+	newProof, err = db.GetRangeProofAtRoot(context.Background(), origRootID, maybe.Some([]byte("k")), maybe.Some([]byte("key3")), 10, DefaultProofConfig)
 	require.NoError(err)
 	require.NotNil(newProof)
 	require.NoError(newProof.Verify(context.Background(), maybe.Some([]byte("k")), maybe.Some([]byte("key3")), origRootID, db.tokenSize))
@@ -60,7 +60,7 @@ func Test_History_Simple(t *testing.T) {
 	batch = db.NewBatch()
 	require.NoError(batch.Put([]byte("k"), []byte("v")))
 	require.NoError(batch.Write())
-	newProof, err = db.GetRangeProofAtRoot(context.Background(), origRootID, maybe.Some([]byte("k")), maybe.Some([]byte("key3")), 10)
+	newProof, err = db.GetRangeProofAtRoot(context.Background(), origRootID, maybe.Some([]byte("k")), maybe.Some([]byte("key3")), 10, DefaultProofConfig)
 	require.NoError(err)
 	require.NotNil(newProof)
 	require.NoError(newProof.Verify(context.Background(), maybe.Some([]byte("k")), maybe.Some([]byte("key3")), origRootID, db.tokenSize))
@@ -76,7 +76,7 @@ func Test_History_Simple(t *testing.T) {
 	require.NoError(batch.Delete([]byte("key5")))
 	require.NoError(batch.Delete([]byte("key8")))
 	require.NoError(batch.Write())
-	newProof, err = db.GetRangeProofAtRoot(context.Background(), origRootID, maybe.Some([]byte("k")), maybe.Some([]byte("key3")), 10)
+	newProof, err = db.GetRangeProofAtRoot(context.Background(), origRootID, maybe.Some([]byte("k")), maybe.Some([]byte("key3")), 10, DefaultProofConfig)
 	require.NoError(err)
 	require.NotNil(newProof)
 	require.NoError(newProof.Verify(context.Background(), maybe.Some([]byte("k")), maybe.Some([]byte("key3")), origRootID, db.tokenSize))
@@ -138,7 +138,7 @@ func Test_History_Large(t *testing.T) {
 		}
 
 		for i := 0; i < numIters; i += numIters / 10 {
-			proof, err := db.GetRangeProofAtRoot(context.Background(), roots[i], maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 10)
+			proof, err := db.GetRangeProofAtRoot(context.Background(), roots[i], maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 10, DefaultProofConfig)
 			require.NoError(err)
 			require.NotNil(proof)
 
@@ -250,7 +250,7 @@ func Test_History_Trigger_History_Queue_Looping(t *testing.T) {
 	require.NoError(batch.Write())
 
 	// ensure that previous root is still present and generates a valid proof
-	newProof, err := db.GetRangeProofAtRoot(context.Background(), origRootID, maybe.Some([]byte("k")), maybe.Some([]byte("key3")), 10)
+	newProof, err := db.GetRangeProofAtRoot(context.Background(), origRootID, maybe.Some([]byte("k")), maybe.Some([]byte("key3")), 10, DefaultProofConfig)
 	require.NoError(err)
 	require.NotNil(newProof)
 	require.NoError(newProof.Verify(
@@ -267,7 +267,7 @@ func Test_History_Trigger_History_Queue_Looping(t *testing.T) {
 	require.NoError(batch.Write())
 
 	// proof from first root shouldn't be generatable since it should have been removed from the history
-	_, err = db.GetRangeProofAtRoot(context.Background(), origRootID, maybe.Some([]byte("k")), maybe.Some([]byte("key3")), 10)
+	_, err = db.GetRangeProofAtRoot(context.Background(), origRootID, maybe.Some([]byte("k")), maybe.Some([]byte("key3")), 10, DefaultProofConfig)
 	require.ErrorIs(err, ErrInsufficientHistory)
 }
 
@@ -348,7 +348,7 @@ func Test_History_RepeatedRoot(t *testing.T) {
 	require.NoError(batch.Put([]byte("key2"), []byte("other")))
 	require.NoError(batch.Put([]byte("key3"), []byte("other")))
 	require.NoError(batch.Write())
-	newProof, err := db.GetRangeProofAtRoot(context.Background(), origRootID, maybe.Some([]byte("k")), maybe.Some([]byte("key3")), 10)
+	newProof, err := db.GetRangeProofAtRoot(context.Background(), origRootID, maybe.Some([]byte("k")), maybe.Some([]byte("key3")), 10, DefaultProofConfig)
 	require.NoError(err)
 	require.NotNil(newProof)
 	require.NoError(newProof.Verify(context.Background(), maybe.Some([]byte("k")), maybe.Some([]byte("key3")), origRootID, db.tokenSize))
@@ -360,7 +360,7 @@ func Test_History_RepeatedRoot(t *testing.T) {
 	require.NoError(batch.Put([]byte("key3"), []byte("value3")))
 	require.NoError(batch.Write())
 
-	newProof, err = db.GetRangeProofAtRoot(context.Background(), origRootID, maybe.Some([]byte("k")), maybe.Some([]byte("key3")), 10)
+	newProof, err = db.GetRangeProofAtRoot(context.Background(), origRootID, maybe.Some([]byte("k")), maybe.Some([]byte("key3")), 10, DefaultProofConfig)
 	require.NoError(err)
 	require.NotNil(newProof)
 	require.NoError(newProof.Verify(context.Background(), maybe.Some([]byte("k")), maybe.Some([]byte("key3")), origRootID, db.tokenSize))
@@ -393,7 +393,7 @@ func Test_History_ExcessDeletes(t *testing.T) {
 	require.NoError(batch.Delete([]byte("key4")))
 	require.NoError(batch.Delete([]byte("key5")))
 	require.NoError(batch.Write())
-	newProof, err := db.GetRangeProofAtRoot(context.Background(), origRootID, maybe.Some([]byte("k")), maybe.Some([]byte("key3")), 10)
+	newProof, err := db.GetRangeProofAtRoot(context.Background(), origRootID, maybe.Some([]byte("k")), maybe.Some([]byte("key3")), 10, DefaultProofConfig)
 	require.NoError(err)
 	require.NotNil(newProof)
 	require.NoError(newProof.Verify(context.Background(), maybe.Some([]byte("k")), maybe.Some([]byte("key3")), origRootID, db.tokenSize))
@@ -422,7 +422,7 @@ func Test_History_DontIncludeAllNodes(t *testing.T) {
 	batch = db.NewBatch()
 	require.NoError(batch.Put([]byte("z"), []byte("z")))
 	require.NoError(batch.Write())
-	newProof, err := db.GetRangeProofAtRoot(context.Background(), origRootID, maybe.Some([]byte("k")), maybe.Some([]byte("key3")), 10)
+	newProof, err := db.GetRangeProofAtRoot(context.Background(), origRootID, maybe.Some([]byte("k")), maybe.Some([]byte("key3")), 10, DefaultProofConfig)
 	require.NoError(err)
 	require.NotNil(newProof)
 	require.NoError(newProof.Verify(context.Background(), maybe.Some([]byte("k")), maybe.Some([]byte("key3")), origRootID, db.tokenSize))
@@ -450,7 +450,7 @@ func Test_History_Branching2Nodes(t *testing.T) {
 	batch = db.NewBatch()
 	require.NoError(batch.Put([]byte("k"), []byte("v")))
 	require.NoError(batch.Write())
-	newProof, err := db.GetRangeProofAtRoot(context.Background(), origRootID, maybe.Some([]byte("k")), maybe.Some([]byte("key3")), 10)
+	newProof, err := db.GetRangeProofAtRoot(context.Background(), origRootID, maybe.Some([]byte("k")), maybe.Some([]byte("key3")), 10, DefaultProofConfig)
 	require.NoError(err)
 	require.NotNil(newProof)
 	require.NoError(newProof.Verify(context.Background(), maybe.Some([]byte("k")), maybe.Some([]byte("key3")), origRootID, db.tokenSize))
@@ -479,7 +479,7 @@ func Test_History_Branching3Nodes(t *testing.T) {
 	batch = db.NewBatch()
 	require.NoError(batch.Put([]byte("key321"), []byte("value321")))
 	require.NoError(batch.Write())
-	newProof, err := db.GetRangeProofAtRoot(context.Background(), origRootID, maybe.Some([]byte("k")), maybe.Some([]byte("key3")), 10)
+	newProof, err := db.GetRangeProofAtRoot(context.Background(), origRootID, maybe.Some([]byte("k")), maybe.Some([]byte("key3")), 10, DefaultProofConfig)
 	require.NoError(err)
 	require.NotNil(newProof)
 	require.NoError(newProof.Verify(context.Background(), maybe.Some([]byte("k")), maybe.Some([]byte("key3")), origRootID, db.tokenSize))
@@ -0,0 +1,39 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+// DefaultProofConfig doesn't limit the size of proofs beyond the maximum
+// number of key/value pairs requested.
+var DefaultProofConfig = ProofConfig{}
+
+// ProofConfig limits the size of generated range and change proofs.
+//
+// A proof that is shortened by these limits is still a valid proof of the
+// keys it contains, so the requester can continue from its last key. At least
+// one key/value pair is always included, if one exists in the requested range,
+// to ensure progress.
+type ProofConfig struct {
+	// MaxProofNodes is the maximum number of nodes in the start and end proofs
+	// of a proof. If a proof has more nodes, the number of key/value pairs in
+	// the proof is halved until it doesn't, or until only one key/value pair
+	// remains. If <= 0, the number of proof nodes isn't limited.
+	MaxProofNodes int
+	// MaxInlineValueSize is the maximum length of a value included in a
+	// proof after its first key/value pair. A proof ends before the first
+	// value that is larger, so large values are sent at the start of their
+	// own proof. If <= 0, the length of values isn't limited.
+	MaxInlineValueSize int
+}
+
+// includeValue returns true if [value] should be added to a proof that already
+// has [numKeyValues] key/value pairs.
+func (c ProofConfig) includeValue(numKeyValues int, value []byte) bool {
+	return numKeyValues == 0 || c.MaxInlineValueSize <= 0 || len(value) <= c.MaxInlineValueSize
+}
+
+// exceedsMaxProofNodes returns true if a proof with [numProofNodes] nodes is
+// too large.
+func (c ProofConfig) exceedsMaxProofNodes(numProofNodes int) bool {
+	return c.MaxProofNodes > 0 && numProofNodes > c.MaxProofNodes
+}
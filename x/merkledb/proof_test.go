@@ -6,12 +6,15 @@ package merkledb
 import (
 	"bytes"
 	"context"
+	"math"
 	"math/rand"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"google.golang.org/protobuf/proto"
+
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/hashing"
@@ -516,6 +519,66 @@ func Test_RangeProof(t *testing.T) {
 	))
 }
 
+func Test_RangeProof_ProofConfig(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	// Every tenth value is larger than a hash.
+	batch := db.NewBatch()
+	for i := 0; i < 50; i++ {
+		value := []byte{byte(i)}
+		if i%10 == 5 {
+			value = bytes.Repeat(value, 100)
+		}
+		require.NoError(batch.Put([]byte{byte(i)}, value))
+	}
+	require.NoError(batch.Write())
+
+	root := db.getMerkleRoot()
+	getProof := func(start maybe.Maybe[[]byte], config ProofConfig) *RangeProof {
+		proof, err := db.GetRangeProofAtRoot(context.Background(), root, start, maybe.Nothing[[]byte](), 50, config)
+		require.NoError(err)
+		require.NoError(proof.Verify(
+			context.Background(),
+			start,
+			maybe.Nothing[[]byte](),
+			root,
+			db.tokenSize,
+		))
+		return proof
+	}
+
+	// The default config only limits the number of key/value pairs.
+	defaultProof := getProof(maybe.Nothing[[]byte](), DefaultProofConfig)
+	require.Len(defaultProof.KeyValues, 50)
+	require.Equal(defaultProof, getProof(maybe.Nothing[[]byte](), ProofConfig{
+		MaxProofNodes:      math.MaxInt,
+		MaxInlineValueSize: math.MaxInt,
+	}))
+
+	// The proof ends before the first large value.
+	inlineConfig := ProofConfig{
+		MaxInlineValueSize: 1,
+	}
+	proof := getProof(maybe.Nothing[[]byte](), inlineConfig)
+	require.Len(proof.KeyValues, 5)
+	require.Less(proto.Size(proof.ToProto()), proto.Size(defaultProof.ToProto()))
+
+	// A large value is still included at the start of a proof.
+	proof = getProof(maybe.Some([]byte{5}), inlineConfig)
+	require.Len(proof.KeyValues, 10)
+	require.Equal([]byte{5}, proof.KeyValues[0].Key)
+
+	// The proof is shortened until it has a single key/value pair.
+	proof = getProof(maybe.Some([]byte{5}), ProofConfig{
+		MaxProofNodes: 1,
+	})
+	require.Len(proof.KeyValues, 1)
+	require.Less(proto.Size(proof.ToProto()), proto.Size(defaultProof.ToProto()))
+}
+
 func Test_RangeProof_BadBounds(t *testing.T) {
 	require := require.New(t)
 
@@ -653,7 +716,7 @@ func Test_ChangeProof_Missing_History_For_EndRoot(t *testing.T) {
 	startRoot, err := db.GetMerkleRoot(context.Background())
 	require.NoError(err)
 
-	_, err = db.GetChangeProof(context.Background(), startRoot, ids.Empty, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 50)
+	_, err = db.GetChangeProof(context.Background(), startRoot, ids.Empty, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 50, DefaultProofConfig)
 	require.ErrorIs(err, ErrInsufficientHistory)
 }
 
@@ -672,7 +735,7 @@ func Test_ChangeProof_BadBounds(t *testing.T) {
 	require.NoError(err)
 
 	// non-nil start/end
-	proof, err := db.GetChangeProof(context.Background(), startRoot, endRoot, maybe.Some([]byte("key4")), maybe.Some([]byte("key3")), 50)
+	proof, err := db.GetChangeProof(context.Background(), startRoot, endRoot, maybe.Some([]byte("key4")), maybe.Some([]byte("key3")), 50, DefaultProofConfig)
 	require.ErrorIs(err, ErrStartAfterEnd)
 	require.Nil(proof)
 }
@@ -728,21 +791,21 @@ func Test_ChangeProof_Verify(t *testing.T) {
 	require.NoError(err)
 
 	// non-nil start/end
-	proof, err := db.GetChangeProof(context.Background(), startRoot, endRoot, maybe.Some([]byte("key21")), maybe.Some([]byte("key30")), 50)
+	proof, err := db.GetChangeProof(context.Background(), startRoot, endRoot, maybe.Some([]byte("key21")), maybe.Some([]byte("key30")), 50, DefaultProofConfig)
 	require.NoError(err)
 	require.NotNil(proof)
 
 	require.NoError(dbClone.VerifyChangeProof(context.Background(), proof, maybe.Some([]byte("key21")), maybe.Some([]byte("key30")), db.getMerkleRoot()))
 
 	// low maxLength
-	proof, err = db.GetChangeProof(context.Background(), startRoot, endRoot, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 5)
+	proof, err = db.GetChangeProof(context.Background(), startRoot, endRoot, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 5, DefaultProofConfig)
 	require.NoError(err)
 	require.NotNil(proof)
 
 	require.NoError(dbClone.VerifyChangeProof(context.Background(), proof, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), db.getMerkleRoot()))
 
 	// nil start/end
-	proof, err = db.GetChangeProof(context.Background(), startRoot, endRoot, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 50)
+	proof, err = db.GetChangeProof(context.Background(), startRoot, endRoot, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 50, DefaultProofConfig)
 	require.NoError(err)
 	require.NotNil(proof)
 
@@ -753,13 +816,75 @@ func Test_ChangeProof_Verify(t *testing.T) {
 	require.NoError(err)
 	require.Equal(endRoot, newRoot)
 
-	proof, err = db.GetChangeProof(context.Background(), startRoot, endRoot, maybe.Some([]byte("key20")), maybe.Some([]byte("key30")), 50)
+	proof, err = db.GetChangeProof(context.Background(), startRoot, endRoot, maybe.Some([]byte("key20")), maybe.Some([]byte("key30")), 50, DefaultProofConfig)
 	require.NoError(err)
 	require.NotNil(proof)
 
 	require.NoError(dbClone.VerifyChangeProof(context.Background(), proof, maybe.Some([]byte("key20")), maybe.Some([]byte("key30")), db.getMerkleRoot()))
 }
 
+func Test_ChangeProof_ProofConfig(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	dbClone, err := getBasicDB()
+	require.NoError(err)
+
+	startRoot := db.getMerkleRoot()
+
+	// Every tenth value is larger than a hash.
+	batch := db.NewBatch()
+	for i := 0; i < 50; i++ {
+		value := []byte{byte(i)}
+		if i%10 == 5 {
+			value = bytes.Repeat(value, 100)
+		}
+		require.NoError(batch.Put([]byte{byte(i)}, value))
+	}
+	require.NoError(batch.Write())
+
+	endRoot := db.getMerkleRoot()
+	getProof := func(config ProofConfig) *ChangeProof {
+		proof, err := db.GetChangeProof(
+			context.Background(),
+			startRoot,
+			endRoot,
+			maybe.Nothing[[]byte](),
+			maybe.Nothing[[]byte](),
+			50,
+			config,
+		)
+		require.NoError(err)
+		require.NoError(dbClone.VerifyChangeProof(
+			context.Background(),
+			proof,
+			maybe.Nothing[[]byte](),
+			maybe.Nothing[[]byte](),
+			endRoot,
+		))
+		return proof
+	}
+
+	// The default config only limits the number of key changes.
+	defaultProof := getProof(DefaultProofConfig)
+	require.Len(defaultProof.KeyChanges, 50)
+
+	// The proof ends before the first large value.
+	proof := getProof(ProofConfig{
+		MaxInlineValueSize: 1,
+	})
+	require.Len(proof.KeyChanges, 5)
+	require.Less(proto.Size(proof.ToProto()), proto.Size(defaultProof.ToProto()))
+
+	// The proof is shortened until it has a single key change.
+	proof = getProof(ProofConfig{
+		MaxProofNodes: 1,
+	})
+	require.Len(proof.KeyChanges, 1)
+	require.Less(proto.Size(proof.ToProto()), proto.Size(defaultProof.ToProto()))
+}
+
 func Test_ChangeProof_Verify_Bad_Data(t *testing.T) {
 	type test struct {
 		name        string
@@ -815,7 +940,7 @@ func Test_ChangeProof_Verify_Bad_Data(t *testing.T) {
 			dbClone, err := getBasicDB()
 			require.NoError(err)
 
-			proof, err := db.GetChangeProof(context.Background(), startRoot, endRoot, maybe.Some([]byte{2}), maybe.Some([]byte{3, 0}), 50)
+			proof, err := db.GetChangeProof(context.Background(), startRoot, endRoot, maybe.Some([]byte{2}), maybe.Some([]byte{3, 0}), 50, DefaultProofConfig)
 			require.NoError(err)
 			require.NotNil(proof)
 
@@ -1849,6 +1974,7 @@ func FuzzChangeProofVerification(f *testing.F) {
 			start,
 			end,
 			int(maxProofLen),
+			DefaultProofConfig,
 		)
 		require.NoError(err)
 
@@ -391,6 +391,18 @@ func (t *trieView) GetRangeProof(
 	start maybe.Maybe[[]byte],
 	end maybe.Maybe[[]byte],
 	maxLength int,
+) (*RangeProof, error) {
+	return t.getRangeProof(ctx, start, end, maxLength, DefaultProofConfig)
+}
+
+// getRangeProof returns a range proof for (at least part of) the key range
+// [start, end] whose size is limited by [maxLength] and [config].
+func (t *trieView) getRangeProof(
+	ctx context.Context,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	maxLength int,
+	config ProofConfig,
 ) (*RangeProof, error) {
 	ctx, span := t.db.infoTracer.Start(ctx, "MerkleDB.trieview.GetRangeProof")
 	defer span.End()
@@ -412,6 +424,9 @@ func (t *trieView) GetRangeProof(
 	result.KeyValues = make([]KeyValue, 0, initKeyValuesSize)
 	it := t.NewIteratorWithStart(start.Value())
 	for it.Next() && len(result.KeyValues) < maxLength && (end.IsNothing() || bytes.Compare(it.Key(), end.Value()) <= 0) {
+		if !config.includeValue(len(result.KeyValues), it.Value()) {
+			break
+		}
 		// clone the value to prevent editing of the values stored within the trie
 		result.KeyValues = append(result.KeyValues, KeyValue{
 			Key:   it.Key(),
@@ -13,6 +13,8 @@ import (
 
 	"go.uber.org/zap"
 
+	"golang.org/x/sync/semaphore"
+
 	"google.golang.org/protobuf/proto"
 
 	"github.com/ava-labs/avalanchego/ids"
@@ -29,6 +31,10 @@ const (
 	maxRetryWait     = time.Second
 	retryWaitFactor  = 1.5 // Larger --> timeout grows more quickly
 
+	// The time a peer has to respond to a request if
+	// [ClientConfig.RequestTimeout] isn't set.
+	defaultRequestTimeout = 30 * time.Second
+
 	epsilon = 1e-6 // small amount to add to time to avoid division by 0
 )
 
@@ -71,31 +77,61 @@ type client struct {
 	stateSyncNodes      []ids.NodeID
 	stateSyncNodeIdx    uint32
 	stateSyncMinVersion *version.Application
+	stateSyncMaxVersion *version.Application
 	log                 logging.Logger
 	metrics             SyncMetrics
 	tokenSize           int
+	requestTimeout      time.Duration
+
+	// Bounds the number of concurrent GetChangeProof calls. Nil if the number
+	// of calls is unbounded.
+	changeProofRequests *semaphore.Weighted
 }
 
 type ClientConfig struct {
 	NetworkClient       NetworkClient
 	StateSyncNodeIDs    []ids.NodeID
 	StateSyncMinVersion *version.Application
+	StateSyncMaxVersion *version.Application
 	Log                 logging.Logger
 	Metrics             SyncMetrics
 	BranchFactor        merkledb.BranchFactor
+
+	// MaxChangeProofRequests is the maximum number of change proofs that may
+	// be fetched concurrently. This is enforced in addition to the limit on
+	// the number of requests of [NetworkClient]. If 0, the number of change
+	// proofs fetched concurrently is only limited by [NetworkClient].
+	MaxChangeProofRequests int64
+
+	// RequestTimeout is the time a peer has to respond to a request before
+	// the request is retried. Peers whose measured bandwidth is too low to
+	// deliver the expected response within this time are avoided. If 0,
+	// defaultRequestTimeout is used.
+	RequestTimeout time.Duration
 }
 
 func NewClient(config *ClientConfig) (Client, error) {
 	if err := config.BranchFactor.Valid(); err != nil {
 		return nil, err
 	}
+	var changeProofRequests *semaphore.Weighted
+	if config.MaxChangeProofRequests > 0 {
+		changeProofRequests = semaphore.NewWeighted(config.MaxChangeProofRequests)
+	}
+	requestTimeout := config.RequestTimeout
+	if requestTimeout == 0 {
+		requestTimeout = defaultRequestTimeout
+	}
 	return &client{
 		networkClient:       config.NetworkClient,
 		stateSyncNodes:      config.StateSyncNodeIDs,
 		stateSyncMinVersion: config.StateSyncMinVersion,
+		stateSyncMaxVersion: config.StateSyncMaxVersion,
 		log:                 config.Log,
 		metrics:             config.Metrics,
 		tokenSize:           merkledb.BranchFactorToTokenSize[config.BranchFactor],
+		changeProofRequests: changeProofRequests,
+		requestTimeout:      requestTimeout,
 	}, nil
 }
 
@@ -196,7 +232,16 @@ func (c *client) GetChangeProof(
 	if err != nil {
 		return nil, err
 	}
-	return getAndParse(ctx, c, reqBytes, parseFn)
+
+	// The slot is held until the change proof has been fetched, including
+	// any retries.
+	if c.changeProofRequests != nil {
+		if err := c.changeProofRequests.Acquire(ctx, 1); err != nil {
+			return nil, errAcquiringSemaphore
+		}
+		defer c.changeProofRequests.Release(1)
+	}
+	return getAndParse(ctx, c, reqBytes, int(req.BytesLimit), parseFn)
 }
 
 // Verify [rangeProof] is a valid range proof for keys in [start, end] for
@@ -284,11 +329,12 @@ func (c *client) GetRangeProof(
 		return nil, err
 	}
 
-	return getAndParse(ctx, c, reqBytes, parseFn)
+	return getAndParse(ctx, c, reqBytes, int(req.BytesLimit), parseFn)
 }
 
 // getAndParse uses [client] to send [request] to an arbitrary peer.
 // Returns the response to the request.
+// [expectedSize] is the maximum expected size of the response.
 // [parseFn] parses the raw response.
 // If the request is unsuccessful or the response can't be parsed,
 // retries the request to a different peer until [ctx] expires.
@@ -298,6 +344,7 @@ func getAndParse[T any](
 	ctx context.Context,
 	client *client,
 	request []byte,
+	expectedSize int,
 	parseFn func(context.Context, []byte) (*T, error),
 ) (*T, error) {
 	var (
@@ -306,7 +353,7 @@ func getAndParse[T any](
 	)
 	// Loop until the context is cancelled or we get a valid response.
 	for attempt := 1; ; attempt++ {
-		nodeID, responseBytes, err := client.get(ctx, request)
+		nodeID, responseBytes, err := client.get(ctx, request, expectedSize)
 		if err == nil {
 			if response, err = parseFn(ctx, responseBytes); err == nil {
 				return response, nil
@@ -349,13 +396,15 @@ func getAndParse[T any](
 }
 
 // get sends [request] to an arbitrary peer and blocks
-// until the node receives a response, failure notification
-// or [ctx] is canceled.
+// until the node receives a response, failure notification,
+// [c.requestTimeout] elapses or [ctx] is canceled.
 // Returns the peer's NodeID and response.
 // Returns [errAppSendFailed] if we failed to send an AppRequest/AppResponse.
 // This should be treated as fatal.
+// [expectedSize] is the maximum expected size of the response, or 0 if it is
+// unknown.
 // It's safe to call this method multiple times concurrently.
-func (c *client) get(ctx context.Context, request []byte) (ids.NodeID, []byte, error) {
+func (c *client) get(ctx context.Context, request []byte, expectedSize int) (ids.NodeID, []byte, error) {
 	var (
 		response []byte
 		nodeID   ids.NodeID
@@ -364,8 +413,13 @@ func (c *client) get(ctx context.Context, request []byte) (ids.NodeID, []byte, e
 
 	c.metrics.RequestMade()
 
+	// The deadline allows [expectedSize] to be used to avoid peers that are
+	// too slow to respond in time.
+	ctx, cancel := context.WithTimeout(ctx, c.requestTimeout)
+	defer cancel()
+
 	if len(c.stateSyncNodes) == 0 {
-		nodeID, response, err = c.networkClient.RequestAny(ctx, c.stateSyncMinVersion, request)
+		nodeID, response, err = c.networkClient.RequestAny(ctx, c.stateSyncMinVersion, c.stateSyncMaxVersion, expectedSize, request)
 	} else {
 		// Get the next nodeID to query using the [nodeIdx] offset.
 		// If we're out of nodes, loop back to 0.
@@ -6,6 +6,7 @@ package sync
 import (
 	"context"
 	"math/rand"
+	"sync"
 	"testing"
 	"time"
 
@@ -24,6 +25,8 @@ import (
 	"github.com/ava-labs/avalanchego/trace"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/utils/units"
 	"github.com/ava-labs/avalanchego/version"
 	"github.com/ava-labs/avalanchego/x/merkledb"
 
@@ -101,9 +104,11 @@ func sendRangeProofRequest(
 	networkClient.EXPECT().RequestAny(
 		gomock.Any(), // ctx
 		gomock.Any(), // min version
+		gomock.Any(), // max version
+		gomock.Any(), // expected size
 		gomock.Any(), // request
 	).DoAndReturn(
-		func(_ context.Context, _ *version.Application, request []byte) (ids.NodeID, []byte, error) {
+		func(_ context.Context, _, _ *version.Application, _ int, request []byte) (ids.NodeID, []byte, error) {
 			go func() {
 				// Get response from server
 				require.NoError(server.AppRequest(context.Background(), clientNodeID, 0, time.Now().Add(time.Hour), request))
@@ -274,7 +279,7 @@ func TestGetRangeProof(t *testing.T) {
 				start := maybe.Some(response.KeyValues[1].Key)
 				rootID, err := largeTrieDB.GetMerkleRoot(context.Background())
 				require.NoError(t, err)
-				proof, err := largeTrieDB.GetRangeProofAtRoot(context.Background(), rootID, start, maybe.Nothing[[]byte](), defaultRequestKeyLimit)
+				proof, err := largeTrieDB.GetRangeProofAtRoot(context.Background(), rootID, start, maybe.Nothing[[]byte](), defaultRequestKeyLimit, merkledb.DefaultProofConfig)
 				require.NoError(t, err)
 				response.KeyValues = proof.KeyValues
 				response.StartProof = proof.StartProof
@@ -420,9 +425,11 @@ func sendChangeProofRequest(
 	networkClient.EXPECT().RequestAny(
 		gomock.Any(), // ctx
 		gomock.Any(), // min version
+		gomock.Any(), // max version
+		gomock.Any(), // expected size
 		gomock.Any(), // request
 	).DoAndReturn(
-		func(_ context.Context, _ *version.Application, request []byte) (ids.NodeID, []byte, error) {
+		func(_ context.Context, _, _ *version.Application, _ int, request []byte) (ids.NodeID, []byte, error) {
 			go func() {
 				// Get response from server
 				require.NoError(server.AppRequest(context.Background(), clientNodeID, 0, time.Now().Add(time.Hour), request))
@@ -812,6 +819,8 @@ func TestAppRequestSendFailed(t *testing.T) {
 		gomock.Any(),
 		gomock.Any(),
 		gomock.Any(),
+		gomock.Any(),
+		gomock.Any(),
 	).Return(ids.EmptyNodeID, nil, errAppSendFailed).Times(2)
 
 	_, err = client.GetChangeProof(
@@ -827,3 +836,153 @@ func TestAppRequestSendFailed(t *testing.T) {
 	)
 	require.ErrorIs(err, errAppSendFailed)
 }
+
+func TestGetChangeProofMaxRequests(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	const (
+		maxChangeProofRequests = 2
+		numChangeProofRequests = 5
+	)
+
+	var (
+		networkClient = NewMockNetworkClient(ctrl)
+
+		lock        sync.Mutex
+		inFlight    int
+		maxInFlight int
+		started     = make(chan struct{}, numChangeProofRequests)
+	)
+	networkClient.EXPECT().RequestAny(
+		gomock.Any(), // ctx
+		gomock.Any(), // min version
+		gomock.Any(), // max version
+		gomock.Any(), // expected size
+		gomock.Any(), // request
+	).DoAndReturn(
+		func(ctx context.Context, _, _ *version.Application, _ int, _ []byte) (ids.NodeID, []byte, error) {
+			lock.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			lock.Unlock()
+
+			started <- struct{}{}
+			<-ctx.Done()
+
+			lock.Lock()
+			inFlight--
+			lock.Unlock()
+			return ids.EmptyNodeID, nil, ctx.Err()
+		},
+	).AnyTimes()
+
+	client, err := NewClient(&ClientConfig{
+		NetworkClient:          networkClient,
+		Metrics:                &mockMetrics{},
+		Log:                    logging.NoLog{},
+		BranchFactor:           merkledb.BranchFactor16,
+		MaxChangeProofRequests: maxChangeProofRequests,
+	})
+	require.NoError(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for i := 0; i < numChangeProofRequests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, _ = client.GetChangeProof(
+				ctx,
+				&pb.SyncGetChangeProofRequest{
+					KeyLimit:   defaultRequestKeyLimit,
+					BytesLimit: defaultRequestByteSizeLimit,
+				},
+				nil,
+			)
+		}()
+	}
+
+	// Wait for the window to fill up and make sure that no other requests are
+	// sent.
+	for i := 0; i < maxChangeProofRequests; i++ {
+		<-started
+	}
+	select {
+	case <-started:
+		require.FailNow("sent more change proof requests than allowed")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	cancel()
+	wg.Wait()
+	require.Equal(maxChangeProofRequests, maxInFlight)
+}
+
+func TestClientGetAvoidsSlowPeers(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	var (
+		slowNodeID = ids.GenerateTestNodeID()
+		fastNodeID = ids.GenerateTestNodeID()
+
+		sender   = common.NewMockSender(ctrl)
+		response = []byte{1, 2, 3}
+	)
+
+	networkClientIntf, err := NewNetworkClient(
+		sender,
+		ids.GenerateTestNodeID(),
+		1,
+		logging.NoLog{},
+		"",
+		prometheus.NewRegistry(),
+	)
+	require.NoError(err)
+	networkClient := networkClientIntf.(*networkClient)
+
+	require.NoError(networkClient.Connected(context.Background(), slowNodeID, version.CurrentApp))
+	require.NoError(networkClient.Connected(context.Background(), fastNodeID, version.CurrentApp))
+	networkClient.peers.TrackBandwidth(slowNodeID, units.KiB)
+	networkClient.peers.TrackBandwidth(fastNodeID, units.GiB)
+
+	syncClient, err := NewClient(&ClientConfig{
+		NetworkClient:  networkClient,
+		Metrics:        &mockMetrics{},
+		Log:            logging.NoLog{},
+		BranchFactor:   merkledb.BranchFactor16,
+		RequestTimeout: time.Second,
+	})
+	require.NoError(err)
+
+	// The slow peer can't deliver a MiB within the request timeout, so every
+	// request must be sent to the fast peer.
+	const numRequests = 10
+	sender.EXPECT().SendAppRequest(
+		gomock.Any(), // ctx
+		set.Of(fastNodeID),
+		gomock.Any(), // requestID
+		gomock.Any(), // requestBytes
+	).DoAndReturn(
+		func(ctx context.Context, _ set.Set[ids.NodeID], requestID uint32, _ []byte) error {
+			go func() {
+				require.NoError(networkClient.AppResponse(ctx, fastNodeID, requestID, response))
+			}()
+			return nil
+		},
+	).Times(numRequests)
+
+	for i := 0; i < numRequests; i++ {
+		nodeID, gotResponse, err := syncClient.(*client).get(context.Background(), []byte{0}, units.MiB)
+		require.NoError(err)
+		require.Equal(fastNodeID, nodeID)
+		require.Equal(response, gotResponse)
+
+		// Keep the measured bandwidth of the fast peer high
+		networkClient.peers.TrackBandwidth(fastNodeID, units.GiB)
+	}
+}
@@ -3,7 +3,14 @@
 
 package sync
 
-import "github.com/ava-labs/avalanchego/x/merkledb"
+import (
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+)
 
 type DB interface {
 	merkledb.Clearer
@@ -12,3 +19,33 @@ type DB interface {
 	merkledb.ChangeProofer
 	merkledb.RangeProofer
 }
+
+// VerifyRangeProof returns nil iff [proof] is a valid range proof of the keys
+// in [start, end] in the trie with root [rootID] and branch factor
+// [branchFactor].
+//
+// The proof is verified without a DB, so this can be used by clients that don't
+// maintain local state.
+func VerifyRangeProof(
+	ctx context.Context,
+	proof *merkledb.RangeProof,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	rootID ids.ID,
+	branchFactor merkledb.BranchFactor,
+) error {
+	if err := branchFactor.Valid(); err != nil {
+		return err
+	}
+
+	if err := proof.Verify(
+		ctx,
+		start,
+		end,
+		rootID,
+		merkledb.BranchFactorToTokenSize[branchFactor],
+	); err != nil {
+		return fmt.Errorf("%w due to %w", errInvalidRangeProof, err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+)
+
+func TestVerifyRangeProof(t *testing.T) {
+	r := rand.New(rand.NewSource(1)) // #nosec G404
+
+	db, err := generateTrie(t, r, 100)
+	require.NoError(t, err)
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(t, err)
+
+	proof, err := db.GetRangeProof(
+		context.Background(),
+		maybe.Nothing[[]byte](),
+		maybe.Nothing[[]byte](),
+		10,
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		rootID       ids.ID
+		branchFactor merkledb.BranchFactor
+		expectedErr  error
+	}{
+		{
+			name:         "valid",
+			rootID:       root,
+			branchFactor: merkledb.BranchFactor16,
+			expectedErr:  nil,
+		},
+		{
+			name:         "wrong root",
+			rootID:       ids.GenerateTestID(),
+			branchFactor: merkledb.BranchFactor16,
+			expectedErr:  errInvalidRangeProof,
+		},
+		{
+			name:         "invalid branch factor",
+			rootID:       root,
+			branchFactor: 3,
+			expectedErr:  merkledb.ErrInvalidBranchFactor,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyRangeProof(
+				context.Background(),
+				proof,
+				maybe.Nothing[[]byte](),
+				maybe.Nothing[[]byte](),
+				tt.rootID,
+				tt.branchFactor,
+			)
+			require.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
@@ -17,7 +17,11 @@ import (
 	pb "github.com/ava-labs/avalanchego/proto/pb/sync"
 )
 
-var _ sync.DB = (*DBClient)(nil)
+var (
+	_ sync.DB = (*DBClient)(nil)
+
+	errUnsupportedProofConfig = errors.New("only the default proof config is supported")
+)
 
 func NewDBClient(client pb.DBClient) *DBClient {
 	return &DBClient{
@@ -44,7 +48,14 @@ func (c *DBClient) GetChangeProof(
 	startKey maybe.Maybe[[]byte],
 	endKey maybe.Maybe[[]byte],
 	keyLimit int,
+	config merkledb.ProofConfig,
 ) (*merkledb.ChangeProof, error) {
+	// The proof config isn't part of the request, so only the default
+	// config can be honored.
+	if config != merkledb.DefaultProofConfig {
+		return nil, errUnsupportedProofConfig
+	}
+
 	resp, err := c.client.GetChangeProof(ctx, &pb.GetChangeProofRequest{
 		StartRootHash: startRootID[:],
 		EndRootHash:   endRootID[:],
@@ -132,7 +143,14 @@ func (c *DBClient) GetRangeProofAtRoot(
 	startKey maybe.Maybe[[]byte],
 	endKey maybe.Maybe[[]byte],
 	keyLimit int,
+	config merkledb.ProofConfig,
 ) (*merkledb.RangeProof, error) {
+	// The proof config isn't part of the request, so only the default
+	// config can be honored.
+	if config != merkledb.DefaultProofConfig {
+		return nil, errUnsupportedProofConfig
+	}
+
 	resp, err := c.client.GetRangeProof(ctx, &pb.GetRangeProofRequest{
 		RootHash: rootID[:],
 		StartKey: &pb.MaybeBytes{
@@ -72,6 +72,7 @@ func (s *DBServer) GetChangeProof(
 		start,
 		end,
 		int(req.KeyLimit),
+		merkledb.DefaultProofConfig,
 	)
 	if err != nil {
 		if !errors.Is(err, merkledb.ErrInsufficientHistory) {
@@ -166,7 +167,7 @@ func (s *DBServer) GetRangeProof(
 	if req.EndKey != nil && !req.EndKey.IsNothing {
 		end = maybe.Some(req.EndKey.Value)
 	}
-	proof, err := s.db.GetRangeProofAtRoot(ctx, rootID, start, end, int(req.KeyLimit))
+	proof, err := s.db.GetRangeProofAtRoot(ctx, rootID, start, end, int(req.KeyLimit), merkledb.DefaultProofConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -6,6 +6,7 @@ package sync
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"sync"
@@ -108,6 +109,25 @@ type Manager struct {
 	syncing   bool
 	closeOnce sync.Once
 	tokenSize int
+
+	// [workLock] must be held when accessing [keysSynced] and
+	// [proofsVerified].
+	keysSynced     uint64
+	proofsVerified uint64
+}
+
+// SyncProgress is a snapshot of how far along a sync is.
+type SyncProgress struct {
+	// Number of key-value pairs and key changes committed to the database.
+	// Keys that are re-synced after the target root changes are counted again.
+	KeysSynced uint64 `json:"keysSynced"`
+	// Number of range and change proofs that have been verified and applied.
+	ProofsVerified uint64 `json:"proofsVerified"`
+	// The root that is currently being synced to.
+	TargetRoot ids.ID `json:"targetRoot"`
+	// Estimated fraction, in [0, 1], of the key space that has been synced to
+	// [TargetRoot]. This assumes keys are uniformly distributed.
+	EstimatedCompletion float64 `json:"estimatedCompletion"`
 }
 
 type ManagerConfig struct {
@@ -308,6 +328,7 @@ func (m *Manager) getAndApplyChangeProof(ctx context.Context, work *workItem) {
 			}
 			largestHandledKey = maybe.Some(changeProof.KeyChanges[len(changeProof.KeyChanges)-1].Key)
 		}
+		m.recordAppliedProof(len(changeProof.KeyChanges))
 
 		m.completeWorkItem(ctx, work, largestHandledKey, targetRootID, changeProof.EndProof)
 		return
@@ -324,6 +345,7 @@ func (m *Manager) getAndApplyChangeProof(ctx context.Context, work *workItem) {
 		}
 		largestHandledKey = maybe.Some(rangeProof.KeyValues[len(rangeProof.KeyValues)-1].Key)
 	}
+	m.recordAppliedProof(len(rangeProof.KeyValues))
 
 	m.completeWorkItem(ctx, work, largestHandledKey, targetRootID, rangeProof.EndProof)
 }
@@ -366,6 +388,7 @@ func (m *Manager) getAndApplyRangeProof(ctx context.Context, work *workItem) {
 		m.setError(err)
 		return
 	}
+	m.recordAppliedProof(len(proof.KeyValues))
 
 	if len(proof.KeyValues) > 0 {
 		largestHandledKey = maybe.Some(proof.KeyValues[len(proof.KeyValues)-1].Key)
@@ -621,6 +644,40 @@ func (m *Manager) UpdateSyncTarget(syncTargetRoot ids.ID) error {
 	return nil
 }
 
+// Progress returns a snapshot of the progress of the sync.
+func (m *Manager) Progress() SyncProgress {
+	// Hold [syncTargetLock] to ensure [processedWork] corresponds to
+	// [TargetRoot].
+	m.syncTargetLock.RLock()
+	defer m.syncTargetLock.RUnlock()
+
+	m.workLock.Lock()
+	defer m.workLock.Unlock()
+
+	return SyncProgress{
+		KeysSynced:          m.keysSynced,
+		ProofsVerified:      m.proofsVerified,
+		TargetRoot:          m.config.TargetRoot,
+		EstimatedCompletion: m.processedWork.keySpaceFraction(),
+	}
+}
+
+// HealthCheck reports the progress of the sync. Returns an error if the sync
+// fatally errored.
+func (m *Manager) HealthCheck(context.Context) (interface{}, error) {
+	return m.Progress(), m.Error()
+}
+
+// Record that a proof containing [numKeys] keys was verified and applied.
+// Assumes [m.workLock] is not held.
+func (m *Manager) recordAppliedProof(numKeys int) {
+	m.workLock.Lock()
+	defer m.workLock.Unlock()
+
+	m.keysSynced += uint64(numKeys)
+	m.proofsVerified++
+}
+
 func (m *Manager) getTargetRoot() ids.ID {
 	m.syncTargetLock.RLock()
 	defer m.syncTargetLock.RUnlock()
@@ -817,6 +874,18 @@ func midPoint(startMaybe, endMaybe maybe.Maybe[[]byte]) maybe.Maybe[[]byte] {
 	return maybe.Some(midpoint)
 }
 
+// keyToFraction returns the position of [key] in the key space as a value in
+// [0, 1], using only the first 8 bytes of [key].
+// Nothing [key] is treated as [nothingValue].
+func keyToFraction(key maybe.Maybe[[]byte], nothingValue float64) float64 {
+	if key.IsNothing() {
+		return nothingValue
+	}
+	var prefix [8]byte
+	copy(prefix[:], key.Value())
+	return float64(binary.BigEndian.Uint64(prefix[:])) / (1 << 64)
+}
+
 // findChildDifference returns the first child index that is different between node 1 and node 2 if one exists and
 // a bool indicating if any difference was found
 func findChildDifference(node1, node2 *merkledb.ProofNode, startIndex int) (byte, bool) {
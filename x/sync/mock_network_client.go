@@ -9,7 +9,9 @@ import (
 	reflect "reflect"
 
 	ids "github.com/ava-labs/avalanchego/ids"
+	sync "github.com/ava-labs/avalanchego/proto/pb/sync"
 	version "github.com/ava-labs/avalanchego/version"
+	merkledb "github.com/ava-labs/avalanchego/x/merkledb"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -92,6 +94,34 @@ func (mr *MockNetworkClientMockRecorder) Disconnected(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disconnected", reflect.TypeOf((*MockNetworkClient)(nil).Disconnected), arg0, arg1)
 }
 
+// IsConnected mocks base method.
+func (m *MockNetworkClient) IsConnected(nodeID ids.NodeID) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsConnected", nodeID)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsConnected indicates an expected call of IsConnected.
+func (mr *MockNetworkClientMockRecorder) IsConnected(nodeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsConnected", reflect.TypeOf((*MockNetworkClient)(nil).IsConnected), nodeID)
+}
+
+// OutstandingRequests mocks base method.
+func (m *MockNetworkClient) OutstandingRequests() []OutstandingRequestInfo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OutstandingRequests")
+	ret0, _ := ret[0].([]OutstandingRequestInfo)
+	return ret0
+}
+
+// OutstandingRequests indicates an expected call of OutstandingRequests.
+func (mr *MockNetworkClientMockRecorder) OutstandingRequests() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OutstandingRequests", reflect.TypeOf((*MockNetworkClient)(nil).OutstandingRequests))
+}
+
 // Request mocks base method.
 func (m *MockNetworkClient) Request(ctx context.Context, nodeID ids.NodeID, request []byte) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -108,9 +138,9 @@ func (mr *MockNetworkClientMockRecorder) Request(ctx, nodeID, request interface{
 }
 
 // RequestAny mocks base method.
-func (m *MockNetworkClient) RequestAny(ctx context.Context, minVersion *version.Application, request []byte) (ids.NodeID, []byte, error) {
+func (m *MockNetworkClient) RequestAny(ctx context.Context, minVersion, maxVersion *version.Application, expectedSize int, request []byte) (ids.NodeID, []byte, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RequestAny", ctx, minVersion, request)
+	ret := m.ctrl.Call(m, "RequestAny", ctx, minVersion, maxVersion, expectedSize, request)
 	ret0, _ := ret[0].(ids.NodeID)
 	ret1, _ := ret[1].([]byte)
 	ret2, _ := ret[2].(error)
@@ -118,9 +148,51 @@ func (m *MockNetworkClient) RequestAny(ctx context.Context, minVersion *version.
 }
 
 // RequestAny indicates an expected call of RequestAny.
-func (mr *MockNetworkClientMockRecorder) RequestAny(ctx, minVersion, request interface{}) *gomock.Call {
+func (mr *MockNetworkClientMockRecorder) RequestAny(ctx, minVersion, maxVersion, expectedSize, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestAny", reflect.TypeOf((*MockNetworkClient)(nil).RequestAny), ctx, minVersion, maxVersion, expectedSize, request)
+}
+
+// RequestPreferred mocks base method.
+func (m *MockNetworkClient) RequestPreferred(ctx context.Context, preferred ids.NodeID, minVersion, maxVersion *version.Application, expectedSize int, request []byte) (ids.NodeID, []byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestPreferred", ctx, preferred, minVersion, maxVersion, expectedSize, request)
+	ret0, _ := ret[0].(ids.NodeID)
+	ret1, _ := ret[1].([]byte)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RequestPreferred indicates an expected call of RequestPreferred.
+func (mr *MockNetworkClientMockRecorder) RequestPreferred(ctx, preferred, minVersion, maxVersion, expectedSize, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestPreferred", reflect.TypeOf((*MockNetworkClient)(nil).RequestPreferred), ctx, preferred, minVersion, maxVersion, expectedSize, request)
+}
+
+// RequestRangeProofStreamed mocks base method.
+func (m *MockNetworkClient) RequestRangeProofStreamed(ctx context.Context, nodeID ids.NodeID, request *sync.SyncGetRangeProofRequest, branchFactor merkledb.BranchFactor, handler func(*merkledb.RangeProof) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestRangeProofStreamed", ctx, nodeID, request, branchFactor, handler)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestRangeProofStreamed indicates an expected call of RequestRangeProofStreamed.
+func (mr *MockNetworkClientMockRecorder) RequestRangeProofStreamed(ctx, nodeID, request, branchFactor, handler interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestRangeProofStreamed", reflect.TypeOf((*MockNetworkClient)(nil).RequestRangeProofStreamed), ctx, nodeID, request, branchFactor, handler)
+}
+
+// Shutdown mocks base method.
+func (m *MockNetworkClient) Shutdown() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Shutdown")
+}
+
+// Shutdown indicates an expected call of Shutdown.
+func (mr *MockNetworkClientMockRecorder) Shutdown() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestAny", reflect.TypeOf((*MockNetworkClient)(nil).RequestAny), ctx, minVersion, request)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shutdown", reflect.TypeOf((*MockNetworkClient)(nil).Shutdown))
 }
 
 // TrackBandwidth mocks base method.
@@ -4,6 +4,7 @@
 package sync
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -14,14 +15,20 @@ import (
 
 	"go.uber.org/zap"
 
+	"golang.org/x/exp/slices"
 	"golang.org/x/sync/semaphore"
 
+	"google.golang.org/protobuf/proto"
+
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/network/p2p"
 	"github.com/ava-labs/avalanchego/snow/engine/common"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/version"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+
+	pb "github.com/ava-labs/avalanchego/proto/pb/sync"
 )
 
 // Minimum amount of time to handle a request
@@ -30,20 +37,47 @@ const minRequestHandlingDuration = 100 * time.Millisecond
 var (
 	_ NetworkClient = (*networkClient)(nil)
 
+	ErrShuttingDown = errors.New("network client is shutting down")
+
 	errAcquiringSemaphore = errors.New("error acquiring semaphore")
 	errRequestFailed      = errors.New("request failed")
 	errAppSendFailed      = errors.New("failed to send app message")
+	errOutOfOrderChunk    = errors.New("range proof chunk out of order")
+
+	errNoPeersInVersionRange = errors.New("no peers found in version range")
 )
 
 // NetworkClient defines ability to send request / response through the Network
 type NetworkClient interface {
 	// RequestAny synchronously sends request to an arbitrary peer with a
-	// node version greater than or equal to minVersion.
+	// node version in the range [minVersion, maxVersion]. A nil bound is not
+	// enforced.
+	// If [expectedSize] is positive and [ctx] has a deadline, peers whose
+	// measured bandwidth is too low to deliver [expectedSize] bytes before the
+	// deadline are avoided. An [expectedSize] of 0 means the size is unknown.
 	// Returns response bytes, the ID of the chosen peer, and ErrRequestFailed if
 	// the request should be retried.
 	RequestAny(
 		ctx context.Context,
 		minVersion *version.Application,
+		maxVersion *version.Application,
+		expectedSize int,
+		request []byte,
+	) (ids.NodeID, []byte, error)
+
+	// RequestPreferred synchronously sends request to [preferred]. If
+	// [preferred] isn't connected with a version in the range
+	// [minVersion, maxVersion], is too slow to deliver [expectedSize] bytes
+	// before the deadline of [ctx], or the request to it fails, the request is
+	// sent to an arbitrary peer as in RequestAny.
+	// Returns response bytes, the ID of the peer that served the response, and
+	// ErrRequestFailed if the request should be retried.
+	RequestPreferred(
+		ctx context.Context,
+		preferred ids.NodeID,
+		minVersion *version.Application,
+		maxVersion *version.Application,
+		expectedSize int,
 		request []byte,
 	) (ids.NodeID, []byte, error)
 
@@ -56,6 +90,40 @@ type NetworkClient interface {
 		request []byte,
 	) ([]byte, error)
 
+	// Fetches the range proof specified by [request] from [nodeID] as a
+	// sequence of chunks and calls [handler] with each chunk, in order.
+	//
+	// Each chunk is a range proof, at the requested root, of the keys
+	// immediately following the last key of the previous chunk. This allows
+	// proofs that would exceed the message size limits to be transferred in
+	// parts. The p2p layer delivers exactly one response per request, so each
+	// chunk is fetched with its own range proof request, which the server
+	// handles like any other. The stream ends once a chunk contains no keys
+	// or reaches the requested end key.
+	//
+	// Every chunk is verified against the requested root and range, using
+	// [branchFactor], before it is passed to [handler].
+	//
+	// Returns an error, without calling [handler] again, if a chunk can't be
+	// fetched, doesn't follow the previous chunk, is invalid, or if [handler]
+	// errors.
+	RequestRangeProofStreamed(
+		ctx context.Context,
+		nodeID ids.NodeID,
+		request *pb.SyncGetRangeProofRequest,
+		branchFactor merkledb.BranchFactor,
+		handler func(chunk *merkledb.RangeProof) error,
+	) error
+
+	// Returns true if [nodeID] is currently connected. This can be used to
+	// avoid sending a request to a peer that is known to have disconnected.
+	IsConnected(nodeID ids.NodeID) bool
+
+	// Returns the requests that were sent but haven't received a response or
+	// failure yet, ordered by request ID. This is intended for debugging
+	// stalled syncs.
+	OutstandingRequests() []OutstandingRequestInfo
+
 	// The following declarations allow this interface to be embedded in the VM
 	// to handle incoming responses from peers.
 
@@ -74,6 +142,29 @@ type NetworkClient interface {
 
 	// Removes given [nodeID] from the peer list.
 	Disconnected(context.Context, ids.NodeID) error
+
+	// Shutdown unblocks all callers waiting for an outbound request slot,
+	// causing them to return ErrShuttingDown. Requests made after Shutdown
+	// also return ErrShuttingDown.
+	Shutdown()
+}
+
+// OutstandingRequestInfo describes a request that hasn't received a response
+// or failure yet.
+type OutstandingRequestInfo struct {
+	RequestID uint32
+	// The peer the request was sent to
+	NodeID ids.NodeID
+	// Time elapsed since the request was sent
+	Age time.Duration
+}
+
+// outstandingRequest is a request that hasn't received a response or failure
+// yet.
+type outstandingRequest struct {
+	handler  ResponseHandler
+	nodeID   ids.NodeID
+	sentTime time.Time
 }
 
 type networkClient struct {
@@ -83,10 +174,14 @@ type networkClient struct {
 	myNodeID ids.NodeID
 	// requestID counter used to track outbound requests
 	requestID uint32
-	// requestID => handler for the response/failure
-	outstandingRequestHandlers map[uint32]ResponseHandler
+	// requestID => handler for the response/failure and the peer the request
+	// was sent to
+	outstandingRequests map[uint32]*outstandingRequest
 	// controls maximum number of active outbound requests
 	activeRequests *semaphore.Weighted
+	// cancelled on Shutdown to unblock callers waiting on [activeRequests]
+	shutdownCtx    context.Context
+	shutdownCancel context.CancelFunc
 	// tracking of peers & bandwidth usage
 	peers *p2p.PeerTracker
 	// For sending messages to peers
@@ -106,13 +201,16 @@ func NewNetworkClient(
 		return nil, fmt.Errorf("failed to create peer tracker: %w", err)
 	}
 
+	shutdownCtx, shutdownCancel := context.WithCancel(context.Background())
 	return &networkClient{
-		appSender:                  appSender,
-		myNodeID:                   myNodeID,
-		outstandingRequestHandlers: make(map[uint32]ResponseHandler),
-		activeRequests:             semaphore.NewWeighted(maxActiveRequests),
-		peers:                      peerTracker,
-		log:                        log,
+		appSender:           appSender,
+		myNodeID:            myNodeID,
+		outstandingRequests: make(map[uint32]*outstandingRequest),
+		activeRequests:      semaphore.NewWeighted(maxActiveRequests),
+		shutdownCtx:         shutdownCtx,
+		shutdownCancel:      shutdownCancel,
+		peers:               peerTracker,
+		log:                 log,
 	}, nil
 }
 
@@ -181,32 +279,54 @@ func (c *networkClient) AppRequestFailed(
 // Returns false if there's no outstanding request with [requestID].
 // Assumes [c.lock] is held.
 func (c *networkClient) getRequestHandler(requestID uint32) (ResponseHandler, bool) {
-	handler, exists := c.outstandingRequestHandlers[requestID]
+	request, exists := c.outstandingRequests[requestID]
 	if !exists {
 		return nil, false
 	}
 	// mark message as processed, release activeRequests slot
-	delete(c.outstandingRequestHandlers, requestID)
-	return handler, true
+	delete(c.outstandingRequests, requestID)
+	return request.handler, true
+}
+
+func (c *networkClient) OutstandingRequests() []OutstandingRequestInfo {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := time.Now()
+	requests := make([]OutstandingRequestInfo, 0, len(c.outstandingRequests))
+	for requestID, request := range c.outstandingRequests {
+		requests = append(requests, OutstandingRequestInfo{
+			RequestID: requestID,
+			NodeID:    request.nodeID,
+			Age:       now.Sub(request.sentTime),
+		})
+	}
+	slices.SortFunc(requests, func(a, b OutstandingRequestInfo) bool {
+		return a.RequestID < b.RequestID
+	})
+	return requests
 }
 
 // If [errAppSendFailed] is returned this should be considered fatal.
 func (c *networkClient) RequestAny(
 	ctx context.Context,
 	minVersion *version.Application,
+	maxVersion *version.Application,
+	expectedSize int,
 	request []byte,
 ) (ids.NodeID, []byte, error) {
 	// Take a slot from total [activeRequests] and block until a slot becomes available.
-	if err := c.activeRequests.Acquire(ctx, 1); err != nil {
-		return ids.EmptyNodeID, nil, errAcquiringSemaphore
+	if err := c.acquire(ctx); err != nil {
+		return ids.EmptyNodeID, nil, err
 	}
 	defer c.activeRequests.Release(1)
 
-	nodeID, ok := c.peers.GetAnyPeer(minVersion)
+	minBandwidth := requiredBandwidth(ctx, expectedSize, time.Now())
+	nodeID, ok := c.peers.GetAnyPeerWithBandwidth(minVersion, maxVersion, minBandwidth)
 	if !ok {
 		return ids.EmptyNodeID, nil, fmt.Errorf(
-			"no peers found matching version %s out of %d peers",
-			minVersion, c.peers.Size(),
+			"%w: version range [%s, %s] out of %d peers",
+			errNoPeersInVersionRange, minVersion, maxVersion, c.peers.Size(),
 		)
 	}
 
@@ -214,6 +334,44 @@ func (c *networkClient) RequestAny(
 	return nodeID, response, err
 }
 
+// If [errAppSendFailed] is returned this should be considered fatal.
+func (c *networkClient) RequestPreferred(
+	ctx context.Context,
+	preferred ids.NodeID,
+	minVersion *version.Application,
+	maxVersion *version.Application,
+	expectedSize int,
+	request []byte,
+) (ids.NodeID, []byte, error) {
+	minBandwidth := requiredBandwidth(ctx, expectedSize, time.Now())
+	if c.peers.CanServe(preferred, minVersion, maxVersion, minBandwidth) {
+		response, err := c.Request(ctx, preferred, request)
+		if !errors.Is(err, errRequestFailed) {
+			return preferred, response, err
+		}
+
+		c.log.Debug("request to preferred peer failed, falling back to any peer",
+			zap.Stringer("nodeID", preferred),
+		)
+	}
+	return c.RequestAny(ctx, minVersion, maxVersion, expectedSize, request)
+}
+
+// requiredBandwidth returns the bandwidth, in bytes per second, needed to
+// receive [expectedSize] bytes before the deadline of [ctx]. Returns 0 if
+// the size or the deadline is unknown.
+func requiredBandwidth(ctx context.Context, expectedSize int, now time.Time) float64 {
+	deadline, ok := ctx.Deadline()
+	if !ok || expectedSize <= 0 {
+		return 0
+	}
+	remaining := deadline.Sub(now)
+	if remaining <= 0 {
+		return 0
+	}
+	return float64(expectedSize) / remaining.Seconds()
+}
+
 // If [errAppSendFailed] is returned this should be considered fatal.
 func (c *networkClient) Request(
 	ctx context.Context,
@@ -222,14 +380,144 @@ func (c *networkClient) Request(
 ) ([]byte, error) {
 	// Take a slot from total [activeRequests]
 	// and block until a slot becomes available.
-	if err := c.activeRequests.Acquire(ctx, 1); err != nil {
-		return nil, errAcquiringSemaphore
+	if err := c.acquire(ctx); err != nil {
+		return nil, err
 	}
 	defer c.activeRequests.Release(1)
 
 	return c.request(ctx, nodeID, request)
 }
 
+// acquire takes a slot from [activeRequests], blocking until a slot becomes
+// available, [ctx] is cancelled, or the client is shut down.
+// If the client is shut down, [ErrShuttingDown] is returned.
+func (c *networkClient) acquire(ctx context.Context) error {
+	acquireCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-c.shutdownCtx.Done():
+			cancel()
+		case <-acquireCtx.Done():
+		}
+	}()
+
+	if err := c.activeRequests.Acquire(acquireCtx, 1); err != nil {
+		if c.shutdownCtx.Err() != nil {
+			return ErrShuttingDown
+		}
+		return errAcquiringSemaphore
+	}
+	if c.shutdownCtx.Err() != nil {
+		c.activeRequests.Release(1)
+		return ErrShuttingDown
+	}
+	return nil
+}
+
+func (c *networkClient) Shutdown() {
+	c.shutdownCancel()
+}
+
+// If [errAppSendFailed] is returned this should be considered fatal.
+func (c *networkClient) RequestRangeProofStreamed(
+	ctx context.Context,
+	nodeID ids.NodeID,
+	request *pb.SyncGetRangeProofRequest,
+	branchFactor merkledb.BranchFactor,
+	handler func(chunk *merkledb.RangeProof) error,
+) error {
+	if err := branchFactor.Valid(); err != nil {
+		return err
+	}
+
+	var (
+		tokenSize = merkledb.BranchFactorToTokenSize[branchFactor]
+		startKey  = request.StartKey
+		endKey    = maybeBytesToMaybe(request.EndKey)
+	)
+	for chunkIndex := 0; ; chunkIndex++ {
+		chunkRequest := &pb.SyncGetRangeProofRequest{
+			RootHash:   request.RootHash,
+			StartKey:   startKey,
+			EndKey:     request.EndKey,
+			KeyLimit:   request.KeyLimit,
+			BytesLimit: request.BytesLimit,
+		}
+		requestBytes, err := proto.Marshal(&pb.Request{
+			Message: &pb.Request_RangeProofRequest{
+				RangeProofRequest: chunkRequest,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		responseBytes, err := c.Request(ctx, nodeID, requestBytes)
+		if err != nil {
+			return fmt.Errorf("failed to fetch range proof chunk %d: %w", chunkIndex, err)
+		}
+		if len(responseBytes) > int(request.BytesLimit) {
+			return fmt.Errorf(
+				"%w: chunk %d (%d) > %d",
+				errTooManyBytes, chunkIndex, len(responseBytes), request.BytesLimit,
+			)
+		}
+
+		var chunkProto pb.RangeProof
+		if err := proto.Unmarshal(responseBytes, &chunkProto); err != nil {
+			return err
+		}
+		var chunk merkledb.RangeProof
+		if err := chunk.UnmarshalProto(&chunkProto); err != nil {
+			return err
+		}
+
+		numKeys := len(chunk.KeyValues)
+		// The first key of this chunk must come after every key of the
+		// previous chunk. Otherwise the peer responded with a stale or
+		// reordered chunk.
+		chunkStart := maybeBytesToMaybe(startKey)
+		if numKeys > 0 && chunkStart.HasValue() &&
+			bytes.Compare(chunk.KeyValues[0].Key, chunkStart.Value()) < 0 {
+			return fmt.Errorf(
+				"%w: chunk %d starts at %x, expected at or after %x",
+				errOutOfOrderChunk, chunkIndex, chunk.KeyValues[0].Key, chunkStart.Value(),
+			)
+		}
+
+		if err := verifyRangeProof(
+			ctx,
+			&chunk,
+			int(request.KeyLimit),
+			chunkStart,
+			endKey,
+			request.RootHash,
+			tokenSize,
+		); err != nil {
+			return fmt.Errorf("invalid range proof chunk %d: %w", chunkIndex, err)
+		}
+
+		if err := handler(&chunk); err != nil {
+			return err
+		}
+
+		if numKeys == 0 {
+			return nil
+		}
+		lastKey := chunk.KeyValues[numKeys-1].Key
+		if endKey.HasValue() && bytes.Compare(lastKey, endKey.Value()) >= 0 {
+			return nil
+		}
+
+		// The next chunk starts at the smallest key after [lastKey].
+		nextKey := make([]byte, len(lastKey)+1)
+		copy(nextKey, lastKey)
+		startKey = &pb.MaybeBytes{Value: nextKey}
+	}
+}
+
 // Sends [request] to [nodeID] and returns the response.
 // Returns an error if the request failed or [ctx] is canceled.
 // If [errAppSendFailed] is returned this should be considered fatal.
@@ -268,7 +556,11 @@ func (c *networkClient) request(
 	}
 
 	handler := newResponseHandler()
-	c.outstandingRequestHandlers[requestID] = handler
+	c.outstandingRequests[requestID] = &outstandingRequest{
+		handler:  handler,
+		nodeID:   nodeID,
+		sentTime: time.Now(),
+	}
 
 	c.lock.Unlock() // unlock so response can be received
 
@@ -282,9 +574,12 @@ func (c *networkClient) request(
 		c.peers.TrackBandwidth(nodeID, 0)
 		return nil, ctx.Err()
 	case response = <-handler.responseChan:
-		elapsedSeconds := time.Since(startTime).Seconds()
-		bandwidth := float64(len(response))/elapsedSeconds + epsilon
+		elapsed := time.Since(startTime)
+		bandwidth := float64(len(response))/elapsed.Seconds() + epsilon
 		c.peers.TrackBandwidth(nodeID, bandwidth)
+		if !handler.failed {
+			c.peers.TrackLatency(nodeID, elapsed)
+		}
 	}
 	if handler.failed {
 		c.peers.TrackBandwidth(nodeID, 0)
@@ -299,6 +594,10 @@ func (c *networkClient) request(
 	return response, nil
 }
 
+func (c *networkClient) IsConnected(nodeID ids.NodeID) bool {
+	return c.peers.IsConnected(nodeID)
+}
+
 func (c *networkClient) Connected(
 	_ context.Context,
 	nodeID ids.NodeID,
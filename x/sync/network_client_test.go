@@ -0,0 +1,597 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/stretchr/testify/require"
+
+	"go.uber.org/mock/gomock"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+
+	pb "github.com/ava-labs/avalanchego/proto/pb/sync"
+)
+
+func TestRequestRangeProofStreamed(t *testing.T) {
+	r := rand.New(rand.NewSource(1)) // #nosec G404
+
+	const (
+		keyLimit    = 10
+		numTrieKeys = 5 * keyLimit
+	)
+
+	serverDB, _, err := generateTrieWithMinKeyLen(t, r, numTrieKeys, 1)
+	require.NoError(t, err)
+	serverRoot, err := serverDB.GetMerkleRoot(context.Background())
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		// Returns the response to deliver for the chunk with [chunkIndex].
+		// [responses] contains every response generated by the server so far.
+		// If nil is returned, the request is marked as failed.
+		modifyResponse func(chunkIndex int, responses [][]byte) []byte
+		expectedErr    error
+	}{
+		"chunks applied in order": {},
+		"out of order chunk": {
+			modifyResponse: func(chunkIndex int, responses [][]byte) []byte {
+				if chunkIndex == 2 {
+					// Replay the first chunk
+					return responses[0]
+				}
+				return responses[chunkIndex]
+			},
+			expectedErr: errOutOfOrderChunk,
+		},
+		"missing chunk": {
+			modifyResponse: func(chunkIndex int, responses [][]byte) []byte {
+				if chunkIndex == 2 {
+					return nil
+				}
+				return responses[chunkIndex]
+			},
+			expectedErr: errRequestFailed,
+		},
+		"chunk with a removed key": {
+			modifyResponse: func(chunkIndex int, responses [][]byte) []byte {
+				if chunkIndex != 2 {
+					return responses[chunkIndex]
+				}
+				var proof pb.RangeProof
+				require.NoError(t, proto.Unmarshal(responses[chunkIndex], &proof))
+				proof.KeyValues = proof.KeyValues[1:]
+				responseBytes, err := proto.Marshal(&proof)
+				require.NoError(t, err)
+				return responseBytes
+			},
+			expectedErr: errInvalidRangeProof,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			ctrl := gomock.NewController(t)
+
+			var (
+				clientNodeID = ids.GenerateTestNodeID()
+				serverNodeID = ids.GenerateTestNodeID()
+
+				clientSender = common.NewMockSender(ctrl)
+				serverSender = common.NewMockSender(ctrl)
+
+				server = NewNetworkServer(serverSender, serverDB, logging.NoLog{})
+
+				responses  [][]byte
+				chunkIndex int
+			)
+
+			networkClient, err := NewNetworkClient(
+				clientSender,
+				clientNodeID,
+				1,
+				logging.NoLog{},
+				"",
+				prometheus.NewRegistry(),
+			)
+			require.NoError(err)
+
+			clientSender.EXPECT().SendAppRequest(
+				gomock.Any(), // ctx
+				set.Of(serverNodeID),
+				gomock.Any(), // requestID
+				gomock.Any(), // requestBytes
+			).DoAndReturn(
+				func(_ context.Context, _ set.Set[ids.NodeID], requestID uint32, requestBytes []byte) error {
+					// The client holds its lock while sending the request, so
+					// the request must be served asynchronously.
+					go func() {
+						require.NoError(server.AppRequest(
+							context.Background(),
+							clientNodeID,
+							requestID,
+							time.Now().Add(time.Hour),
+							requestBytes,
+						))
+					}()
+					return nil
+				},
+			).AnyTimes()
+
+			serverSender.EXPECT().SendAppResponse(
+				gomock.Any(), // ctx
+				clientNodeID,
+				gomock.Any(), // requestID
+				gomock.Any(), // responseBytes
+			).DoAndReturn(
+				func(ctx context.Context, _ ids.NodeID, requestID uint32, responseBytes []byte) error {
+					responses = append(responses, responseBytes)
+					if test.modifyResponse != nil {
+						responseBytes = test.modifyResponse(chunkIndex, responses)
+					}
+					chunkIndex++
+
+					if responseBytes == nil {
+						return networkClient.AppRequestFailed(ctx, serverNodeID, requestID)
+					}
+					return networkClient.AppResponse(ctx, serverNodeID, requestID, responseBytes)
+				},
+			).AnyTimes()
+
+			clientDB, err := merkledb.New(
+				context.Background(),
+				memdb.New(),
+				newDefaultDBConfig(),
+			)
+			require.NoError(err)
+
+			var (
+				startKey  = maybe.Nothing[[]byte]()
+				numChunks int
+			)
+			err = networkClient.RequestRangeProofStreamed(
+				context.Background(),
+				serverNodeID,
+				&pb.SyncGetRangeProofRequest{
+					RootHash:   serverRoot[:],
+					KeyLimit:   keyLimit,
+					BytesLimit: defaultRequestByteSizeLimit,
+				},
+				merkledb.BranchFactor16,
+				func(chunk *merkledb.RangeProof) error {
+					numChunks++
+					if err := clientDB.CommitRangeProof(
+						context.Background(),
+						startKey,
+						maybe.Nothing[[]byte](),
+						chunk,
+					); err != nil {
+						return err
+					}
+
+					if len(chunk.KeyValues) > 0 {
+						lastKey := chunk.KeyValues[len(chunk.KeyValues)-1].Key
+						nextKey := make([]byte, len(lastKey)+1)
+						copy(nextKey, lastKey)
+						startKey = maybe.Some(nextKey)
+					}
+					return nil
+				},
+			)
+			require.ErrorIs(err, test.expectedErr)
+			if test.expectedErr != nil {
+				// Chunks after the invalid chunk must not be handled.
+				require.Equal(2, numChunks)
+				return
+			}
+
+			require.Greater(numChunks, numTrieKeys/keyLimit)
+
+			clientRoot, err := clientDB.GetMerkleRoot(context.Background())
+			require.NoError(err)
+			require.Equal(serverRoot, clientRoot)
+		})
+	}
+}
+
+func TestNetworkClientIsConnected(t *testing.T) {
+	require := require.New(t)
+
+	myNodeID := ids.GenerateTestNodeID()
+	networkClient, err := NewNetworkClient(
+		nil,
+		myNodeID,
+		1,
+		logging.NoLog{},
+		"",
+		prometheus.NewRegistry(),
+	)
+	require.NoError(err)
+
+	nodeID := ids.GenerateTestNodeID()
+	require.False(networkClient.IsConnected(nodeID))
+
+	require.NoError(networkClient.Connected(context.Background(), nodeID, version.CurrentApp))
+	require.True(networkClient.IsConnected(nodeID))
+
+	require.NoError(networkClient.Disconnected(context.Background(), nodeID))
+	require.False(networkClient.IsConnected(nodeID))
+
+	// This node is never registered as a peer
+	require.NoError(networkClient.Connected(context.Background(), myNodeID, version.CurrentApp))
+	require.False(networkClient.IsConnected(myNodeID))
+}
+
+func TestNetworkClientRequestPreferredFallback(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	var (
+		preferredNodeID = ids.GenerateTestNodeID()
+		fallbackNodeID  = ids.GenerateTestNodeID()
+
+		sender   = common.NewMockSender(ctrl)
+		response = []byte{1, 2, 3}
+	)
+
+	networkClient, err := NewNetworkClient(
+		sender,
+		ids.GenerateTestNodeID(),
+		1,
+		logging.NoLog{},
+		"",
+		prometheus.NewRegistry(),
+	)
+	require.NoError(err)
+
+	require.NoError(networkClient.Connected(context.Background(), preferredNodeID, version.CurrentApp))
+	require.NoError(networkClient.Connected(context.Background(), fallbackNodeID, version.CurrentApp))
+
+	sender.EXPECT().SendAppRequest(
+		gomock.Any(), // ctx
+		set.Of(preferredNodeID),
+		gomock.Any(), // requestID
+		gomock.Any(), // requestBytes
+	).DoAndReturn(
+		func(ctx context.Context, _ set.Set[ids.NodeID], requestID uint32, _ []byte) error {
+			// The client holds its lock while sending the request, so the
+			// failure must be delivered asynchronously.
+			go func() {
+				require.NoError(networkClient.AppRequestFailed(ctx, preferredNodeID, requestID))
+			}()
+			return nil
+		},
+	)
+	sender.EXPECT().SendAppRequest(
+		gomock.Any(), // ctx
+		set.Of(fallbackNodeID),
+		gomock.Any(), // requestID
+		gomock.Any(), // requestBytes
+	).DoAndReturn(
+		func(ctx context.Context, _ set.Set[ids.NodeID], requestID uint32, _ []byte) error {
+			go func() {
+				require.NoError(networkClient.AppResponse(ctx, fallbackNodeID, requestID, response))
+			}()
+			return nil
+		},
+	)
+
+	nodeID, gotResponse, err := networkClient.RequestPreferred(
+		context.Background(),
+		preferredNodeID,
+		version.CurrentApp,
+		nil,
+		0,
+		[]byte{0},
+	)
+	require.NoError(err)
+	require.Equal(fallbackNodeID, nodeID)
+	require.Equal(response, gotResponse)
+}
+
+func TestNetworkClientRequestPreferredVersionRange(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	var (
+		preferredNodeID = ids.GenerateTestNodeID()
+		fallbackNodeID  = ids.GenerateTestNodeID()
+
+		sender   = common.NewMockSender(ctrl)
+		response = []byte{1, 2, 3}
+	)
+
+	networkClient, err := NewNetworkClient(
+		sender,
+		ids.GenerateTestNodeID(),
+		1,
+		logging.NoLog{},
+		"",
+		prometheus.NewRegistry(),
+	)
+	require.NoError(err)
+
+	// The preferred peer is older than the minimum version
+	oldVersion := &version.Application{
+		Major: version.CurrentApp.Major - 1,
+	}
+	require.NoError(networkClient.Connected(context.Background(), preferredNodeID, oldVersion))
+	require.NoError(networkClient.Connected(context.Background(), fallbackNodeID, version.CurrentApp))
+
+	// The request must only be sent to the fallback peer
+	sender.EXPECT().SendAppRequest(
+		gomock.Any(), // ctx
+		set.Of(fallbackNodeID),
+		gomock.Any(), // requestID
+		gomock.Any(), // requestBytes
+	).DoAndReturn(
+		func(ctx context.Context, _ set.Set[ids.NodeID], requestID uint32, _ []byte) error {
+			go func() {
+				require.NoError(networkClient.AppResponse(ctx, fallbackNodeID, requestID, response))
+			}()
+			return nil
+		},
+	)
+
+	nodeID, gotResponse, err := networkClient.RequestPreferred(
+		context.Background(),
+		preferredNodeID,
+		version.CurrentApp,
+		nil,
+		0,
+		[]byte{0},
+	)
+	require.NoError(err)
+	require.Equal(fallbackNodeID, nodeID)
+	require.Equal(response, gotResponse)
+}
+
+func TestNetworkClientRequestPreferredBandwidth(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	var (
+		preferredNodeID = ids.GenerateTestNodeID()
+		fallbackNodeID  = ids.GenerateTestNodeID()
+
+		sender   = common.NewMockSender(ctrl)
+		response = []byte{1, 2, 3}
+	)
+
+	networkClientIntf, err := NewNetworkClient(
+		sender,
+		ids.GenerateTestNodeID(),
+		1,
+		logging.NoLog{},
+		"",
+		prometheus.NewRegistry(),
+	)
+	require.NoError(err)
+	networkClient := networkClientIntf.(*networkClient)
+
+	require.NoError(networkClient.Connected(context.Background(), preferredNodeID, version.CurrentApp))
+	require.NoError(networkClient.Connected(context.Background(), fallbackNodeID, version.CurrentApp))
+
+	// The preferred peer is too slow to deliver a MiB before the deadline
+	networkClient.peers.TrackBandwidth(preferredNodeID, units.KiB)
+	networkClient.peers.TrackBandwidth(fallbackNodeID, units.GiB)
+
+	// The request must only be sent to the fallback peer
+	sender.EXPECT().SendAppRequest(
+		gomock.Any(), // ctx
+		set.Of(fallbackNodeID),
+		gomock.Any(), // requestID
+		gomock.Any(), // requestBytes
+	).DoAndReturn(
+		func(ctx context.Context, _ set.Set[ids.NodeID], requestID uint32, _ []byte) error {
+			go func() {
+				require.NoError(networkClient.AppResponse(ctx, fallbackNodeID, requestID, response))
+			}()
+			return nil
+		},
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	nodeID, gotResponse, err := networkClient.RequestPreferred(
+		ctx,
+		preferredNodeID,
+		nil,
+		nil,
+		units.MiB,
+		[]byte{0},
+	)
+	require.NoError(err)
+	require.Equal(fallbackNodeID, nodeID)
+	require.Equal(response, gotResponse)
+}
+
+func TestNetworkClientRequestAnyVersionRange(t *testing.T) {
+	require := require.New(t)
+
+	networkClient, err := NewNetworkClient(
+		nil,
+		ids.GenerateTestNodeID(),
+		1,
+		logging.NoLog{},
+		"",
+		prometheus.NewRegistry(),
+	)
+	require.NoError(err)
+
+	require.NoError(networkClient.Connected(context.Background(), ids.GenerateTestNodeID(), version.CurrentApp))
+
+	// The only peer is newer than the maximum version
+	maxVersion := &version.Application{
+		Major: version.CurrentApp.Major - 1,
+	}
+	_, _, err = networkClient.RequestAny(
+		context.Background(),
+		nil,
+		maxVersion,
+		0,
+		[]byte{0},
+	)
+	require.ErrorIs(err, errNoPeersInVersionRange)
+}
+
+func TestNetworkClientOutstandingRequests(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	var (
+		nodeID   = ids.GenerateTestNodeID()
+		sender   = common.NewMockSender(ctrl)
+		response = []byte{1, 2, 3}
+	)
+
+	networkClient, err := NewNetworkClient(
+		sender,
+		ids.GenerateTestNodeID(),
+		1,
+		logging.NoLog{},
+		"",
+		prometheus.NewRegistry(),
+	)
+	require.NoError(err)
+	require.Empty(networkClient.OutstandingRequests())
+
+	// The peer never responds on its own
+	sender.EXPECT().SendAppRequest(
+		gomock.Any(), // ctx
+		set.Of(nodeID),
+		gomock.Any(), // requestID
+		gomock.Any(), // requestBytes
+	).Return(nil)
+
+	type result struct {
+		response []byte
+		err      error
+	}
+	results := make(chan result)
+	go func() {
+		response, err := networkClient.Request(context.Background(), nodeID, []byte{0})
+		results <- result{
+			response: response,
+			err:      err,
+		}
+	}()
+
+	var requests []OutstandingRequestInfo
+	require.Eventually(
+		func() bool {
+			requests = networkClient.OutstandingRequests()
+			return len(requests) == 1
+		},
+		time.Second,
+		time.Millisecond,
+	)
+	request := requests[0]
+	require.Equal(nodeID, request.NodeID)
+	require.GreaterOrEqual(request.Age, time.Duration(0))
+
+	require.NoError(networkClient.AppResponse(context.Background(), nodeID, request.RequestID, response))
+	res := <-results
+	require.NoError(res.err)
+	require.Equal(response, res.response)
+	require.Empty(networkClient.OutstandingRequests())
+}
+
+func TestRequiredBandwidth(t *testing.T) {
+	now := time.Now()
+	ctxWithDeadline, cancel := context.WithDeadline(context.Background(), now.Add(2*time.Second))
+	defer cancel()
+	expiredCtx, cancel := context.WithDeadline(context.Background(), now.Add(-time.Second))
+	defer cancel()
+
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		expectedSize int
+		expected     float64
+	}{
+		{
+			name:         "no deadline",
+			ctx:          context.Background(),
+			expectedSize: 1000,
+			expected:     0,
+		},
+		{
+			name:         "unknown size",
+			ctx:          ctxWithDeadline,
+			expectedSize: 0,
+			expected:     0,
+		},
+		{
+			name:         "expired deadline",
+			ctx:          expiredCtx,
+			expectedSize: 1000,
+			expected:     0,
+		},
+		{
+			name:         "size and deadline",
+			ctx:          ctxWithDeadline,
+			expectedSize: 1000,
+			expected:     500,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, requiredBandwidth(test.ctx, test.expectedSize, now))
+		})
+	}
+}
+
+func TestNetworkClientShutdownUnblocksWaiters(t *testing.T) {
+	require := require.New(t)
+
+	client, err := NewNetworkClient(
+		nil,
+		ids.GenerateTestNodeID(),
+		1,
+		logging.NoLog{},
+		"",
+		prometheus.NewRegistry(),
+	)
+	require.NoError(err)
+
+	// Take the only slot so that the request below blocks.
+	require.NoError(client.(*networkClient).activeRequests.Acquire(context.Background(), 1))
+
+	errs := make(chan error)
+	go func() {
+		_, _, err := client.RequestAny(
+			context.Background(),
+			nil,
+			nil,
+			0,
+			[]byte{0},
+		)
+		errs <- err
+	}()
+
+	client.Shutdown()
+	require.ErrorIs(<-errs, ErrShuttingDown)
+
+	// Requests made after shutting down fail immediately.
+	_, err = client.Request(context.Background(), ids.GenerateTestNodeID(), []byte{0})
+	require.ErrorIs(err, ErrShuttingDown)
+}
@@ -195,7 +195,7 @@ func (s *NetworkServer) HandleChangeProofRequest(
 	}
 
 	for keyLimit > 0 {
-		changeProof, err := s.db.GetChangeProof(ctx, startRoot, endRoot, start, end, int(keyLimit))
+		changeProof, err := s.db.GetChangeProof(ctx, startRoot, endRoot, start, end, int(keyLimit), merkledb.DefaultProofConfig)
 		if err != nil {
 			if !errors.Is(err, merkledb.ErrInsufficientHistory) {
 				return err
@@ -269,6 +269,8 @@ func (s *NetworkServer) HandleChangeProofRequest(
 }
 
 // Generates a range proof and sends it to [nodeID].
+// Each chunk of a streamed range proof is requested as an ordinary range
+// proof request, so no additional state is kept between chunks.
 // If [errAppSendFailed] is returned, this should be considered fatal.
 func (s *NetworkServer) HandleRangeProofRequest(
 	ctx context.Context,
@@ -343,6 +345,7 @@ func getRangeProof(
 			maybeBytesToMaybe(req.StartKey),
 			maybeBytesToMaybe(req.EndKey),
 			keyLimit,
+			merkledb.DefaultProofConfig,
 		)
 		if err != nil {
 			if errors.Is(err, merkledb.ErrInsufficientHistory) {
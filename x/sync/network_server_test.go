@@ -386,6 +386,7 @@ func TestAppRequestErrAppSendFailed(t *testing.T) {
 					gomock.Any(),
 					gomock.Any(),
 					gomock.Any(),
+					gomock.Any(),
 				).Return(&merkledb.ChangeProof{}, nil).Times(1)
 
 				return NewNetworkServer(sender, db, logging.NoLog{})
@@ -421,6 +422,7 @@ func TestAppRequestErrAppSendFailed(t *testing.T) {
 					gomock.Any(),
 					gomock.Any(),
 					gomock.Any(),
+					gomock.Any(),
 				).Return(&merkledb.RangeProof{}, nil).Times(1)
 
 				return NewNetworkServer(sender, db, logging.NoLog{})
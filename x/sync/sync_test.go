@@ -56,6 +56,7 @@ func newCallthroughSyncClient(ctrl *gomock.Controller, db merkledb.MerkleDB) *Mo
 				maybeBytesToMaybe(request.StartKey),
 				maybeBytesToMaybe(request.EndKey),
 				int(request.KeyLimit),
+				merkledb.DefaultProofConfig,
 			)
 			if err != nil {
 				return nil, err
@@ -846,6 +847,53 @@ func Test_Sync_Result_Correct_Root(t *testing.T) {
 	require.Equal(syncRoot, newRoot)
 }
 
+func Test_Sync_Progress(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now().UnixNano()
+	t.Logf("seed: %d", now)
+	r := rand.New(rand.NewSource(now)) // #nosec G404
+
+	const numKeys = 1000
+	dbToSync, err := generateTrie(t, r, numKeys)
+	require.NoError(err)
+	syncRoot, err := dbToSync.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	db, err := merkledb.New(
+		context.Background(),
+		memdb.New(),
+		newDefaultDBConfig(),
+	)
+	require.NoError(err)
+	syncer, err := NewManager(ManagerConfig{
+		DB:                    db,
+		Client:                newCallthroughSyncClient(ctrl, dbToSync),
+		TargetRoot:            syncRoot,
+		SimultaneousWorkLimit: 5,
+		Log:                   logging.NoLog{},
+		BranchFactor:          merkledb.BranchFactor16,
+	})
+	require.NoError(err)
+
+	require.Equal(SyncProgress{TargetRoot: syncRoot}, syncer.Progress())
+
+	require.NoError(syncer.Start(context.Background()))
+	require.NoError(syncer.Wait(context.Background()))
+
+	progress := syncer.Progress()
+	require.Equal(syncRoot, progress.TargetRoot)
+	require.GreaterOrEqual(progress.KeysSynced, uint64(numKeys))
+	require.Positive(progress.ProofsVerified)
+	require.InDelta(1, progress.EstimatedCompletion, 1e-9)
+
+	health, err := syncer.HealthCheck(context.Background())
+	require.NoError(err)
+	require.Equal(progress, health)
+}
+
 func Test_Sync_Result_Correct_Root_With_Sync_Restart(t *testing.T) {
 	require := require.New(t)
 	ctrl := gomock.NewController(t)
@@ -945,7 +993,7 @@ func Test_Sync_Error_During_Sync(t *testing.T) {
 			endRoot, err := ids.ToID(request.EndRootHash)
 			require.NoError(err)
 
-			changeProof, err := dbToSync.GetChangeProof(ctx, startRoot, endRoot, maybeBytesToMaybe(request.StartKey), maybeBytesToMaybe(request.EndKey), int(request.KeyLimit))
+			changeProof, err := dbToSync.GetChangeProof(ctx, startRoot, endRoot, maybeBytesToMaybe(request.StartKey), maybeBytesToMaybe(request.EndKey), int(request.KeyLimit), merkledb.DefaultProofConfig)
 			if err != nil {
 				return nil, err
 			}
@@ -1030,7 +1078,7 @@ func Test_Sync_Result_Correct_Root_Update_Root_During(t *testing.T) {
 			<-updatedRootChan
 			root, err := ids.ToID(request.RootHash)
 			require.NoError(err)
-			return dbToSync.GetRangeProofAtRoot(ctx, root, maybeBytesToMaybe(request.StartKey), maybeBytesToMaybe(request.EndKey), int(request.KeyLimit))
+			return dbToSync.GetRangeProofAtRoot(ctx, root, maybeBytesToMaybe(request.StartKey), maybeBytesToMaybe(request.EndKey), int(request.KeyLimit), merkledb.DefaultProofConfig)
 		},
 	).AnyTimes()
 	client.EXPECT().GetChangeProof(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
@@ -1043,7 +1091,7 @@ func Test_Sync_Result_Correct_Root_Update_Root_During(t *testing.T) {
 			endRoot, err := ids.ToID(request.EndRootHash)
 			require.NoError(err)
 
-			changeProof, err := dbToSync.GetChangeProof(ctx, startRoot, endRoot, maybeBytesToMaybe(request.StartKey), maybeBytesToMaybe(request.EndKey), int(request.KeyLimit))
+			changeProof, err := dbToSync.GetChangeProof(ctx, startRoot, endRoot, maybeBytesToMaybe(request.StartKey), maybeBytesToMaybe(request.EndKey), int(request.KeyLimit), merkledb.DefaultProofConfig)
 			if err != nil {
 				return nil, err
 			}
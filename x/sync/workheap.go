@@ -155,6 +155,17 @@ func (wh *workHeap) remove(item *workItem) {
 	wh.sortedItems.Delete(item)
 }
 
+// Returns the estimated fraction, in [0, 1], of the key space covered by the
+// ranges in the heap.
+func (wh *workHeap) keySpaceFraction() float64 {
+	var fraction float64
+	wh.sortedItems.Ascend(func(item *workItem) bool {
+		fraction += keyToFraction(item.end, 1) - keyToFraction(item.start, 0)
+		return true
+	})
+	return fraction
+}
+
 func (wh *workHeap) Len() int {
 	return wh.innerHeap.Len()
 }
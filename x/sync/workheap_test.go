@@ -309,3 +309,34 @@ func TestWorkHeapMergeInsertRandom(t *testing.T) {
 		}
 	}
 }
+
+func TestWorkHeapKeySpaceFraction(t *testing.T) {
+	require := require.New(t)
+
+	h := newWorkHeap()
+	require.Zero(h.keySpaceFraction())
+
+	// The first quarter of the key space
+	h.Insert(&workItem{
+		start:    maybe.Nothing[[]byte](),
+		end:      maybe.Some([]byte{0x40}),
+		priority: lowPriority,
+	})
+	require.InDelta(0.25, h.keySpaceFraction(), 1e-9)
+
+	// The last half of the key space
+	h.Insert(&workItem{
+		start:    maybe.Some([]byte{0x80}),
+		end:      maybe.Nothing[[]byte](),
+		priority: lowPriority,
+	})
+	require.InDelta(0.75, h.keySpaceFraction(), 1e-9)
+
+	// The remaining quarter of the key space
+	h.Insert(&workItem{
+		start:    maybe.Some([]byte{0x40}),
+		end:      maybe.Some([]byte{0x80}),
+		priority: lowPriority,
+	})
+	require.InDelta(1, h.keySpaceFraction(), 1e-9)
+}